@@ -4,6 +4,10 @@ package ast
 type Assert struct {
 	Expr *Binary
 	Pos  string
+
+	// Message is optional, like `assert(x == y, "totals must match")`. It is
+	// shown when the assertion fails.
+	Message Node
 }
 
 // Position returns the position.
@@ -16,6 +20,9 @@ type AssertRaise struct {
 	Call        *Call
 	TypeOrValue Node
 	Pos         string
+
+	// Message is optional, see Assert.
+	Message Node
 }
 
 // Position returns the position.
@@ -29,6 +29,7 @@ func cmpOptions() cmp.Options {
 		cmpopts.IgnoreFields(ast.Func{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Func{}, "UniqueName"),
 		cmpopts.IgnoreFields(ast.Group{}, "Pos"),
+		cmpopts.IgnoreFields(ast.Hook{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Identifier{}, "Pos"),
 		cmpopts.IgnoreFields(ast.If{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Interpolate{}, "Pos"),
@@ -39,6 +40,9 @@ func cmpOptions() cmp.Options {
 		cmpopts.IgnoreFields(ast.On{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Raise{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Return{}, "Pos"),
+		cmpopts.IgnoreFields(ast.SelectCase{}, "Pos"),
+		cmpopts.IgnoreFields(ast.Select{}, "Pos"),
+		cmpopts.IgnoreFields(ast.Spawn{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Switch{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Test{}, "Pos"),
 		cmpopts.IgnoreFields(ast.Unary{}, "Pos"),
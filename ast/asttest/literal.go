@@ -39,6 +39,13 @@ func NewLiteralBool(b bool) *ast.Literal {
 	}
 }
 
+// NewLiteralNil create a new literal representing nil.
+func NewLiteralNil() *ast.Literal {
+	return &ast.Literal{
+		Kind: types.Nil,
+	}
+}
+
 // NewLiteralChar create a new literal representing a character value.
 func NewLiteralChar(c rune) *ast.Literal {
 	return &ast.Literal{
@@ -1,10 +1,17 @@
 package ast
 
+import "github.com/elliotchance/ok/types"
+
 // Call represents a function call with zero or more arguments.
 type Call struct {
 	// Expr is the expression that returns the function to be called.
 	Expr Node
 
+	// TypeArguments are the explicit type arguments for a generic function,
+	// such as "number" in "Stack[number]()". They are usually not needed
+	// because they are inferred from the arguments.
+	TypeArguments []*types.Type
+
 	// Arguments contains zero or more elements that represent each of the
 	// arguments respectively.
 	Arguments []Node
@@ -0,0 +1,25 @@
+package ast
+
+import "github.com/elliotchance/ok/types"
+
+// Enum declares a type that has a fixed set of values, like:
+//
+//	enum Level { Debug, Info, Warn }
+type Enum struct {
+	Name string
+
+	// Values will always contain at least one element.
+	Values []string
+
+	Pos string
+}
+
+// Position returns the position.
+func (node *Enum) Position() string {
+	return node.Pos
+}
+
+// Type returns the type that is declared by the enum.
+func (node *Enum) Type() *types.Type {
+	return types.NewEnum(node.Name, node.Values)
+}
@@ -26,6 +26,10 @@ type Func struct {
 	// referenced by the VM.
 	UniqueName string
 
+	// TypeParameters contains the names of the type parameters of a generic
+	// function, such as "T" and "U" in "func Map[T, U](items []T) []U".
+	TypeParameters []string
+
 	// Arguments may contain zero or more elements. They will always be in the
 	// order in which their are declared.
 	Arguments []*Argument
@@ -99,6 +103,10 @@ func (f *Func) String() string {
 		prefix += " " + f.Name
 	}
 
+	if len(f.TypeParameters) > 0 {
+		prefix += "[" + strings.Join(f.TypeParameters, ", ") + "]"
+	}
+
 	return prefix + "(" + strings.Join(args, ", ") + ")" + returnSignature
 }
 
@@ -116,13 +124,23 @@ func (f *Func) Type() *types.Type {
 			panic(err)
 		}
 
-		returns = []*types.Type{types.NewInterface(f.Name, iface)}
+		ty := types.NewInterface(f.Name, iface)
+		for _, name := range f.TypeParameters {
+			ty.TypeArguments = append(ty.TypeArguments,
+				types.NewTypeParameter(name))
+		}
+
+		returns = []*types.Type{ty}
 	} else {
 		for _, r := range f.Returns {
 			returns = append(returns, r)
 		}
 	}
 
+	if len(f.TypeParameters) > 0 {
+		return types.NewFunc(args, returns).Generic(f.TypeParameters)
+	}
+
 	return types.NewFunc(args, returns)
 }
 
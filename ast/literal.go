@@ -29,6 +29,9 @@ type Literal struct {
 	// placement.
 	Reader *bufio.Reader
 
+	// Channel is used for channels. It is only created and used by the VM.
+	Channel interface{}
+
 	IsGlobal bool
 }
 
@@ -68,5 +71,7 @@ func (node *Literal) Copy() *Literal {
 		Array: node.Array,
 		Map:   node.Map,
 		Pos:   node.Pos,
+
+		Channel: node.Channel,
 	}
 }
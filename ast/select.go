@@ -0,0 +1,36 @@
+package ast
+
+// SelectCase represents a case of a select statement.
+type SelectCase struct {
+	// Op is the channel operation. It is a call to Send or Receive on a
+	// channel, or an assignment of a single variable from a call to Receive.
+	Op Node
+
+	// Statements may be nil.
+	Statements []Node
+
+	Pos string
+}
+
+// Position returns the position.
+func (node *SelectCase) Position() string {
+	return node.Pos
+}
+
+// Select represents a select statement. It waits until one of the channel
+// operations of the cases can be performed.
+type Select struct {
+	// Cases may be nil.
+	Cases []*SelectCase
+
+	// Else will be nil when there is no else. When there is an else the
+	// select does not wait.
+	Else []Node
+
+	Pos string
+}
+
+// Position returns the position.
+func (node *Select) Position() string {
+	return node.Pos
+}
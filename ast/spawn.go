@@ -0,0 +1,13 @@
+package ast
+
+// Spawn runs a function call as a new task. The arguments are evaluated before
+// the task starts.
+type Spawn struct {
+	Call *Call
+	Pos  string
+}
+
+// Position returns the position.
+func (node *Spawn) Position() string {
+	return node.Pos
+}
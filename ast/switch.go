@@ -24,7 +24,8 @@ type Switch struct {
 	// Cases may be nil.
 	Cases []*Case
 
-	// Else may be nil.
+	// Else will be nil when there is no else. An else without any statements
+	// is an empty slice.
 	Else []Node
 
 	Pos string
@@ -1,13 +1,52 @@
 package ast
 
-// Test is a named test.
+// Test is a named test. A benchmark is declared in the same way, but with
+// "bench" instead of "test". A fuzz target also has arguments, like:
+//
+//	fuzz "reverse"(s string) { ... }
 type Test struct {
 	Name       string
 	Statements []Node
 	Pos        string
+
+	// IsBench is true for a benchmark. Benchmarks only run with "ok test
+	// -bench".
+	IsBench bool
+
+	// Cases is set for a table test, like:
+	//
+	//   test "parse" for c, name in cases { ... }
+	//
+	// The statements run once for each element, and each one is reported as a
+	// separate test named "parse/" followed by the key.
+	Cases *In
+
+	// IsFuzz is true for a fuzz target. The Arguments are generated by "ok
+	// test -fuzz", or replayed from the corpus by "ok test".
+	IsFuzz    bool
+	Arguments []*Argument
 }
 
 // Position returns the position.
 func (node *Test) Position() string {
 	return node.Pos
 }
+
+// Hook is a setup or teardown block. All of the setup blocks of a package run
+// before each test (and each run of a benchmark), and all of the teardown
+// blocks run after. Variables assigned in a setup block can be used by the
+// tests and teardown blocks.
+type Hook struct {
+	// IsTeardown is false for a setup block.
+	IsTeardown bool
+
+	// Statements may be nil.
+	Statements []Node
+
+	Pos string
+}
+
+// Position returns the position.
+func (node *Hook) Position() string {
+	return node.Pos
+}
@@ -1,7 +1,9 @@
 package asm
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"sort"
 
@@ -10,7 +12,11 @@ import (
 	"github.com/elliotchance/ok/vm"
 )
 
-type Command struct{}
+type Command struct {
+	// OKC is the path of an okc file to disassemble instead of compiling a
+	// package. See vm.Store.
+	OKC string
+}
 
 func check(err error) {
 	if err != nil {
@@ -24,7 +30,33 @@ func (*Command) Description() string {
 }
 
 // Run is the entry point for the "ok asm" command.
-func (*Command) Run(args []string) {
+func (c *Command) Run(args []string) {
+	flag.StringVar(&c.OKC, "okc", "", "disassemble an okc file")
+	check(flag.CommandLine.Parse(args))
+	args = flag.Args()
+
+	if c.OKC != "" {
+		// Everything is shown by default, including tests.
+		if len(args) < 1 {
+			args = []string{"*"}
+		}
+
+		data, err := ioutil.ReadFile(c.OKC)
+		check(err)
+
+		pkg := new(vm.File)
+		check(pkg.UnmarshalBinary(data))
+		printSymbols(pkg, args)
+
+		for _, test := range pkg.Tests {
+			if matchesAny(test.TestName, args) {
+				printTest(test)
+			}
+		}
+
+		return
+	}
+
 	if len(args) < 1 {
 		args = []string{"."}
 	}
@@ -44,9 +76,24 @@ func (*Command) Run(args []string) {
 		&anonFunctionName, false)
 	util.CheckErrorsWithExit(errs)
 
+	printSymbols(pkg, args[1:])
+}
+
+func matchesAny(s string, globs []string) bool {
+	for _, glob := range globs {
+		if util.MatchesGlob(s, glob) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printSymbols prints the functions and values that match any of the globs.
+func printSymbols(pkg *vm.File, globs []string) {
 	// Create a map as a function may match more than one glob.
 	funcsToPrint := map[vm.SymbolRegister]struct{}{}
-	for _, glob := range args[1:] {
+	for _, glob := range globs {
 		for symbolRegister, symbol := range pkg.Symbols {
 			if symbol.Func != nil &&
 				(util.MatchesGlob(symbol.Func.UniqueName, glob) ||
@@ -86,13 +133,37 @@ func (*Command) Run(args []string) {
 			fmt.Printf("%s (symbol=%s, name=%s, unique=%s):\n",
 				pkg.Types.Get(string(symbol.Type)).String(), symbolRegister,
 				symbol.Func.Name, symbol.Func.UniqueName)
+			printInstructions(symbol.Func.Instructions)
+		}
+	}
+}
 
-			for i, ins := range symbol.Func.Instructions.Instructions {
-				ty := fmt.Sprintf("%T", ins)[4:]
+// printTest prints the instructions of a test, followed by any setup, teardown
+// and cases.
+func printTest(test *vm.CompiledTest) {
+	fmt.Printf("\ntest %q:\n", test.TestName)
+	printInstructions(test.Instructions)
 
-				// "-22" is chosen here because is is the longer instruction name.
-				fmt.Printf("  %3d %-22s # %s\n", i+1, ty, ins)
-			}
+	for _, set := range []struct {
+		name         string
+		instructions *vm.Instructions
+	}{
+		{"setup", test.Setup},
+		{"teardown", test.Teardown},
+		{"cases", test.Cases},
+	} {
+		if set.instructions != nil {
+			fmt.Printf("%s:\n", set.name)
+			printInstructions(set.instructions)
 		}
 	}
 }
+
+func printInstructions(instructions *vm.Instructions) {
+	for i, ins := range instructions.Instructions {
+		ty := fmt.Sprintf("%T", ins)[4:]
+
+		// "-22" is chosen here because is is the longer instruction name.
+		fmt.Printf("  %3d %-22s # %s\n", i+1, ty, ins)
+	}
+}
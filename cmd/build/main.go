@@ -3,34 +3,63 @@ package build
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/elliotchance/ok/compiler"
 	"github.com/elliotchance/ok/util"
-	"github.com/elliotchance/ok/vm"
 )
 
+// okModule is the Go module that the generated program needs to import to be
+// able to run the VM.
+const okModule = "github.com/elliotchance/ok"
+
 func check(err error) {
 	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
-type Command struct{}
+type Command struct {
+	// Compile will stop after compiling, without producing an executable.
+	Compile bool
+
+	// Verbose will show the compiled packages as well as the commands used to
+	// build the executable.
+	Verbose bool
+
+	// Output is the file path of the executable. If it is empty the name of
+	// the package directory will be used.
+	Output string
+
+	// GOOS and GOARCH are passed to "go build" for cross compiling. If they
+	// are empty the environment (or host) values will be used.
+	GOOS, GOARCH string
+}
 
 // Description is shown in "ok -help".
 func (*Command) Description() string {
 	return "compile a program"
 }
 
-// Run is the entry point for the "ok run" command.
-func (*Command) Run(args []string) {
-	var flagCompile, flagVerbose bool
-	flag.BoolVar(&flagCompile, "c", false, "compile only")
-	flag.BoolVar(&flagVerbose, "v", false, "verbose output")
+// Run is the entry point for the "ok build" command.
+func (c *Command) Run(args []string) {
+	flag.BoolVar(&c.Compile, "c", false, "compile only")
+	flag.BoolVar(&c.Verbose, "v", false, "verbose output")
+	flag.StringVar(&c.Output, "o", "",
+		"output file, only allowed when building a single package")
+	flag.StringVar(&c.GOOS, "goos", os.Getenv("GOOS"),
+		"target operating system")
+	flag.StringVar(&c.GOARCH, "goarch", os.Getenv("GOARCH"),
+		"target architecture")
 	check(flag.CommandLine.Parse(args))
 	args = flag.Args()
 
@@ -38,12 +67,16 @@ func (*Command) Run(args []string) {
 		args = []string{"."}
 	}
 
+	if c.Output != "" && len(args) > 1 {
+		log.Fatalln("-o cannot be used with multiple packages")
+	}
+
 	for _, arg := range args {
-		runArg(arg, flagCompile, flagVerbose)
+		c.runArg(arg)
 	}
 }
 
-func runArg(arg string, flagCompile, flagVerbose bool) {
+func (c *Command) runArg(arg string) {
 	okPath, err := util.OKPath()
 	check(err)
 
@@ -52,46 +85,168 @@ func runArg(arg string, flagCompile, flagVerbose bool) {
 		packageName = "."
 	}
 	anonFunctionName := 0
-	file, _, errs := compiler.Compile(okPath, packageName, false,
-		&anonFunctionName, flagVerbose)
+	file, packageType, errs := compiler.Compile(okPath, packageName, false,
+		&anonFunctionName, c.Verbose)
 	util.CheckErrorsWithExit(errs)
 
-	if flagCompile {
+	if c.Compile {
 		return
 	}
 
-	goFile := path.Join(arg, "main.go")
-	f, err := os.Create(goFile)
+	okc, err := file.MarshalBinary()
+	check(err)
+
+	output, err := filepath.Abs(c.outputPath(arg))
 	check(err)
 
-	defer os.Remove(goFile)
-
-	fmt.Fprintf(f, "package main\n\n")
-	fmt.Fprintf(f, "import \"github.com/elliotchance/ok/ast\"\n")
-	fmt.Fprintf(f, "import \"github.com/elliotchance/ok/vm\"\n\n")
-	fmt.Fprintf(f, "func main() {\n")
-	fmt.Fprintf(f, "\tfile := ")
-	vm.Render(f, file, "\t", false)
-	fmt.Fprintf(f, "\n")
-	fmt.Fprintf(f, "\tm := vm.NewVM(nil, nil, nil, \"\")\n")
-	fmt.Fprintf(f, "\tif err := m.LoadFile(\"\", file); err != nil {\n")
-	fmt.Fprintf(f, "\t\tpanic(err)\n")
-	fmt.Fprintf(f, "\t}\n")
-	fmt.Fprintf(f, "\tif err := m.Run(); err != nil {\n")
-	fmt.Fprintf(f, "\t\tpanic(err)\n")
-	fmt.Fprintf(f, "\t}\n")
-	fmt.Fprintf(f, "}\n")
-	fmt.Fprintf(f, "\n")
+	// The Go program is generated in a temporary directory so that nothing is
+	// written to the package directory and the build does not depend on the
+	// module the user happens to be in.
+	buildDir, err := ioutil.TempDir("", "ok-build")
+	check(err)
+	defer os.RemoveAll(buildDir)
+
+	goMod, err := goModFile()
+	check(err)
+
+	err = ioutil.WriteFile(path.Join(buildDir, "go.mod"), []byte(goMod), 0644)
+	check(err)
+
+	mainGo := mainFile(string(okc), "$"+packageType.Name)
+	err = ioutil.WriteFile(path.Join(buildDir, "main.go"), []byte(mainGo), 0644)
+	check(err)
 
 	goExecutable, err := exec.LookPath("go")
 	check(err)
 
-	goBuild := &exec.Cmd{
-		Path:   goExecutable,
-		Args:   []string{goExecutable, "build", "./" + arg},
-		Stdout: os.Stdout,
-		Stderr: os.Stdout,
+	c.goCommand(buildDir, goExecutable, "mod", "tidy")
+	c.goCommand(buildDir, goExecutable, "build", "-o", output, ".")
+}
+
+// outputPath follows the same rules as "go build": the executable is named
+// after the package directory and placed in the current directory.
+func (c *Command) outputPath(arg string) string {
+	if c.Output != "" {
+		return c.Output
+	}
+
+	absArg, err := filepath.Abs(arg)
+	check(err)
+
+	output := filepath.Base(absArg)
+	if c.goos() == "windows" {
+		output += ".exe"
+	}
+
+	return output
+}
+
+func (c *Command) goos() string {
+	if c.GOOS != "" {
+		return c.GOOS
+	}
+
+	return runtime.GOOS
+}
+
+func (c *Command) goCommand(dir, goExecutable string, args ...string) {
+	cmd := exec.Command(goExecutable, args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "GO111MODULE=on")
+	if c.GOOS != "" {
+		cmd.Env = append(cmd.Env, "GOOS="+c.GOOS)
+	}
+	if c.GOARCH != "" {
+		cmd.Env = append(cmd.Env, "GOARCH="+c.GOARCH)
+	}
+
+	if c.Verbose {
+		fmt.Println("go", strings.Join(args, " "))
+	}
+
+	check(cmd.Run())
+}
+
+// mainFile returns the source of the Go program that will run the compiled
+// file. The okc file is embedded exactly how it would be stored by vm.Store.
+func mainFile(okc, mainPackage string) string {
+	return fmt.Sprintf(`package main
+
+import (
+	"log"
+
+	"github.com/elliotchance/ok/vm"
+)
+
+const okc = %s
+
+func main() {
+	file := new(vm.File)
+	if err := file.UnmarshalBinary([]byte(okc)); err != nil {
+		log.Fatalln(err)
+	}
+
+	m := vm.NewVM("no-package")
+	if err := m.LoadFile(file); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := m.Run(%s); err != nil {
+		log.Fatalln(err)
+	}
+}
+`, strconv.Quote(okc), strconv.Quote(mainPackage))
+}
+
+// goModFile returns the go.mod for the generated program. The local ok source
+// is preferred when it can be found (see okSourceDir), otherwise the ok module
+// version this binary was built with is downloaded.
+func goModFile() (string, error) {
+	goMod := "module main\n\n"
+
+	if sourceDir := okSourceDir(); sourceDir != "" {
+		goMod += fmt.Sprintf("require %s v0.0.0\n\n", okModule)
+		goMod += fmt.Sprintf("replace %s => %s\n", okModule, sourceDir)
+
+		return goMod, nil
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path != okModule || info.Main.Version == "(devel)" {
+		return "", fmt.Errorf("cannot find the ok source, set $OKROOT")
+	}
+
+	goMod += fmt.Sprintf("require %s %s\n", okModule, info.Main.Version)
+
+	return goMod, nil
+}
+
+// okSourceDir locates the root of the ok source. It can be provided with
+// $OKROOT, otherwise the directory this file was compiled from is used if it
+// still exists. An empty string is returned if the source cannot be found.
+func okSourceDir() string {
+	dir := os.Getenv("OKROOT")
+	if dir == "" {
+		_, fileName, _, ok := runtime.Caller(0)
+		if !ok {
+			return ""
+		}
+
+		// This file is in cmd/build.
+		dir = filepath.Dir(filepath.Dir(filepath.Dir(fileName)))
+	}
+
+	goMod, err := ioutil.ReadFile(path.Join(dir, "go.mod"))
+	if err != nil || !strings.Contains(string(goMod), "module "+okModule+"\n") {
+		return ""
+	}
+
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return ""
 	}
 
-	check(goBuild.Run())
+	return dir
 }
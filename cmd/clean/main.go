@@ -0,0 +1,41 @@
+package clean
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/elliotchance/ok/vm"
+)
+
+type Command struct {
+	// Verbose will print each file that is removed.
+	Verbose bool
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// Description is shown in "ok -help".
+func (*Command) Description() string {
+	return "remove compiled files"
+}
+
+// Run is the entry point for the "ok clean" command. Only the compiled files
+// are removed from the compile cache, see vm.Directory and vm.Clean.
+func (c *Command) Run(args []string) {
+	flag.BoolVar(&c.Verbose, "v", false, "verbose output")
+	check(flag.CommandLine.Parse(args))
+
+	removed, err := vm.Clean()
+	if c.Verbose {
+		for _, path := range removed {
+			fmt.Println("rm", path)
+		}
+	}
+
+	check(err)
+}
@@ -0,0 +1,76 @@
+package debug
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/elliotchance/ok/debugger"
+	"github.com/elliotchance/ok/util"
+)
+
+type Command struct {
+	// DAP will use the Debug Adapter Protocol on stdin and stdout instead of
+	// the interactive console.
+	DAP bool
+
+	// Breakpoints can be set before the program starts.
+	Breakpoints breakpoints
+}
+
+type breakpoints []string
+
+func (b *breakpoints) String() string {
+	return ""
+}
+
+func (b *breakpoints) Set(value string) error {
+	*b = append(*b, value)
+
+	return nil
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// Description is shown in "ok -help".
+func (*Command) Description() string {
+	return "debug ok program"
+}
+
+// Run is the entry point for the "ok debug" command.
+func (c *Command) Run(args []string) {
+	flag.BoolVar(&c.DAP, "dap", false,
+		"use the Debug Adapter Protocol on stdin and stdout")
+	flag.Var(&c.Breakpoints, "b",
+		"add a breakpoint, file:line or function name (may be repeated)")
+	check(flag.CommandLine.Parse(args))
+	args = flag.Args()
+
+	okPath, err := util.OKPath()
+	check(err)
+
+	// The program to debug is provided by the "launch" request.
+	if c.DAP {
+		check(debugger.NewDAPServer(os.Stdin, os.Stdout, okPath).Serve())
+		return
+	}
+
+	arg := "."
+	if len(args) > 0 {
+		arg = args[0]
+	}
+
+	m, run, err := debugger.Load(okPath, arg, os.Stdout)
+	check(err)
+
+	controller := debugger.NewController(m, len(c.Breakpoints) == 0)
+	for _, breakpoint := range c.Breakpoints {
+		check(controller.SetBreakpoint(breakpoint))
+	}
+
+	check(debugger.NewConsole(controller, os.Stdin, os.Stdout).Run(run))
+}
@@ -6,9 +6,11 @@ import (
 	"os"
 	"path"
 	"sort"
+	"strings"
 
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/util"
 )
 
@@ -108,7 +110,12 @@ func (*Command) Run(args []string) {
 				}
 
 				fmt.Println("```")
-				fmt.Printf("%s = %s\n", name, constants[name].Value)
+				if ty := constants[name].Kind; ty.Kind == types.KindEnum {
+					fmt.Printf("enum %s { %s }\n", name,
+						strings.Join(ty.Values, ", "))
+				} else {
+					fmt.Printf("%s = %s\n", name, constants[name].Value)
+				}
 				fmt.Println("```")
 				fmt.Println()
 			}
@@ -0,0 +1,130 @@
+package format
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/elliotchance/ok/formatter"
+	"github.com/elliotchance/ok/util"
+)
+
+type Command struct {
+	// List will print the names of files that are not formatted instead of
+	// rewriting them.
+	List bool
+
+	// Diff will print the changes that formatting would make instead of
+	// rewriting the files.
+	Diff bool
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// Description is shown in "ok -help".
+func (*Command) Description() string {
+	return "format source code"
+}
+
+// Run is the entry point for the "ok fmt" command.
+func (c *Command) Run(args []string) {
+	flag.BoolVar(&c.List, "l", false, "list files whose formatting differs")
+	flag.BoolVar(&c.Diff, "d", false, "display diffs instead of rewriting files")
+	check(flag.CommandLine.Parse(args))
+	args = flag.Args()
+
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	failed := false
+	for _, arg := range args {
+		fileNames, err := sourceFiles(arg)
+		check(err)
+
+		for _, fileName := range fileNames {
+			if !c.formatFile(fileName) {
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// formatFile returns false if the file could not be formatted.
+func (c *Command) formatFile(fileName string) bool {
+	data, err := ioutil.ReadFile(fileName)
+	check(err)
+
+	source := string(data)
+	formatted, errs := formatter.Format(source, fileName)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		return false
+	}
+
+	if formatted == source {
+		return true
+	}
+
+	if c.List {
+		fmt.Println(fileName)
+	}
+
+	if c.Diff {
+		fmt.Print(util.Diff(fileName+".orig", fileName, source, formatted))
+	}
+
+	if !c.List && !c.Diff {
+		info, err := os.Stat(fileName)
+		check(err)
+
+		err = ioutil.WriteFile(fileName, []byte(formatted), info.Mode())
+		check(err)
+	}
+
+	return true
+}
+
+// sourceFiles returns the path itself if it is a file. Otherwise it will
+// return all of the ".ok" and ".okt" files in the directory, including
+// subdirectories.
+func sourceFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var fileNames []string
+	err = filepath.Walk(path, func(fileName string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(fileName)
+		if !info.IsDir() && (ext == ".ok" || ext == ".okt") {
+			fileNames = append(fileNames, fileName)
+		}
+
+		return nil
+	})
+
+	return fileNames, err
+}
@@ -0,0 +1,29 @@
+package lsp
+
+import (
+	"log"
+	"os"
+
+	"github.com/elliotchance/ok/lsp"
+)
+
+type Command struct{}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// Description is shown in "ok -help".
+func (*Command) Description() string {
+	return "run the language server"
+}
+
+// Run is the entry point for the "ok lsp" command.
+func (*Command) Run(args []string) {
+	// The root of the workspace provided by the editor will be used unless
+	// $OKPATH is set.
+	server := lsp.NewServer(os.Stdin, os.Stdout, os.Getenv("OKPATH"))
+	check(server.Serve())
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"hash/crc32"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// fieldCodecs contains how to encode and decode each type of field that can be
+// used in an instruction. The "%s" is replaced with the field.
+var fieldCodecs = map[string]struct{ encode, decode string }{
+	"Register":       {"e.string(string(%s))", "Register(d.string())"},
+	"Registers":      {"e.registers(%s)", "d.registers()"},
+	"SymbolRegister": {"e.string(string(%s))", "SymbolRegister(d.string())"},
+	"TypeRegister":   {"e.string(string(%s))", "TypeRegister(d.string())"},
+	"bool":           {"e.bool(%s)", "d.bool()"},
+	"int":            {"e.int(%s)", "d.int()"},
+	"string":         {"e.string(%s)", "d.string()"},
+}
+
+type field struct {
+	name, typ string
+}
+
+type instruction struct {
+	name   string
+	fields []field
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func main() {
+	instructions := parseInstructions("vm")
+
+	// The checksum describes every instruction and its fields, so that an
+	// okc file is never decoded with different opcodes.
+	var description strings.Builder
+	for _, ins := range instructions {
+		description.WriteString(ins.name)
+		for _, f := range ins.fields {
+			fmt.Fprintf(&description, " %s:%s", f.name, f.typ)
+		}
+		description.WriteString("\n")
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by ops-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package vm\n\n")
+	fmt.Fprintf(&out, "import \"fmt\"\n\n")
+
+	fmt.Fprintf(&out, "// opcodesChecksum changes when any instruction is added, removed or has\n")
+	fmt.Fprintf(&out, "// its fields changed. It is stored in the header of okc files.\n")
+	fmt.Fprintf(&out, "const opcodesChecksum = 0x%08x\n\n",
+		crc32.ChecksumIEEE([]byte(description.String())))
+
+	fmt.Fprintf(&out, "const (\n")
+	for i, ins := range instructions {
+		if i == 0 {
+			fmt.Fprintf(&out, "\top%s = iota + 1\n", ins.name)
+		} else {
+			fmt.Fprintf(&out, "\top%s\n", ins.name)
+		}
+	}
+	fmt.Fprintf(&out, ")\n\n")
+
+	fmt.Fprintf(&out, "func encodeInstruction(e *encoder, ins Instruction) {\n")
+	fmt.Fprintf(&out, "\tswitch ins := ins.(type) {\n")
+	for _, ins := range instructions {
+		fmt.Fprintf(&out, "\tcase *%s:\n", ins.name)
+		fmt.Fprintf(&out, "\t\te.uvarint(op%s)\n", ins.name)
+		for _, f := range ins.fields {
+			fmt.Fprintf(&out, "\t\t"+fieldCodecs[f.typ].encode+"\n",
+				"ins."+f.name)
+		}
+		fmt.Fprintf(&out, "\n")
+	}
+	fmt.Fprintf(&out, "\tdefault:\n")
+	fmt.Fprintf(&out, "\t\te.fail(fmt.Errorf(\"cannot encode instruction %%T\", ins))\n")
+	fmt.Fprintf(&out, "\t}\n")
+	fmt.Fprintf(&out, "}\n\n")
+
+	fmt.Fprintf(&out, "func decodeInstruction(d *decoder) Instruction {\n")
+	fmt.Fprintf(&out, "\tswitch op := d.uvarint(); op {\n")
+	for _, ins := range instructions {
+		fmt.Fprintf(&out, "\tcase op%s:\n", ins.name)
+		if len(ins.fields) == 0 {
+			fmt.Fprintf(&out, "\t\treturn &%s{}\n\n", ins.name)
+			continue
+		}
+
+		fmt.Fprintf(&out, "\t\treturn &%s{\n", ins.name)
+		for _, f := range ins.fields {
+			fmt.Fprintf(&out, "\t\t\t%s: "+fieldCodecs[f.typ].decode+",\n",
+				f.name)
+		}
+		fmt.Fprintf(&out, "\t\t}\n\n")
+	}
+	fmt.Fprintf(&out, "\tdefault:\n")
+	fmt.Fprintf(&out, "\t\td.fail(fmt.Errorf(\"unknown opcode %%d\", op))\n\n")
+	fmt.Fprintf(&out, "\t\treturn nil\n")
+	fmt.Fprintf(&out, "\t}\n")
+	fmt.Fprintf(&out, "}\n")
+
+	source, err := format.Source(out.Bytes())
+	check(err)
+
+	check(ioutil.WriteFile("vm/ops.go", source, 0644))
+}
+
+// parseInstructions finds all of the instructions in the package directory.
+// An instruction is any struct that has an Execute method. The instructions are
+// sorted by name.
+func parseInstructions(dir string) []*instruction {
+	notTest := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, notTest, 0)
+	check(err)
+
+	structs := map[string]*ast.StructType{}
+	executes := map[string]bool{}
+	for _, file := range pkgs["vm"].Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						if s, ok := spec.Type.(*ast.StructType); ok {
+							structs[spec.Name.Name] = s
+						}
+					}
+				}
+
+			case *ast.FuncDecl:
+				if decl.Name.Name == "Execute" && decl.Recv != nil {
+					if star, ok := decl.Recv.List[0].Type.(*ast.StarExpr); ok {
+						executes[star.X.(*ast.Ident).Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	var instructions []*instruction
+	for name := range executes {
+		ins := &instruction{name: name}
+		for _, f := range structs[name].Fields.List {
+			typ := typeName(f.Type)
+			if _, ok := fieldCodecs[typ]; !ok {
+				log.Fatalf("%s: cannot encode field of type %s", name, typ)
+			}
+
+			for _, fieldName := range f.Names {
+				ins.fields = append(ins.fields, field{fieldName.Name, typ})
+			}
+		}
+
+		instructions = append(instructions, ins)
+	}
+
+	sort.Slice(instructions, func(i, j int) bool {
+		return instructions[i].name < instructions[j].name
+	})
+
+	return instructions
+}
+
+func typeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+
+	case *ast.ArrayType:
+		return "[]" + typeName(expr.Elt)
+	}
+
+	return fmt.Sprintf("%T", expr)
+}
@@ -0,0 +1,103 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/util"
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = "... "
+)
+
+type Command struct{}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// Description is shown in "ok -help".
+func (*Command) Description() string {
+	return "interactive interpreter"
+}
+
+// Run is the entry point for the "ok repl" command.
+func (*Command) Run(args []string) {
+	okPath, err := util.OKPath()
+	check(err)
+
+	s := newSession(okPath, os.Stdout)
+
+	fmt.Println(`Type ":asm" to show the instructions of the last input or ":quit" to exit.`)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	input := ""
+	fmt.Print(prompt)
+	for scanner.Scan() {
+		input += scanner.Text() + "\n"
+
+		if needsMoreInput(input) {
+			fmt.Print(continuationPrompt)
+			continue
+		}
+
+		switch strings.TrimSpace(input) {
+		case "":
+
+		case ":quit":
+			return
+
+		case ":asm":
+			s.printInstructions()
+
+		default:
+			for _, err := range s.eval(input) {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+
+		input = ""
+		fmt.Print(prompt)
+	}
+	check(scanner.Err())
+
+	// The input may have ended in the middle of a block.
+	fmt.Println()
+	if strings.TrimSpace(input) != "" {
+		for _, err := range s.eval(input) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+// needsMoreInput returns true when there are brackets that have not been
+// closed, such as the start of a block.
+func needsMoreInput(input string) bool {
+	tokens, _, err := lexer.TokenizeString(input, lexer.Options{}, "repl")
+	if err != nil {
+		// Let the parser report the problem.
+		return false
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		switch token.Kind {
+		case lexer.TokenCurlyOpen, lexer.TokenParenOpen, lexer.TokenSquareOpen:
+			depth++
+
+		case lexer.TokenCurlyClose, lexer.TokenParenClose,
+			lexer.TokenSquareClose:
+			depth--
+		}
+	}
+
+	return depth > 0
+}
@@ -0,0 +1,338 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+// fileName is used in error messages.
+const fileName = "repl"
+
+// firstLine finds the positions on the first line of an input.
+var firstLine = regexp.MustCompile(regexp.QuoteMeta(fileName) + `:1:(\d+)`)
+
+// session holds everything that must be kept between inputs. A single parser
+// is used for all inputs so that functions (and the types they produce) can be
+// referenced by later inputs.
+type session struct {
+	okPath string
+	out    io.Writer
+
+	parser  *parser.Parser
+	file    *vm.File
+	vm      *vm.VM
+	imports map[string]*types.Type
+
+	// scope is never called. Each input containing statements is compiled into
+	// scope, replacing the previous instructions, and run with vm.Eval. This
+	// keeps the variables (and their types) between inputs.
+	scope          *vm.CompiledFunc
+	scopeOverrides map[string]*types.Type
+
+	anonFunctionName int
+	inputs           int
+
+	// last contains the compiled functions from the last input for ":asm".
+	last []*vm.CompiledFunc
+}
+
+func newSession(okPath string, out io.Writer) *session {
+	s := &session{
+		okPath: okPath,
+		out:    out,
+		file: &vm.File{
+			Types:   types.Registry{},
+			Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+			Globals: map[string]string{},
+		},
+		vm:             vm.NewVM(fileName),
+		imports:        map[string]*types.Type{},
+		scopeOverrides: map[string]*types.Type{},
+	}
+
+	// The parser gets its own range of anonymous function names, the same way
+	// that compiler.Compile does for each package.
+	s.anonFunctionName += 10000
+	s.parser = parser.NewParser(s.anonFunctionName)
+
+	s.scope = vm.NewCompiledFunc(&ast.Func{
+		Name:       fileName,
+		UniqueName: fileName,
+		Pos:        fileName,
+	}, nil, s.parser.Constants, s.file)
+	s.vm.Stdout = out
+
+	// Exiting, or a panic in the VM, only ends the input that caused it.
+	s.vm.ReturnOnExit = true
+
+	return s
+}
+
+// eval compiles and runs a single input. An input that starts with "import" or
+// a named function is treated as the package-level code that would appear in a
+// file. Anything else is treated as statements inside a function.
+func (s *session) eval(input string) (errs []error) {
+	// Some invalid code can cause the parser or compiler to panic. That should
+	// not end the session.
+	defer func() {
+		if r := recover(); r != nil {
+			errs = []error{fmt.Errorf("%v", r)}
+		}
+	}()
+
+	tokens, _, err := lexer.TokenizeString(input, lexer.Options{}, fileName)
+	if err != nil {
+		return []error{err}
+	}
+
+	s.inputs++
+
+	if tokens[0].Kind == lexer.TokenImport ||
+		(tokens[0].Kind == lexer.TokenFunc &&
+			tokens[1].Kind == lexer.TokenIdentifier) {
+		return s.declare(input)
+	}
+
+	return s.run(input)
+}
+
+// parse returns the errors from only this input, since the parser is reused.
+func (s *session) parse(input string) []error {
+	before := len(s.parser.Errors())
+	s.parser.ParseString(input, fileName)
+
+	return s.parser.Errors()[before:]
+}
+
+// declare handles imports and package-level functions.
+func (s *session) declare(input string) []error {
+	funcsBefore := map[string]bool{}
+	for uniqueName := range s.parser.Funcs() {
+		funcsBefore[uniqueName] = true
+	}
+
+	importsBefore := map[string]bool{}
+	for variableName := range s.parser.Imports() {
+		importsBefore[variableName] = true
+	}
+
+	s.last = nil
+	var newFuncs []*ast.Func
+	var newImports []string
+	errs := s.parse(input)
+
+	for _, uniqueName := range s.parser.SortedFuncNames() {
+		if !funcsBefore[uniqueName] {
+			newFuncs = append(newFuncs, s.parser.Funcs()[uniqueName])
+		}
+	}
+
+	for variableName, pkgName := range s.parser.Imports() {
+		if !importsBefore[variableName] {
+			newImports = append(newImports, pkgName)
+		}
+	}
+
+	if len(errs) == 0 {
+		errs = s.compileDeclarations(newImports, newFuncs)
+	}
+
+	// Anything that was not compiled must be forgotten, otherwise later inputs
+	// would reference functions that do not exist.
+	if len(errs) > 0 {
+		for _, fn := range newFuncs {
+			delete(s.parser.Funcs(), fn.UniqueName)
+			delete(s.parser.Constants, fn.Name)
+		}
+
+		for variableName := range s.parser.Imports() {
+			if !importsBefore[variableName] {
+				delete(s.parser.Imports(), variableName)
+			}
+		}
+
+		s.last = nil
+	}
+
+	return errs
+}
+
+func (s *session) compileDeclarations(
+	newImports []string,
+	newFuncs []*ast.Func,
+) []error {
+	for _, pkgName := range newImports {
+		pkgFile, pkgType, errs := compiler.Compile(s.okPath, pkgName, false,
+			&s.anonFunctionName, false)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		// See compiler.Compile.
+		s.imports[pkgName] = pkgType
+		pkgVariable := path.Base(pkgName)
+		s.parser.Constants[pkgVariable] = &ast.Literal{
+			Kind:     pkgType,
+			Value:    strings.ReplaceAll(pkgName, "/", "__"),
+			IsGlobal: true,
+		}
+		s.parser.Constants["_"+pkgVariable] = &ast.Literal{
+			Kind: types.NewFunc(nil, []*types.Type{pkgType}),
+		}
+
+		s.file = vm.Merge(s.file, pkgFile)
+	}
+
+	err := s.parser.ResolveTypes(s.file.Types, s.imports)
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, fn := range newFuncs {
+		s.parser.Constants[fn.Name] = &ast.Literal{
+			Kind:  fn.Type(),
+			Value: fn.UniqueName,
+			Pos:   fn.Position(),
+		}
+
+		if fn.IsConstructor() {
+			interfaceType, err := fn.Interface()
+			if err != nil {
+				return []error{err}
+			}
+
+			_, err = s.file.Types.Add(types.NewInterface(fn.Name, interfaceType))
+			if err != nil {
+				return []error{err}
+			}
+		}
+	}
+
+	for _, fn := range newFuncs {
+		compiledFunc, err := compiler.CompileFunc(fn, s.file, nil,
+			s.parser.Constants, s.imports, map[string]*types.Type{})
+		if err != nil {
+			return []error{err}
+		}
+
+		s.file.AddSymbolFunc(compiledFunc)
+		s.last = append(s.last, compiledFunc)
+	}
+
+	if err := s.vm.LoadFile(s.file); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+// run compiles the statements into the scope and runs them. The value of a
+// trailing expression is printed with its type.
+func (s *session) run(input string) []error {
+	// The statements are wrapped in a function so they can be parsed. The
+	// opening line is not separated so that the line numbers in errors are
+	// correct. However, the columns on the first line need to be corrected.
+	name := fmt.Sprintf("__repl%d", s.inputs)
+	prefix := fmt.Sprintf("func %s() {", name)
+
+	return unwrapErrors(s.runWrapped(name, prefix+input+"\n}"), len(prefix))
+}
+
+// unwrapErrors removes the length of the wrapper from the columns of positions
+// on the first line.
+func unwrapErrors(errs []error, prefixLength int) []error {
+	for i, err := range errs {
+		errs[i] = errors.New(firstLine.ReplaceAllStringFunc(err.Error(),
+			func(pos string) string {
+				column, _ := strconv.Atoi(firstLine.FindStringSubmatch(pos)[1])
+
+				return fmt.Sprintf("%s:1:%d", fileName, column-prefixLength)
+			}))
+	}
+
+	return errs
+}
+
+func (s *session) runWrapped(name, wrapped string) []error {
+	errs := s.parse(wrapped)
+
+	// The wrapper function is not part of the package.
+	var fn *ast.Func
+	for uniqueName, f := range s.parser.Funcs() {
+		if f.Name == name {
+			fn = f
+			defer delete(s.parser.Funcs(), uniqueName)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	err := s.parser.ResolveTypes(s.file.Types, s.imports)
+	if err != nil {
+		return []error{err}
+	}
+
+	// The statements are compiled into a copy of the scope so that a failed
+	// compile does not declare any variables.
+	scope := s.scope.Copy()
+	scopeOverrides := map[string]*types.Type{}
+	for name, ty := range s.scopeOverrides {
+		scopeOverrides[name] = ty
+	}
+
+	results, resultTypes, err := compiler.CompileStatements(scope,
+		fn.Statements, s.file, s.imports, scopeOverrides)
+	if err != nil {
+		return []error{err}
+	}
+	s.scope, s.scopeOverrides = scope, scopeOverrides
+	s.last = []*vm.CompiledFunc{s.scope}
+
+	if err := s.vm.LoadFile(s.file); err != nil {
+		return []error{err}
+	}
+
+	if err := s.vm.Eval(s.scope); err != nil {
+		return []error{err}
+	}
+
+	for i, result := range results {
+		fmt.Fprintf(s.out, "%s (%s)\n", s.vm.Inspect(result), resultTypes[i])
+	}
+
+	return nil
+}
+
+// printInstructions prints the instructions of the last input in the same
+// format as "ok asm".
+func (s *session) printInstructions() {
+	for i, fn := range s.last {
+		// Just for vanity, put an empty line between functions.
+		if i > 0 {
+			fmt.Fprintln(s.out)
+		}
+
+		if fn != s.scope {
+			fmt.Fprintf(s.out, "%s (unique=%s):\n", fn.Name, fn.UniqueName)
+		}
+
+		for j, ins := range fn.Instructions.Instructions {
+			ty := fmt.Sprintf("%T", ins)[4:]
+			fmt.Fprintf(s.out, "  %3d %-22s # %s\n", j+1, ty, ins)
+		}
+	}
+}
@@ -1,14 +1,30 @@
 package run
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/profile"
+	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/util"
 	"github.com/elliotchance/ok/vm"
+	"github.com/elliotchance/ok/watch"
 )
 
-type Command struct{}
+type Command struct {
+	// CPUProfile is the file to write the profile to, in the pprof format.
+	CPUProfile string
+
+	// Watch will run the program again each time a source file changes.
+	Watch bool
+
+	// Deterministic runs spawned tasks in a reproducible order.
+	Deterministic bool
+}
 
 func check(err error) {
 	if err != nil {
@@ -22,7 +38,16 @@ func (*Command) Description() string {
 }
 
 // Run is the entry point for the "ok run" command.
-func (*Command) Run(args []string) {
+func (c *Command) Run(args []string) {
+	flag.StringVar(&c.CPUProfile, "cpuprofile", "",
+		"write a profile of instructions and time to the file, in the pprof format")
+	flag.BoolVar(&c.Watch, "watch", false,
+		"run again when any source file of the packages changes")
+	flag.BoolVar(&c.Deterministic, "deterministic", false,
+		"run spawned tasks in a reproducible order")
+	check(flag.CommandLine.Parse(args))
+	args = flag.Args()
+
 	if len(args) == 0 {
 		args = []string{"."}
 	}
@@ -30,6 +55,41 @@ func (*Command) Run(args []string) {
 	okPath, err := util.OKPath()
 	check(err)
 
+	if !c.Watch {
+		c.run(okPath, args, nil, new(int))
+		return
+	}
+
+	// The cache and anonymous function names must be kept between runs so that
+	// packages that have not changed do not need to be compiled again.
+	cache := compiler.NewCache()
+	anonFunctionName := 0
+	w := &watch.Watcher{
+		Interval: watch.DefaultInterval,
+		Out:      os.Stdout,
+	}
+	w.Run(func() []string {
+		return c.run(okPath, args, cache, &anonFunctionName)
+	})
+}
+
+// run compiles and runs each package. The directories of the packages and
+// everything they import are returned for -watch.
+//
+// When there is a cache (for -watch) compile errors and the program exiting
+// will not exit "ok run".
+func (c *Command) run(
+	okPath string,
+	args []string,
+	cache *compiler.Cache,
+	anonFunctionName *int,
+) (dirs []string) {
+	var p *vm.Profile
+	if c.CPUProfile != "" {
+		p = vm.NewProfile()
+	}
+	startTime := time.Now()
+
 	for _, arg := range args {
 		packageName := util.PackageNameFromPath(okPath, arg)
 		if arg == "." {
@@ -37,12 +97,55 @@ func (*Command) Run(args []string) {
 		}
 
 		m := vm.NewVM("no-package")
-		anonFunctionName := 0
-		_, packageType, errs := compiler.Compile(okPath, packageName, false,
-			&anonFunctionName, false)
+		var file *vm.File
+		var packageType *types.Type
+		var errs []error
+		if cache != nil {
+			file, packageType, errs = cache.Compile(okPath, packageName, false,
+				anonFunctionName, false)
+			dirs = append(dirs, cache.Dirs(okPath, packageName)...)
+			m.ReturnOnExit = true
+		} else {
+			file, packageType, errs = compiler.Compile(okPath, packageName,
+				false, anonFunctionName, false)
+		}
+
+		if cache != nil && len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Println(err)
+			}
+
+			return dirs
+		}
 		util.CheckErrorsWithExit(errs)
 
-		check(m.LoadPackage(packageName))
-		check(m.Run("$" + packageType.Name))
+		m.Profile = p
+		m.Deterministic = c.Deterministic
+		check(m.LoadFile(file))
+
+		err := m.Run("$" + packageType.Name)
+		if exitErr, ok := err.(*vm.ExitError); ok {
+			if exitErr.Status != 0 {
+				fmt.Println(exitErr)
+			}
+
+			return dirs
+		}
+		check(err)
+	}
+
+	if p != nil {
+		p.Stop()
+		writeProfile(c.CPUProfile, p, startTime)
 	}
+
+	return dirs
+}
+
+func writeProfile(filePath string, p *vm.Profile, startTime time.Time) {
+	f, err := os.Create(filePath)
+	check(err)
+	defer f.Close()
+
+	check(profile.Write(f, p, startTime, time.Since(startTime)))
 }
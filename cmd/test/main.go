@@ -3,14 +3,20 @@ package test
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"regexp"
+	"runtime"
 	"time"
 
 	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/cover"
+	"github.com/elliotchance/ok/profile"
+	"github.com/elliotchance/ok/report"
 	"github.com/elliotchance/ok/util"
 	"github.com/elliotchance/ok/vm"
+	"github.com/elliotchance/ok/watch"
 )
 
 type Command struct {
@@ -19,6 +25,54 @@ type Command struct {
 
 	// Filter is a regexp based on the test name.
 	Filter string
+
+	// Cover will print the coverage of each function and file.
+	Cover bool
+
+	// CoverProfile is the file to write the coverage profile to. The coverage
+	// is recorded when either Cover or CoverProfile is set.
+	CoverProfile string
+
+	// CPUProfile is the file to write the profile to, in the pprof format.
+	CPUProfile string
+
+	// Bench is a regexp based on the benchmark name. Benchmarks only run when
+	// it is set. Use "." to run all benchmarks.
+	Bench string
+
+	// BenchTime is the minimum time to run each benchmark for.
+	BenchTime time.Duration
+
+	// Fuzz is a regexp based on the fuzz target name. Inputs are only
+	// generated when it is set, otherwise only the corpus is run with the
+	// tests.
+	Fuzz string
+
+	// FuzzTime is the time to generate inputs for each fuzz target.
+	FuzzTime time.Duration
+
+	// JSON will print a stream of JSON events instead of the usual output.
+	JSON bool
+
+	// JUnit is the file to write the results to, in the JUnit XML format.
+	JUnit string
+
+	// Watch will run the tests again each time a source file changes.
+	Watch bool
+
+	// Update will write the snapshots with the current values, rather than
+	// comparing them.
+	Update bool
+
+	// Parallel is the maximum number of tests to run at the same time. Each
+	// test runs in its own VM when it is more than 1.
+	Parallel int
+
+	// Timeout is the maximum time each test can run for. MaxInstructions is
+	// the maximum number of instructions each test can execute. Zero means
+	// there is no limit.
+	Timeout         time.Duration
+	MaxInstructions int
 }
 
 func check(err error) {
@@ -36,6 +90,32 @@ func (*Command) Description() string {
 func (c *Command) Run(args []string) {
 	flag.StringVar(&c.Filter, "f", "", "regexp to filter tests by name")
 	flag.BoolVar(&c.Verbose, "v", false, "print all test names")
+	flag.BoolVar(&c.Cover, "cover", false, "print coverage of each function and file")
+	flag.StringVar(&c.CoverProfile, "coverprofile", "",
+		"write a coverage profile to the file, in the format of \"go tool cover\"")
+	flag.StringVar(&c.CPUProfile, "cpuprofile", "",
+		"write a profile of instructions and time to the file, in the pprof format")
+	flag.StringVar(&c.Bench, "bench", "",
+		"regexp to filter benchmarks by name, benchmarks only run when set")
+	flag.DurationVar(&c.BenchTime, "benchtime", time.Second,
+		"minimum time to run each benchmark for")
+	flag.StringVar(&c.Fuzz, "fuzz", "",
+		"regexp to filter fuzz targets by name, inputs are only generated when set")
+	flag.DurationVar(&c.FuzzTime, "fuzztime", 10*time.Second,
+		"time to generate inputs for each fuzz target")
+	flag.BoolVar(&c.JSON, "json", false, "print results as a stream of JSON events")
+	flag.StringVar(&c.JUnit, "junit", "",
+		"write the results to the file, in the JUnit XML format")
+	flag.BoolVar(&c.Watch, "watch", false,
+		"run again when any source file of the packages changes")
+	flag.BoolVar(&c.Update, "update", false,
+		"write the snapshots with the current values rather than comparing them")
+	flag.IntVar(&c.Parallel, "parallel", 1,
+		"maximum number of tests to run at the same time, each in its own VM")
+	flag.DurationVar(&c.Timeout, "timeout", 0,
+		"fail each test that runs for longer than the duration, 0 for no limit")
+	flag.IntVar(&c.MaxInstructions, "maxinstructions", 0,
+		"fail each test that executes more instructions, 0 for no limit")
 	check(flag.CommandLine.Parse(args))
 	args = flag.Args()
 
@@ -46,38 +126,221 @@ func (c *Command) Run(args []string) {
 	okPath, err := util.OKPath()
 	check(err)
 
+	if !c.Watch {
+		if passed, _ := c.test(okPath, args, nil, new(int)); !passed {
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	// The cache and anonymous function names must be kept between runs so that
+	// packages that have not changed do not need to be compiled again.
+	cache := compiler.NewCache()
+	anonFunctionName := 0
+	w := &watch.Watcher{
+		Interval: watch.DefaultInterval,
+		Out:      os.Stdout,
+	}
+	w.Run(func() []string {
+		_, dirs := c.test(okPath, args, cache, &anonFunctionName)
+
+		return dirs
+	})
+}
+
+// test runs the tests for each package, stopping after the first package that
+// fails. The directories of the packages and everything they import are
+// returned for -watch.
+//
+// When there is a cache (for -watch) compile errors will not exit "ok test".
+func (c *Command) test(
+	okPath string,
+	args []string,
+	cache *compiler.Cache,
+	anonFunctionName *int,
+) (passed bool, dirs []string) {
+	coverReport := cover.NewReport()
+
+	var p *vm.Profile
+	if c.CPUProfile != "" {
+		p = vm.NewProfile()
+	}
+	profileStartTime := time.Now()
+
+	// Everything that would be printed is sent as events when using -json. It
+	// is also recorded for -junit so that it can be included in the file.
+	var recorder *report.Recorder
+	var out io.Writer = os.Stdout
+	if c.JSON {
+		recorder = report.NewRecorder(os.Stdout)
+		out = recorder.Output()
+	} else if c.JUnit != "" {
+		recorder = report.NewRecorder(nil)
+		out = io.MultiWriter(os.Stdout, recorder.Output())
+	}
+
+	// The same header as "go test -bench" so that the results can be compared
+	// with benchstat.
+	if c.Bench != "" {
+		fmt.Fprintf(out, "goos: %s\ngoarch: %s\n", runtime.GOOS, runtime.GOARCH)
+	}
+
 	for _, arg := range args {
 		packageName := util.PackageNameFromPath(okPath, arg)
 		if arg == "." {
 			packageName = "."
 		}
-		anonFunctionName := 0
-		f, _, errs := compiler.Compile(okPath, packageName, true,
-			&anonFunctionName, false)
+		var f *vm.File
+		var errs []error
+		if cache != nil {
+			f, _, errs = cache.Compile(okPath, packageName, true,
+				anonFunctionName, false)
+			dirs = append(dirs, cache.Dirs(okPath, packageName)...)
+		} else {
+			f, _, errs = compiler.Compile(okPath, packageName, true,
+				anonFunctionName, false)
+		}
+
+		if cache != nil && len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintln(out, err)
+			}
+
+			return false, dirs
+		}
 		util.CheckErrorsWithExit(errs)
 
 		m := vm.NewVM("no-package")
+		m.ReturnOnExit = cache != nil
+		m.UpdateSnapshots = c.Update
+		m.TestTimeout = c.Timeout
+		m.MaxInstructions = c.MaxInstructions
+		m.Deterministic = true
+		if c.Cover || c.CoverProfile != "" {
+			m.Coverage = vm.NewCoverage()
+		}
+		m.Profile = p
+		m.Stdout = out
+		m.TestOutput = out
+		if recorder != nil {
+			m.TestReporter = recorder
+			recorder.StartPackage(packageName)
+		}
+
 		startTime := time.Now()
 		check(m.LoadFile(f))
-		err := m.RunTests(c.Verbose, regexp.MustCompile(c.Filter), packageName)
+		err := m.RunTestsParallel(c.Parallel, c.Verbose,
+			regexp.MustCompile(c.Filter), packageName)
 		elapsed := time.Since(startTime).Milliseconds()
+		if exitErr, ok := err.(*vm.ExitError); ok {
+			fmt.Fprintln(out, exitErr)
+			m.TestsFailed++
+			err = nil
+		}
 		check(err)
 
 		assertWord := pluralise("assert", m.TotalAssertions)
 		if m.TestsFailed > 0 {
-			fmt.Printf("%s: %d failed %d passed %d %s (%d ms)\n",
+			fmt.Fprintf(out, "%s: %d failed %d passed %d %s (%d ms)\n",
 				packageName, m.TestsFailed, m.TestsPass,
 				m.TotalAssertions, assertWord, elapsed)
 		} else {
-			fmt.Printf("%s: %d passed %d %s (%d ms)\n",
+			fmt.Fprintf(out, "%s: %d passed %d %s (%d ms)\n",
 				packageName, m.TestsPass,
 				m.TotalAssertions, assertWord, elapsed)
 		}
 
+		if m.Coverage != nil {
+			packageReport := cover.NewReport()
+			packageReport.Add(f, okPath, packageName, m.Coverage)
+			coverReport.Add(f, okPath, packageName, m.Coverage)
+
+			fmt.Fprintf(out, "%s: coverage: %.1f%% of statements\n", packageName,
+				packageReport.Total().Percent())
+			if c.Cover {
+				check(packageReport.WriteSummary(out))
+			}
+		}
+
+		if c.Fuzz != "" && m.TestsFailed == 0 {
+			err := m.RunFuzz(regexp.MustCompile(c.Fuzz), c.FuzzTime, c.Verbose,
+				packageName)
+			if exitErr, ok := err.(*vm.ExitError); ok {
+				fmt.Fprintln(out, exitErr)
+				m.TestsFailed++
+				err = nil
+			}
+			check(err)
+		}
+
+		if c.Bench != "" && m.TestsFailed == 0 {
+			fmt.Fprintf(out, "pkg: %s\n", packageName)
+			err := m.RunBenchmarks(regexp.MustCompile(c.Bench), c.BenchTime,
+				packageName)
+			if exitErr, ok := err.(*vm.ExitError); ok {
+				fmt.Fprintln(out, exitErr)
+				m.TestsFailed++
+				err = nil
+			}
+			check(err)
+		}
+
+		if recorder != nil {
+			recorder.FinishPackage(m.TestsFailed == 0)
+		}
+
 		if m.TestsFailed > 0 {
-			os.Exit(1)
+			c.writeCoverProfile(coverReport)
+			c.writeCPUProfile(p, profileStartTime)
+			c.writeJUnit(recorder)
+
+			return false, dirs
 		}
 	}
+
+	c.writeCoverProfile(coverReport)
+	c.writeCPUProfile(p, profileStartTime)
+	c.writeJUnit(recorder)
+
+	return true, dirs
+}
+
+func (c *Command) writeJUnit(recorder *report.Recorder) {
+	if c.JUnit == "" {
+		return
+	}
+
+	f, err := os.Create(c.JUnit)
+	check(err)
+	defer f.Close()
+
+	check(recorder.WriteJUnit(f))
+}
+
+func (c *Command) writeCPUProfile(p *vm.Profile, startTime time.Time) {
+	if p == nil {
+		return
+	}
+	p.Stop()
+
+	f, err := os.Create(c.CPUProfile)
+	check(err)
+	defer f.Close()
+
+	check(profile.Write(f, p, startTime, time.Since(startTime)))
+}
+
+func (c *Command) writeCoverProfile(report *cover.Report) {
+	if c.CoverProfile == "" {
+		return
+	}
+
+	f, err := os.Create(c.CoverProfile)
+	check(err)
+	defer f.Close()
+
+	check(report.WriteProfile(f))
 }
 
 func pluralise(word string, n int) string {
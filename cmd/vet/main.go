@@ -0,0 +1,71 @@
+package vet
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/vet"
+)
+
+type Command struct {
+	// JSON will print the issues as a JSON array instead of one per line.
+	JSON bool
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// Description is shown in "ok -help".
+func (*Command) Description() string {
+	return "report suspicious code"
+}
+
+// Run is the entry point for the "ok vet" command.
+func (c *Command) Run(args []string) {
+	flag.BoolVar(&c.JSON, "json", false, "print the issues as JSON")
+	check(flag.CommandLine.Parse(args))
+	args = flag.Args()
+
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	// Always output an array for -json, even if there are no issues.
+	issues := []*vet.Issue{}
+	for _, arg := range args {
+		p := parser.NewParser(0)
+		p.ParseDirectory(arg, true)
+
+		// The checks rely on the AST so the package must at least parse.
+		if errs := p.Errors(); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintln(os.Stderr, err)
+			}
+
+			os.Exit(1)
+		}
+
+		issues = append(issues, vet.Check(p)...)
+	}
+
+	if c.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		check(encoder.Encode(issues))
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+	}
+
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
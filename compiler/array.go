@@ -43,12 +43,21 @@ func compileArray(
 			Symbol: file.AddSymbolLiteral(asttest.NewLiteralNumber(fmt.Sprintf("%d", index))),
 		})
 
-		// TODO(elliot): Check all elements are the same kind.
-		valueRegisters, valueKind, _ := compileExpr(compiledFunc, value, file, scopeOverrides)
+		valueRegisters, valueKind, err := compileExpr(compiledFunc, value, file,
+			scopeOverrides)
+		if err != nil {
+			return "", nil, err
+		}
+
 		if n.Kind == nil {
 			n.Kind = valueKind[0].ToArray()
 		}
 
+		if !file.Types.Accepts(n.Kind.Element, valueKind[0]) {
+			return "", nil, fmt.Errorf("%s cannot use %s as an element of %s",
+				value.Position(), valueKind[0], n.Kind)
+		}
+
 		compiledFunc.Append(&vm.ArraySet{
 			Array: arrayRegister,
 			Index: indexRegister,
@@ -56,7 +65,8 @@ func compileArray(
 		})
 	}
 
-	typeRegister := file.AddType(n.Kind)
+	// Type parameters only exist when compiling.
+	typeRegister := file.AddType(n.Kind.Erase())
 	arrayAlloc.Kind = typeRegister
 
 	return arrayRegister, n.Kind, nil
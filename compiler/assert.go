@@ -25,12 +25,29 @@ func compileAssert(
 		return fmt.Errorf("assert condition must be a bool but is %s", returnKind)
 	}
 
+	var message vm.Register
+	if n.Message != nil {
+		messageResults, messageKinds, err := compileExpr(compiledFunc,
+			n.Message, file, scopeOverrides)
+		if err != nil {
+			return err
+		}
+
+		if messageKinds[0].Kind != types.KindString {
+			return fmt.Errorf("%s assert message must be a string but is %s",
+				n.Message.Position(), messageKinds[0])
+		}
+
+		message = messageResults[0]
+	}
+
 	compiledFunc.Append(&vm.Assert{
-		Left:  left,
-		Op:    n.Expr.Op,
-		Right: right,
-		Final: returns,
-		Pos:   n.Position(),
+		Left:    left,
+		Op:      n.Expr.Op,
+		Right:   right,
+		Final:   returns,
+		Pos:     n.Position(),
+		Message: message,
 	})
 
 	return nil
@@ -89,7 +106,8 @@ func compileAssertRaise(
 			Left:  &ast.Identifier{Name: raisedVariable},
 			Right: asttest.NewLiteralBool(true),
 		},
-		Pos: n.Position(),
+		Pos:     n.Position(),
+		Message: n.Message,
 	}, file, scopeOverrides)
 	if err != nil {
 		return err
@@ -60,6 +60,57 @@ func TestAssert(t *testing.T) {
 				},
 			},
 		},
+		"message": {
+			node: &ast.Assert{
+				Expr: asttest.NewBinary(
+					asttest.NewLiteralNumber("1"),
+					lexer.TokenEqual,
+					asttest.NewLiteralNumber("2"),
+				),
+				Message: asttest.NewLiteralString("must match"),
+			},
+			expected: []vm.Instruction{
+				&vm.AssignSymbol{
+					Result: "1",
+					Symbol: "0",
+				},
+				&vm.AssignSymbol{
+					Result: "2",
+					Symbol: "1",
+				},
+				&vm.EqualNumber{
+					Left:   "1",
+					Right:  "2",
+					Result: "3",
+				},
+				&vm.AssignSymbol{
+					Result: "4",
+					Symbol: "2",
+				},
+				&vm.Assert{
+					Left:    "1",
+					Op:      "==",
+					Right:   "2",
+					Final:   "3",
+					Message: "4",
+				},
+			},
+		},
+		"message-not-a-string": {
+			node: &ast.Assert{
+				Expr: asttest.NewBinary(
+					asttest.NewLiteralNumber("1"),
+					lexer.TokenEqual,
+					asttest.NewLiteralNumber("2"),
+				),
+				Message: &ast.Literal{
+					Kind:  types.Number,
+					Value: "3",
+					Pos:   "a.ok:1:20",
+				},
+			},
+			err: errors.New("a.ok:1:20 assert message must be a string but is number"),
+		},
 	} {
 		t.Run(testName, func(t *testing.T) {
 			compiledFunc, err := compiler.CompileFunc(&ast.Func{
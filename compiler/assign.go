@@ -45,13 +45,32 @@ func compileAssign(
 			variableName := l.Name
 
 			// Make sure we do not assign the wrong type to an existing variable.
-			if v, ok := compiledFunc.GetTypeForVariable(variableName, scopeOverrides); ok && v.String() != "any" && rightResults[0].kind.String() != v.String() {
+			// An optional variable also accepts nil and values of its element.
+			if v, ok := compiledFunc.GetTypeForVariable(variableName, scopeOverrides); ok && v.String() != "any" && rr.kind.String() != v.String() &&
+				!(v.Kind == types.KindOptional && file.Types.Accepts(v, rr.kind)) {
+				// An optional that has been narrowed by checking for nil can
+				// only be assigned a value for the rest of the block.
+				if declared, ok := compiledFunc.GetTypeForVariable(variableName, nil); ok &&
+					declared.Kind == types.KindOptional && declared.String() != v.String() {
+					return fmt.Errorf(
+						"%s cannot assign %s to variable %s (expecting %s because %s was checked for %s)",
+						node.Position(), rr.kind, variableName, v, variableName,
+						types.Nil)
+				}
+
 				return fmt.Errorf(
 					"%s cannot assign %s to variable %s (expecting %s)",
-					node.Position(), rightResults[0].kind, variableName, v)
+					node.Position(), rr.kind, variableName, v)
 			}
 
-			resolvedTypeRegister, err := file.Types.Add(rr.kind)
+			// The variable stays optional, even if it is narrowed in this
+			// scope.
+			ty := rr.kind
+			if v, ok := compiledFunc.GetTypeForVariable(variableName, nil); ok && v.Kind == types.KindOptional {
+				ty = v
+			}
+
+			resolvedTypeRegister, err := file.Types.Add(ty)
 			if err != nil {
 				return err
 			}
@@ -76,18 +95,23 @@ func compileAssign(
 				return err
 			}
 
+			err = checkElementAssign(l, arrayOrMapKind[0], rr.kind, file)
+			if err != nil {
+				return err
+			}
+
 			if arrayOrMapKind[0].Kind == types.KindArray {
 				ins := &vm.ArraySet{
 					Array: arrayOrMapResults[0],
 					Index: keyResults[0],
-					Value: rightResults[0].result,
+					Value: rr.result,
 				}
 				compiledFunc.Append(ins)
 			} else {
 				ins := &vm.MapSet{
 					Map:   arrayOrMapResults[0],
 					Key:   keyResults[0],
-					Value: rightResults[0].result,
+					Value: rr.result,
 				}
 				compiledFunc.Append(ins)
 			}
@@ -96,3 +120,36 @@ func compileAssign(
 
 	return nil
 }
+
+// checkElementAssign makes sure that a value can be assigned to an element of
+// an array or map, or the property of an object.
+func checkElementAssign(
+	key *ast.Key,
+	arrayOrMapKind, valueKind *types.Type,
+	file *vm.File,
+) error {
+	switch arrayOrMapKind.Kind {
+	case types.KindArray, types.KindMap:
+		if !file.Types.Accepts(arrayOrMapKind.Element, valueKind) {
+			return fmt.Errorf(
+				"%s cannot assign %s to element of %s (expecting %s)",
+				key.Position(), valueKind, arrayOrMapKind,
+				arrayOrMapKind.Element)
+		}
+
+	case types.KindResolvedInterface:
+		name, ok := key.Key.(*ast.Literal)
+		if !ok {
+			return nil
+		}
+
+		property := arrayOrMapKind.Properties[name.Value]
+		if property != nil && !file.Types.Accepts(property, valueKind) {
+			return fmt.Errorf(
+				"%s cannot assign %s to property %s (expecting %s)",
+				key.Position(), valueKind, name.Value, property)
+		}
+	}
+
+	return nil
+}
@@ -162,20 +162,60 @@ func getBinaryInstruction(
 	return nil, nil
 }
 
+// binaryOp returns the operation that is used to find the instruction for a
+// binary operator, such as "number + number".
+func binaryOp(left *types.Type, op string, right *types.Type) string {
+	if (op == lexer.TokenEqual || op == lexer.TokenNotEqual) &&
+		left.String() == right.String() {
+		switch left.Kind {
+		case types.KindTypeParameter:
+			// A type parameter could be any type at runtime, so it can only be
+			// compared with the same type parameter.
+			return fmt.Sprintf("any %s any", op)
+
+		case types.KindEnum:
+			// Enum values are stored as their name. Values of different enums
+			// cannot be compared.
+			return fmt.Sprintf("string %s string", op)
+		}
+	}
+
+	return fmt.Sprintf("%s %s %s", left, op, right)
+}
+
 func compileBinary(
 	compiledFunc *vm.CompiledFunc,
 	node *ast.Binary,
 	file *vm.File,
 	scopeOverrides map[string]*types.Type,
 ) (vm.Register, *types.Type, error) {
+	if node.Op == lexer.TokenNilCoalesce {
+		return compileNilCoalesce(compiledFunc, node, file, scopeOverrides)
+	}
+
 	// Type check.
 	if node.Op == lexer.TokenIs {
-		left, _, err := compileExpr(compiledFunc, node.Left, file,
+		left, leftTypes, err := compileExpr(compiledFunc, node.Left, file,
 			scopeOverrides)
 		if err != nil {
 			return "", nil, err
 		}
 
+		if node.Right.(*ast.Identifier).Name == types.Nil.String() {
+			result, err := compileIsNil(compiledFunc, left[0], leftTypes[0],
+				node.Position(), file)
+
+			return result, types.Bool, err
+		}
+
+		// Values are never optional at runtime. They are either nil or a value
+		// of the element type.
+		ty := types.TypeFromString(node.Right.(*ast.Identifier).Name)
+		if ty.Kind == types.KindOptional {
+			return "", nil, fmt.Errorf("%s cannot check for %s, use %s nil",
+				node.Position(), ty, lexer.TokenIs)
+		}
+
 		typeRegister := compiledFunc.NextRegister()
 		tyName := asttest.NewLiteralString(node.Right.(*ast.Identifier).Name)
 		compiledFunc.Append(&vm.AssignSymbol{
@@ -345,7 +385,7 @@ func compileComparison(
 
 	returns := compiledFunc.NextRegister()
 
-	op := fmt.Sprintf("%s %s %s", leftKind[0], node.Op, rightKind[0])
+	op := binaryOp(leftKind[0], node.Op, rightKind[0])
 	if bop, kind := getBinaryInstruction(op, left[0], right[0], returns); bop != nil {
 		// TODO(elliot): It would be nice to be able to evaluate expressions
 		//  involving literals here. So, 1 + 1 just becomes 2.
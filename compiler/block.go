@@ -31,6 +31,7 @@ func compileBlock(
 	}
 
 	for _, statement := range stmts {
+		compiledFunc.StartStatement(statement.Position())
 		err := compileStatement(compiledFunc, statement, breakIns, continueIns,
 			file, scopeOverrides)
 		if err != nil {
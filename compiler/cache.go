@@ -0,0 +1,208 @@
+package compiler
+
+import (
+	"encoding/json"
+
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/util"
+	"github.com/elliotchance/ok/vm"
+)
+
+// Cache keeps compiled packages in memory so that compiling a package again
+// will only compile the packages that have changed, or that import a package
+// that has changed. This is used by "-watch" to avoid compiling all of the
+// dependencies after each change.
+type Cache struct {
+	packages map[cacheKey]*cachedPackage
+
+	// imports contains the directories of the packages imported by each
+	// package directory, from the last time it was parsed.
+	imports map[string][]string
+}
+
+type cacheKey struct {
+	dir          string
+	includeTests bool
+}
+
+type cachedPackage struct {
+	key cacheKey
+
+	// fingerprints contains the fingerprint of the package and each of the
+	// packages that were compiled into it (see vm.File.Sources), by directory.
+	fingerprints map[string]string
+
+	// The compiled file and type are stored in their serialized form because
+	// vm.Merge will modify the file. Each time the package is used it needs a
+	// new copy.
+	file, pkgType []byte
+
+	// dependencies are the packages that were imported when this package was
+	// compiled. If any of them have been compiled again then this package
+	// also needs to be compiled again.
+	dependencies []*cachedPackage
+}
+
+// NewCache creates an empty cache.
+func NewCache() *Cache {
+	return &Cache{
+		packages: map[cacheKey]*cachedPackage{},
+		imports:  map[string][]string{},
+	}
+}
+
+// Compile is the same as the Compile function, except that packages that have
+// not changed since the last call will not be compiled again.
+func (c *Cache) Compile(
+	rootPath,
+	pkgPath string,
+	includeTests bool,
+	anonFunctionName *int,
+	verbose bool,
+) (*vm.File, *types.Type, []error) {
+	return compile(c, rootPath, pkgPath, includeTests, anonFunctionName,
+		verbose)
+}
+
+// Dirs returns the directory of the package and the directories of all of the
+// packages that it imports, directly or indirectly. The imports are known even
+// if the package (or one of its dependencies) did not compile, as long as it
+// could be parsed.
+func (c *Cache) Dirs(rootPath, pkgPath string) []string {
+	var dirs []string
+	seen := map[string]bool{}
+
+	var walk func(dir string)
+	walk = func(dir string) {
+		if seen[dir] {
+			return
+		}
+
+		seen[dir] = true
+		dirs = append(dirs, dir)
+		for _, importDir := range c.imports[dir] {
+			walk(importDir)
+		}
+	}
+	walk(packageDir(rootPath, pkgPath))
+
+	return dirs
+}
+
+func (c *Cache) setImports(
+	dir, rootPath string,
+	imports map[string]string,
+) {
+	var dirs []string
+	for _, pkgName := range imports {
+		dirs = append(dirs, packageDir(rootPath, pkgName))
+	}
+	c.setImportDirs(dir, dirs)
+}
+
+// setImportDirs is the same as setImports, except the directories of the
+// imported packages are already known.
+func (c *Cache) setImportDirs(dir string, dirs []string) {
+	if c == nil {
+		return
+	}
+
+	c.imports[dir] = dirs
+}
+
+// get returns a copy of the package if it has not changed. A nil file is
+// returned if the package needs to be compiled.
+func (c *Cache) get(dir string, includeTests bool) (*vm.File, *types.Type) {
+	if c == nil {
+		return nil, nil
+	}
+
+	pkg := c.packages[cacheKey{dir, includeTests}]
+	if !c.isValid(pkg) {
+		return nil, nil
+	}
+
+	file := new(vm.File)
+	if err := file.UnmarshalBinary(pkg.file); err != nil {
+		return nil, nil
+	}
+
+	var pkgType *types.Type
+	if err := json.Unmarshal(pkg.pkgType, &pkgType); err != nil {
+		return nil, nil
+	}
+
+	return file, pkgType
+}
+
+// isValid returns false if the source files of the package, or any of its
+// dependencies, have changed.
+func (c *Cache) isValid(pkg *cachedPackage) bool {
+	if pkg == nil {
+		return false
+	}
+
+	for dir, fingerprint := range pkg.fingerprints {
+		// Imports are always compiled without tests.
+		includeTests := dir == pkg.key.dir && pkg.key.includeTests
+		if util.SourceFingerprint(dir, includeTests) != fingerprint {
+			return false
+		}
+	}
+
+	for _, dependency := range pkg.dependencies {
+		if c.packages[dependency.key] != dependency || !c.isValid(dependency) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// put must be called before the file is merged into another file.
+func (c *Cache) put(
+	dir string,
+	includeTests bool,
+	file *vm.File,
+	pkgType *types.Type,
+) error {
+	if c == nil {
+		return nil
+	}
+
+	pkg := &cachedPackage{
+		key: cacheKey{dir, includeTests},
+		fingerprints: map[string]string{
+			dir: util.SourceFingerprint(dir, includeTests),
+		},
+	}
+
+	for sourceDir := range file.Sources {
+		if sourceDir != dir {
+			pkg.fingerprints[sourceDir] = util.SourceFingerprint(sourceDir,
+				false)
+		}
+	}
+
+	var err error
+	pkg.file, err = file.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	pkg.pkgType, err = json.Marshal(pkgType)
+	if err != nil {
+		return err
+	}
+
+	// Imports are always compiled without tests.
+	for _, importDir := range c.imports[dir] {
+		if dependency, ok := c.packages[cacheKey{importDir, false}]; ok {
+			pkg.dependencies = append(pkg.dependencies, dependency)
+		}
+	}
+
+	c.packages[pkg.key] = pkg
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package compiler_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/ok/compiler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Compile(t *testing.T) {
+	okPath, err := ioutil.TempDir("", "ok-cache-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(okPath)
+
+	okCache, err := ioutil.TempDir("", "ok-cache-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(okCache)
+	defer os.Setenv("OKCACHE", os.Getenv("OKCACHE"))
+	require.NoError(t, os.Setenv("OKCACHE", okCache))
+
+	files := map[string]string{
+		"cache/lib/lib.ok": `func Greet() string {
+    return "hello"
+}`,
+		"cache/app/main.ok": `import "cache/lib"
+
+func main() {
+    print(lib.Greet())
+}`,
+	}
+	for fileName, source := range files {
+		filePath := filepath.Join(okPath, fileName)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(source), 0644))
+	}
+
+	// Each package that is compiled reserves a range of anonymous function
+	// names, so this is a count of the packages compiled.
+	cache := compiler.NewCache()
+	anonFunctionName := 0
+	compiled := func(cache *compiler.Cache) int {
+		before := anonFunctionName
+		file, pkgType, errs := cache.Compile(okPath, "cache/app", false,
+			&anonFunctionName, false)
+		require.Empty(t, errs)
+		require.NotNil(t, file)
+		require.NotNil(t, pkgType)
+
+		return (anonFunctionName - before) / 10000
+	}
+
+	edit := func(fileName, source string, d time.Duration) {
+		filePath := filepath.Join(okPath, fileName)
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(source), 0644))
+
+		// Make sure the modification time changes, even if the file system
+		// does not have a fine resolution.
+		mtime := time.Now().Add(d)
+		require.NoError(t, os.Chtimes(filePath, mtime, mtime))
+	}
+
+	assert.Equal(t, 2, compiled(cache))
+	assert.Equal(t, 0, compiled(cache))
+
+	// Packages that have not changed are loaded from disk.
+	assert.Equal(t, 0, compiled(compiler.NewCache()))
+
+	edit("cache/app/main.ok", files["cache/app/main.ok"]+"\n", time.Hour)
+	assert.Equal(t, 1, compiled(cache))
+	assert.Equal(t, 0, compiled(cache))
+
+	// The package that imports lib must also be compiled again.
+	edit("cache/lib/lib.ok", files["cache/lib/lib.ok"]+"\n", 2*time.Hour)
+	assert.Equal(t, 2, compiled(cache))
+
+	// Reverting the source uses the files that were compiled first.
+	edit("cache/app/main.ok", files["cache/app/main.ok"], 3*time.Hour)
+	edit("cache/lib/lib.ok", files["cache/lib/lib.ok"], 3*time.Hour)
+	assert.Equal(t, 0, compiled(cache))
+
+	assert.Equal(t, []string{
+		filepath.Join(okPath, "cache/app"),
+		filepath.Join(okPath, "cache/lib"),
+	}, cache.Dirs(okPath, "cache/app"))
+}
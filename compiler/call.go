@@ -2,8 +2,10 @@ package compiler
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/lexer"
 	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/vm"
 )
@@ -13,46 +15,69 @@ type builtinFn func(
 	args []vm.Register,
 ) (vm.Instruction, []vm.Register, []*types.Type, error)
 
-// TODO(elliot): These needs to check function signatures.
-var builtinFunctions = map[string]builtinFn{
-	"__call":        funcCall,
-	"__close":       funcClose,
-	"__env_get":     funcEnvGet,
-	"__env_set":     funcEnvSet,
-	"__env_unset":   funcEnvUnset,
-	"__exit":        funcExit,
-	"__fromunix":    funcFromUnix,
-	"__get":         funcGet,
-	"__info":        funcInfo,
-	"__interface":   funcInterface,
-	"__len":         funcLen,
-	"__log":         funcLog,
-	"__mkdir":       funcMkdir,
-	"__now":         funcNow,
-	"__open":        funcOpen,
-	"__pow":         funcPow,
-	"__props":       funcProps,
-	"__rand":        funcRand,
-	"__read_data":   funcReadData,
-	"__read_string": funcReadString,
-	"__remove":      funcRemove,
-	"__rename":      funcRename,
-	"__seek":        funcSeek,
-	"__set":         funcSet,
-	"__sleep":       funcSleep,
-	"__stack":       funcStack,
-	"__type":        funcType,
-	"__unicode_is":  funcUnicodeIs,
-	"__unicode_to":  funcUnicodeTo,
-	"__unix":        funcUnix,
-	"__write":       funcWrite,
-
-	"char":   funcChar,
-	"data":   funcData,
-	"len":    funcLen,
-	"number": funcNumber,
-	"print":  funcPrint,
-	"string": funcString,
+// builtinFunction is a function that is compiled directly into an instruction.
+type builtinFunction struct {
+	// arguments are the types of each argument. They are not checked if the
+	// function is variadic.
+	arguments []*types.Type
+	variadic  bool
+
+	// oneOf is used instead of arguments for a function that has a single
+	// argument which may be any one of several types, such as len.
+	oneOf []*types.Type
+
+	compile builtinFn
+}
+
+func builtin(fn builtinFn, arguments ...*types.Type) builtinFunction {
+	return builtinFunction{arguments: arguments, compile: fn}
+}
+
+func builtinOneOf(fn builtinFn, oneOf ...*types.Type) builtinFunction {
+	return builtinFunction{oneOf: oneOf, compile: fn}
+}
+
+var builtinFunctions = map[string]builtinFunction{
+	"__call":        builtin(funcCall, types.Any, types.AnyArray),
+	"__close":       builtin(funcClose, types.Data),
+	"__env_get":     builtin(funcEnvGet, types.String),
+	"__env_set":     builtin(funcEnvSet, types.String, types.String),
+	"__env_unset":   builtin(funcEnvUnset, types.String),
+	"__exit":        builtin(funcExit, types.Number),
+	"__fromunix":    builtin(funcFromUnix, types.Number),
+	"__get":         builtin(funcGet, types.Any, types.Any),
+	"__info":        builtin(funcInfo, types.String),
+	"__interface":   builtin(funcInterface, types.Any),
+	"__len":         builtin(funcLen, types.Any),
+	"__log":         builtin(funcLog, types.Number),
+	"__mkdir":       builtin(funcMkdir, types.String),
+	"__now":         builtin(funcNow),
+	"__open":        builtin(funcOpen, types.String),
+	"__pow":         builtin(funcPow, types.Number, types.Number),
+	"__props":       builtin(funcProps, types.Any),
+	"__rand":        builtin(funcRand),
+	"__read_data":   builtin(funcReadData, types.Data, types.Number),
+	"__read_string": builtin(funcReadString, types.Data, types.Number),
+	"__remove":      builtin(funcRemove, types.String),
+	"__rename":      builtin(funcRename, types.String, types.String),
+	"__seek":        builtin(funcSeek, types.Data, types.Number, types.Number),
+	"__set":         builtin(funcSet, types.Any, types.Any, types.Any),
+	"__sleep":       builtin(funcSleep, types.Number),
+	"__snapshot":    builtin(funcSnapshot, types.String, types.String),
+	"__stack":       builtin(funcStack),
+	"__timeout":     builtin(funcTimeout, types.Number),
+	"__type":        builtin(funcType, types.Any),
+	"__unicode_is":  builtin(funcUnicodeIs, types.String, types.Char),
+	"__unicode_to":  builtin(funcUnicodeTo, types.String, types.Char),
+	"__unix":        builtin(funcUnix, types.Any),
+	"__write":       builtin(funcWrite, types.Data, types.Data),
+
+	"char":   builtin(funcChar, types.Number),
+	"data":   builtin(funcData, types.Any), // Any value can be rendered.
+	"len":    builtinOneOf(funcLen, types.String, types.Data, types.AnyArray, types.AnyMap),
+	"number": builtinOneOf(funcNumber, types.Char, types.String),
+	"print":  {variadic: true, compile: funcPrint},
+	"string": builtin(funcString, types.Any), // Any value can be rendered.
 }
 
 func compileCall(
@@ -62,13 +87,20 @@ func compileCall(
 	scopeOverrides map[string]*types.Type,
 ) ([]vm.Register, []*types.Type, error) {
 	var argResults []vm.Register
+	var argTypes []*types.Type
 	for _, arg := range call.Arguments {
-		argResult, _, err := compileExpr(compiledFunc, arg, file, scopeOverrides)
+		argResult, argType, err := compileExpr(compiledFunc, arg, file, scopeOverrides)
 		if err != nil {
 			return nil, nil, err
 		}
 
 		argResults = append(argResults, argResult...)
+		argTypes = append(argTypes, argType...)
+	}
+
+	if enum := enumType(compiledFunc, call.Expr); enum != nil {
+		return compileEnumCast(compiledFunc, call, enum, argResults, argTypes,
+			file)
 	}
 
 	if name, ok := call.Expr.(*ast.Identifier); ok {
@@ -78,8 +110,34 @@ func compileCall(
 			return []vm.Register{argResults[0]}, []*types.Type{types.Any}, nil
 		}
 
+		if name.Name == "channel" {
+			return compileChannelAlloc(compiledFunc, call, argResults, argTypes,
+				file)
+		}
+
+		if name.Name == lexer.TokenQuestion {
+			return compileOptionalCast(compiledFunc, call, argResults, argTypes,
+				file)
+		}
+
 		if fn, ok := builtinFunctions[name.Name]; ok {
-			ins, result, returnType, err := fn(compiledFunc, argResults)
+			if len(call.TypeArguments) > 0 {
+				return nil, nil, notGenericError(call)
+			}
+
+			var err error
+			switch {
+			case fn.oneOf != nil:
+				err = checkArgumentOneOf(file, call, fn.oneOf, argTypes)
+
+			case !fn.variadic:
+				err = checkArguments(file, call, fn.arguments, argTypes)
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+
+			ins, result, returnType, err := fn.compile(compiledFunc, argResults)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -90,31 +148,72 @@ func compileCall(
 		}
 	}
 
-	fnResult, fnType, err := compileExpr(compiledFunc, call.Expr, file, scopeOverrides)
-	if err != nil {
-		return nil, nil, err
+	var fnResult []vm.Register
+	var fnType []*types.Type
+	var err error
+	if key, ok := call.Expr.(*ast.Key); ok && enumType(compiledFunc, key.Expr) == nil {
+		objResults, objTypes, err := compileExpr(compiledFunc, key.Expr, file,
+			scopeOverrides)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The methods of a channel are compiled into instructions.
+		if objTypes[0].Kind == types.KindChannel {
+			return compileChannelMethod(compiledFunc, call, key, objResults[0],
+				objTypes[0], argResults, argTypes, file)
+		}
+
+		result, ty, err := compileKeyOf(compiledFunc, key, objResults, objTypes,
+			file, scopeOverrides)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fnResult, fnType = []vm.Register{result}, []*types.Type{ty}
+	} else {
+		fnResult, fnType, err = compileExpr(compiledFunc, call.Expr, file,
+			scopeOverrides)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	// TODO(elliot): IMPORTANT: Check argument types are valid.
 	if len(fnType) != 1 || fnType[0].Kind != types.KindFunc {
 		// TODO(elliot): Make this error message cleaner and more helpful.
 		return nil, nil, fmt.Errorf("%s cannot call %s",
 			call.Expr.Position(), fnType)
 	}
 
+	fn := fnType[0]
+	if len(fn.TypeParameters) > 0 {
+		fn, err = instantiateFunc(file, call, fn, argTypes)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if len(call.TypeArguments) > 0 {
+		return nil, nil, notGenericError(call)
+	}
+
+	err = checkArguments(file, call, fn.Arguments, argTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Prepare enough return registers.
 	var returnRegisters []vm.Register
-	for range fnType[0].Returns {
+	for range fn.Returns {
 		returnRegisters = append(returnRegisters, compiledFunc.NextRegister())
 	}
 
 	objType := types.Any
-	if len(fnType[0].Returns) == 1 &&
-		fnType[0].Returns[0].Kind == types.KindResolvedInterface {
-		objType = fnType[0].Returns[0]
+	if len(fn.Returns) == 1 &&
+		fn.Returns[0].Kind == types.KindResolvedInterface {
+		objType = fn.Returns[0]
 	}
 
-	typeRegister := file.AddType(objType)
+	// Type parameters only exist when compiling.
+	typeRegister := file.AddType(objType.Erase())
 	ins := &vm.Call{
 		FunctionName: fmt.Sprintf("*%s", string(fnResult[0])),
 		Arguments:    argResults,
@@ -125,7 +224,152 @@ func compileCall(
 
 	compiledFunc.Append(ins)
 
-	return returnRegisters, fnType[0].Returns, nil
+	return returnRegisters, fn.Returns, nil
+}
+
+func notGenericError(call *ast.Call) error {
+	return fmt.Errorf("%s %s is not a generic function",
+		call.Expr.Position(), callName(call))
+}
+
+// instantiateFunc returns the type of a generic function for a call. The type
+// arguments are either provided explicitly, such as "Stack[number]()", or are
+// inferred from the arguments.
+func instantiateFunc(
+	file *vm.File,
+	call *ast.Call,
+	fn *types.Type,
+	argTypes []*types.Type,
+) (*types.Type, error) {
+	name := callName(call)
+	pos := call.Expr.Position()
+	if pos == "" {
+		pos = call.Pos
+	}
+
+	typeArguments := map[string]*types.Type{}
+	if len(call.TypeArguments) > 0 {
+		if len(call.TypeArguments) != len(fn.TypeParameters) {
+			plural := "s"
+			if len(fn.TypeParameters) == 1 {
+				plural = ""
+			}
+
+			return nil, fmt.Errorf("%s expected %d type argument%s for %s, got %d",
+				pos, len(fn.TypeParameters), plural, name,
+				len(call.TypeArguments))
+		}
+
+		for i, typeParameter := range fn.TypeParameters {
+			typeArguments[typeParameter] = call.TypeArguments[i]
+		}
+	} else {
+		// The wrong number of arguments is a better error than not being
+		// able to infer a type.
+		if len(argTypes) != len(fn.Arguments) {
+			return nil, checkArguments(file, call, fn.Arguments, argTypes)
+		}
+
+		typeArguments = file.Types.Infer(fn.Arguments, argTypes)
+		for _, typeParameter := range fn.TypeParameters {
+			if _, ok := typeArguments[typeParameter]; !ok {
+				return nil, fmt.Errorf("%s cannot infer type %s for %s",
+					pos, typeParameter, name)
+			}
+		}
+	}
+
+	return fn.Instantiate(typeArguments), nil
+}
+
+// checkArguments returns an error if the wrong number of arguments are passed
+// to a function, or one of the arguments is not an acceptable type.
+func checkArguments(
+	file *vm.File,
+	call *ast.Call,
+	params, argTypes []*types.Type,
+) error {
+	name := callName(call)
+	pos := call.Expr.Position()
+	if pos == "" {
+		pos = call.Pos
+	}
+
+	if len(argTypes) != len(params) {
+		plural := "s"
+		if len(params) == 1 {
+			plural = ""
+		}
+
+		return fmt.Errorf("%s expected %d argument%s for %s, got %d",
+			pos, len(params), plural, name, len(argTypes))
+	}
+
+	for i, param := range params {
+		if file.Types.Accepts(param, argTypes[i]) {
+			continue
+		}
+
+		// The position of the argument can only be known if each argument
+		// is a single value.
+		if len(call.Arguments) == len(argTypes) &&
+			call.Arguments[i].Position() != "" {
+			pos = call.Arguments[i].Position()
+		}
+
+		return fmt.Errorf("%s expected %s for argument %d of %s, got %s",
+			pos, param, i+1, name, argTypes[i])
+	}
+
+	return nil
+}
+
+// checkArgumentOneOf checks a call that has a single argument, which must be
+// accepted by at least one of the types.
+func checkArgumentOneOf(
+	file *vm.File,
+	call *ast.Call,
+	oneOf, argTypes []*types.Type,
+) error {
+	// This also checks the number of arguments.
+	err := checkArguments(file, call, []*types.Type{types.Any}, argTypes)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, ty := range oneOf {
+		if file.Types.Accepts(ty, argTypes[0]) {
+			return nil
+		}
+
+		names = append(names, ty.String())
+	}
+
+	pos := call.Expr.Position()
+	if len(call.Arguments) == 1 && call.Arguments[0].Position() != "" {
+		pos = call.Arguments[0].Position()
+	}
+
+	return fmt.Errorf("%s expected %s or %s for argument 1 of %s, got %s",
+		pos, strings.Join(names[:len(names)-1], ", "), names[len(names)-1],
+		callName(call), argTypes[0])
+}
+
+// callName is the name of the function being called, as it would be written
+// in an error message.
+func callName(call *ast.Call) string {
+	switch e := call.Expr.(type) {
+	case *ast.Identifier:
+		return strings.TrimPrefix(e.Name, "^")
+
+	case *ast.Key:
+		if key, ok := e.Key.(*ast.Literal); ok {
+			return key.Value
+		}
+	}
+
+	return "function"
 }
 
 func funcNumber(compiledFunc *vm.CompiledFunc, args []vm.Register) (vm.Instruction, []vm.Register, []*types.Type, error) {
@@ -515,6 +759,23 @@ func funcEnvUnset(_ *vm.CompiledFunc, args []vm.Register) (vm.Instruction, []vm.
 	return ins, nil, nil, nil
 }
 
+func funcSnapshot(_ *vm.CompiledFunc, args []vm.Register) (vm.Instruction, []vm.Register, []*types.Type, error) {
+	ins := &vm.Snapshot{
+		Name:  args[0],
+		Value: args[1],
+	}
+
+	return ins, nil, nil, nil
+}
+
+func funcTimeout(_ *vm.CompiledFunc, args []vm.Register) (vm.Instruction, []vm.Register, []*types.Type, error) {
+	ins := &vm.Timeout{
+		Seconds: args[0],
+	}
+
+	return ins, nil, nil, nil
+}
+
 func funcUnicodeIs(
 	compiledFunc *vm.CompiledFunc,
 	args []vm.Register,
@@ -1,6 +1,7 @@
 package compiler_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/elliotchance/ok/ast"
@@ -54,6 +55,79 @@ func TestCall(t *testing.T) {
 				},
 			},
 		},
+		"len-0": {
+			nodes: []ast.Node{
+				&ast.Call{
+					Expr: &ast.Identifier{Name: "len"},
+				},
+			},
+			err: errors.New(" expected 1 argument for len, got 0"),
+		},
+		"builtin-wrong-type": {
+			nodes: []ast.Node{
+				&ast.Call{
+					Expr: &ast.Identifier{Name: "__pow"},
+					Arguments: []ast.Node{
+						asttest.NewLiteralNumber("2"),
+						asttest.NewLiteralString("3"),
+					},
+				},
+			},
+			err: errors.New(" expected number for argument 2 of __pow, got string"),
+		},
+		"len-wrong-type": {
+			nodes: []ast.Node{
+				&ast.Call{
+					Expr: &ast.Identifier{Name: "len"},
+					Arguments: []ast.Node{
+						asttest.NewLiteralNumber("3"),
+					},
+				},
+			},
+			err: errors.New(" expected string, data, []any or {}any for argument 1 of len, got number"),
+		},
+		"number-wrong-type": {
+			nodes: []ast.Node{
+				&ast.Call{
+					Expr: &ast.Identifier{Name: "number"},
+					Arguments: []ast.Node{
+						asttest.NewArrayNumbers([]string{"1"}),
+					},
+				},
+			},
+			err: errors.New(" expected char or string for argument 1 of number, got []number"),
+		},
+		"number-char": {
+			nodes: []ast.Node{
+				&ast.Call{
+					Expr: &ast.Identifier{Name: "number"},
+					Arguments: []ast.Node{
+						asttest.NewLiteralChar('a'),
+					},
+				},
+			},
+			expected: []vm.Instruction{
+				&vm.AssignSymbol{
+					Result: "1",
+					Symbol: "0",
+				},
+				&vm.CastNumber{
+					X:      "1",
+					Result: "2",
+				},
+			},
+		},
+		"char-wrong-type": {
+			nodes: []ast.Node{
+				&ast.Call{
+					Expr: &ast.Identifier{Name: "char"},
+					Arguments: []ast.Node{
+						asttest.NewLiteralString("a"),
+					},
+				},
+			},
+			err: errors.New(" expected number for argument 1 of char, got string"),
+		},
 		"assign-print": {
 			nodes: []ast.Node{
 				&ast.Assign{
@@ -173,3 +247,130 @@ func TestCall(t *testing.T) {
 		})
 	}
 }
+
+func TestCall_Generic(t *testing.T) {
+	constants := map[string]*ast.Literal{
+		"First": {
+			Kind:  types.TypeFromString("func[T]([]T) T"),
+			Value: "1",
+		},
+		"Map": {
+			Kind:  types.TypeFromString("func[T, U]([]T, func(T) U) []U"),
+			Value: "2",
+		},
+		"New": {
+			Kind:  types.TypeFromString("func[T]() []T"),
+			Value: "3",
+		},
+	}
+
+	for testName, test := range map[string]struct {
+		call *ast.Call
+		op   string
+		err  error
+	}{
+		"inferred": {
+			call: &ast.Call{
+				Expr: &ast.Identifier{Name: "First"},
+				Arguments: []ast.Node{
+					asttest.NewArrayNumbers([]string{"1"}),
+				},
+			},
+			op: "+",
+		},
+		"inferred-return-type": {
+			call: &ast.Call{
+				Expr: &ast.Identifier{Name: "First"},
+				Arguments: []ast.Node{
+					&ast.Array{
+						Elements: []ast.Node{asttest.NewLiteralString("a")},
+					},
+				},
+			},
+			op:  "+",
+			err: errors.New(" cannot perform string + number"),
+		},
+		"inferred-wrong-type": {
+			call: &ast.Call{
+				Expr: &ast.Identifier{Name: "Map"},
+				Arguments: []ast.Node{
+					asttest.NewArrayNumbers([]string{"1"}),
+					&ast.Func{
+						Arguments: []*ast.Argument{
+							{Name: "s", Type: types.String},
+						},
+						Returns: []*types.Type{types.Number},
+					},
+				},
+			},
+			err: errors.New(" expected func(number) number for argument 2 of Map, got func(string) number"),
+		},
+		"explicit": {
+			call: &ast.Call{
+				Expr:          &ast.Identifier{Name: "New"},
+				TypeArguments: []*types.Type{types.Number},
+			},
+		},
+		"explicit-wrong-type": {
+			call: &ast.Call{
+				Expr:          &ast.Identifier{Name: "First"},
+				TypeArguments: []*types.Type{types.String},
+				Arguments: []ast.Node{
+					asttest.NewArrayNumbers([]string{"1"}),
+				},
+			},
+			err: errors.New(" expected []string for argument 1 of First, got []number"),
+		},
+		"explicit-count": {
+			call: &ast.Call{
+				Expr:          &ast.Identifier{Name: "New"},
+				TypeArguments: []*types.Type{types.Number, types.String},
+			},
+			err: errors.New(" expected 1 type argument for New, got 2"),
+		},
+		"cannot-infer": {
+			call: &ast.Call{
+				Expr: &ast.Identifier{Name: "New"},
+			},
+			err: errors.New(" cannot infer type T for New"),
+		},
+		"argument-count": {
+			call: &ast.Call{
+				Expr: &ast.Identifier{Name: "First"},
+			},
+			err: errors.New(" expected 1 argument for First, got 0"),
+		},
+		"not-generic": {
+			call: &ast.Call{
+				Expr:          &ast.Identifier{Name: "len"},
+				TypeArguments: []*types.Type{types.Number},
+				Arguments: []ast.Node{
+					asttest.NewArrayNumbers(nil),
+				},
+			},
+			err: errors.New(" len is not a generic function"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			var node ast.Node = test.call
+			if test.op != "" {
+				node = &ast.Binary{
+					Left:  test.call,
+					Op:    test.op,
+					Right: asttest.NewLiteralNumber("1"),
+				}
+			}
+
+			_, err := compiler.CompileFunc(newFunc(node),
+				&vm.File{
+					Types:   types.Registry{},
+					Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+				}, nil, constants, nil, nil)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+// compileChannelAlloc creates a channel, such as "channel[number](10)". The
+// size of the buffer is optional. Without a buffer each send blocks until the
+// value is received.
+func compileChannelAlloc(
+	compiledFunc *vm.CompiledFunc,
+	call *ast.Call,
+	argResults []vm.Register,
+	argTypes []*types.Type,
+	file *vm.File,
+) ([]vm.Register, []*types.Type, error) {
+	if len(call.TypeArguments) != 1 {
+		return nil, nil, fmt.Errorf("%s expected 1 type argument for channel, got %d",
+			call.Expr.Position(), len(call.TypeArguments))
+	}
+
+	if len(argTypes) == 0 {
+		size, _ := compileLiteral(compiledFunc, asttest.NewLiteralNumber("0"),
+			file)
+		argResults = []vm.Register{size}
+	} else {
+		err := checkArguments(file, call, []*types.Type{types.Number}, argTypes)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ty := types.NewChannel(call.TypeArguments[0])
+	result := compiledFunc.NextRegister()
+	compiledFunc.Append(&vm.ChannelAlloc{
+		Size:   argResults[0],
+		Result: result,
+		Kind:   file.AddType(ty.Erase()),
+	})
+
+	return []vm.Register{result}, []*types.Type{ty}, nil
+}
+
+// compileChannelMethod compiles a call to Send, Receive or Close on a channel.
+func compileChannelMethod(
+	compiledFunc *vm.CompiledFunc,
+	call *ast.Call,
+	key *ast.Key,
+	channel vm.Register,
+	ty *types.Type,
+	argResults []vm.Register,
+	argTypes []*types.Type,
+	file *vm.File,
+) ([]vm.Register, []*types.Type, error) {
+	if len(call.TypeArguments) > 0 {
+		return nil, nil, notGenericError(call)
+	}
+
+	switch callName(call) {
+	case "Send":
+		err := checkArguments(file, call, []*types.Type{ty.Element}, argTypes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		compiledFunc.Append(&vm.Send{
+			Channel: channel,
+			Value:   argResults[0],
+		})
+
+		return nil, nil, nil
+
+	case "Receive":
+		err := checkArguments(file, call, nil, argTypes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		result := compiledFunc.NextRegister()
+		compiledFunc.Append(&vm.Receive{
+			Channel: channel,
+			Result:  result,
+		})
+
+		return []vm.Register{result}, []*types.Type{ty.Element}, nil
+
+	case "Close":
+		err := checkArguments(file, call, nil, argTypes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		compiledFunc.Append(&vm.CloseChannel{
+			Channel: channel,
+		})
+
+		return nil, nil, nil
+	}
+
+	return nil, nil, fmt.Errorf("%s %s does not have method %s",
+		key.Position(), ty, callName(call))
+}
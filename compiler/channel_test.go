@@ -0,0 +1,185 @@
+package compiler_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newChannelMethod(name string, args ...ast.Node) *ast.Call {
+	return &ast.Call{
+		Expr: &ast.Key{
+			Expr: &ast.Identifier{Name: "c"},
+			Key:  asttest.NewLiteralString(name),
+		},
+		Arguments: args,
+	}
+}
+
+func TestChannel(t *testing.T) {
+	newChannel := &ast.Assign{
+		Lefts: []ast.Node{&ast.Identifier{Name: "c"}},
+		Rights: []ast.Node{&ast.Call{
+			Expr:          &ast.Identifier{Name: "channel"},
+			TypeArguments: []*types.Type{types.Number},
+		}},
+	}
+
+	for testName, test := range map[string]struct {
+		nodes []ast.Node
+		err   error
+	}{
+		"buffered": {
+			nodes: []ast.Node{&ast.Call{
+				Expr:          &ast.Identifier{Name: "channel"},
+				TypeArguments: []*types.Type{types.String},
+				Arguments:     []ast.Node{asttest.NewLiteralNumber("3")},
+			}},
+		},
+		"missing-type-argument": {
+			nodes: []ast.Node{&ast.Call{
+				Expr: &ast.Identifier{Name: "channel"},
+			}},
+			err: errors.New(" expected 1 type argument for channel, got 0"),
+		},
+		"size-not-number": {
+			nodes: []ast.Node{&ast.Call{
+				Expr:          &ast.Identifier{Name: "channel"},
+				TypeArguments: []*types.Type{types.String},
+				Arguments:     []ast.Node{asttest.NewLiteralString("3")},
+			}},
+			err: errors.New(" expected number for argument 1 of channel, got string"),
+		},
+		"send-receive-close": {
+			nodes: []ast.Node{
+				newChannel,
+				newChannelMethod("Send", asttest.NewLiteralNumber("1")),
+				newChannelMethod("Receive"),
+				newChannelMethod("Close"),
+			},
+		},
+		"send-wrong-type": {
+			nodes: []ast.Node{
+				newChannel,
+				newChannelMethod("Send", asttest.NewLiteralString("1")),
+			},
+			err: errors.New(" expected number for argument 1 of Send, got string"),
+		},
+		"unknown-method": {
+			nodes: []ast.Node{
+				newChannel,
+				newChannelMethod("Length"),
+			},
+			err: errors.New(" chan number does not have method Length"),
+		},
+		"method-not-called": {
+			nodes: []ast.Node{
+				newChannel,
+				&ast.Key{
+					Expr: &ast.Identifier{Name: "c"},
+					Key:  asttest.NewLiteralString("Send"),
+				},
+			},
+			err: errors.New(" methods of chan number must be called"),
+		},
+		"for-in": {
+			nodes: []ast.Node{
+				newChannel,
+				&ast.For{
+					Condition: &ast.In{
+						Value: "v",
+						Expr:  &ast.Identifier{Name: "c"},
+					},
+				},
+			},
+		},
+		"for-in-key": {
+			nodes: []ast.Node{
+				newChannel,
+				&ast.For{
+					Condition: &ast.In{
+						Key:   "k",
+						Value: "v",
+						Expr:  &ast.Identifier{Name: "c"},
+					},
+				},
+			},
+			err: errors.New(": cannot iterate chan number with a key"),
+		},
+		"select": {
+			nodes: []ast.Node{
+				newChannel,
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{
+							Op: &ast.Assign{
+								Lefts:  []ast.Node{&ast.Identifier{Name: "v"}},
+								Rights: []ast.Node{newChannelMethod("Receive")},
+							},
+						},
+						{
+							Op: newChannelMethod("Send", asttest.NewLiteralNumber("2")),
+						},
+					},
+					Else: []ast.Node{},
+				},
+			},
+		},
+		"select-close": {
+			nodes: []ast.Node{
+				newChannel,
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{Op: newChannelMethod("Close")},
+					},
+				},
+			},
+			err: errors.New(" select case must call Send or Receive on a channel"),
+		},
+		"select-not-channel": {
+			nodes: []ast.Node{
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{Op: &ast.Call{
+							Expr: &ast.Key{
+								Expr: asttest.NewLiteralString("c"),
+								Key:  asttest.NewLiteralString("Receive"),
+							},
+						}},
+					},
+				},
+			},
+			err: errors.New(" select case must call Send or Receive on a channel"),
+		},
+		"spawn-builtin": {
+			nodes: []ast.Node{
+				&ast.Spawn{
+					Call: &ast.Call{
+						Expr:      &ast.Identifier{Name: "print"},
+						Arguments: []ast.Node{asttest.NewLiteralString("hi")},
+					},
+				},
+			},
+			err: errors.New(" cannot spawn print"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			_, err := compiler.CompileFunc(newFunc(test.nodes...),
+				&vm.File{
+					Types:   types.Registry{},
+					Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+				}, nil, nil, nil, nil)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
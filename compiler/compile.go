@@ -22,22 +22,115 @@ func Compile(
 	anonFunctionName *int,
 	verbose bool,
 ) (*vm.File, *types.Type, []error) {
-	*anonFunctionName += 10000
-	p := parser.NewParser(*anonFunctionName)
+	return compile(nil, rootPath, pkgPath, includeTests, anonFunctionName,
+		verbose)
+}
 
+// packageDir is the directory that contains the source for pkgPath.
+func packageDir(rootPath, pkgPath string) string {
 	// TODO(elliot): This will prevent import from legitimately importing any
 	//  packages that exist as a root. We need to keep track of the real stdlib
 	//  imports.
-	dir := path.Join(rootPath, pkgPath)
 	if !strings.Contains(pkgPath, "/") {
-		dir = "/" + pkgPath
+		return "/" + pkgPath
+	}
+
+	return path.Join(rootPath, pkgPath)
+}
+
+// compile is the same as Compile, except that packages will be taken from the
+// cache if they have not changed. The cache may be nil, in which case only the
+// compile cache on disk is used (see vm.Store).
+func compile(
+	cache *Cache,
+	rootPath,
+	pkgPath string,
+	includeTests bool,
+	anonFunctionName *int,
+	verbose bool,
+) (*vm.File, *types.Type, []error) {
+	dir := packageDir(rootPath, pkgPath)
+	if file, pkgType := cache.get(dir, includeTests); file != nil {
+		return file, pkgType, nil
 	}
 
+	key := okcKey(rootPath, dir, includeTests)
+	if key != "" {
+		file, pkgType := loadOKC(rootPath, pkgPath, dir, key, includeTests)
+		if file != nil {
+			var importDirs []string
+			for sourceDir := range file.Sources {
+				if sourceDir != dir {
+					importDirs = append(importDirs, sourceDir)
+				}
+			}
+			cache.setImportDirs(dir, importDirs)
+
+			if err := cache.put(dir, includeTests, file, pkgType); err != nil {
+				return nil, nil, []error{err}
+			}
+
+			return file, pkgType, nil
+		}
+	}
+
+	*anonFunctionName += 10000
+	functionNames := *anonFunctionName
+	if key != "" {
+		functionNames = anonFunctionBase(key)
+	}
+	p := parser.NewParser(functionNames)
+
 	p.ParseDirectory(dir, includeTests)
 	if errs := p.Errors(); len(errs) > 0 {
 		return nil, nil, errs
 	}
 
+	cache.setImports(dir, rootPath, p.Imports())
+	file, pkgType, errs := compilePackage(cache, p, rootPath, pkgPath,
+		anonFunctionName, verbose)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	if key != "" {
+		file.Sources[dir] = key
+		if err := vm.Store(file, key); err != nil {
+			return nil, nil, []error{err}
+		}
+	}
+
+	if err := cache.put(dir, includeTests, file, pkgType); err != nil {
+		return nil, nil, []error{err}
+	}
+
+	return file, pkgType, nil
+}
+
+// CompilePackage is the same as Compile, except the package has already been
+// parsed. This allows the source of the package to come from somewhere other
+// than the files, such as unsaved files in an editor.
+//
+// The parser must not contain any errors. Tests will be compiled if they were
+// parsed.
+func CompilePackage(
+	p *parser.Parser,
+	rootPath,
+	pkgPath string,
+	anonFunctionName *int,
+	verbose bool,
+) (*vm.File, *types.Type, []error) {
+	return compilePackage(nil, p, rootPath, pkgPath, anonFunctionName, verbose)
+}
+
+func compilePackage(
+	cache *Cache,
+	p *parser.Parser,
+	rootPath,
+	pkgPath string,
+	anonFunctionName *int,
+	verbose bool,
+) (*vm.File, *types.Type, []error) {
 	packageName := util.PackageNameFromPath(rootPath, pkgPath)
 	imports := map[string]*types.Type{}
 	var dependencies []*vm.File
@@ -53,7 +146,7 @@ func Compile(
 	for _, pkgName := range importNames {
 		// TODO(elliot): Check import location exists.
 
-		subFile, packageFunc, errs := Compile(rootPath, pkgName,
+		subFile, packageFunc, errs := compile(cache, rootPath, pkgName,
 			false, anonFunctionName, verbose)
 		if len(errs) > 0 {
 			return nil, nil, errs
@@ -67,6 +160,7 @@ func Compile(
 		Types:   types.Registry{},
 		Symbols: map[vm.SymbolRegister]*vm.Symbol{},
 		Globals: map[string]string{},
+		Sources: map[string]string{},
 	}
 
 	err := p.ResolveTypes(file.Types, imports)
@@ -156,12 +250,21 @@ func Compile(
 	// resolve the type now for the return later.
 	compiledPackageFnType := file.Types.Get(string(compiledPackageFn.Type)).Returns[0]
 	if len(imports) > 0 {
+		sources := file.Sources
+		for _, dependency := range dependencies {
+			for dir, key := range dependency.Sources {
+				sources[dir] = key
+			}
+		}
+
 		file = vm.Merge(append(append([]*vm.File(nil), file), dependencies...)...)
+		file.Sources = sources
 	}
 
 	// Compile and append tests, if any. Only in the root level package.
 	for _, test := range p.Tests() {
-		compiledTest, err := CompileTest(test, file, p.Constants, imports)
+		compiledTest, err := CompileTest(test, p.Hooks(), file, p.Constants,
+			imports)
 		if err != nil {
 			return nil, nil, []error{err}
 		}
@@ -169,11 +272,6 @@ func Compile(
 		file.Tests = append(file.Tests, compiledTest)
 	}
 
-	err = vm.Store(file, packageName)
-	if err != nil {
-		return nil, nil, []error{err}
-	}
-
 	if verbose {
 		// TODO(elliot): Fix plurals.
 		fmt.Printf("compiled %s: %d symbols, %d types\n",
@@ -0,0 +1,148 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+// enumType returns the enum when expr is the name of an enum, such as "Level"
+// or "log.Level", rather than a value. Otherwise, nil is returned.
+func enumType(compiledFunc *vm.CompiledFunc, expr ast.Node) *types.Type {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		c, ok := compiledFunc.Constants[e.Name]
+		if ok && c.Kind.Kind == types.KindEnum {
+			return c.Kind
+		}
+
+	case *ast.Key:
+		// Enums are types, so they are not a value of the package. They are
+		// found with the package type instead.
+		pkg, ok := e.Expr.(*ast.Identifier)
+		key, ok2 := e.Key.(*ast.Literal)
+		if !ok || !ok2 {
+			return nil
+		}
+
+		c, ok := compiledFunc.Constants[pkg.Name]
+		if !ok || !c.IsGlobal {
+			return nil
+		}
+
+		ty := c.Kind.Properties[key.Value]
+		if ty != nil && ty.Kind == types.KindEnum {
+			return ty
+		}
+	}
+
+	return nil
+}
+
+// compileEnumValue compiles a value of an enum, such as "Level.Debug".
+func compileEnumValue(
+	compiledFunc *vm.CompiledFunc,
+	n *ast.Key,
+	enum *types.Type,
+	file *vm.File,
+) (vm.Register, *types.Type, error) {
+	name := n.Key.(*ast.Literal).Value
+	if !enum.HasValue(name) {
+		return "", nil, fmt.Errorf("%s %s has no value %s",
+			n.Position(), enum.Name, name)
+	}
+
+	result, ty := compileLiteral(compiledFunc, &ast.Literal{
+		Kind:  enum,
+		Value: name,
+	}, file)
+
+	return result, ty, nil
+}
+
+// compileEnumValues compiles the name of an enum when it is used as a value.
+// It is an array of all of the values, in the order they were declared. This
+// allows an enum to be iterated, like "for level in Level".
+func compileEnumValues(
+	compiledFunc *vm.CompiledFunc,
+	enum *types.Type,
+	file *vm.File,
+	scopeOverrides map[string]*types.Type,
+) (vm.Register, *types.Type, error) {
+	array := &ast.Array{
+		Kind: enum.ToArray(),
+	}
+
+	for _, value := range enum.Values {
+		array.Elements = append(array.Elements, &ast.Literal{
+			Kind:  enum,
+			Value: value,
+		})
+	}
+
+	return compileArray(compiledFunc, array, file, scopeOverrides)
+}
+
+// compileEnumCast converts a string into a value of the enum, such as
+// "Level("Debug")". An error is raised at runtime if the enum does not have a
+// value with that name.
+func compileEnumCast(
+	compiledFunc *vm.CompiledFunc,
+	call *ast.Call,
+	enum *types.Type,
+	argResults []vm.Register,
+	argTypes []*types.Type,
+	file *vm.File,
+) ([]vm.Register, []*types.Type, error) {
+	if len(call.TypeArguments) > 0 {
+		return nil, nil, notGenericError(call)
+	}
+
+	err := checkArguments(file, call, []*types.Type{types.String}, argTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := compiledFunc.NextRegister()
+	compiledFunc.Append(&vm.CastEnum{
+		X:      argResults[0],
+		Result: result,
+		Type:   file.AddType(enum),
+	})
+
+	return []vm.Register{result}, []*types.Type{enum}, nil
+}
+
+// missingEnumValues returns the values of the enum that are not handled by any
+// case of a switch, in the order they were declared. Only cases that are
+// values of the enum, like "Level.Debug", are known to handle a value.
+func missingEnumValues(
+	compiledFunc *vm.CompiledFunc,
+	n *ast.Switch,
+	enum *types.Type,
+) []string {
+	handled := map[string]bool{}
+	for _, caseStmt := range n.Cases {
+		for _, condition := range caseStmt.Conditions {
+			key, ok := condition.(*ast.Key)
+			if !ok || enumType(compiledFunc, key.Expr) == nil {
+				continue
+			}
+
+			if value, ok := key.Key.(*ast.Literal); ok {
+				handled[value.Value] = true
+			}
+		}
+	}
+
+	var missing []string
+	for _, value := range enum.Values {
+		if !handled[value] {
+			missing = append(missing, value)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,205 @@
+package compiler_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEnumValue(name string) *ast.Key {
+	return &ast.Key{
+		Expr: &ast.Identifier{Name: "Level"},
+		Key:  asttest.NewLiteralString(name),
+	}
+}
+
+func TestEnum(t *testing.T) {
+	level := types.NewEnum("Level", []string{"Debug", "Info", "Warn"})
+	constants := map[string]*ast.Literal{
+		"Level": {Kind: level},
+		"Color": {Kind: types.NewEnum("Color", []string{"Red"})},
+	}
+
+	for testName, test := range map[string]struct {
+		node ast.Node
+		err  error
+	}{
+		"value": {
+			node: newEnumValue("Info"),
+		},
+		"missing-value": {
+			node: newEnumValue("Error"),
+			err:  errors.New(" Level has no value Error"),
+		},
+		"values": {
+			node: &ast.Identifier{Name: "Level"},
+		},
+		"element": {
+			node: &ast.Key{
+				Expr: &ast.Identifier{Name: "Level"},
+				Key:  asttest.NewLiteralNumber("1"),
+			},
+		},
+		"cast": {
+			node: &ast.Call{
+				Expr:      &ast.Identifier{Name: "Level"},
+				Arguments: []ast.Node{asttest.NewLiteralString("Warn")},
+			},
+		},
+		"cast-wrong-type": {
+			node: &ast.Call{
+				Expr:      &ast.Identifier{Name: "Level"},
+				Arguments: []ast.Node{asttest.NewLiteralNumber("1")},
+			},
+			err: errors.New(" expected string for argument 1 of Level, got number"),
+		},
+		"to-string": {
+			node: &ast.Call{
+				Expr:      &ast.Identifier{Name: "string"},
+				Arguments: []ast.Node{newEnumValue("Debug")},
+			},
+		},
+		"equal": {
+			node: asttest.NewBinary(newEnumValue("Debug"), lexer.TokenEqual, newEnumValue("Info")),
+		},
+		"not-equal": {
+			node: asttest.NewBinary(newEnumValue("Debug"), lexer.TokenNotEqual, newEnumValue("Info")),
+		},
+		"equal-string": {
+			node: asttest.NewBinary(newEnumValue("Debug"), lexer.TokenEqual, asttest.NewLiteralString("Debug")),
+			err:  errors.New(" cannot perform Level == string"),
+		},
+		"equal-other-enum": {
+			node: asttest.NewBinary(newEnumValue("Debug"), lexer.TokenEqual, &ast.Key{
+				Expr: &ast.Identifier{Name: "Color"},
+				Key:  asttest.NewLiteralString("Red"),
+			}),
+			err: errors.New(" cannot perform Level == Color"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			_, err := compiler.CompileFunc(newFunc(test.node),
+				&vm.File{
+					Types:   types.Registry{},
+					Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+				}, nil, constants, nil, nil)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEnum_Switch(t *testing.T) {
+	constants := map[string]*ast.Literal{
+		"Level": {Kind: types.NewEnum("Level", []string{"Debug", "Info", "Warn"})},
+	}
+
+	newCase := func(values ...string) *ast.Case {
+		c := &ast.Case{}
+		for _, value := range values {
+			c.Conditions = append(c.Conditions, newEnumValue(value))
+		}
+
+		return c
+	}
+
+	for testName, test := range map[string]struct {
+		node *ast.Switch
+		err  error
+	}{
+		"all-values": {
+			node: &ast.Switch{
+				Expr:  newEnumValue("Info"),
+				Cases: []*ast.Case{newCase("Debug"), newCase("Info", "Warn")},
+			},
+		},
+		"missing-values": {
+			node: &ast.Switch{
+				Expr:  newEnumValue("Info"),
+				Cases: []*ast.Case{newCase("Info")},
+			},
+			err: errors.New(" switch on Level does not handle Debug, Warn"),
+		},
+		"else": {
+			node: &ast.Switch{
+				Expr:  newEnumValue("Info"),
+				Cases: []*ast.Case{newCase("Info")},
+				Else:  []ast.Node{},
+			},
+		},
+		"no-cases": {
+			node: &ast.Switch{
+				Expr: newEnumValue("Info"),
+			},
+			err: errors.New(" switch on Level does not handle Debug, Info, Warn"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			_, err := compiler.CompileFunc(newFunc(test.node),
+				&vm.File{
+					Types:   types.Registry{},
+					Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+				}, nil, constants, nil, nil)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEnum_Declared(t *testing.T) {
+	for testName, test := range map[string]struct {
+		source string
+		err    string
+	}{
+		"enum": {
+			source: "enum Color { Blue }",
+			err:    "b.ok:1:1 Color is already declared",
+		},
+		"func": {
+			source: "func Color() Color {\n    return Color.Red\n}",
+			err:    "b.ok:1:1 Color is already declared",
+		},
+		"constant": {
+			source: "Color = 1",
+			err:    "b.ok:1:1 Color is already declared",
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			okPath, err := ioutil.TempDir("", "ok-enum-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(okPath)
+
+			defer os.Setenv("OKCACHE", os.Getenv("OKCACHE"))
+			require.NoError(t, os.Setenv("OKCACHE", filepath.Join(okPath, "cache")))
+
+			// The declarations are in separate files of the same package.
+			dir := filepath.Join(okPath, "x", "enum")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.ok"),
+				[]byte("enum Color { Red, Green }\n\nfunc main() {\n    print(Color.Red)\n}"), 0644))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.ok"),
+				[]byte(test.source), 0644))
+
+			_, _, errs := compiler.Compile(okPath, "x/enum", false, new(int), false)
+			require.Len(t, errs, 1)
+			assert.EqualError(t, errs[0], filepath.Join(dir, test.err))
+		})
+	}
+}
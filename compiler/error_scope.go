@@ -111,9 +111,18 @@ func compileErrorScope(
 			return err
 		}
 
-		finallyInstructions := compiledFunc.Instructions.Instructions[beforeLen:]
-		compiledFunc.Finally = append(compiledFunc.Finally,
-			vm.NewInstructions(finallyInstructions...))
+		finallyInstructions := vm.NewInstructions(
+			compiledFunc.Instructions.Instructions[beforeLen:]...)
+		for index, pos := range compiledFunc.Instructions.Positions {
+			if index >= beforeLen {
+				if finallyInstructions.Positions == nil {
+					finallyInstructions.Positions = map[int]string{}
+				}
+
+				finallyInstructions.Positions[index-beforeLen] = pos
+			}
+		}
+		compiledFunc.Finally = append(compiledFunc.Finally, finallyInstructions)
 	}
 
 	return nil
@@ -13,6 +13,16 @@ func compileExpr(
 	file *vm.File,
 	scopeOverrides map[string]*types.Type,
 ) ([]vm.Register, []*types.Type, error) {
+	if enum := enumType(compiledFunc, expr); enum != nil {
+		result, ty, err := compileEnumValues(compiledFunc, enum, file,
+			scopeOverrides)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return []vm.Register{result}, []*types.Type{ty}, nil
+	}
+
 	switch e := expr.(type) {
 	case *ast.Assign:
 		err := compileAssign(compiledFunc, e, file, scopeOverrides)
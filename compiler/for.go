@@ -45,6 +45,27 @@ func compileFor(
 		case types.KindString:
 			compiledFunc.NewVariable(cond.Value, types.Char)
 
+		case types.KindChannel, types.KindResolvedInterface:
+			// A channel finishes when it is closed. An iterator finishes when
+			// Next returns false. Neither of them have keys.
+			element := arrayOrMapKind[0].Element
+			if arrayOrMapKind[0].Kind == types.KindResolvedInterface {
+				next := iteratorNext(arrayOrMapKind[0])
+				if next == nil {
+					return fmt.Errorf("%s: %s is not iterable", n.Pos,
+						arrayOrMapKind[0])
+				}
+
+				element = next.Returns[0]
+			}
+
+			if cond.Key != "" {
+				return fmt.Errorf("%s: cannot iterate %s with a key",
+					n.Pos, arrayOrMapKind[0])
+			}
+
+			compiledFunc.NewVariable(cond.Value, element)
+
 		default:
 			return fmt.Errorf("%s: %s is not iterable", n.Pos, arrayOrMapKind[0])
 		}
@@ -62,40 +83,71 @@ func compileFor(
 			}
 		}
 
-		cursorRegister := compiledFunc.NextRegister()
-		compiledFunc.Append(&vm.AssignSymbol{
-			Result: cursorRegister,
-			Symbol: file.AddSymbolLiteral(asttest.NewLiteralNumber("0")),
-		})
-
-		conditionResults = []vm.Register{compiledFunc.NextRegister()}
 		switch arrayOrMapKind[0].Kind {
-		case types.KindArray:
-			compiledFunc.Append(&vm.NextArray{
-				Array:       arrayOrMapResults[0],
-				Cursor:      cursorRegister,
-				KeyResult:   vm.Register(cond.Key),
+		case types.KindChannel:
+			conditionResults = []vm.Register{compiledFunc.NextRegister()}
+			compiledFunc.Append(&vm.NextChannel{
+				Channel:     arrayOrMapResults[0],
 				ValueResult: vm.Register(cond.Value),
 				Result:      conditionResults[0],
 			})
 
-		case types.KindMap:
-			compiledFunc.Append(&vm.NextMap{
-				Map:         arrayOrMapResults[0],
-				Cursor:      cursorRegister,
-				KeyResult:   vm.Register(cond.Key),
-				ValueResult: vm.Register(cond.Value),
-				Result:      conditionResults[0],
+		case types.KindResolvedInterface:
+			// The Next method is only looked up once, before the loop.
+			nextRegister, _, err := compileKeyOf(compiledFunc, &ast.Key{
+				Expr: cond.Expr,
+				Key:  asttest.NewLiteralString("Next"),
+			}, arrayOrMapResults, arrayOrMapKind, file, scopeOverrides)
+			if err != nil {
+				return err
+			}
+
+			conditionResults = []vm.Register{compiledFunc.NextRegister()}
+			compiledFunc.Append(&vm.Call{
+				FunctionName: fmt.Sprintf("*%s", string(nextRegister)),
+				Results: []vm.Register{
+					vm.Register(cond.Value), conditionResults[0],
+				},
+				Type: file.AddType(types.Any),
+				Pos:  n.Pos,
 			})
 
-		case types.KindString:
-			compiledFunc.Append(&vm.NextString{
-				Str:         arrayOrMapResults[0],
-				Cursor:      cursorRegister,
-				KeyResult:   vm.Register(cond.Key),
-				ValueResult: vm.Register(cond.Value),
-				Result:      conditionResults[0],
+		default:
+			cursorRegister := compiledFunc.NextRegister()
+			compiledFunc.Append(&vm.AssignSymbol{
+				Result: cursorRegister,
+				Symbol: file.AddSymbolLiteral(asttest.NewLiteralNumber("0")),
 			})
+
+			conditionResults = []vm.Register{compiledFunc.NextRegister()}
+			switch arrayOrMapKind[0].Kind {
+			case types.KindArray:
+				compiledFunc.Append(&vm.NextArray{
+					Array:       arrayOrMapResults[0],
+					Cursor:      cursorRegister,
+					KeyResult:   vm.Register(cond.Key),
+					ValueResult: vm.Register(cond.Value),
+					Result:      conditionResults[0],
+				})
+
+			case types.KindMap:
+				compiledFunc.Append(&vm.NextMap{
+					Map:         arrayOrMapResults[0],
+					Cursor:      cursorRegister,
+					KeyResult:   vm.Register(cond.Key),
+					ValueResult: vm.Register(cond.Value),
+					Result:      conditionResults[0],
+				})
+
+			case types.KindString:
+				compiledFunc.Append(&vm.NextString{
+					Str:         arrayOrMapResults[0],
+					Cursor:      cursorRegister,
+					KeyResult:   vm.Register(cond.Key),
+					ValueResult: vm.Register(cond.Value),
+					Result:      conditionResults[0],
+				})
+			}
 		}
 
 	default:
@@ -168,3 +220,17 @@ func compileFor(
 
 	return nil
 }
+
+// iteratorNext returns the type of the Next method of an iterator, or nil if
+// the object is not an iterator. An iterator is any object with a method
+// "Next() (T, bool)" that returns the next value and true, or false when there
+// are no more values.
+func iteratorNext(ty *types.Type) *types.Type {
+	next := ty.Properties["Next"]
+	if next == nil || next.Kind != types.KindFunc || len(next.Arguments) > 0 ||
+		len(next.Returns) != 2 || next.Returns[1].Kind != types.KindBool {
+		return nil
+	}
+
+	return next
+}
@@ -1,6 +1,7 @@
 package compiler_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/elliotchance/ok/ast"
@@ -873,3 +874,85 @@ func TestFor(t *testing.T) {
 		})
 	}
 }
+
+func TestFor_Iterator(t *testing.T) {
+	newIterator := func(next *types.Type) *types.Type {
+		return types.NewInterface("Iter", map[string]*types.Type{"Next": next})
+	}
+
+	for testName, test := range map[string]struct {
+		iterator *types.Type
+		key      string
+		expected []vm.Instruction
+		err      error
+	}{
+		"next": {
+			iterator: newIterator(types.NewFunc(nil,
+				[]*types.Type{types.String, types.Bool})),
+			expected: []vm.Instruction{
+				&vm.AssignSymbol{
+					Result: "1",
+					Symbol: "0",
+				},
+				&vm.MapGet{
+					Map:    "it",
+					Key:    "1",
+					Result: "2",
+				},
+				&vm.Call{
+					FunctionName: "*2",
+					Results:      []vm.Register{"v", "3"},
+					Type:         "2",
+				},
+				&vm.JumpUnless{
+					Condition: "3",
+					To:        4,
+				},
+				&vm.Jump{
+					To: 1,
+				},
+			},
+		},
+		"key": {
+			iterator: newIterator(types.NewFunc(nil,
+				[]*types.Type{types.String, types.Bool})),
+			key: "k",
+			err: errors.New(": cannot iterate Iter with a key"),
+		},
+		"no-next": {
+			iterator: types.NewInterface("Iter", map[string]*types.Type{
+				"Prev": types.NewFunc(nil, []*types.Type{types.String, types.Bool}),
+			}),
+			err: errors.New(": Iter is not iterable"),
+		},
+		"next-not-bool": {
+			iterator: newIterator(types.NewFunc(nil,
+				[]*types.Type{types.String, types.Number})),
+			err: errors.New(": Iter is not iterable"),
+		},
+		"next-with-arguments": {
+			iterator: newIterator(types.NewFunc([]*types.Type{types.Number},
+				[]*types.Type{types.String, types.Bool})),
+			err: errors.New(": Iter is not iterable"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			compiledFunc, err := compiler.CompileFunc(newFunc(&ast.For{
+				Condition: &ast.In{
+					Key:   test.key,
+					Value: "v",
+					Expr:  &ast.Identifier{Name: "it"},
+				},
+			}), &vm.File{
+				Types:   types.Registry{},
+				Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+			}, nil, nil, nil, map[string]*types.Type{"it": test.iterator})
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, compiledFunc.Instructions.Instructions)
+			}
+		})
+	}
+}
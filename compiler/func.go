@@ -52,6 +52,24 @@ func CompileFunc(
 		})
 	}
 
+	err = compileDeferredFuncs(compiled, file, constants, imports,
+		scopeOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return compiled, nil
+}
+
+// compileDeferredFuncs compiles the function literals that were found while
+// compiling the scope of compiled.
+func compileDeferredFuncs(
+	compiled *vm.CompiledFunc,
+	file *vm.File,
+	constants map[string]*ast.Literal,
+	imports map[string]*types.Type,
+	scopeOverrides map[string]*types.Type,
+) error {
 	// Now we have finished compiling this scope (and so have discovered and
 	// resolved the type of all variables) we can now compile all the deferred
 	// function literals that might reference variables in this scope.
@@ -59,14 +77,14 @@ func CompileFunc(
 	for _, fn := range compiled.DeferredFuncsToCompile {
 		cf, err := CompileFunc(fn.Func, file, compiled, constants, imports, scopeOverrides)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		file.AddSymbolFunc(cf)
 
 		compiled.Append(&vm.AssignFunc{
 			Result:     fn.Register,
-			Type:       file.AddType(fn.Func.Type()),
+			Type:       file.AddType(fn.Func.Type().Erase()),
 			UniqueName: fn.Func.UniqueName,
 		})
 
@@ -98,9 +116,15 @@ func CompileFunc(
 
 			compiled.Instructions.Instructions[index] = ins
 		}
+
+		positions := map[int]string{}
+		for index, pos := range compiled.Instructions.Positions {
+			positions[index+count] = pos
+		}
+		compiled.Instructions.Positions = positions
 	}
 
-	return compiled, nil
+	return nil
 }
 
 func compileFunc(
@@ -35,13 +35,8 @@ func compileIf(
 	}
 	compiledFunc.Append(ins)
 
-	// The true scope may contain an override, but the false scope must not.
-	trueScope := scopeOverrides
-	if condition, ok := n.Condition.(*ast.Binary); ok && condition.Op == lexer.TokenIs {
-		trueScope = appendScope(trueScope,
-			condition.Left.(*ast.Identifier).Name,
-			types.TypeFromString(condition.Right.(*ast.Identifier).Name))
-	}
+	trueScope, falseScope := narrowScopes(compiledFunc, n.Condition,
+		scopeOverrides)
 
 	err = compileBlock(compiledFunc, n.True, breakIns, continueIns, file,
 		trueScope)
@@ -59,7 +54,7 @@ func compileIf(
 		ins.To = len(compiledFunc.Instructions.Instructions) - 1
 
 		err = compileBlock(compiledFunc, n.False, breakIns, continueIns, file,
-			scopeOverrides)
+			falseScope)
 		if err != nil {
 			return err
 		}
@@ -76,6 +71,51 @@ func compileIf(
 	return nil
 }
 
+// narrowScopes returns the scopes for when an if condition is true and false.
+// "x is T" means that x is a T when the condition is true. An optional is not
+// nil when "x is not nil" is true, or when "x is nil" is false.
+func narrowScopes(
+	compiledFunc *vm.CompiledFunc,
+	condition ast.Node,
+	scope map[string]*types.Type,
+) (trueScope, falseScope map[string]*types.Type) {
+	trueScope, falseScope = scope, scope
+
+	not := false
+	if unary, ok := condition.(*ast.Unary); ok && unary.Op == lexer.TokenNot {
+		condition = unary.Expr
+		not = true
+	}
+
+	binary, ok := condition.(*ast.Binary)
+	if !ok || binary.Op != lexer.TokenIs {
+		return
+	}
+
+	name := binary.Left.(*ast.Identifier).Name
+	ty := types.TypeFromString(binary.Right.(*ast.Identifier).Name)
+	if ty.Kind != types.KindNil {
+		if !not {
+			trueScope = appendScope(scope, name, ty)
+		}
+
+		return
+	}
+
+	optional, ok := compiledFunc.GetTypeForVariable(name, scope)
+	if !ok || optional.Kind != types.KindOptional {
+		return
+	}
+
+	if not {
+		trueScope = appendScope(scope, name, optional.Element)
+	} else {
+		falseScope = appendScope(scope, name, optional.Element)
+	}
+
+	return
+}
+
 func appendScope(
 	scope map[string]*types.Type,
 	name string,
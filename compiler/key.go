@@ -1,6 +1,8 @@
 package compiler
 
 import (
+	"fmt"
+
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/ast/asttest"
 	"github.com/elliotchance/ok/types"
@@ -13,12 +15,34 @@ func compileKey(
 	file *vm.File,
 	scopeOverrides map[string]*types.Type,
 ) (vm.Register, *types.Type, error) {
+	// A value of an enum, like "Level.Debug". Other keys, like "Level[0]", are
+	// an element of all the values.
+	if enum := enumType(compiledFunc, n.Expr); enum != nil {
+		if key, ok := n.Key.(*ast.Literal); ok && key.Kind.Kind == types.KindString {
+			return compileEnumValue(compiledFunc, n, enum, file)
+		}
+	}
+
 	arrayOrMapRegisters, arrayOrMapKind, err := compileExpr(compiledFunc,
 		n.Expr, file, scopeOverrides)
 	if err != nil {
 		return "", nil, err
 	}
 
+	return compileKeyOf(compiledFunc, n, arrayOrMapRegisters, arrayOrMapKind,
+		file, scopeOverrides)
+}
+
+// compileKeyOf is the same as compileKey when the expression that contains
+// the key has already been compiled.
+func compileKeyOf(
+	compiledFunc *vm.CompiledFunc,
+	n *ast.Key,
+	arrayOrMapRegisters []vm.Register,
+	arrayOrMapKind []*types.Type,
+	file *vm.File,
+	scopeOverrides map[string]*types.Type,
+) (vm.Register, *types.Type, error) {
 	// TODO(elliot): This can be removed once the compiler can understand Key
 	//  expressions better.
 	key := n.Key
@@ -29,6 +53,12 @@ func compileKey(
 		}
 	}
 
+	// The methods of a channel are not values.
+	if arrayOrMapKind[0].Kind == types.KindChannel {
+		return "", nil, fmt.Errorf("%s methods of %s must be called",
+			n.Position(), arrayOrMapKind[0])
+	}
+
 	// TODO(elliot): Check key is the correct type.
 	keyRegisters, _, err := compileExpr(compiledFunc, key, file, scopeOverrides)
 	if err != nil {
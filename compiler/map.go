@@ -39,14 +39,21 @@ func compileMap(
 			return "", nil, err
 		}
 
-		// TODO(elliot): Check value is the right type for map.
-		// TODO(elliot): Check all elements are the same kind.
-		valueRegisters, valueKind, _ := compileExpr(compiledFunc, element.Value,
-			file, scopeOverrides)
+		valueRegisters, valueKind, err := compileExpr(compiledFunc,
+			element.Value, file, scopeOverrides)
+		if err != nil {
+			return "", nil, err
+		}
+
 		if n.Kind == nil {
 			n.Kind = valueKind[0].ToMap()
 		}
 
+		if !file.Types.Accepts(n.Kind.Element, valueKind[0]) {
+			return "", nil, fmt.Errorf("%s cannot use %s as a value of %s",
+				element.Value.Position(), valueKind[0], n.Kind)
+		}
+
 		compiledFunc.Append(&vm.MapSet{
 			Map:   mapRegister,
 			Key:   keyRegisters[0],
@@ -54,7 +61,8 @@ func compileMap(
 		})
 	}
 
-	typeRegister := file.AddType(n.Kind)
+	// Type parameters only exist when compiling.
+	typeRegister := file.AddType(n.Kind.Erase())
 	mapAlloc.Kind = typeRegister
 
 	return mapRegister, n.Kind, nil
@@ -0,0 +1,109 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/elliotchance/ok/cmd/version"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/util"
+	"github.com/elliotchance/ok/vm"
+)
+
+var (
+	compilerVersionOnce sync.Once
+	compilerVersionID   string
+)
+
+// compilerVersion identifies the compiler so that files compiled by a
+// different version are never used. Development builds all share the same
+// version, so the size and modification time of the executable are included
+// as well.
+func compilerVersion() string {
+	compilerVersionOnce.Do(func() {
+		compilerVersionID = version.Version
+		if executable, err := os.Executable(); err == nil {
+			if info, err := os.Stat(executable); err == nil {
+				compilerVersionID += fmt.Sprintf(" %d %d", info.Size(),
+					info.ModTime().UnixNano())
+			}
+		}
+	})
+
+	return compilerVersionID
+}
+
+// okcKey is the key of a package in the compile cache (see vm.Store). It
+// changes if the source of the package or the compiler changes. An empty
+// string is returned if the source cannot be read.
+//
+// The root path is included because it determines the package name.
+func okcKey(rootPath, dir string, includeTests bool) string {
+	sourceHash := util.SourceHash(dir, includeTests)
+	if sourceHash == "" {
+		return ""
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%t\n%s\n", compilerVersion(), rootPath, dir,
+		includeTests, sourceHash)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// maxInt is the largest int, it depends on the architecture.
+const maxInt = int(^uint(0) >> 1)
+
+// anonFunctionBase is where the names of the functions in a package start.
+// Packages are merged together, possibly with packages that were compiled by a
+// different run and loaded from the cache, so the names are derived from the
+// key rather than the order the packages are compiled in.
+func anonFunctionBase(key string) int {
+	return anonFunctionBaseUpTo(key, maxInt)
+}
+
+// anonFunctionBaseUpTo is anonFunctionBase where all of the names of the
+// package (each package has 10000) must not be greater than max. That is,
+// they must not overflow an int on 32-bit architectures. On 64-bit
+// architectures the limit is never reached.
+func anonFunctionBaseUpTo(key string, max int) int {
+	n, _ := strconv.ParseUint(key[:10], 16, 64)
+	bases := uint64(max/10000 - 1)
+
+	return int(n%bases+1) * 10000
+}
+
+// loadOKC returns the package from the compile cache. A nil file is returned
+// if the package is not in the cache, or if any of the packages that it
+// imports have changed since it was compiled.
+func loadOKC(
+	rootPath, pkgPath, dir, key string,
+	includeTests bool,
+) (*vm.File, *types.Type) {
+	file, err := vm.Load(key)
+	if err != nil {
+		return nil, nil
+	}
+
+	for sourceDir, sourceKey := range file.Sources {
+		// Imports are always compiled without tests.
+		if okcKey(rootPath, sourceDir, sourceDir == dir && includeTests) != sourceKey {
+			return nil, nil
+		}
+	}
+
+	packageName := util.PackageNameFromPath(rootPath, pkgPath)
+	packageFn := file.Globals["$"+strings.ReplaceAll(packageName, "/", "__")]
+	for _, symbol := range file.Symbols {
+		if symbol.Func != nil && symbol.Func.UniqueName == packageFn {
+			return file, file.Types.Get(string(symbol.Type)).Returns[0]
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+package compiler
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonFunctionBase(t *testing.T) {
+	for testName, test := range map[string]struct {
+		key      string
+		max      int64
+		expected int64
+	}{
+		"zero": {
+			key:      strings.Repeat("0", 64),
+			max:      math.MaxInt64,
+			expected: 10000,
+		},
+		"largest": {
+			key:      strings.Repeat("f", 64),
+			max:      math.MaxInt64,
+			expected: 0x10000000000 * 10000,
+		},
+		"32-bit-zero": {
+			key:      strings.Repeat("0", 64),
+			max:      math.MaxInt32,
+			expected: 10000,
+		},
+		"32-bit-largest": {
+			key:      "00000346da" + strings.Repeat("f", 54),
+			max:      math.MaxInt32,
+			expected: 214747 * 10000,
+		},
+		"32-bit-wraps": {
+			key:      "00000346db" + strings.Repeat("f", 54),
+			max:      math.MaxInt32,
+			expected: 10000,
+		},
+		"32-bit-10-digits": {
+			key:      strings.Repeat("f", 64),
+			max:      math.MaxInt32,
+			expected: (0xffffffffff%214747 + 1) * 10000,
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			if test.max > int64(maxInt) {
+				t.Skip("int is too small")
+			}
+
+			base := anonFunctionBaseUpTo(test.key, int(test.max))
+			assert.Equal(t, test.expected, int64(base))
+
+			// The last name of the package must not overflow.
+			assert.True(t, base > 0 && int64(base) <= test.max-9999)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+// compileOptionalCast makes a value optional, such as "?string nil". It is the
+// only way to create an optional variable without a function.
+func compileOptionalCast(
+	compiledFunc *vm.CompiledFunc,
+	call *ast.Call,
+	argResults []vm.Register,
+	argTypes []*types.Type,
+	file *vm.File,
+) ([]vm.Register, []*types.Type, error) {
+	ty := types.NewOptional(call.TypeArguments[0])
+	if len(argTypes) != 1 || !file.Types.Accepts(ty, argTypes[0]) {
+		return nil, nil, fmt.Errorf("%s cannot cast %s to %s",
+			call.Arguments[0].Position(), argTypes[0], ty)
+	}
+
+	result := compiledFunc.NextRegister()
+	compiledFunc.Append(&vm.Assign{
+		Result:   result,
+		Register: argResults[0],
+	})
+
+	return []vm.Register{result}, []*types.Type{ty}, nil
+}
+
+// compileIsNil checks if a value is nil. A value that is not optional can
+// never be nil.
+func compileIsNil(
+	compiledFunc *vm.CompiledFunc,
+	value vm.Register,
+	ty *types.Type,
+	pos string,
+	file *vm.File,
+) (vm.Register, error) {
+	if ty.Kind != types.KindOptional && ty.Kind != types.KindNil &&
+		ty.Kind != types.KindAny {
+		return "", fmt.Errorf("%s %s can never be nil", pos, ty)
+	}
+
+	nilRegister := compiledFunc.NextRegister()
+	compiledFunc.Append(&vm.AssignSymbol{
+		Result: nilRegister,
+		Symbol: file.AddSymbolLiteral(asttest.NewLiteralString(types.Nil.String())),
+	})
+
+	result := compiledFunc.NextRegister()
+	compiledFunc.Append(&vm.Is{
+		Value:  value,
+		Type:   nilRegister,
+		Result: result,
+	})
+
+	return result, nil
+}
+
+// compileNilCoalesce returns the left value, or the right value if the left
+// value is nil. The right side is only evaluated when it is needed.
+func compileNilCoalesce(
+	compiledFunc *vm.CompiledFunc,
+	node *ast.Binary,
+	file *vm.File,
+	scopeOverrides map[string]*types.Type,
+) (vm.Register, *types.Type, error) {
+	left, leftTypes, err := compileExpr(compiledFunc, node.Left, file,
+		scopeOverrides)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if leftTypes[0].Kind != types.KindOptional {
+		return "", nil, fmt.Errorf("%s cannot use %s on %s, it is not optional",
+			node.Position(), lexer.TokenNilCoalesce, leftTypes[0])
+	}
+
+	result := compiledFunc.NextRegister()
+	compiledFunc.Append(&vm.Assign{
+		Result:   result,
+		Register: left[0],
+	})
+
+	isNil, err := compileIsNil(compiledFunc, left[0], leftTypes[0],
+		node.Position(), file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	jump := &vm.JumpUnless{
+		Condition: isNil,
+		To:        -1, // Corrected after the right side.
+	}
+	compiledFunc.Append(jump)
+
+	right, rightTypes, err := compileExpr(compiledFunc, node.Right, file,
+		scopeOverrides)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The result is only optional when the default is also optional.
+	ty := leftTypes[0].Element
+	if !file.Types.Accepts(ty, rightTypes[0]) {
+		if !file.Types.Accepts(leftTypes[0], rightTypes[0]) {
+			return "", nil, fmt.Errorf("%s cannot use %s as default for %s",
+				node.Position(), rightTypes[0], leftTypes[0])
+		}
+
+		ty = leftTypes[0]
+	}
+
+	compiledFunc.Append(&vm.Assign{
+		Result:   result,
+		Register: right[0],
+	})
+	jump.To = len(compiledFunc.Instructions.Instructions) - 1
+
+	return result, ty, nil
+}
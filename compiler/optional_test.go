@@ -0,0 +1,324 @@
+package compiler_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOptionalCast(ty *types.Type, value ast.Node) *ast.Call {
+	return &ast.Call{
+		Expr:          &ast.Identifier{Name: lexer.TokenQuestion},
+		TypeArguments: []*types.Type{ty},
+		Arguments:     []ast.Node{value},
+	}
+}
+
+func TestOptional(t *testing.T) {
+	newOptional := &ast.Assign{
+		Lefts: []ast.Node{&ast.Identifier{Name: "x"}},
+		Rights: []ast.Node{
+			newOptionalCast(types.Number, asttest.NewLiteralNil()),
+		},
+	}
+	isNil := &ast.Binary{
+		Left:  &ast.Identifier{Name: "x"},
+		Op:    lexer.TokenIs,
+		Right: &ast.Identifier{Name: "nil"},
+	}
+	plusOne := &ast.Binary{
+		Left:  &ast.Identifier{Name: "x"},
+		Op:    lexer.TokenPlus,
+		Right: asttest.NewLiteralNumber("1"),
+	}
+
+	optionalValue := &ast.Identifier{Name: "o"}
+	scope := map[string]*types.Type{
+		"o": types.NewOptional(types.Number),
+		"a": types.NumberArray,
+		"m": types.NumberMap,
+		"p": types.NewInterface("P", map[string]*types.Type{
+			"X": types.Number,
+		}),
+	}
+
+	for testName, test := range map[string]struct {
+		fn  *ast.Func
+		err error
+	}{
+		"cast-nil": {
+			fn: newFunc(newOptional),
+		},
+		"cast-value": {
+			fn: newFunc(newOptionalCast(types.Number,
+				asttest.NewLiteralNumber("1"))),
+		},
+		"cast-wrong-type": {
+			fn: newFunc(newOptionalCast(types.Number,
+				asttest.NewLiteralString("1"))),
+			err: errors.New(" cannot cast string to ?number"),
+		},
+		"assign-value-and-nil": {
+			fn: newFunc(
+				newOptional,
+				&ast.Assign{
+					Lefts:  []ast.Node{&ast.Identifier{Name: "x"}},
+					Rights: []ast.Node{asttest.NewLiteralNumber("1")},
+				},
+				&ast.Assign{
+					Lefts:  []ast.Node{&ast.Identifier{Name: "x"}},
+					Rights: []ast.Node{asttest.NewLiteralNil()},
+				},
+			),
+		},
+		"assign-nil-to-value": {
+			fn: newFunc(
+				&ast.Assign{
+					Lefts:  []ast.Node{&ast.Identifier{Name: "x"}},
+					Rights: []ast.Node{asttest.NewLiteralNumber("1")},
+				},
+				&ast.Assign{
+					Lefts:  []ast.Node{&ast.Identifier{Name: "x"}},
+					Rights: []ast.Node{asttest.NewLiteralNil()},
+				},
+			),
+			err: errors.New(" cannot assign nil to variable x (expecting number)"),
+		},
+		"optional-used-as-value": {
+			fn:  newFunc(newOptional, plusOne),
+			err: errors.New(" cannot perform ?number + number"),
+		},
+		"is-nil": {
+			fn: newFunc(newOptional, isNil),
+		},
+		"is-nil-not-optional": {
+			fn: newFunc(
+				&ast.Assign{
+					Lefts:  []ast.Node{&ast.Identifier{Name: "x"}},
+					Rights: []ast.Node{asttest.NewLiteralNumber("1")},
+				},
+				isNil,
+			),
+			err: errors.New(" number can never be nil"),
+		},
+		"is-optional": {
+			fn: newFunc(newOptional, &ast.Binary{
+				Left:  &ast.Identifier{Name: "x"},
+				Op:    lexer.TokenIs,
+				Right: &ast.Identifier{Name: "?number"},
+			}),
+			err: errors.New(" cannot check for ?number, use is nil"),
+		},
+		"is-not-nil-narrows": {
+			fn: newFunc(newOptional, &ast.If{
+				Condition: &ast.Unary{Op: lexer.TokenNot, Expr: isNil},
+				True:      []ast.Node{plusOne},
+			}),
+		},
+		"is-not-nil-does-not-narrow-else": {
+			fn: newFunc(newOptional, &ast.If{
+				Condition: &ast.Unary{Op: lexer.TokenNot, Expr: isNil},
+				False:     []ast.Node{plusOne},
+			}),
+			err: errors.New(" cannot perform ?number + number"),
+		},
+		"is-nil-value": {
+			fn: newFunc(newOptional, &ast.Assign{
+				Lefts:  []ast.Node{&ast.Identifier{Name: "y"}},
+				Rights: []ast.Node{isNil},
+			}),
+		},
+		"is-not-nil-and-does-not-narrow": {
+			fn: newFunc(newOptional, &ast.If{
+				Condition: &ast.Binary{
+					Left:  &ast.Unary{Op: lexer.TokenNot, Expr: isNil},
+					Op:    lexer.TokenAnd,
+					Right: asttest.NewLiteralBool(true),
+				},
+				True: []ast.Node{plusOne},
+			}),
+			err: errors.New(" cannot perform ?number + number"),
+		},
+		"assign-optional-when-narrowed": {
+			fn: newFunc(newOptional, &ast.If{
+				Condition: &ast.Unary{Op: lexer.TokenNot, Expr: isNil},
+				True: []ast.Node{
+					&ast.Assign{
+						Lefts:  []ast.Node{&ast.Identifier{Name: "x"}},
+						Rights: []ast.Node{asttest.NewLiteralNil()},
+					},
+				},
+			}),
+			err: errors.New(" cannot assign nil to variable x (expecting number because x was checked for nil)"),
+		},
+		"assign-value-when-narrowed": {
+			fn: newFunc(newOptional, &ast.If{
+				Condition: &ast.Unary{Op: lexer.TokenNot, Expr: isNil},
+				True: []ast.Node{
+					&ast.Assign{
+						Lefts:  []ast.Node{&ast.Identifier{Name: "x"}},
+						Rights: []ast.Node{asttest.NewLiteralNumber("2")},
+					},
+				},
+			}),
+		},
+		"is-nil-narrows-else": {
+			fn: newFunc(newOptional, &ast.If{
+				Condition: isNil,
+				False:     []ast.Node{plusOne},
+			}),
+		},
+		"is-nil-does-not-narrow": {
+			fn: newFunc(newOptional, &ast.If{
+				Condition: isNil,
+				True:      []ast.Node{plusOne},
+			}),
+			err: errors.New(" cannot perform ?number + number"),
+		},
+		"coalesce": {
+			fn: newFunc(newOptional, &ast.Binary{
+				Left: &ast.Binary{
+					Left:  &ast.Identifier{Name: "x"},
+					Op:    lexer.TokenNilCoalesce,
+					Right: asttest.NewLiteralNumber("0"),
+				},
+				Op:    lexer.TokenPlus,
+				Right: asttest.NewLiteralNumber("1"),
+			}),
+		},
+		"coalesce-not-optional": {
+			fn: newFunc(&ast.Binary{
+				Left:  asttest.NewLiteralNumber("1"),
+				Op:    lexer.TokenNilCoalesce,
+				Right: asttest.NewLiteralNumber("0"),
+			}),
+			err: errors.New(" cannot use ?? on number, it is not optional"),
+		},
+		"coalesce-wrong-default": {
+			fn: newFunc(newOptional, &ast.Binary{
+				Left:  &ast.Identifier{Name: "x"},
+				Op:    lexer.TokenNilCoalesce,
+				Right: asttest.NewLiteralString("0"),
+			}),
+			err: errors.New(" cannot use string as default for ?number"),
+		},
+		"array-element": {
+			fn: newFunc(&ast.Array{
+				Kind: types.NumberArray,
+				Elements: []ast.Node{
+					asttest.NewLiteralNumber("1"),
+					asttest.NewLiteralNil(),
+				},
+			}),
+			err: errors.New(" cannot use nil as an element of []number"),
+		},
+		"optional-array-element": {
+			fn: newFunc(&ast.Array{
+				Kind: types.NewOptional(types.Number).ToArray(),
+				Elements: []ast.Node{
+					asttest.NewLiteralNumber("1"),
+					asttest.NewLiteralNil(),
+					optionalValue,
+				},
+			}),
+		},
+		"map-value": {
+			fn: newFunc(&ast.Map{
+				Kind: types.NumberMap,
+				Elements: []*ast.KeyValue{
+					{
+						Key:   asttest.NewLiteralString("a"),
+						Value: optionalValue,
+					},
+				},
+			}),
+			err: errors.New(" cannot use ?number as a value of {}number"),
+		},
+		"array-index-assign": {
+			fn: newFunc(&ast.Assign{
+				Lefts: []ast.Node{&ast.Key{
+					Expr: &ast.Identifier{Name: "a"},
+					Key:  asttest.NewLiteralNumber("0"),
+				}},
+				Rights: []ast.Node{optionalValue},
+			}),
+			err: errors.New(" cannot assign ?number to element of []number (expecting number)"),
+		},
+		"map-key-assign": {
+			fn: newFunc(&ast.Assign{
+				Lefts: []ast.Node{&ast.Key{
+					Expr: &ast.Identifier{Name: "m"},
+					Key:  asttest.NewLiteralString("a"),
+				}},
+				Rights: []ast.Node{asttest.NewLiteralNil()},
+			}),
+			err: errors.New(" cannot assign nil to element of {}number (expecting number)"),
+		},
+		"property-assign": {
+			fn: newFunc(&ast.Assign{
+				Lefts: []ast.Node{&ast.Key{
+					Expr: &ast.Identifier{Name: "p"},
+					Key:  asttest.NewLiteralString("X"),
+				}},
+				Rights: []ast.Node{asttest.NewLiteralNil()},
+			}),
+			err: errors.New(" cannot assign nil to property X (expecting number)"),
+		},
+		"property-assign-value": {
+			fn: newFunc(&ast.Assign{
+				Lefts: []ast.Node{&ast.Key{
+					Expr: &ast.Identifier{Name: "p"},
+					Key:  asttest.NewLiteralString("X"),
+				}},
+				Rights: []ast.Node{
+					&ast.Binary{
+						Left:  optionalValue,
+						Op:    lexer.TokenNilCoalesce,
+						Right: asttest.NewLiteralNumber("0"),
+					},
+				},
+			}),
+		},
+		"return-nil": {
+			fn: &ast.Func{
+				Returns: []*types.Type{types.NewOptional(types.String)},
+				Statements: []ast.Node{
+					&ast.Return{
+						Exprs: []ast.Node{asttest.NewLiteralNil()},
+					},
+				},
+			},
+		},
+		"return-nil-not-optional": {
+			fn: &ast.Func{
+				Returns: []*types.Type{types.String},
+				Statements: []ast.Node{
+					&ast.Return{
+						Exprs: []ast.Node{asttest.NewLiteralNil()},
+					},
+				},
+			},
+			err: errors.New(" cannot return nil, expecting string"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			_, err := compiler.CompileFunc(test.fn,
+				&vm.File{
+					Types:   types.Registry{},
+					Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+				}, nil, nil, nil, scope)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -22,7 +22,7 @@ func compileRaise(
 		return err
 	}
 
-	typeRegister := file.AddType(resultKind[0])
+	typeRegister := file.AddType(resultKind[0].Erase())
 	compiledFunc.Append(&vm.Raise{
 		Err:  result[0],
 		Type: typeRegister,
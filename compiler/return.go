@@ -1,6 +1,8 @@
 package compiler
 
 import (
+	"fmt"
+
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/vm"
@@ -13,14 +15,29 @@ func compileReturn(
 	scopeOverrides map[string]*types.Type,
 ) error {
 	var results []vm.Register
+	var resultTypes []*types.Type
 	for _, expr := range n.Exprs {
 		// TODO(elliot): Check return types are valid.
-		result, _, err := compileExpr(compiledFunc, expr, file, scopeOverrides)
+		result, resultType, err := compileExpr(compiledFunc, expr, file,
+			scopeOverrides)
 		if err != nil {
 			return err
 		}
 
 		results = append(results, result...)
+		resultTypes = append(resultTypes, resultType...)
+	}
+
+	// Until all return types are checked, at least make sure that an optional
+	// value (or nil) is not returned where a value is required.
+	returns := file.Types.Get(string(compiledFunc.Type)).Returns
+	for i, ty := range resultTypes {
+		if i < len(returns) &&
+			(ty.Kind == types.KindOptional || ty.Kind == types.KindNil) &&
+			!file.Types.Accepts(returns[i], ty) {
+			return fmt.Errorf("%s cannot return %s, expecting %s",
+				n.Pos, ty, returns[i])
+		}
 	}
 
 	compiledFunc.Append(&vm.Return{
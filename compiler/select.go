@@ -0,0 +1,183 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+// selectOp is the channel operation of a select case.
+type selectOp struct {
+	call     *ast.Call
+	key      *ast.Key
+	variable string
+}
+
+// selectCaseOp returns the channel operation of a case. The parser only checks
+// that it is a call, or an assignment from a call.
+func selectCaseOp(c *ast.SelectCase) (*selectOp, error) {
+	op := &selectOp{}
+	switch n := c.Op.(type) {
+	case *ast.Assign:
+		op.call, _ = n.Rights[0].(*ast.Call)
+		op.variable = n.Lefts[0].(*ast.Identifier).Name
+
+	case *ast.Call:
+		op.call = n
+	}
+
+	if op.call != nil {
+		op.key, _ = op.call.Expr.(*ast.Key)
+	}
+
+	if op.key == nil {
+		return nil, fmt.Errorf("%s select case must call Send or Receive on a channel",
+			c.Position())
+	}
+
+	name := callName(op.call)
+	if name != "Send" && name != "Receive" {
+		return nil, fmt.Errorf("%s select case must call Send or Receive on a channel",
+			c.Position())
+	}
+
+	if op.variable != "" && name != "Receive" {
+		return nil, fmt.Errorf("%s select case can only assign from Receive",
+			c.Position())
+	}
+
+	return op, nil
+}
+
+func compileSelect(
+	compiledFunc *vm.CompiledFunc,
+	n *ast.Select,
+	breakIns,
+	continueIns vm.Instruction,
+	file *vm.File,
+	scopeOverrides map[string]*types.Type,
+) error {
+	// All of the channels and values to send are evaluated before the select
+	// waits.
+	var ops []*selectOp
+	var elements []*types.Type
+	ins := &vm.Select{
+		Else:   n.Else != nil,
+		Chosen: compiledFunc.NextRegister(),
+		Value:  compiledFunc.NextRegister(),
+	}
+	for _, c := range n.Cases {
+		op, err := selectCaseOp(c)
+		if err != nil {
+			return err
+		}
+
+		channelResults, channelTypes, err := compileExpr(compiledFunc,
+			op.key.Expr, file, scopeOverrides)
+		if err != nil {
+			return err
+		}
+
+		if len(channelTypes) != 1 || channelTypes[0].Kind != types.KindChannel {
+			return fmt.Errorf("%s select case must call Send or Receive on a channel",
+				c.Position())
+		}
+
+		var argResults []vm.Register
+		var argTypes []*types.Type
+		for _, arg := range op.call.Arguments {
+			argResult, argType, err := compileExpr(compiledFunc, arg, file,
+				scopeOverrides)
+			if err != nil {
+				return err
+			}
+
+			argResults = append(argResults, argResult...)
+			argTypes = append(argTypes, argType...)
+		}
+
+		var params []*types.Type
+		value := vm.Register("")
+		if callName(op.call) == "Send" {
+			params = []*types.Type{channelTypes[0].Element}
+			if len(argResults) > 0 {
+				value = argResults[0]
+			}
+		}
+
+		err = checkArguments(file, op.call, params, argTypes)
+		if err != nil {
+			return err
+		}
+
+		ops = append(ops, op)
+		elements = append(elements, channelTypes[0].Element)
+		ins.Channels = append(ins.Channels, channelResults[0])
+		ins.Values = append(ins.Values, value)
+	}
+
+	compiledFunc.Append(ins)
+
+	afterMatch := &vm.Jump{
+		To: -1, // Corrected later.
+	}
+
+	for i, c := range n.Cases {
+		index := compiledFunc.NextRegister()
+		compiledFunc.Append(&vm.AssignSymbol{
+			Result: index,
+			Symbol: file.AddSymbolLiteral(
+				asttest.NewLiteralNumber(fmt.Sprintf("%d", i))),
+		})
+
+		matched := compiledFunc.NextRegister()
+		compiledFunc.Append(&vm.EqualNumber{
+			Left:   ins.Chosen,
+			Right:  index,
+			Result: matched,
+		})
+
+		jump := &vm.JumpUnless{
+			Condition: matched,
+			To:        -1, // Corrected after the case.
+		}
+		compiledFunc.Append(jump)
+
+		if variable := ops[i].variable; variable != "" {
+			ty, ok := compiledFunc.GetTypeForVariable(variable, scopeOverrides)
+			if ok && ty.String() != elements[i].String() {
+				return fmt.Errorf(
+					"%s cannot assign %s to variable %s (expecting %s)",
+					c.Position(), elements[i], variable, ty)
+			}
+
+			compiledFunc.NewVariable(variable, elements[i])
+			compiledFunc.Append(&vm.Assign{
+				Result:   vm.Register(variable),
+				Register: ins.Value,
+			})
+		}
+
+		err := compileBlock(compiledFunc, c.Statements, breakIns, continueIns,
+			file, scopeOverrides)
+		if err != nil {
+			return err
+		}
+
+		compiledFunc.Append(afterMatch)
+		jump.To = len(compiledFunc.Instructions.Instructions) - 1
+	}
+
+	err := compileBlock(compiledFunc, n.Else, breakIns, continueIns, file,
+		scopeOverrides)
+	if err != nil {
+		return err
+	}
+
+	afterMatch.To = len(compiledFunc.Instructions.Instructions) - 1
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+func compileSpawn(
+	compiledFunc *vm.CompiledFunc,
+	n *ast.Spawn,
+	file *vm.File,
+	scopeOverrides map[string]*types.Type,
+) error {
+	_, _, err := compileCall(compiledFunc, n.Call, file, scopeOverrides)
+	if err != nil {
+		return err
+	}
+
+	// The call is compiled as normal so that the arguments are evaluated and
+	// checked. Then the call itself is replaced with the spawn. Builtin
+	// functions and channel methods are not real calls, so they cannot be
+	// spawned.
+	instructions := compiledFunc.Instructions.Instructions
+	call, ok := instructions[len(instructions)-1].(*vm.Call)
+	if !ok {
+		return fmt.Errorf("%s cannot spawn %s", n.Position(), callName(n.Call))
+	}
+
+	instructions[len(instructions)-1] = &vm.Spawn{
+		Function:  vm.Register(call.FunctionName[1:]),
+		Arguments: call.Arguments,
+		Pos:       call.Pos,
+	}
+
+	return nil
+}
@@ -48,6 +48,13 @@ func compileStatement(
 
 	case *ast.Raise:
 		return compileRaise(compiledFunc, n, file, scopeOverrides)
+
+	case *ast.Spawn:
+		return compileSpawn(compiledFunc, n, file, scopeOverrides)
+
+	case *ast.Select:
+		return compileSelect(compiledFunc, n, breakIns, continueIns, file,
+			scopeOverrides)
 	}
 
 	_, _, err := compileExpr(compiledFunc, statement, file, scopeOverrides)
@@ -0,0 +1,67 @@
+package compiler
+
+import (
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+// CompileStatements replaces the instructions of an existing function with the
+// instructions for stmts. Unlike CompileFunc, the variables of compiledFunc are
+// kept so that code can be compiled incrementally (such as "ok repl") where
+// each set of statements can use the variables from the previous.
+//
+// If the last statement is an expression, the registers and types of its
+// results are returned.
+func CompileStatements(
+	compiledFunc *vm.CompiledFunc,
+	stmts []ast.Node,
+	file *vm.File,
+	imports map[string]*types.Type,
+	scopeOverrides map[string]*types.Type,
+) ([]vm.Register, []*types.Type, error) {
+	compiledFunc.Instructions = new(vm.Instructions)
+	compiledFunc.Finally = nil
+	compiledFunc.DeferredFuncsToCompile = nil
+
+	var last ast.Node
+	if len(stmts) > 0 && isExpression(stmts[len(stmts)-1]) {
+		last = stmts[len(stmts)-1]
+		stmts = stmts[:len(stmts)-1]
+	}
+
+	err := compileBlock(compiledFunc, stmts, nil, nil, file, scopeOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var results []vm.Register
+	var resultTypes []*types.Type
+	if last != nil {
+		results, resultTypes, err = compileExpr(compiledFunc, last, file,
+			scopeOverrides)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = compileDeferredFuncs(compiledFunc, file, compiledFunc.Constants,
+		imports, scopeOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return results, resultTypes, nil
+}
+
+// isExpression returns false for statements that do not produce a value.
+func isExpression(stmt ast.Node) bool {
+	switch stmt.(type) {
+	case *ast.Assign, *ast.Break, *ast.Continue, *ast.Return, *ast.Assert,
+		*ast.AssertRaise, *ast.For, *ast.If, *ast.Switch, *ast.ErrorScope,
+		*ast.Raise, *ast.Select, *ast.Spawn:
+		return false
+	}
+
+	return true
+}
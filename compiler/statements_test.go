@@ -0,0 +1,101 @@
+package compiler_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileStatements(t *testing.T) {
+	for testName, test := range map[string]struct {
+		before      []ast.Node
+		stmts       []ast.Node
+		expected    []vm.Instruction
+		results     []vm.Register
+		resultTypes []*types.Type
+		err         error
+	}{
+		"no-statements": {},
+		"assign": {
+			stmts: []ast.Node{
+				&ast.Assign{
+					Lefts:  []ast.Node{&ast.Identifier{Name: "a"}},
+					Rights: []ast.Node{asttest.NewLiteralNumber("1.5")},
+				},
+			},
+			expected: []vm.Instruction{
+				&vm.AssignSymbol{
+					Result: "1",
+					Symbol: "0",
+				},
+				&vm.Assign{
+					Result:   "a",
+					Register: "1",
+				},
+			},
+		},
+		"expression": {
+			stmts: []ast.Node{
+				asttest.NewLiteralString("foo"),
+			},
+			expected: []vm.Instruction{
+				&vm.AssignSymbol{
+					Result: "1",
+					Symbol: "0",
+				},
+			},
+			results:     []vm.Register{"1"},
+			resultTypes: []*types.Type{types.String},
+		},
+		"variable-from-before": {
+			before: []ast.Node{
+				&ast.Assign{
+					Lefts:  []ast.Node{&ast.Identifier{Name: "a"}},
+					Rights: []ast.Node{asttest.NewLiteralNumber("1.5")},
+				},
+			},
+			stmts: []ast.Node{
+				&ast.Identifier{Name: "a"},
+			},
+			results:     []vm.Register{"a"},
+			resultTypes: []*types.Type{types.Number},
+		},
+		"undefined-variable": {
+			stmts: []ast.Node{
+				&ast.Identifier{Name: "a"},
+			},
+			err: errors.New(" undefined variable: a"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			file := &vm.File{
+				Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+				Types:   types.Registry{},
+			}
+			compiledFunc := vm.NewCompiledFunc(&ast.Func{}, nil, nil, file)
+
+			_, _, err := compiler.CompileStatements(compiledFunc, test.before,
+				file, nil, map[string]*types.Type{})
+			require.NoError(t, err)
+
+			results, resultTypes, err := compiler.CompileStatements(
+				compiledFunc, test.stmts, file, nil, map[string]*types.Type{})
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected,
+					compiledFunc.Instructions.Instructions)
+				assert.Equal(t, test.results, results)
+				assert.Equal(t, test.resultTypes, resultTypes)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package compiler
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/lexer"
 	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/vm"
 )
@@ -41,7 +43,7 @@ func compileCase(
 		if valueRegister != "" {
 			result := compiledFunc.NextRegister()
 
-			op := fmt.Sprintf("%s == %s", conditionKinds[0], conditionKinds[0])
+			op := binaryOp(conditionKinds[0], lexer.TokenEqual, conditionKinds[0])
 			bop, _ := getBinaryInstruction(op, valueRegister, conditionResults[0], result)
 			compiledFunc.Append(bop)
 
@@ -93,6 +95,15 @@ func compileSwitch(
 		if err != nil {
 			return err
 		}
+
+		// Without an else, a switch on an enum must handle every value.
+		enum := expectedConditionKinds[0]
+		if enum.Kind == types.KindEnum && n.Else == nil {
+			if missing := missingEnumValues(compiledFunc, n, enum); len(missing) > 0 {
+				return fmt.Errorf("%s switch on %s does not handle %s",
+					n.Position(), enum.Name, strings.Join(missing, ", "))
+			}
+		}
 	}
 
 	for _, caseStmt := range n.Cases {
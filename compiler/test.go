@@ -1,33 +1,219 @@
 package compiler
 
 import (
+	"fmt"
+
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/vm"
 )
 
-// CompileTest will compile a test.
+// CompileTest will compile a test, benchmark or fuzz target. The setup and teardown hooks
+// are compiled into the same scope as the test so that variables assigned by
+// the setup can be used in the test.
 func CompileTest(
 	fn *ast.Test,
+	hooks []*ast.Hook,
 	file *vm.File,
 	constants map[string]*ast.Literal,
 	imports map[string]*types.Type,
 ) (*vm.CompiledTest, error) {
-	// Tests can be compiled as if they were functions, then wrapped in a
-	// CompiledTest.
-	//
-	// TODO(elliot): When tests can be nested the third argument for parentFunc
-	//  should not be nil.
-	compiledFunc, err := CompileFunc(&ast.Func{
-		Statements: fn.Statements,
-		Pos:        fn.Pos,
-	}, file, nil, constants, imports, map[string]*types.Type{})
+	// TODO(elliot): When tests can be nested the second argument for
+	//  parentFunc should not be nil.
+	compiledFunc := vm.NewCompiledFunc(&ast.Func{
+		Pos: fn.Pos,
+	}, nil, constants, file)
+
+	test := &vm.CompiledTest{
+		CompiledFunc: compiledFunc,
+		TestName:     fn.Name,
+		IsBench:      fn.IsBench,
+		IsFuzz:       fn.IsFuzz,
+	}
+
+	if fn.IsFuzz {
+		err := compileFuzzArguments(test, fn, file, constants)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The cases must be compiled first because they are evaluated before the
+	// setup.
+	if fn.Cases != nil {
+		err := compileTestCases(test, fn.Cases, file, imports)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var setup, teardown []ast.Node
+	for _, hook := range hooks {
+		if hook.IsTeardown {
+			teardown = append(teardown, hook.Statements...)
+		} else {
+			setup = append(setup, hook.Statements...)
+		}
+	}
+
+	var err error
+	if len(setup) > 0 {
+		test.Setup, err = compileTestBlock(compiledFunc, setup, file, imports)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := compileTestBlock(compiledFunc, fn.Statements, file, imports)
 	if err != nil {
 		return nil, err
 	}
 
-	return &vm.CompiledTest{
-		CompiledFunc: compiledFunc,
-		TestName:     fn.Name,
-	}, nil
+	if len(teardown) > 0 {
+		test.Teardown, err = compileTestBlock(compiledFunc, teardown, file,
+			imports)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Each block replaces the instructions of compiledFunc, so the body must
+	// be put back last.
+	test.Instructions = body
+
+	return test, nil
+}
+
+// compileTestBlock compiles statements into the scope of a test and returns
+// the instructions. An expression as the last statement is still compiled,
+// even though the result is not used.
+func compileTestBlock(
+	compiledFunc *vm.CompiledFunc,
+	stmts []ast.Node,
+	file *vm.File,
+	imports map[string]*types.Type,
+) (*vm.Instructions, error) {
+	_, _, err := CompileStatements(compiledFunc, stmts, file, imports,
+		map[string]*types.Type{})
+	if err != nil {
+		return nil, err
+	}
+
+	return compiledFunc.Instructions, nil
+}
+
+func compileTestCases(
+	test *vm.CompiledTest,
+	cases *ast.In,
+	file *vm.File,
+	imports map[string]*types.Type,
+) error {
+	results, resultTypes, err := CompileStatements(test.CompiledFunc,
+		[]ast.Node{cases.Expr}, file, imports, map[string]*types.Type{})
+	if err != nil {
+		return err
+	}
+
+	kind := resultTypes[0].Kind
+	if kind != types.KindArray && kind != types.KindMap {
+		return fmt.Errorf("%s: test cases must be an array or map, got %s",
+			cases.Pos, resultTypes[0])
+	}
+
+	test.CompiledFunc.NewVariable(cases.Value, resultTypes[0].Element)
+	test.Cases = test.CompiledFunc.Instructions
+	test.CasesResult = results[0]
+	test.CaseValue = vm.Register(cases.Value)
+	if cases.Key != "" {
+		keyType := types.Number
+		if kind == types.KindMap {
+			keyType = types.String
+		}
+
+		test.CompiledFunc.NewVariable(cases.Key, keyType)
+		test.CaseKey = vm.Register(cases.Key)
+	}
+
+	return nil
+}
+
+// compileFuzzArguments declares the arguments of a fuzz target as variables of
+// the test. Each argument must be a type that can be generated.
+func compileFuzzArguments(
+	test *vm.CompiledTest,
+	fn *ast.Test,
+	file *vm.File,
+	constants map[string]*ast.Literal,
+) error {
+	if len(fn.Arguments) == 0 {
+		return fmt.Errorf("%s: fuzz target must have at least one argument",
+			fn.Pos)
+	}
+
+	test.FuzzConstructors = map[string]string{}
+	for _, arg := range fn.Arguments {
+		err := checkFuzzType(test, arg.Type, constants, map[string]bool{})
+		if err != nil {
+			return fmt.Errorf("%s: cannot fuzz %s: %v", fn.Pos, arg, err)
+		}
+
+		test.CompiledFunc.NewVariable(arg.Name, arg.Type)
+		test.FuzzArguments = append(test.FuzzArguments, &vm.FuzzArgument{
+			Name: arg.Name,
+			Type: file.AddType(arg.Type),
+		})
+	}
+
+	return nil
+}
+
+// checkFuzzType returns an error if values of ty cannot be generated. Objects
+// are generated by calling their constructor, which is added to the
+// FuzzConstructors of the test. objects contains the objects that ty is an
+// argument of. An object that is an argument of itself cannot be generated,
+// unless it is inside an array or map (which may be empty).
+func checkFuzzType(
+	test *vm.CompiledTest,
+	ty *types.Type,
+	constants map[string]*ast.Literal,
+	objects map[string]bool,
+) error {
+	switch ty.Kind {
+	case types.KindBool, types.KindChar, types.KindData, types.KindNumber,
+		types.KindString:
+		return nil
+
+	case types.KindArray, types.KindMap:
+		return checkFuzzType(test, ty.Element, constants, map[string]bool{})
+
+	case types.KindResolvedInterface:
+		if objects[ty.Name] {
+			return fmt.Errorf("%s is an argument of itself", ty.Name)
+		}
+
+		if _, ok := test.FuzzConstructors[ty.Name]; ok {
+			return nil
+		}
+
+		constructor, ok := constants[ty.Name]
+		if !ok || constructor.Kind.Kind != types.KindFunc ||
+			len(constructor.Kind.Returns) != 1 ||
+			constructor.Kind.Returns[0].Name != ty.Name {
+			return fmt.Errorf("%s does not have a constructor", ty.Name)
+		}
+
+		test.FuzzConstructors[ty.Name] = constructor.Value
+		objects[ty.Name] = true
+		for _, argType := range constructor.Kind.Arguments {
+			err := checkFuzzType(test, argType, constants, objects)
+			if err != nil {
+				return err
+			}
+		}
+		delete(objects, ty.Name)
+
+		return nil
+	}
+
+	return fmt.Errorf("values of type %s cannot be generated", ty)
 }
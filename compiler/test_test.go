@@ -1,9 +1,11 @@
 package compiler_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
 	"github.com/elliotchance/ok/compiler"
 	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/vm"
@@ -13,9 +15,12 @@ import (
 
 func TestTest(t *testing.T) {
 	for testName, test := range map[string]struct {
-		fn       *ast.Test
-		expected []vm.Instruction
-		err      error
+		fn               *ast.Test
+		hooks            []*ast.Hook
+		expected         []vm.Instruction
+		expectedSetup    []vm.Instruction
+		expectedTeardown []vm.Instruction
+		err              error
 	}{
 		"no-statements": {
 			fn: &ast.Test{},
@@ -32,18 +37,123 @@ func TestTest(t *testing.T) {
 				&vm.Print{},
 			},
 		},
+		"hooks": {
+			fn: &ast.Test{
+				Statements: []ast.Node{
+					&ast.Call{
+						Expr: &ast.Identifier{Name: "print"},
+					},
+				},
+			},
+			hooks: []*ast.Hook{
+				{
+					Statements: []ast.Node{
+						&ast.Call{
+							Expr: &ast.Identifier{Name: "print"},
+						},
+					},
+				},
+				{IsTeardown: true},
+				{
+					IsTeardown: true,
+					Statements: []ast.Node{
+						&ast.Call{
+							Expr: &ast.Identifier{Name: "print"},
+						},
+						&ast.Call{
+							Expr: &ast.Identifier{Name: "print"},
+						},
+					},
+				},
+			},
+			expected: []vm.Instruction{
+				&vm.Print{},
+			},
+			expectedSetup: []vm.Instruction{
+				&vm.Print{},
+			},
+			expectedTeardown: []vm.Instruction{
+				&vm.Print{},
+				&vm.Print{},
+			},
+		},
+		"cases-not-iterable": {
+			fn: &ast.Test{
+				Cases: &ast.In{
+					Value: "c",
+					Expr:  asttest.NewLiteralNumber("1"),
+					Pos:   "a.ok:1:1",
+				},
+			},
+			err: errors.New("a.ok:1:1: test cases must be an array or map, got number"),
+		},
+		"fuzz": {
+			fn: &ast.Test{
+				IsFuzz: true,
+				Arguments: []*ast.Argument{
+					{Name: "s", Type: types.String},
+				},
+				Statements: []ast.Node{
+					&ast.Call{
+						Expr:      &ast.Identifier{Name: "print"},
+						Arguments: []ast.Node{&ast.Identifier{Name: "s"}},
+					},
+				},
+			},
+			expected: []vm.Instruction{
+				&vm.Print{Arguments: []vm.Register{"s"}},
+			},
+		},
+		"fuzz-without-arguments": {
+			fn: &ast.Test{
+				IsFuzz: true,
+				Pos:    "a.ok:1:1",
+			},
+			err: errors.New("a.ok:1:1: fuzz target must have at least one argument"),
+		},
+		"fuzz-func-argument": {
+			fn: &ast.Test{
+				IsFuzz: true,
+				Arguments: []*ast.Argument{
+					{Name: "f", Type: types.NewFunc(nil, nil)},
+				},
+				Pos: "a.ok:1:1",
+			},
+			err: errors.New("a.ok:1:1: cannot fuzz f func(): values of type func() cannot be generated"),
+		},
+		"fuzz-object-without-constructor": {
+			fn: &ast.Test{
+				IsFuzz: true,
+				Arguments: []*ast.Argument{
+					{Name: "p", Type: types.NewInterface("Person", nil).ToArray()},
+				},
+				Pos: "a.ok:1:1",
+			},
+			err: errors.New("a.ok:1:1: cannot fuzz p []Person: Person does not have a constructor"),
+		},
 	} {
 		t.Run(testName, func(t *testing.T) {
-			compiledFunc, err := compiler.CompileTest(test.fn,
+			compiledTest, err := compiler.CompileTest(test.fn, test.hooks,
 				&vm.File{
-					Types: types.Registry{},
+					Types:   types.Registry{},
+					Symbols: map[vm.SymbolRegister]*vm.Symbol{},
 				}, nil, nil)
 			if test.err != nil {
 				assert.EqualError(t, err, test.err.Error())
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, test.expected, compiledFunc.Instructions.Instructions)
+				assert.Equal(t, test.expected, compiledTest.Instructions.Instructions)
+				assert.Equal(t, test.expectedSetup, instructions(compiledTest.Setup))
+				assert.Equal(t, test.expectedTeardown, instructions(compiledTest.Teardown))
 			}
 		})
 	}
 }
+
+func instructions(ins *vm.Instructions) []vm.Instruction {
+	if ins == nil {
+		return nil
+	}
+
+	return ins.Instructions
+}
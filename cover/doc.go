@@ -0,0 +1,7 @@
+// Package cover creates coverage reports from the statements recorded by
+// vm.Coverage while running tests.
+//
+// Profiles are written in the same format as "go tool cover" so that existing
+// tools can read them. Each statement is a block that starts at the position
+// of the statement and ends at the end of the line.
+package cover
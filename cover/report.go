@@ -0,0 +1,349 @@
+package cover
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elliotchance/ok/fs"
+	"github.com/elliotchance/ok/vm"
+)
+
+// Statement is a single statement that can be covered.
+type Statement struct {
+	// FileName is the file path relative to $OKPATH, like "foo/bar.ok".
+	FileName string
+
+	Line, Column int
+
+	// EndColumn is the column after the last character on the line.
+	EndColumn int
+
+	// Func is the name of the function that contains the statement.
+	Func string
+
+	// Count is the number of times the statement was executed.
+	Count int
+}
+
+// Summary is the coverage of a function or file.
+type Summary struct {
+	// Name is the function name or file name.
+	Name string
+
+	// Pos is the position of the function, or the file name.
+	Pos string
+
+	Covered, Total int
+}
+
+// Percent returns the percentage of statements that were covered. A summary
+// without any statements is 100% covered.
+func (s Summary) Percent() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+
+	return float64(s.Covered) / float64(s.Total) * 100
+}
+
+// Report is the coverage of one or more packages.
+type Report struct {
+	Statements []Statement
+	funcs      []Summary
+}
+
+// NewReport creates an empty report.
+func NewReport() *Report {
+	return &Report{}
+}
+
+// Add adds the statements of a package to the report. Only the functions that
+// are in the package directory (that are not in test files) are included,
+// since file also contains all of the imported packages.
+func (r *Report) Add(file *vm.File, okPath, packageName string,
+	coverage *vm.Coverage) {
+	// This is the same as compiler.Compile.
+	dir := path.Join(okPath, packageName)
+	if !strings.Contains(packageName, "/") {
+		dir = "/" + packageName
+	}
+
+	// Sorted by position for consistent output.
+	var funcs []*vm.CompiledFunc
+	for _, symbol := range file.Symbols {
+		if fn := symbol.Func; fn != nil && fn.Instructions != nil &&
+			path.Dir(fileName(fn.Pos)) == dir &&
+			path.Ext(fileName(fn.Pos)) == ".ok" {
+			funcs = append(funcs, fn)
+		}
+	}
+	sort.Slice(funcs, func(i, j int) bool {
+		return lessPos(funcs[i].Pos, funcs[j].Pos)
+	})
+
+	lineLengths := map[string][]int{}
+	summaries := map[string]*Summary{}
+	var names []string
+	for _, fn := range funcs {
+		positions := statementPositions(fn)
+		if len(positions) == 0 {
+			continue
+		}
+
+		name := funcName(fn)
+		summary := summaries[name]
+		if summary == nil {
+			summary = &Summary{
+				Name: name,
+				Pos:  relativePos(okPath, fn.Pos),
+			}
+			summaries[name] = summary
+			names = append(names, name)
+		}
+
+		for _, pos := range positions {
+			filePath, line, column := splitPos(pos)
+			if _, ok := lineLengths[filePath]; !ok {
+				lineLengths[filePath] = readLineLengths(filePath)
+			}
+
+			endColumn := column + 1
+			if line <= len(lineLengths[filePath]) {
+				endColumn = lineLengths[filePath][line-1] + 1
+			}
+
+			count := coverage.Counts[pos]
+			r.Statements = append(r.Statements, Statement{
+				FileName:  relativePath(okPath, filePath),
+				Line:      line,
+				Column:    column,
+				EndColumn: endColumn,
+				Func:      name,
+				Count:     count,
+			})
+
+			summary.Total++
+			if count > 0 {
+				summary.Covered++
+			}
+		}
+	}
+
+	for _, name := range names {
+		r.funcs = append(r.funcs, *summaries[name])
+	}
+}
+
+// statementPositions returns the positions of the statements in the function,
+// including those in finally blocks.
+func statementPositions(fn *vm.CompiledFunc) []string {
+	seen := map[string]bool{}
+	var positions []string
+	for _, ins := range append([]*vm.Instructions{fn.Instructions},
+		fn.Finally...) {
+		for _, pos := range ins.Positions {
+			if !seen[pos] {
+				seen[pos] = true
+				positions = append(positions, pos)
+			}
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return lessPos(positions[i], positions[j])
+	})
+
+	return positions
+}
+
+// funcName uses the closest named function, since statements in function
+// literals belong to the function that contains them. The outermost function is
+// the package function so it is not included.
+func funcName(fn *vm.CompiledFunc) string {
+	var names []string
+	for ; fn != nil && fn.Parent != nil; fn = fn.Parent {
+		if fn.Name != "" {
+			names = append([]string{fn.Name}, names...)
+		}
+	}
+
+	return strings.Join(names, ".")
+}
+
+// Funcs returns the coverage of each function.
+func (r *Report) Funcs() []Summary {
+	return r.funcs
+}
+
+// Files returns the coverage of each file, sorted by name.
+func (r *Report) Files() []Summary {
+	summaries := map[string]*Summary{}
+	var names []string
+	for _, statement := range r.Statements {
+		summary := summaries[statement.FileName]
+		if summary == nil {
+			summary = &Summary{
+				Name: statement.FileName,
+				Pos:  statement.FileName,
+			}
+			summaries[statement.FileName] = summary
+			names = append(names, statement.FileName)
+		}
+
+		summary.Total++
+		if statement.Count > 0 {
+			summary.Covered++
+		}
+	}
+	sort.Strings(names)
+
+	var files []Summary
+	for _, name := range names {
+		files = append(files, *summaries[name])
+	}
+
+	return files
+}
+
+// Total returns the coverage of all statements.
+func (r *Report) Total() Summary {
+	total := Summary{Name: "total"}
+	for _, statement := range r.Statements {
+		total.Total++
+		if statement.Count > 0 {
+			total.Covered++
+		}
+	}
+
+	return total
+}
+
+// WriteProfile writes the profile in the format used by "go tool cover".
+func (r *Report) WriteProfile(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "mode: count"); err != nil {
+		return err
+	}
+
+	for _, statement := range r.Statements {
+		_, err := fmt.Fprintf(w, "%s:%d.%d,%d.%d 1 %d\n",
+			statement.FileName, statement.Line, statement.Column,
+			statement.Line, statement.EndColumn, statement.Count)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteSummary writes the coverage of each function and file, in a similar
+// format to "go tool cover -func".
+func (r *Report) WriteSummary(w io.Writer) error {
+	var lines [][]string
+	for _, fn := range r.Funcs() {
+		lines = append(lines, []string{fn.Pos + ":", fn.Name, percent(fn)})
+	}
+	for _, file := range r.Files() {
+		lines = append(lines, []string{file.Name + ":", "(file)", percent(file)})
+	}
+	lines = append(lines, []string{"total:", "(statements)", percent(r.Total())})
+
+	widths := make([]int, 2)
+	for _, line := range lines {
+		for i := range widths {
+			if len(line[i]) > widths[i] {
+				widths[i] = len(line[i])
+			}
+		}
+	}
+
+	for _, line := range lines {
+		_, err := fmt.Fprintf(w, "%-*s  %-*s  %6s\n", widths[0], line[0],
+			widths[1], line[1], line[2])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func percent(s Summary) string {
+	return fmt.Sprintf("%.1f%%", s.Percent())
+}
+
+// splitPos splits a position like "/foo/bar.ok:12:3".
+func splitPos(pos string) (string, int, int) {
+	parts := strings.Split(pos, ":")
+	if len(parts) < 3 {
+		return pos, 0, 0
+	}
+
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	column, _ := strconv.Atoi(parts[len(parts)-1])
+
+	return strings.Join(parts[:len(parts)-2], ":"), line, column
+}
+
+func fileName(pos string) string {
+	filePath, _, _ := splitPos(pos)
+
+	return filePath
+}
+
+func lessPos(a, b string) bool {
+	aFile, aLine, aColumn := splitPos(a)
+	bFile, bLine, bColumn := splitPos(b)
+	if aFile != bFile {
+		return aFile < bFile
+	}
+
+	if aLine != bLine {
+		return aLine < bLine
+	}
+
+	return aColumn < bColumn
+}
+
+func relativePath(okPath, filePath string) string {
+	if rel, err := filepath.Rel(okPath, filePath); err == nil &&
+		!strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return strings.TrimPrefix(filePath, "/")
+}
+
+func relativePos(okPath, pos string) string {
+	filePath, line, _ := splitPos(pos)
+
+	return fmt.Sprintf("%s:%d", relativePath(okPath, filePath), line)
+}
+
+// readLineLengths returns the number of characters on each line of a file. It
+// returns nil if the file cannot be read.
+func readLineLengths(filePath string) []int {
+	f, err := fs.Filesystem.OpenFile(filePath, os.O_RDONLY, 0)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil
+	}
+
+	var lengths []int
+	for _, line := range strings.Split(string(data), "\n") {
+		lengths = append(lengths, len([]rune(line)))
+	}
+
+	return lengths
+}
@@ -0,0 +1,193 @@
+package cover_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/cover"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFile creates a file with a package function, a function "add" with two
+// statements and a function "sub" with one statement that contains a function
+// literal.
+func newFile() *vm.File {
+	pkg := &vm.CompiledFunc{
+		Name:         "pkg",
+		Pos:          "/ok/lib/pkg/main.ok:1:1",
+		Instructions: &vm.Instructions{},
+	}
+
+	add := &vm.CompiledFunc{
+		Name: "add",
+		Pos:  "/ok/lib/pkg/main.ok:1:1",
+		Instructions: &vm.Instructions{
+			Positions: map[int]string{
+				0: "/ok/lib/pkg/main.ok:2:5",
+				3: "/ok/lib/pkg/main.ok:3:5",
+			},
+		},
+		Parent: pkg,
+	}
+
+	sub := &vm.CompiledFunc{
+		Name: "sub",
+		Pos:  "/ok/lib/pkg/sub.ok:1:1",
+		Instructions: &vm.Instructions{
+			Positions: map[int]string{
+				0: "/ok/lib/pkg/sub.ok:2:5",
+			},
+		},
+		Parent: pkg,
+	}
+
+	literal := &vm.CompiledFunc{
+		Pos: "/ok/lib/pkg/sub.ok:2:9",
+		Instructions: &vm.Instructions{
+			Positions: map[int]string{
+				0: "/ok/lib/pkg/sub.ok:3:9",
+			},
+		},
+		Parent: sub,
+	}
+
+	test := &vm.CompiledFunc{
+		Name: "helper",
+		Pos:  "/ok/lib/pkg/main.okt:1:1",
+		Instructions: &vm.Instructions{
+			Positions: map[int]string{
+				0: "/ok/lib/pkg/main.okt:2:5",
+			},
+		},
+		Parent: pkg,
+	}
+
+	other := &vm.CompiledFunc{
+		Name: "other",
+		Pos:  "/ok/lib/other/main.ok:1:1",
+		Instructions: &vm.Instructions{
+			Positions: map[int]string{
+				0: "/ok/lib/other/main.ok:2:5",
+			},
+		},
+		Parent: pkg,
+	}
+
+	file := &vm.File{
+		Symbols: map[vm.SymbolRegister]*vm.Symbol{},
+	}
+	for i, fn := range []*vm.CompiledFunc{pkg, add, sub, literal, test, other} {
+		file.Symbols[vm.SymbolRegister(fmt.Sprintf("%d", i))] = &vm.Symbol{
+			Func: fn,
+		}
+	}
+
+	return file
+}
+
+func TestReport(t *testing.T) {
+	for testName, test := range map[string]struct {
+		counts  map[string]int
+		profile string
+		summary string
+	}{
+		"none": {
+			profile: `mode: count
+lib/pkg/main.ok:2.5,2.6 1 0
+lib/pkg/main.ok:3.5,3.6 1 0
+lib/pkg/sub.ok:2.5,2.6 1 0
+lib/pkg/sub.ok:3.9,3.10 1 0
+`,
+			summary: `lib/pkg/main.ok:1:  add             0.0%
+lib/pkg/sub.ok:1:   sub             0.0%
+lib/pkg/main.ok:    (file)          0.0%
+lib/pkg/sub.ok:     (file)          0.0%
+total:              (statements)    0.0%
+`,
+		},
+		"some": {
+			counts: map[string]int{
+				"/ok/lib/pkg/main.ok:2:5":   3,
+				"/ok/lib/pkg/sub.ok:3:9":    1,
+				"/ok/lib/pkg/main.okt:2:5":  1,
+				"/ok/lib/other/main.ok:2:5": 1,
+			},
+			profile: `mode: count
+lib/pkg/main.ok:2.5,2.6 1 3
+lib/pkg/main.ok:3.5,3.6 1 0
+lib/pkg/sub.ok:2.5,2.6 1 0
+lib/pkg/sub.ok:3.9,3.10 1 1
+`,
+			summary: `lib/pkg/main.ok:1:  add            50.0%
+lib/pkg/sub.ok:1:   sub            50.0%
+lib/pkg/main.ok:    (file)         50.0%
+lib/pkg/sub.ok:     (file)         50.0%
+total:              (statements)   50.0%
+`,
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			coverage := vm.NewCoverage()
+			for pos, count := range test.counts {
+				coverage.Counts[pos] = count
+			}
+
+			report := cover.NewReport()
+			report.Add(newFile(), "/ok", "lib/pkg", coverage)
+
+			profile := bytes.NewBuffer(nil)
+			assert.NoError(t, report.WriteProfile(profile))
+			assert.Equal(t, test.profile, profile.String())
+
+			summary := bytes.NewBuffer(nil)
+			assert.NoError(t, report.WriteSummary(summary))
+			assert.Equal(t, test.summary, summary.String())
+		})
+	}
+}
+
+func TestReport_Cache(t *testing.T) {
+	okPath, err := ioutil.TempDir("", "ok")
+	require.NoError(t, err)
+	defer os.RemoveAll(okPath)
+
+	defer os.Setenv("OKCACHE", os.Getenv("OKCACHE"))
+	require.NoError(t, os.Setenv("OKCACHE", filepath.Join(okPath, "cache")))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(okPath, "x", "p"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(okPath, "x", "p", "main.ok"),
+		[]byte("func A() {\n    print(1)\n    print(2)\n}\n\nfunc B() {\n    print(3)\n}\n"),
+		0644))
+
+	file, _, errs := compiler.Compile(okPath, "x/p", false, new(int), false)
+	require.Empty(t, errs)
+
+	// The functions loaded from the cache must still know their parents to be
+	// named.
+	require.NoError(t, vm.Store(file, "x-p"))
+	file, err = vm.Load("x-p")
+	require.NoError(t, err)
+
+	report := cover.NewReport()
+	report.Add(file, okPath, "x/p", vm.NewCoverage())
+
+	summary := bytes.NewBuffer(nil)
+	assert.NoError(t, report.WriteSummary(summary))
+	assert.Equal(t, `x/p/main.ok:1:  A               0.0%
+x/p/main.ok:6:  B               0.0%
+x/p/main.ok:    (file)          0.0%
+total:          (statements)    0.0%
+`, summary.String())
+}
+
+func TestSummary_Percent(t *testing.T) {
+	assert.Equal(t, 100.0, cover.Summary{}.Percent())
+	assert.Equal(t, 25.0, cover.Summary{Covered: 1, Total: 4}.Percent())
+}
@@ -0,0 +1,297 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/elliotchance/ok/fs"
+)
+
+const consoleHelp = `Commands:
+  break <file:line|func>   add a breakpoint (b)
+  clear <file:line|func>   remove a breakpoint
+  breakpoints              list breakpoints
+  continue                 run until the next breakpoint (c)
+  step                     step into the next statement (s)
+  next                     step over the next statement (n)
+  out                      step out of the current function (o)
+  stack                    print the stack (bt)
+  vars [frame]             print the variables of a frame (v)
+  regs [frame]             print the registers of a frame (r)
+  print <name> [frame]     print a single variable or register (p)
+  error                    print the current error and where it was raised
+  quit                     stop debugging (q)
+`
+
+// Console is an interactive front end for a controller.
+type Console struct {
+	controller *Controller
+	in         *bufio.Scanner
+	out        io.Writer
+}
+
+// NewConsole creates a console that reads commands from in. The program should
+// be started with the controller stopped on entry so that breakpoints can be
+// set.
+func NewConsole(controller *Controller, in io.Reader, out io.Writer) *Console {
+	return &Console{
+		controller: controller,
+		in:         bufio.NewScanner(in),
+		out:        out,
+	}
+}
+
+// Run starts the program and handles commands until the program finishes or
+// the user quits.
+func (con *Console) Run(run func() error) error {
+	con.controller.Start(run)
+	fmt.Fprintln(con.out, `Type "help" for a list of commands.`)
+
+	for event := range con.controller.Events() {
+		if event.Exited {
+			fmt.Fprintln(con.out, "program exited")
+			return event.Err
+		}
+
+		con.printStop(event)
+
+		if quit := con.prompt(); quit {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// prompt handles commands until the program is resumed. It returns true if the
+// user wants to quit.
+func (con *Console) prompt() bool {
+	for {
+		fmt.Fprint(con.out, "(ok) ")
+		if !con.in.Scan() {
+			fmt.Fprintln(con.out)
+			return true
+		}
+
+		args := strings.Fields(con.in.Text())
+		if len(args) == 0 {
+			continue
+		}
+
+		resumed, quit, err := con.command(args[0], args[1:])
+		if err != nil {
+			fmt.Fprintln(con.out, err)
+		}
+
+		if resumed || quit {
+			return quit
+		}
+	}
+}
+
+func (con *Console) command(name string, args []string) (resumed, quit bool, err error) {
+	c := con.controller
+
+	switch name {
+	case "help", "h":
+		fmt.Fprint(con.out, consoleHelp)
+
+	case "quit", "q":
+		return false, true, nil
+
+	case "break", "b":
+		if len(args) != 1 {
+			return false, false, fmt.Errorf("usage: break <file:line|func>")
+		}
+
+		return false, false, c.SetBreakpoint(args[0])
+
+	case "clear":
+		if len(args) != 1 {
+			return false, false, fmt.Errorf("usage: clear <file:line|func>")
+		}
+
+		return false, false, c.ClearBreakpoint(args[0])
+
+	case "breakpoints":
+		for _, breakpoint := range c.Breakpoints() {
+			fmt.Fprintln(con.out, breakpoint)
+		}
+
+	case "continue", "c":
+		return true, false, c.Continue()
+
+	case "step", "s":
+		return true, false, c.StepIn()
+
+	case "next", "n":
+		return true, false, c.StepOver()
+
+	case "out", "o":
+		return true, false, c.StepOut()
+
+	case "stack", "bt":
+		for i, frame := range c.Frames() {
+			fmt.Fprintf(con.out, "  #%d %s() at %s\n", i, frame.Name,
+				trimWorkingDirectory(frame.Pos))
+		}
+
+	case "vars", "v", "regs", "r":
+		frame, err := frameArg(args, 0)
+		if err != nil {
+			return false, false, err
+		}
+
+		variables, err := con.variables(name, frame)
+		if err != nil {
+			return false, false, err
+		}
+
+		for _, variable := range variables {
+			con.printVariable(variable)
+		}
+
+	case "print", "p":
+		if len(args) == 0 {
+			return false, false, fmt.Errorf("usage: print <name> [frame]")
+		}
+
+		frame, err := frameArg(args, 1)
+		if err != nil {
+			return false, false, err
+		}
+
+		return false, false, con.print(args[0], frame)
+
+	case "error":
+		if c.Error() != "" {
+			con.printError()
+		} else {
+			fmt.Fprintln(con.out, "no error")
+		}
+
+	default:
+		return false, false, fmt.Errorf(`unknown command %q, type "help" for a list of commands`, name)
+	}
+
+	return false, false, nil
+}
+
+func (con *Console) variables(command string, frame int) ([]Variable, error) {
+	if command[0] == 'r' {
+		return con.controller.Registers(frame)
+	}
+
+	return con.controller.Variables(frame)
+}
+
+// print looks for a variable, then a register, with the name.
+func (con *Console) print(name string, frame int) error {
+	for _, command := range []string{"vars", "regs"} {
+		variables, err := con.variables(command, frame)
+		if err != nil {
+			return err
+		}
+
+		for _, variable := range variables {
+			if variable.Name == name {
+				con.printVariable(variable)
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("no such variable or register: %s", name)
+}
+
+func (con *Console) printVariable(variable Variable) {
+	fmt.Fprintf(con.out, "  %s = %s (%s)\n", variable.Name, variable.Value,
+		variable.Type)
+}
+
+func (con *Console) printStop(event Event) {
+	if event.Reason == ReasonException {
+		con.printError()
+	}
+
+	name := ""
+	if frames := con.controller.Frames(); len(frames) > 0 {
+		name = frames[0].Name
+	}
+
+	fmt.Fprintf(con.out, "stopped at %s in %s() (%s)\n",
+		trimWorkingDirectory(event.Pos), name, event.Reason)
+
+	if line := sourceLine(event.Pos); line != "" {
+		fmt.Fprintf(con.out, "  %s\n", line)
+	}
+}
+
+// printError uses the same format as an unhandled error.
+func (con *Console) printError() {
+	fmt.Fprintln(con.out, con.controller.Error())
+	frames := con.controller.ErrorStack()
+	for i, frame := range frames {
+		fmt.Fprintln(con.out, "", "", len(frames)-i, frame.Name+"()", "at",
+			trimWorkingDirectory(frame.Pos))
+	}
+}
+
+func frameArg(args []string, i int) (int, error) {
+	if len(args) <= i {
+		return 0, nil
+	}
+
+	frame, err := strconv.Atoi(args[i])
+	if err != nil {
+		return 0, fmt.Errorf("invalid frame: %s", args[i])
+	}
+
+	return frame, nil
+}
+
+func trimWorkingDirectory(pos string) string {
+	wd, _ := os.Getwd()
+	if wd != "" && strings.HasPrefix(pos, wd+"/") {
+		return pos[len(wd)+1:]
+	}
+
+	return pos
+}
+
+// sourceLine returns the line of source code for a position, without
+// indentation. An empty string is returned if it cannot be read.
+func sourceLine(pos string) string {
+	parts := strings.Split(pos, ":")
+	if len(parts) < 2 {
+		return ""
+	}
+
+	line, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	f, err := fs.Filesystem.OpenFile(parts[0], os.O_RDONLY, 0)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if line > len(lines) {
+		return ""
+	}
+
+	return strings.TrimSpace(lines[line-1])
+}
@@ -0,0 +1,523 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/vm"
+)
+
+// The reasons that the program can stop.
+const (
+	ReasonEntry              = "entry"
+	ReasonStep               = "step"
+	ReasonBreakpoint         = "breakpoint"
+	ReasonFunctionBreakpoint = "function breakpoint"
+	ReasonPause              = "pause"
+	ReasonException          = "exception"
+)
+
+// ErrNotStopped is returned when trying to resume a program that is running.
+var ErrNotStopped = errors.New("program is not stopped")
+
+// Event is sent by the controller each time the program stops, and once more
+// when the program finishes.
+type Event struct {
+	// Reason is one of the Reason constants. It will be empty when the program
+	// has exited.
+	Reason string
+
+	// Pos is where the program stopped, like "main.ok:12:3".
+	Pos string
+
+	// Exited is true when the program has finished. Err is the error returned
+	// by the VM, if any.
+	Exited bool
+	Err    error
+}
+
+// Frame is a function call on the stack.
+type Frame struct {
+	// Name is the name of the function.
+	Name string
+
+	// Pos is the position of the statement being executed. For frames other
+	// than the innermost, this is the statement that made the call.
+	Pos string
+
+	// stack is used to identify the frame in vm.Stack.
+	stack *ast.Literal
+}
+
+// Variable is a variable or register in a frame.
+type Variable struct {
+	Name, Type, Value string
+}
+
+type action int
+
+const (
+	actionContinue action = iota
+	actionStepIn
+	actionStepOver
+	actionStepOut
+)
+
+// Controller controls a VM. It must be created before the program is started.
+//
+// The program runs in its own goroutine. Frames, Variables and Registers may
+// only be used while the program is stopped, that is, after an Event has been
+// received and before the program is resumed.
+type Controller struct {
+	vm *vm.VM
+
+	// frames mirrors vm.Stack, with the addition of the current position of
+	// each frame. It is only modified by the program goroutine.
+	frames []Frame
+
+	// enteredDepth is the depth of a function that was just entered and
+	// matched a function breakpoint. It will stop at the first statement.
+	enteredDepth int
+
+	// errorReported prevents the same error stopping each frame as it is
+	// passed up the stack.
+	errorReported bool
+
+	events chan Event
+	resume chan action
+
+	// Everything below is protected by mu because it is changed by the front
+	// end while the program is running.
+	mu              sync.Mutex
+	breakpoints     map[string]bool
+	funcBreakpoints map[string]bool
+	action          action
+	actionDepth     int
+	pausing         bool
+	stopped         bool
+	entry           bool
+}
+
+// NewController attaches a controller to m. If stopOnEntry is true the program
+// will stop at the first statement.
+func NewController(m *vm.VM, stopOnEntry bool) *Controller {
+	c := &Controller{
+		vm:              m,
+		events:          make(chan Event),
+		resume:          make(chan action),
+		breakpoints:     map[string]bool{},
+		funcBreakpoints: map[string]bool{},
+		entry:           stopOnEntry,
+	}
+	m.Debugger = c
+
+	return c
+}
+
+// Start runs the program in a new goroutine. Events will be sent until the
+// program finishes.
+func (c *Controller) Start(run func() error) {
+	go func() {
+		err := run()
+		c.events <- Event{Exited: true, Err: err}
+		close(c.events)
+	}()
+}
+
+// Events returns the channel that receives an Event each time the program
+// stops. The program will not continue until it is resumed.
+func (c *Controller) Events() <-chan Event {
+	return c.events
+}
+
+// SetBreakpoint adds a breakpoint. It may be a position ("main.ok:12") or the
+// name of a function. A position only needs to contain the end of the file
+// path.
+func (c *Controller) SetBreakpoint(breakpoint string) error {
+	breakpoint, isFunc, err := parseBreakpoint(breakpoint)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if isFunc {
+		c.funcBreakpoints[breakpoint] = true
+	} else {
+		c.breakpoints[breakpoint] = true
+	}
+
+	return nil
+}
+
+// ClearBreakpoint removes a breakpoint added with SetBreakpoint.
+func (c *Controller) ClearBreakpoint(breakpoint string) error {
+	breakpoint, isFunc, err := parseBreakpoint(breakpoint)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breakpoints := c.breakpoints
+	if isFunc {
+		breakpoints = c.funcBreakpoints
+	}
+
+	if !breakpoints[breakpoint] {
+		return fmt.Errorf("no such breakpoint: %s", breakpoint)
+	}
+	delete(breakpoints, breakpoint)
+
+	return nil
+}
+
+// ClearBreakpoints removes all of the breakpoints in the file (when funcs is
+// false) or all of the function breakpoints (when funcs is true).
+func (c *Controller) ClearBreakpoints(filePath string, funcs bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if funcs {
+		c.funcBreakpoints = map[string]bool{}
+		return
+	}
+
+	for breakpoint := range c.breakpoints {
+		if strings.HasPrefix(breakpoint, filePath+":") {
+			delete(c.breakpoints, breakpoint)
+		}
+	}
+}
+
+// Breakpoints returns all of the breakpoints, sorted.
+func (c *Controller) Breakpoints() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var breakpoints []string
+	for breakpoint := range c.breakpoints {
+		breakpoints = append(breakpoints, breakpoint)
+	}
+	for breakpoint := range c.funcBreakpoints {
+		breakpoints = append(breakpoints, breakpoint)
+	}
+	sort.Strings(breakpoints)
+
+	return breakpoints
+}
+
+// parseBreakpoint validates the line number of a position. The column is not
+// used.
+func parseBreakpoint(breakpoint string) (string, bool, error) {
+	parts := strings.Split(breakpoint, ":")
+	if len(parts) == 1 {
+		return breakpoint, true, nil
+	}
+
+	line, err := strconv.Atoi(parts[1])
+	if err != nil || line < 1 || parts[0] == "" {
+		return "", false, fmt.Errorf("invalid breakpoint: %s", breakpoint)
+	}
+
+	return fmt.Sprintf("%s:%d", parts[0], line), false, nil
+}
+
+// Continue resumes the program until the next breakpoint.
+func (c *Controller) Continue() error {
+	return c.resumeWith(actionContinue)
+}
+
+// StepIn resumes the program until the next statement, which may be in a
+// function that is called.
+func (c *Controller) StepIn() error {
+	return c.resumeWith(actionStepIn)
+}
+
+// StepOver resumes the program until the next statement in the current
+// function. Breakpoints in functions that are called will still stop.
+func (c *Controller) StepOver() error {
+	return c.resumeWith(actionStepOver)
+}
+
+// StepOut resumes the program until the current function returns.
+func (c *Controller) StepOut() error {
+	return c.resumeWith(actionStepOut)
+}
+
+func (c *Controller) resumeWith(a action) error {
+	c.mu.Lock()
+	if !c.stopped {
+		c.mu.Unlock()
+		return ErrNotStopped
+	}
+	c.stopped = false
+	c.mu.Unlock()
+
+	c.resume <- a
+
+	return nil
+}
+
+// Pause stops the program at the next statement.
+func (c *Controller) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pausing = true
+}
+
+// Frames returns the stack, starting with the innermost function. The
+// outermost frames that have not executed any statements (such as the
+// initialization of packages) are not included.
+func (c *Controller) Frames() []Frame {
+	var frames []Frame
+	for i := len(c.frames) - 1; i >= 0; i-- {
+		if c.frames[i].Pos == "" && i < len(c.frames)-1 {
+			break
+		}
+
+		frames = append(frames, c.frames[i])
+	}
+
+	return frames
+}
+
+// Variables returns the variables of a frame, where 0 is the innermost frame.
+func (c *Controller) Variables(frame int) ([]Variable, error) {
+	registers, err := c.stackFrame(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	state := registers[vm.StateRegister].Map
+	var names []string
+	for name := range state {
+		if name != vm.StateRegister {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var variables []Variable
+	for _, name := range names {
+		variables = append(variables, newVariable(name, state[name]))
+	}
+
+	return variables, nil
+}
+
+// Registers returns the registers of a frame, where 0 is the innermost frame.
+func (c *Controller) Registers(frame int) ([]Variable, error) {
+	registers, err := c.stackFrame(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers []int
+	for register := range registers {
+		if register == vm.StateRegister || register == vm.StackRegister {
+			continue
+		}
+
+		if n, err := strconv.Atoi(string(register)); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	var variables []Variable
+	for _, n := range numbers {
+		name := strconv.Itoa(n)
+		variables = append(variables, newVariable(name,
+			registers[vm.Register(name)]))
+	}
+
+	return variables, nil
+}
+
+func (c *Controller) stackFrame(frame int) (map[vm.Register]*ast.Literal, error) {
+	if frame < 0 || frame >= len(c.frames) {
+		return nil, fmt.Errorf("no such frame: %d", frame)
+	}
+
+	return c.vm.Stack[len(c.frames)-frame-1], nil
+}
+
+func newVariable(name string, value *ast.Literal) Variable {
+	if value == nil {
+		return Variable{Name: name, Value: "nil"}
+	}
+
+	return Variable{
+		Name:  name,
+		Type:  value.Kind.String(),
+		Value: vm.InspectLiteral(value),
+	}
+}
+
+// ErrorStack returns where the current error was raised, starting with the
+// innermost function. It will be empty if there is no error.
+func (c *Controller) ErrorStack() []Frame {
+	if c.vm.ErrType == nil {
+		return nil
+	}
+
+	var frames []Frame
+	for i := len(c.vm.ErrStack) - 1; i >= 0; i-- {
+		parts := strings.SplitN(c.vm.ErrStack[i], "|", 2)
+		if len(parts) == 2 {
+			frames = append(frames, Frame{Name: parts[1], Pos: parts[0]})
+		}
+	}
+
+	return frames
+}
+
+// Error returns the description of the current error. It will be empty if there
+// is no error.
+func (c *Controller) Error() string {
+	if c.vm.ErrType == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s: %v", c.vm.ErrType, c.vm.ErrValue.Map["Error"])
+}
+
+// BeforeInstruction implements vm.Debugger.
+func (c *Controller) BeforeInstruction(m *vm.VM, ins *vm.Instructions, i int) {
+	if m.ErrType == nil {
+		c.errorReported = false
+	}
+
+	c.syncFrames()
+	depth := len(c.frames)
+	if pos := ins.Pos(i); pos != "" {
+		c.frames[depth-1].Pos = pos
+	}
+
+	// The program can only stop at the start of a statement.
+	pos, ok := ins.Positions[i]
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	reason := ""
+	switch {
+	case c.entry:
+		reason = ReasonEntry
+
+	case c.pausing:
+		reason = ReasonPause
+
+	case c.action == actionStepIn,
+		c.action == actionStepOver && depth <= c.actionDepth,
+		c.action == actionStepOut && depth < c.actionDepth:
+		reason = ReasonStep
+
+	case c.enteredDepth == depth:
+		reason = ReasonFunctionBreakpoint
+
+	case c.isBreakpoint(pos):
+		reason = ReasonBreakpoint
+	}
+	c.mu.Unlock()
+
+	if reason != "" {
+		c.stop(reason, pos)
+	}
+}
+
+// ErrorRaised implements vm.Debugger.
+func (c *Controller) ErrorRaised(m *vm.VM) {
+	if c.errorReported {
+		return
+	}
+	c.errorReported = true
+
+	pos := ""
+	if len(m.ErrStack) > 0 {
+		pos = strings.SplitN(m.ErrStack[len(m.ErrStack)-1], "|", 2)[0]
+	}
+
+	c.stop(ReasonException, pos)
+}
+
+// syncFrames updates frames to match the stack of the VM. Each frame in the
+// stack has a unique description literal that is used to detect when a frame
+// has been replaced.
+func (c *Controller) syncFrames() {
+	n := 0
+	for n < len(c.frames) && n < len(c.vm.Stack) &&
+		c.frames[n].stack == c.vm.Stack[n][vm.StackRegister] {
+		n++
+	}
+	c.frames = c.frames[:n]
+
+	for _, registers := range c.vm.Stack[n:] {
+		stack := registers[vm.StackRegister]
+		name := ""
+		if parts := strings.SplitN(stack.Value, "|", 2); len(parts) == 2 {
+			name = parts[1]
+		}
+
+		c.frames = append(c.frames, Frame{
+			Name:  name,
+			stack: stack,
+		})
+
+		c.mu.Lock()
+		if c.funcBreakpoints[name] {
+			c.enteredDepth = len(c.frames)
+		}
+		c.mu.Unlock()
+	}
+
+	if c.enteredDepth > len(c.frames) {
+		c.enteredDepth = 0
+	}
+}
+
+// isBreakpoint must be called with mu locked.
+func (c *Controller) isBreakpoint(pos string) bool {
+	parts := strings.Split(pos, ":")
+	if len(parts) < 2 {
+		return false
+	}
+
+	filePath, line := parts[0], parts[1]
+	for breakpoint := range c.breakpoints {
+		if breakpoint == filePath+":"+line ||
+			strings.HasSuffix(filePath+":"+line, "/"+breakpoint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stop blocks the program until it is resumed.
+func (c *Controller) stop(reason, pos string) {
+	c.mu.Lock()
+	c.stopped = true
+	c.entry = false
+	c.pausing = false
+	c.enteredDepth = 0
+	c.mu.Unlock()
+
+	c.events <- Event{Reason: reason, Pos: pos}
+	a := <-c.resume
+
+	c.mu.Lock()
+	c.action = a
+	c.actionDepth = len(c.frames)
+	c.mu.Unlock()
+}
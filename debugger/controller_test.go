@@ -0,0 +1,242 @@
+package debugger_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/ok/debugger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const programSource = `import "error"
+
+func add(a, b number) number {
+    c = a + b
+    return c
+}
+
+func fail() {
+    raise error.Error("oops")
+}
+
+func main() {
+    x = 1
+    y = add(x, 2)
+    try {
+        fail()
+    } on error.Error {
+        y = 0
+    }
+    print(y)
+}
+`
+
+// load compiles the program in a temporary $OKPATH.
+func load(t *testing.T, stopOnEntry bool) (*debugger.Controller, func() error) {
+	okPath, err := ioutil.TempDir("", "ok-debugger-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(okPath)
+
+	dir := filepath.Join(okPath, "debugger", "program")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.ok"),
+		[]byte(programSource), 0644))
+
+	// Standard library packages are only resolved correctly from $OKPATH.
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(okPath))
+	defer os.Chdir(wd)
+
+	m, run, err := debugger.Load(okPath, "debugger/program", ioutil.Discard)
+	require.NoError(t, err)
+
+	return debugger.NewController(m, stopOnEntry), run
+}
+
+// line returns the line number (as a string) of a position.
+func line(pos string) string {
+	return strings.Split(pos, ":")[1]
+}
+
+func TestController(t *testing.T) {
+	for testName, test := range map[string]struct {
+		stopOnEntry bool
+		breakpoints []string
+
+		// actions are performed after each stop, in order. After the actions
+		// are exhausted the program is continued.
+		actions []func(c *debugger.Controller) error
+
+		// stops is each reason and line, like "step 14".
+		stops []string
+	}{
+		"no-breakpoints": {
+			stops: []string{
+				"exception 9",
+			},
+		},
+		"stop-on-entry": {
+			stopOnEntry: true,
+			stops: []string{
+				"entry 13",
+				"exception 9",
+			},
+		},
+		"line-breakpoint": {
+			breakpoints: []string{"main.ok:14", "program/main.ok:18"},
+			stops: []string{
+				"breakpoint 14",
+				"exception 9",
+				"breakpoint 18",
+			},
+		},
+		"function-breakpoint": {
+			breakpoints: []string{"add"},
+			stops: []string{
+				"function breakpoint 4",
+				"exception 9",
+			},
+		},
+		"step-in": {
+			breakpoints: []string{"main.ok:14"},
+			actions: []func(c *debugger.Controller) error{
+				(*debugger.Controller).StepIn,
+				(*debugger.Controller).StepIn,
+				(*debugger.Controller).StepIn,
+			},
+			stops: []string{
+				"breakpoint 14",
+				"step 4",
+				"step 5",
+				"step 16",
+				"exception 9",
+			},
+		},
+		"step-over": {
+			breakpoints: []string{"main.ok:14"},
+			actions: []func(c *debugger.Controller) error{
+				(*debugger.Controller).StepOver,
+				(*debugger.Controller).StepOver,
+			},
+			stops: []string{
+				"breakpoint 14",
+				"step 16",
+				"exception 9",
+			},
+		},
+		"step-out": {
+			breakpoints: []string{"add"},
+			actions: []func(c *debugger.Controller) error{
+				(*debugger.Controller).StepOut,
+			},
+			stops: []string{
+				"function breakpoint 4",
+				"step 16",
+				"exception 9",
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			c, run := load(t, test.stopOnEntry)
+			for _, breakpoint := range test.breakpoints {
+				require.NoError(t, c.SetBreakpoint(breakpoint))
+			}
+
+			c.Start(run)
+
+			var stops []string
+			for event := range c.Events() {
+				if event.Exited {
+					assert.NoError(t, event.Err)
+					continue
+				}
+
+				stops = append(stops, event.Reason+" "+line(event.Pos))
+
+				action := (*debugger.Controller).Continue
+				if len(test.actions) > 0 {
+					action, test.actions = test.actions[0], test.actions[1:]
+				}
+				require.NoError(t, action(c))
+			}
+
+			assert.Equal(t, test.stops, stops)
+		})
+	}
+}
+
+func TestController_Inspect(t *testing.T) {
+	c, run := load(t, false)
+	require.NoError(t, c.SetBreakpoint("add"))
+	c.Start(run)
+
+	event := <-c.Events()
+	assert.Equal(t, debugger.ReasonFunctionBreakpoint, event.Reason)
+
+	frames := c.Frames()
+	require.Len(t, frames, 2)
+	assert.Equal(t, "add", frames[0].Name)
+	assert.Equal(t, "4", line(frames[0].Pos))
+	assert.Equal(t, "main", frames[1].Name)
+	assert.Equal(t, "14", line(frames[1].Pos))
+
+	variables, err := c.Variables(0)
+	require.NoError(t, err)
+	assert.Equal(t, []debugger.Variable{
+		{Name: "a", Type: "number", Value: "1"},
+		{Name: "b", Type: "number", Value: "2"},
+	}, variables)
+
+	variables, err = c.Variables(1)
+	require.NoError(t, err)
+	assert.Equal(t, []debugger.Variable{
+		{Name: "x", Type: "number", Value: "1"},
+	}, variables)
+
+	_, err = c.Variables(5)
+	assert.EqualError(t, err, "no such frame: 5")
+
+	// The exception.
+	require.NoError(t, c.Continue())
+	event = <-c.Events()
+	assert.Equal(t, debugger.ReasonException, event.Reason)
+	assert.Equal(t, `Error: "oops"`, c.Error())
+
+	errorStack := c.ErrorStack()
+	require.Len(t, errorStack, 2)
+	assert.Equal(t, "fail", errorStack[0].Name)
+	assert.Equal(t, "9", line(errorStack[0].Pos))
+	assert.Equal(t, "main", errorStack[1].Name)
+	assert.Equal(t, "16", line(errorStack[1].Pos))
+
+	require.NoError(t, c.Continue())
+	event = <-c.Events()
+	assert.True(t, event.Exited)
+	assert.EqualError(t, c.Continue(), debugger.ErrNotStopped.Error())
+}
+
+func TestController_SetBreakpoint(t *testing.T) {
+	for breakpoint, expectedErr := range map[string]string{
+		"main":        "",
+		"main.ok:12":  "",
+		"main.ok:0":   "invalid breakpoint: main.ok:0",
+		"main.ok:foo": "invalid breakpoint: main.ok:foo",
+		":12":         "invalid breakpoint: :12",
+	} {
+		t.Run(breakpoint, func(t *testing.T) {
+			c, _ := load(t, false)
+			err := c.SetBreakpoint(breakpoint)
+			if expectedErr == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{breakpoint}, c.Breakpoints())
+			} else {
+				assert.EqualError(t, err, expectedErr)
+			}
+		})
+	}
+}
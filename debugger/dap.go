@@ -0,0 +1,425 @@
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DAPServer is a Debug Adapter Protocol server for a single debug session.
+type DAPServer struct {
+	in     *bufio.Reader
+	out    io.Writer
+	okPath string
+
+	// mu protects out and seq because events are sent from the program
+	// goroutine.
+	mu  sync.Mutex
+	seq int
+
+	controller *Controller
+	run        func() error
+	started    bool
+}
+
+// NewDAPServer creates a server that reads requests from in and writes
+// responses and events to out. The package to debug is provided by the client
+// in the "launch" request.
+func NewDAPServer(in io.Reader, out io.Writer, okPath string) *DAPServer {
+	return &DAPServer{
+		in:     bufio.NewReader(in),
+		out:    out,
+		okPath: okPath,
+	}
+}
+
+// Serve handles requests until the client disconnects.
+func (s *DAPServer) Serve() error {
+	for {
+		req, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		body, err := s.handle(req)
+		res := dapResponse{
+			Type:       "response",
+			RequestSeq: req.Seq,
+			Success:    err == nil,
+			Command:    req.Command,
+			Body:       body,
+		}
+		if err != nil {
+			res.Message = err.Error()
+		}
+
+		if err := s.send(&res, &res.Seq); err != nil {
+			return err
+		}
+
+		// The initialized event tells the client that it can send the
+		// breakpoints. That can only happen once the program is loaded.
+		if req.Command == "launch" && res.Success {
+			if err := s.event("initialized", nil); err != nil {
+				return err
+			}
+		}
+
+		if req.Command == "disconnect" || req.Command == "terminate" {
+			return nil
+		}
+	}
+}
+
+var errNotLaunched = errors.New("program has not been launched")
+
+func (s *DAPServer) handle(req *dapRequest) (interface{}, error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]interface{}{
+			"supportsConfigurationDoneRequest": true,
+			"supportsFunctionBreakpoints":      true,
+			"supportsTerminateRequest":         true,
+		}, nil
+
+	case "launch":
+		var args launchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+
+		return nil, s.launch(args)
+
+	case "disconnect", "terminate", "setExceptionBreakpoints":
+		return nil, nil
+	}
+
+	// Everything else needs the program.
+	if s.controller == nil {
+		return nil, errNotLaunched
+	}
+
+	switch req.Command {
+	case "setBreakpoints":
+		var args setBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+
+		return s.setBreakpoints(args), nil
+
+	case "setFunctionBreakpoints":
+		var args setFunctionBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+
+		return s.setFunctionBreakpoints(args), nil
+
+	case "configurationDone":
+		if !s.started {
+			s.started = true
+			s.start()
+		}
+
+		return nil, nil
+
+	case "threads":
+		return map[string]interface{}{
+			"threads": []thread{{ID: threadID, Name: "main"}},
+		}, nil
+
+	case "stackTrace":
+		return s.stackTrace(), nil
+
+	case "scopes":
+		var args scopesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+
+		return s.scopes(args), nil
+
+	case "variables":
+		var args variablesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+
+		return s.variables(args)
+
+	case "continue":
+		return map[string]interface{}{
+			"allThreadsContinued": true,
+		}, s.controller.Continue()
+
+	case "next":
+		return nil, s.controller.StepOver()
+
+	case "stepIn":
+		return nil, s.controller.StepIn()
+
+	case "stepOut":
+		return nil, s.controller.StepOut()
+
+	case "pause":
+		s.controller.Pause()
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unsupported command: %s", req.Command)
+}
+
+func (s *DAPServer) launch(args launchArguments) error {
+	program := args.Program
+	if program == "" {
+		program = "."
+	}
+
+	m, run, err := Load(s.okPath, program, &outputWriter{server: s})
+	if err != nil {
+		return err
+	}
+
+	s.controller = NewController(m, args.StopOnEntry)
+	s.run = run
+
+	return nil
+}
+
+// start runs the program and forwards the events from the controller.
+func (s *DAPServer) start() {
+	s.controller.Start(s.run)
+
+	go func() {
+		for event := range s.controller.Events() {
+			if event.Exited {
+				exitCode := 0
+				if event.Err != nil {
+					exitCode = 1
+					s.output("stderr", event.Err.Error()+"\n")
+				}
+
+				_ = s.event("exited", map[string]interface{}{
+					"exitCode": exitCode,
+				})
+				_ = s.event("terminated", nil)
+				continue
+			}
+
+			body := map[string]interface{}{
+				"reason":            stopReason(event.Reason),
+				"threadId":          threadID,
+				"allThreadsStopped": true,
+			}
+			if event.Reason == ReasonException {
+				body["text"] = s.controller.Error()
+			}
+
+			_ = s.event("stopped", body)
+		}
+	}()
+}
+
+// stopReason converts a reason into one of the values in the specification.
+func stopReason(reason string) string {
+	if reason == ReasonFunctionBreakpoint {
+		return "function breakpoint"
+	}
+
+	return reason
+}
+
+func (s *DAPServer) setBreakpoints(args setBreakpointsArguments) interface{} {
+	s.controller.ClearBreakpoints(args.Source.Path, false)
+
+	breakpoints := []breakpoint{}
+	for _, bp := range args.Breakpoints {
+		err := s.controller.SetBreakpoint(
+			fmt.Sprintf("%s:%d", args.Source.Path, bp.Line))
+		breakpoints = append(breakpoints, newBreakpoint(bp.Line, err))
+	}
+
+	return map[string]interface{}{
+		"breakpoints": breakpoints,
+	}
+}
+
+func (s *DAPServer) setFunctionBreakpoints(args setFunctionBreakpointsArguments) interface{} {
+	s.controller.ClearBreakpoints("", true)
+
+	breakpoints := []breakpoint{}
+	for _, bp := range args.Breakpoints {
+		err := s.controller.SetBreakpoint(bp.Name)
+		if err == nil && strings.Contains(bp.Name, ":") {
+			err = fmt.Errorf("invalid function name: %s", bp.Name)
+		}
+		breakpoints = append(breakpoints, newBreakpoint(0, err))
+	}
+
+	return map[string]interface{}{
+		"breakpoints": breakpoints,
+	}
+}
+
+func newBreakpoint(line int, err error) breakpoint {
+	if err != nil {
+		return breakpoint{Message: err.Error(), Line: line}
+	}
+
+	return breakpoint{Verified: true, Line: line}
+}
+
+func (s *DAPServer) stackTrace() interface{} {
+	frames := []stackFrame{}
+	for i, frame := range s.controller.Frames() {
+		sf := stackFrame{
+			ID:   i,
+			Name: frame.Name,
+		}
+
+		parts := strings.Split(frame.Pos, ":")
+		if len(parts) == 3 {
+			sf.Source = &source{Path: parts[0]}
+			sf.Line, _ = strconv.Atoi(parts[1])
+			sf.Column, _ = strconv.Atoi(parts[2])
+		}
+
+		frames = append(frames, sf)
+	}
+
+	return map[string]interface{}{
+		"stackFrames": frames,
+		"totalFrames": len(frames),
+	}
+}
+
+// Each frame has two scopes. The variablesReference encodes the frame and
+// scope because it must not be zero.
+const (
+	scopeVariables = 1
+	scopeRegisters = 2
+)
+
+func (s *DAPServer) scopes(args scopesArguments) interface{} {
+	return map[string]interface{}{
+		"scopes": []scope{
+			{
+				Name:               "Variables",
+				VariablesReference: args.FrameID*2 + scopeVariables,
+			},
+			{
+				Name:               "Registers",
+				VariablesReference: args.FrameID*2 + scopeRegisters,
+				Expensive:          true,
+			},
+		},
+	}
+}
+
+func (s *DAPServer) variables(args variablesArguments) (interface{}, error) {
+	frame := (args.VariablesReference - 1) / 2
+	variables, err := s.controller.Variables(frame)
+	if (args.VariablesReference-1)%2+1 == scopeRegisters {
+		variables, err = s.controller.Registers(frame)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := []variable{}
+	for _, v := range variables {
+		result = append(result, variable{
+			Name:  v.Name,
+			Value: v.Value,
+			Type:  v.Type,
+		})
+	}
+
+	return map[string]interface{}{
+		"variables": result,
+	}, nil
+}
+
+func (s *DAPServer) output(category, output string) {
+	_ = s.event("output", map[string]interface{}{
+		"category": category,
+		"output":   output,
+	})
+}
+
+// outputWriter sends the output of the program to the client.
+type outputWriter struct {
+	server *DAPServer
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.server.output("stdout", string(p))
+
+	return len(p), nil
+}
+
+func (s *DAPServer) read() (*dapRequest, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %v", err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+
+	var req *dapRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (s *DAPServer) event(name string, body interface{}) error {
+	e := dapEvent{
+		Type:  "event",
+		Event: name,
+		Body:  body,
+	}
+
+	return s.send(&e, &e.Seq)
+}
+
+// send assigns the next sequence number and writes the message.
+func (s *DAPServer) send(message interface{}, seq *int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	*seq = s.seq
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+
+	return err
+}
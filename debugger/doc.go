@@ -0,0 +1,8 @@
+// Package debugger pauses and inspects a running VM.
+//
+// The Controller is the core of the debugger. It implements vm.Debugger to
+// stop at breakpoints and steps, and exposes the stack, variables and registers
+// while the program is stopped. The same controller is used by the interactive
+// console ("ok debug") and the Debug Adapter Protocol server ("ok debug -dap")
+// that is used by editors.
+package debugger
@@ -0,0 +1,42 @@
+package debugger
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/util"
+	"github.com/elliotchance/ok/vm"
+)
+
+// Load compiles a package and returns a VM that is ready to run it, along with
+// the function that will run the main function.
+func Load(okPath, arg string, stdout io.Writer) (*vm.VM, func() error, error) {
+	packageName := util.PackageNameFromPath(okPath, arg)
+	if arg == "." {
+		packageName = "."
+	}
+
+	anonFunctionName := 0
+	file, packageType, errs := compiler.Compile(okPath, packageName, false,
+		&anonFunctionName, false)
+	if len(errs) > 0 {
+		var messages []string
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+
+		return nil, nil, errors.New(strings.Join(messages, "\n"))
+	}
+
+	m := vm.NewVM("no-package")
+	m.Stdout = stdout
+	if err := m.LoadFile(file); err != nil {
+		return nil, nil, err
+	}
+
+	return m, func() error {
+		return m.Run("$" + packageType.Name)
+	}, nil
+}
@@ -0,0 +1,325 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/parser"
+)
+
+// Indent is used for each level of indentation.
+const Indent = "    "
+
+// Format returns the source code rewritten in the canonical layout. The
+// fileName is only used for error messages.
+//
+// Line breaks are kept (although consecutive empty lines are reduced to one)
+// while indentation, spacing between tokens, the escaping of literals and the
+// alignment of trailing comments are all rewritten.
+//
+// Source code that cannot be parsed will not be formatted. Instead, the parser
+// errors are returned.
+func Format(source, fileName string) (string, []error) {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	p := parser.NewParser(0)
+	p.ParseString(source, fileName)
+	if errs := p.Errors(); len(errs) > 0 {
+		return "", errs
+	}
+
+	tokens, _, err := lexer.TokenizeString(source,
+		lexer.Options{IncludeComments: true}, fileName)
+	if err != nil {
+		return "", []error{err}
+	}
+
+	formatted := render(tokens)
+
+	// Formatting must only ever change whitespace. If the formatted code
+	// produces different tokens it means there is a bug in the formatter and
+	// we must not replace the original source code.
+	if err := compareTokens(source, formatted, fileName); err != nil {
+		return "", []error{err}
+	}
+
+	return formatted, nil
+}
+
+func compareTokens(source, formatted, fileName string) error {
+	before, _, err := lexer.TokenizeString(source, lexer.Options{}, fileName)
+	if err != nil {
+		return err
+	}
+
+	after, _, err := lexer.TokenizeString(formatted, lexer.Options{}, fileName)
+	if err != nil {
+		return fmt.Errorf("%s: formatting produced invalid code: %v",
+			fileName, err)
+	}
+
+	for i := 0; i < len(before) && i < len(after); i++ {
+		if before[i].Kind != after[i].Kind ||
+			before[i].Value != after[i].Value {
+			return fmt.Errorf("%s formatting changed %s to %s",
+				before[i].Pos.String(), before[i], after[i])
+		}
+	}
+
+	if len(before) != len(after) {
+		return fmt.Errorf("%s: formatting changed the number of tokens",
+			fileName)
+	}
+
+	return nil
+}
+
+// render produces the formatted code from all tokens (including comments) in
+// a file.
+func render(lexerTokens []lexer.Token) string {
+	tokens := newTokens(lexerTokens)
+	spaced := spacesBefore(tokens)
+
+	var lines []*line
+	current := &line{}
+	var brackets []*bracket
+
+	// newLine finishes the current line. Only one of the brackets opened on a
+	// line can increase the indentation of the following lines. Otherwise
+	// something like "foo({" would be indented twice.
+	newLine := func(blankLinesBefore bool) {
+		if current.empty() {
+			current.blankBefore = current.blankBefore || blankLinesBefore
+			return
+		}
+
+		for i := len(brackets) - 1; i >= 0; i-- {
+			if brackets[i].line == current {
+				brackets[i].indents = true
+				break
+			}
+		}
+
+		lines = append(lines, current)
+		current = &line{blankBefore: blankLinesBefore}
+	}
+
+	indentation := func() int {
+		indent := 0
+		for _, b := range brackets {
+			if b.indents {
+				indent++
+			}
+		}
+
+		return indent
+	}
+
+	leadingClosers := true
+	for i, tok := range tokens {
+		if i > 0 && tok.line > tokens[i-1].endLine {
+			newLine(tok.line-tokens[i-1].endLine > 1)
+			leadingClosers = true
+		}
+
+		if tok.kind == lexer.TokenComment {
+			comment := strings.Split(tok.text, "\n")
+
+			// A comment that follows code on the same line is kept on that
+			// line. Any remaining lines of the comment are placed on their own
+			// lines.
+			if !current.empty() && !current.isComment {
+				current.comment = comment[0]
+				comment = comment[1:]
+				newLine(false)
+			}
+
+			for _, c := range comment {
+				current.indent = indentation()
+				current.code = c
+				current.isComment = true
+				newLine(false)
+			}
+
+			continue
+		}
+
+		if isCloser(tok.kind) && len(brackets) > 0 {
+			brackets = brackets[:len(brackets)-1]
+		} else {
+			leadingClosers = false
+		}
+
+		// The indentation of the line is only known once we have seen all of
+		// the closing brackets at the start of the line.
+		if current.empty() || leadingClosers {
+			current.indent = indentation()
+		}
+
+		// Package constants are aligned on their "=", see alignConstants.
+		if tok.kind == lexer.TokenAssign && len(brackets) == 0 &&
+			current.indent == 0 && tokens[i-1].kind == lexer.TokenIdentifier &&
+			current.code == tokens[i-1].text {
+			current.constant = true
+		}
+
+		if !current.empty() && spaced[i] {
+			current.code += " "
+		}
+		current.code += tok.text
+
+		if isOpener(tok.kind) {
+			brackets = append(brackets, &bracket{line: current})
+		}
+	}
+	newLine(false)
+
+	alignConstants(lines)
+	alignComments(lines)
+
+	var sb strings.Builder
+	for i, l := range lines {
+		// Empty lines are not allowed at the start of the file, or at the
+		// start or end of a block.
+		if l.blankBefore && i > 0 && !lines[i-1].opensBlock() &&
+			!l.closesBlock() {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString(l.String())
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+type bracket struct {
+	// line is where the bracket was opened.
+	line *line
+
+	// indents will be true if the lines that follow (until the closing bracket)
+	// are indented.
+	indents bool
+}
+
+type line struct {
+	indent int
+
+	// code contains all of the tokens on the line. For lines that only contain
+	// a comment, code will be the comment and isComment will be true.
+	code      string
+	isComment bool
+
+	// comment is a trailing comment after code.
+	comment string
+
+	// padding is the number of spaces placed before the trailing comment so
+	// that it aligns with the surrounding lines.
+	padding int
+
+	// constant is true for the definition of a package constant, like
+	// "Pi = 3.14".
+	constant bool
+
+	// blankBefore is true when there was at least one empty line before this
+	// line in the original source.
+	blankBefore bool
+}
+
+func (l *line) empty() bool {
+	return l.code == ""
+}
+
+func (l *line) opensBlock() bool {
+	return !l.isComment && l.comment == "" &&
+		isOpener(l.code[len(l.code)-1:])
+}
+
+func (l *line) closesBlock() bool {
+	return !l.isComment && isCloser(l.code[:1])
+}
+
+// name is the name of the constant. It is only valid when constant is true.
+func (l *line) name() string {
+	return l.code[:strings.Index(l.code, " ")]
+}
+
+func (l *line) String() string {
+	s := strings.Repeat(Indent, l.indent) + l.code
+	if l.comment != "" {
+		s += strings.Repeat(" ", l.padding+1) + l.comment
+	}
+
+	return s
+}
+
+// alignConstants aligns the "=" of consecutive package constants.
+func alignConstants(lines []*line) {
+	for start := 0; start < len(lines); {
+		if !lines[start].constant {
+			start++
+			continue
+		}
+
+		end, width := start, 0
+		for ; end < len(lines); end++ {
+			l := lines[end]
+			if !l.constant || (end > start && l.blankBefore) {
+				break
+			}
+
+			if w := len([]rune(l.name())); w > width {
+				width = w
+			}
+		}
+
+		for _, l := range lines[start:end] {
+			name := l.name()
+			l.code = name + strings.Repeat(" ", width-len([]rune(name))) +
+				l.code[len(name):]
+		}
+
+		start = end
+	}
+}
+
+// alignComments aligns trailing comments for consecutive lines at the same
+// indentation.
+func alignComments(lines []*line) {
+	for start := 0; start < len(lines); {
+		if lines[start].comment == "" {
+			start++
+			continue
+		}
+
+		end, width := start, 0
+		for ; end < len(lines); end++ {
+			l := lines[end]
+			if l.comment == "" || l.indent != lines[start].indent ||
+				(end > start && l.blankBefore) {
+				break
+			}
+
+			if w := len([]rune(l.code)); w > width {
+				width = w
+			}
+		}
+
+		for _, l := range lines[start:end] {
+			l.padding = width - len([]rune(l.code))
+		}
+
+		start = end
+	}
+}
+
+func isOpener(kind string) bool {
+	return kind == lexer.TokenParenOpen || kind == lexer.TokenSquareOpen ||
+		kind == lexer.TokenCurlyOpen
+}
+
+func isCloser(kind string) bool {
+	return kind == lexer.TokenParenClose || kind == lexer.TokenSquareClose ||
+		kind == lexer.TokenCurlyClose
+}
@@ -0,0 +1,118 @@
+package formatter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/ok/formatter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	for testName, test := range map[string]struct {
+		str      string
+		expected string
+		errs     []error
+	}{
+		"empty": {
+			str:      "",
+			expected: "",
+		},
+		"indentation": {
+			str:      "func main() {\nif true {\n  print(1)\n\t}\n}",
+			expected: "func main() {\n    if true {\n        print(1)\n    }\n}\n",
+		},
+		"spacing": {
+			str:      "func main() {\n    a=[ 1,2 ]\n    b = a [0]+- 1\n    c = {  \"a\" : 1 }\n}\n",
+			expected: "func main() {\n    a = [1, 2]\n    b = a[0] + -1\n    c = {\"a\": 1}\n}\n",
+		},
+		"increment": {
+			str:      "func main() {\n    ++ a\n    b = -- a\n}\n",
+			expected: "func main() {\n    ++a\n    b = --a\n}\n",
+		},
+		"types": {
+			str:      "func foo(a [] number, b {} [] string)[]number {\n    return [] number [] \n}\n",
+			expected: "func foo(a []number, b {}[]string) []number {\n    return []number []\n}\n",
+		},
+		"multiple-returns": {
+			str:      "func foo()(number,bool) {}\n",
+			expected: "func foo() (number, bool) {}\n",
+		},
+		"single-line-block": {
+			str:      "func main() {\n    fn = func() {return 1}\n}\n",
+			expected: "func main() {\n    fn = func() { return 1 }\n}\n",
+		},
+		"interpolation": {
+			str:      "func main() {\n    print(\"{ a+1 } \\{ {b}\")\n}\n",
+			expected: "func main() {\n    print(\"{a + 1} \\{ {b}\")\n}\n",
+		},
+		"escapes": {
+			str:      "func main() {\n    print(\"a\\tb\\\"\", '\\\\', `c\\n`)\n}\n",
+			expected: "func main() {\n    print(\"a\\tb\\\"\", '\\\\', `c\\n`)\n}\n",
+		},
+		"empty-lines": {
+			str:      "\n\nfunc a() {\n\n    a = 1\n\n\n    b = 2\n\n}\n\n\n\nfunc b() {}\n\n",
+			expected: "func a() {\n    a = 1\n\n    b = 2\n}\n\nfunc b() {}\n",
+		},
+		"comments": {
+			str:      "// Foo does\n//   things.\nfunc Foo() {\n        // inside\n    a = 1 // trailing  \n}\n",
+			expected: "// Foo does\n//   things.\nfunc Foo() {\n    // inside\n    a = 1 // trailing\n}\n",
+		},
+		"aligned-comments": {
+			str:      "func FileInfo(\n    Name string, // name\n  Size number, // size\n    IsDir bool   // dir\n) FileInfo {}\n",
+			expected: "func FileInfo(\n    Name string, // name\n    Size number, // size\n    IsDir bool   // dir\n) FileInfo {}\n",
+		},
+		"aligned-constants": {
+			str:      "A = 1\nFoo = 2 // foo\n\nBarBaz = 3\n",
+			expected: "A   = 1\nFoo = 2 // foo\n\nBarBaz = 3\n",
+		},
+		"nested-brackets-indent-once": {
+			str:      "func main() {\n    foo({\n        \"a\": 1\n    })\n}\n",
+			expected: "func main() {\n    foo({\n        \"a\": 1\n    })\n}\n",
+		},
+		"test": {
+			str:      "test \"foo\"{\nassert(1==1)\n}\n",
+			expected: "test \"foo\" {\n    assert(1 == 1)\n}\n",
+		},
+		"fuzz": {
+			str:      "fuzz \"foo\" (a [] number,b string){\nassert(b==\"\")\n}\n",
+			expected: "fuzz \"foo\"(a []number, b string) {\n    assert(b == \"\")\n}\n",
+		},
+		"generics": {
+			str:      "func Map [T,U] (items [] T,s Stack [U]) [] U {\nreturn Map [number] (Stack [T] ())\n}\n",
+			expected: "func Map[T, U](items []T, s Stack[U]) []U {\n    return Map[number](Stack[T]())\n}\n",
+		},
+		"enums": {
+			str:      "enum Small {A,B}\nenum Level{\nDebug\n  Info\n}\n",
+			expected: "enum Small { A, B }\nenum Level {\n    Debug\n    Info\n}\n",
+		},
+		"channels": {
+			str:      "func f(c chan  number) {\nspawn g(c)\nselect {\ncase v=c.Receive() {\n}\nelse {}\n}\n}\n",
+			expected: "func f(c chan number) {\n    spawn g(c)\n    select {\n        case v = c.Receive() {\n        }\n        else {}\n    }\n}\n",
+		},
+		"optionals": {
+			str:      "func f(a ? string,b []? number) ?string {\nx = ?string  nil\nif a is not nil { return a??\"x\" }\nreturn nil\n}\n",
+			expected: "func f(a ?string, b []?number) ?string {\n    x = ?string nil\n    if a is not nil { return a ?? \"x\" }\n    return nil\n}\n",
+		},
+		"parse-error": {
+			str: "func main() {",
+			errs: []error{
+				errors.New("a.ok:1:14 expecting statement"),
+				errors.New("a.ok:1:1 expecting statement"),
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			actual, errs := formatter.Format(test.str, "a.ok")
+			assert.Equal(t, test.errs, errs)
+			assert.Equal(t, test.expected, actual)
+
+			// Formatting must be stable.
+			if len(errs) == 0 {
+				again, errs := formatter.Format(actual, "a.ok")
+				assert.Nil(t, errs)
+				assert.Equal(t, actual, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/elliotchance/ok/lexer"
+)
+
+// token is a lexer token that has been rendered back into source code.
+type token struct {
+	kind string
+	text string
+
+	// line and endLine are the lines the token starts and finishes on. They
+	// will only be different for comments that span multiple lines.
+	line, endLine int
+}
+
+// newTokens converts the lexer tokens. An interpolated string is a single
+// token.
+func newTokens(lexerTokens []lexer.Token) []token {
+	var tokens []token
+	for i := 0; i < len(lexerTokens); i++ {
+		t := lexerTokens[i]
+		tok := token{
+			kind:    t.Kind,
+			text:    t.Value,
+			line:    t.Pos.LineNumber,
+			endLine: t.Pos.LineNumber,
+		}
+
+		switch t.Kind {
+		case lexer.TokenEOF:
+			continue
+
+		case lexer.TokenComment:
+			lines := strings.Split(t.Value, "\n")
+			for j := range lines {
+				lines[j] = strings.TrimRight("//"+lines[j], " \t")
+			}
+			tok.text = strings.Join(lines, "\n")
+			tok.endLine += len(lines) - 1
+
+		case lexer.TokenStringLiteral:
+			tok.text = quote(t.Value, '"')
+
+		case lexer.TokenCharLiteral:
+			tok.text = quote(t.Value, '\'')
+
+		case lexer.TokenDataLiteral:
+			tok.text = quote(t.Value, '`')
+
+		case lexer.TokenInterpolateStart:
+			tok.kind = lexer.TokenStringLiteral
+			tok.text, i = interpolation(lexerTokens, i)
+		}
+
+		tokens = append(tokens, tok)
+	}
+
+	return tokens
+}
+
+// interpolation renders an interpolated string starting at the
+// TokenInterpolateStart. It returns the offset of the TokenInterpolateEnd.
+func interpolation(lexerTokens []lexer.Token, offset int) (string, int) {
+	s := `"`
+	for offset++; lexerTokens[offset].Kind != lexer.TokenInterpolateEnd; offset++ {
+		if lexerTokens[offset].Kind == lexer.TokenStringLiteral {
+			s += escape(lexerTokens[offset].Value, '"', true)
+			continue
+		}
+
+		// Otherwise this is the opening parenthesis of an expression. The
+		// expression may also contain parenthesis.
+		start, depth := offset+1, 1
+		for depth > 0 {
+			offset++
+			switch lexerTokens[offset].Kind {
+			case lexer.TokenParenOpen:
+				depth++
+			case lexer.TokenParenClose:
+				depth--
+			}
+		}
+
+		s += "{" + renderLine(newTokens(lexerTokens[start:offset])) + "}"
+	}
+
+	return s + `"`, offset
+}
+
+var escapes = map[rune]string{
+	'\a': `\a`,
+	'\b': `\b`,
+	'\f': `\f`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+	'\v': `\v`,
+	'\\': `\\`,
+}
+
+// quote is the opposite of what the lexer does when reading a literal.
+func quote(s string, quoteChar rune) string {
+	return string(quoteChar) + escape(s, quoteChar, false) +
+		string(quoteChar)
+}
+
+// escape is used for the inside of a literal. Only the string parts of an
+// interpolated string need to escape "{".
+func escape(s string, quoteChar rune, interpolated bool) string {
+	var sb strings.Builder
+	for _, c := range s {
+		switch {
+		case escapes[c] != "":
+			sb.WriteString(escapes[c])
+
+		case c == '"' && quoteChar == '"',
+			c == '{' && interpolated:
+			sb.WriteRune('\\')
+			sb.WriteRune(c)
+
+		default:
+			sb.WriteRune(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// renderLine renders tokens that must all be placed on a single line.
+func renderLine(tokens []token) string {
+	spaced := spacesBefore(tokens)
+
+	s := ""
+	for i, tok := range tokens {
+		if i > 0 && spaced[i] {
+			s += " "
+		}
+		s += tok.text
+	}
+
+	return s
+}
+
+// opener is a bracket that has not been closed yet.
+type opener struct {
+	offset int
+
+	// padded is used for curly brackets that are on a single line. A block
+	// like "{ return 1 }" is padded, but a map like {"a": 1} is not.
+	padded bool
+
+	// parameters is true for parenthesis around the parameters of a function,
+	// like "func foo(a number) (number, bool)".
+	parameters bool
+
+	// typeParameters is true for the square brackets around the type
+	// parameters of a generic function, like "func Map[T, U]".
+	typeParameters bool
+}
+
+// spacesBefore returns whether each token should be separated from the
+// previous (non-comment) token by a single space if they are on the same line.
+func spacesBefore(tokens []token) []bool {
+	spaces := make([]bool, len(tokens))
+
+	var openers []opener
+
+	// These are indexed by token offset.
+	closesEmpty := make([]bool, len(tokens))
+	closesParameters := make([]bool, len(tokens))
+	closesTypeParameters := make([]bool, len(tokens))
+	isTypeName := make([]bool, len(tokens))
+	isPrefix := make([]bool, len(tokens))
+
+	prev := -1
+	for i, tok := range tokens {
+		if tok.kind == lexer.TokenComment {
+			spaces[i] = true
+			continue
+		}
+
+		// A unary operator has nothing (or no value) before it on the same
+		// line.
+		switch tok.kind {
+		case lexer.TokenMinus, lexer.TokenIncrement, lexer.TokenDecrement:
+			isPrefix[i] = prev < 0 || tokens[prev].endLine < tok.line ||
+				!isOperand(tokens[prev].kind)
+
+		case lexer.TokenQuestion:
+			// Always the start of an optional type, like "?number".
+			isPrefix[i] = true
+		}
+
+		if prev >= 0 {
+			spaces[i] = spaceBetween(tokens, prev, i, openers, closesEmpty,
+				closesParameters, isTypeName, isPrefix)
+		}
+
+		switch {
+		case isOpener(tok.kind):
+			openers = append(openers, opener{
+				offset: i,
+				padded: tok.kind == lexer.TokenCurlyOpen &&
+					isPaddedBlock(tokens, i),
+				parameters: tok.kind == lexer.TokenParenOpen && prev >= 0 &&
+					(tokens[prev].kind == lexer.TokenFunc ||
+						(prev > 0 && tokens[prev-1].kind == lexer.TokenFunc) ||
+						closesTypeParameters[prev] ||
+						isFuzzName(tokens, prev)),
+				typeParameters: tok.kind == lexer.TokenSquareOpen && prev > 0 &&
+					tokens[prev].kind == lexer.TokenIdentifier &&
+					tokens[prev-1].kind == lexer.TokenFunc,
+			})
+
+		case isCloser(tok.kind) && len(openers) > 0:
+			o := openers[len(openers)-1]
+			openers = openers[:len(openers)-1]
+			closesEmpty[i] = o.offset == prev &&
+				tok.kind != lexer.TokenParenClose
+			closesParameters[i] = o.parameters
+			closesTypeParameters[i] = o.typeParameters
+
+		case prev >= 0 && isTypeWord(tok.kind) &&
+			(closesEmpty[prev] || tokens[prev].kind == lexer.TokenQuestion ||
+				(tokens[prev].kind == lexer.TokenDot && prev > 0 &&
+					isTypeName[prev-1])):
+			isTypeName[i] = true
+		}
+
+		prev = i
+	}
+
+	return spaces
+}
+
+func spaceBetween(
+	tokens []token,
+	prev, i int,
+	openers []opener,
+	closesEmpty, closesParameters, isTypeName, isPrefix []bool,
+) bool {
+	a, b := tokens[prev], tokens[i]
+
+	switch b.kind {
+	case lexer.TokenParenClose, lexer.TokenSquareClose, lexer.TokenComma,
+		lexer.TokenSemiColon, lexer.TokenColon, lexer.TokenDot:
+		return false
+
+	case lexer.TokenCurlyClose:
+		return len(openers) > 0 && openers[len(openers)-1].offset != prev &&
+			openers[len(openers)-1].padded
+	}
+
+	switch a.kind {
+	case lexer.TokenParenOpen, lexer.TokenSquareOpen, lexer.TokenDot:
+		return false
+
+	case lexer.TokenCurlyOpen:
+		return len(openers) > 0 && openers[len(openers)-1].padded
+	}
+
+	if isPrefix[prev] {
+		return false
+	}
+
+	switch b.kind {
+	case lexer.TokenIncrement, lexer.TokenDecrement:
+		return isPrefix[i]
+
+	case lexer.TokenParenOpen:
+		if closesParameters[prev] {
+			return true
+		}
+
+		switch a.kind {
+		case lexer.TokenIdentifier, lexer.TokenParenClose,
+			lexer.TokenSquareClose, lexer.TokenFunc, lexer.TokenAssert,
+			lexer.TokenAny, lexer.TokenBool, lexer.TokenChar, lexer.TokenData,
+			lexer.TokenNumber, lexer.TokenString:
+			return false
+		}
+
+		// The arguments of a fuzz target, like `fuzz "reverse"(s string)`.
+		return !isFuzzName(tokens, prev)
+
+	case lexer.TokenSquareOpen:
+		if closesEmpty[prev] {
+			return false
+		}
+
+		switch a.kind {
+		case lexer.TokenIdentifier:
+			// The type of a function parameter, like "(a []number)". This
+			// is not the type arguments of a parameter type, like
+			// "(s Stack[number])".
+			inParameters := len(openers) > 0 &&
+				openers[len(openers)-1].parameters &&
+				i+1 < len(tokens) && tokens[i+1].kind == lexer.TokenSquareClose
+
+			return isTypeName[prev] || inParameters
+
+		case lexer.TokenParenClose:
+			// The return type of a function, like "func() []number".
+			return closesParameters[prev]
+
+		case lexer.TokenSquareClose,
+			lexer.TokenStringLiteral:
+			return false
+		}
+
+		return true
+
+	case lexer.TokenCurlyOpen:
+		return !closesEmpty[prev]
+	}
+
+	// Types such as "[]number", "{}string" or "[]?number".
+	if closesEmpty[prev] && (isTypeWord(b.kind) || b.kind == lexer.TokenFunc ||
+		b.kind == lexer.TokenQuestion) {
+		return false
+	}
+
+	return true
+}
+
+// isFuzzName returns true if the token at offset is the name of a fuzz target.
+func isFuzzName(tokens []token, offset int) bool {
+	return tokens[offset].kind == lexer.TokenStringLiteral && offset > 0 &&
+		tokens[offset-1].kind == lexer.TokenFuzz
+}
+
+// isPaddedBlock returns true if the curly bracket at offset opens a non-empty
+// block that is closed on the same line. Maps are not padded. They are detected
+// by a colon that is not inside any other brackets.
+func isPaddedBlock(tokens []token, offset int) bool {
+	depth := 0
+	for i := offset + 1; i < len(tokens); i++ {
+		if tokens[i].line != tokens[offset].line {
+			return false
+		}
+
+		switch {
+		case isOpener(tokens[i].kind):
+			depth++
+
+		case isCloser(tokens[i].kind):
+			if depth == 0 {
+				return i > offset+1
+			}
+			depth--
+
+		case tokens[i].kind == lexer.TokenColon && depth == 0:
+			return false
+		}
+	}
+
+	return false
+}
+
+// isOperand returns true if the token can be the end of a value. This is used
+// to determine if an operator that follows is binary or unary.
+func isOperand(kind string) bool {
+	switch kind {
+	case lexer.TokenIdentifier, lexer.TokenBoolLiteral, lexer.TokenCharLiteral,
+		lexer.TokenDataLiteral, lexer.TokenNumberLiteral,
+		lexer.TokenStringLiteral, lexer.TokenParenClose, lexer.TokenSquareClose,
+		lexer.TokenCurlyClose:
+		return true
+	}
+
+	return false
+}
+
+// isTypeWord returns true for tokens that can be the name of a type.
+func isTypeWord(kind string) bool {
+	switch kind {
+	case lexer.TokenIdentifier, lexer.TokenAny, lexer.TokenBool,
+		lexer.TokenChar, lexer.TokenData, lexer.TokenNumber, lexer.TokenString:
+		return true
+	}
+
+	return false
+}
@@ -243,23 +243,50 @@ func init() {
 		"\n" +
 		"    // ReadLine will read a string until a new line or carriage return is hit.\n" +
 		"    func ReadLine() string {\n" +
-		"        line = \"\"\n" +
+		"        return readLine(^fd)\n" +
+		"    }\n" +
 		"\n" +
-		"        for {\n" +
-		"            chars = __read_string(^fd, 1)\n" +
-		"            if len(chars) == 0 {\n" +
-		"                break\n" +
-		"            }\n" +
+		"    // Lines iterates over each line of the file, starting from the current\n" +
+		"    // position. Each line includes the new line character, except for the\n" +
+		"    // last line of the file:\n" +
+		"    //\n" +
+		"    //   for line in f.Lines() {\n" +
+		"    //       print(line)\n" +
+		"    //   }\n" +
+		"    //\n" +
+		"    func Lines() LineIterator {\n" +
+		"        return LineIterator(^fd)\n" +
+		"    }\n" +
+		"}\n" +
 		"\n" +
-		"            line += chars\n" +
+		"// LineIterator is an iterator over the lines of a file. It is created with\n" +
+		"// File.Lines.\n" +
+		"func LineIterator(fd data) LineIterator {\n" +
+		"    // Next returns the next line, or false once there are no more lines.\n" +
+		"    func Next() (string, bool) {\n" +
+		"        line = readLine(^fd)\n" +
 		"\n" +
-		"            if chars == \"\\n\" {\n" +
-		"                break\n" +
-		"            }\n" +
+		"        return line, line != \"\"\n" +
+		"    }\n" +
+		"}\n" +
+		"\n" +
+		"func readLine(fd data) string {\n" +
+		"    line = \"\"\n" +
+		"\n" +
+		"    for {\n" +
+		"        chars = __read_string(fd, 1)\n" +
+		"        if len(chars) == 0 {\n" +
+		"            break\n" +
 		"        }\n" +
 		"\n" +
-		"        return line\n" +
+		"        line += chars\n" +
+		"\n" +
+		"        if chars == \"\\n\" {\n" +
+		"            break\n" +
+		"        }\n" +
 		"    }\n" +
+		"\n" +
+		"    return line\n" +
 		"}\n" +
 		"\n" +
 		"// Open opens a file for reading or writing. If the file does not exist it will\n" +
@@ -876,6 +903,37 @@ func init() {
 		""))
 	Filesystem.Mount(fs, "/strings")
 	fs = memfs.Create()
+	f, _ = fs.OpenFile("snapshot.ok", os.O_RDWR|os.O_CREATE, 0777)
+	f.Write([]byte("// Snapshot asserts that value is the same as the snapshot called name. This is\n" +
+		"// useful for large values, such as reports or rendered templates, that would be\n" +
+		"// difficult to write out in the test.\n" +
+		"//\n" +
+		"// Snapshots are stored in the \"testdata\" directory next to the test, with the\n" +
+		"// name followed by \".snapshot\". Running \"ok test -update\" will write each\n" +
+		"// snapshot with the current value instead of comparing it.\n" +
+		"//\n" +
+		"// Snapshot can only be used in tests.\n" +
+		"func Snapshot(name, value string) {\n" +
+		"    __snapshot(name, value)\n" +
+		"}\n" +
+		""))
+	f, _ = fs.OpenFile("timeout.ok", os.O_RDWR|os.O_CREATE, 0777)
+	f.Write([]byte("import \"time\"\n" +
+		"\n" +
+		"// Timeout replaces the timeout of the current test, which is set for all tests\n" +
+		"// with \"ok test -timeout\". The timeout is measured from the start of the test,\n" +
+		"// including its setup. A duration of zero removes the timeout.\n" +
+		"//\n" +
+		"// A test that runs for longer than its timeout is abandoned and reported as\n" +
+		"// timed out, without running its teardown.\n" +
+		"//\n" +
+		"// Timeout can only be used in tests.\n" +
+		"func Timeout(duration time.Duration) {\n" +
+		"    __timeout(duration.Seconds())\n" +
+		"}\n" +
+		""))
+	Filesystem.Mount(fs, "/testing")
+	fs = memfs.Create()
 	f, _ = fs.OpenFile("add.ok", os.O_RDWR|os.O_CREATE, 0777)
 	f.Write([]byte("// Add returns a new time after applying a duration. You may use a negative\n" +
 		"// duration to subtract.\n" +
@@ -18,31 +18,44 @@ const (
 	TokenStringLiteral = "string literal" // string literal, eg. "hello"
 
 	// Keywords
+	//
+	// TokenBench, TokenFuzz, TokenSetup and TokenTeardown are only keywords
+	// when they start a block at the top level. Anywhere else they are
+	// identifiers.
 	TokenAnd      = "and"
 	TokenAny      = "any"
 	TokenAssert   = "assert"
+	TokenBench    = "bench"
 	TokenBool     = "bool"
 	TokenBreak    = "break"
 	TokenCase     = "case"
+	TokenChan     = "chan"
 	TokenChar     = "char"
 	TokenContinue = "continue"
 	TokenData     = "data"
 	TokenElse     = "else"
+	TokenEnum     = "enum"
 	TokenFinally  = "finally"
 	TokenFor      = "for"
 	TokenFunc     = "func"
+	TokenFuzz     = "fuzz"
 	TokenIf       = "if"
 	TokenImport   = "import"
 	TokenIn       = "in"
 	TokenIs       = "is"
+	TokenNil      = "nil"
 	TokenNot      = "not"
 	TokenNumber   = "number"
 	TokenOn       = "on"
 	TokenOr       = "or"
 	TokenRaise    = "raise"
 	TokenReturn   = "return"
+	TokenSelect   = "select"
+	TokenSetup    = "setup"
+	TokenSpawn    = "spawn"
 	TokenString   = "string"
 	TokenSwitch   = "switch"
+	TokenTeardown = "teardown"
 	TokenTest     = "test"
 	TokenTry      = "try"
 
@@ -64,11 +77,13 @@ const (
 	TokenLessThanEqual    = "<="
 	TokenMinus            = "-"
 	TokenMinusAssign      = "-="
+	TokenNilCoalesce      = "??"
 	TokenNotEqual         = "!="
 	TokenParenClose       = ")"
 	TokenParenOpen        = "("
 	TokenPlus             = "+"
 	TokenPlusAssign       = "+="
+	TokenQuestion         = "?"
 	TokenRemainder        = "%"
 	TokenRemainderAssign  = "%="
 	TokenSemiColon        = ";"
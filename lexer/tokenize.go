@@ -22,6 +22,11 @@ func TokenizeString(str string, options Options, fileName string) ([]Token, []*a
 	endOfLineForNextToken := 0
 
 	var lastComment *ast.Comment
+
+	// tokensAtLastComment is the number of tokens when lastComment was last
+	// extended. A comment can only continue the previous comment if no other
+	// tokens have been found in between.
+	tokensAtLastComment := 0
 	pos := Pos{
 		FileName:        fileName,
 		LineNumber:      1,
@@ -53,6 +58,13 @@ func TokenizeString(str string, options Options, fileName string) ([]Token, []*a
 
 		case '/':
 			if i+1 < runesLen && runes[i+1] == '/' {
+				// A word directly before the comment must be finished first,
+				// otherwise it would be placed after the comment.
+				if word != "" {
+					tokens = appendToken(tokens, tokenWord(word, pos), &endOfLineForNextToken, &pos)
+					word = ""
+				}
+
 				token.Kind = TokenComment
 				i += 2
 				hasNewLine := false
@@ -73,9 +85,10 @@ func TokenizeString(str string, options Options, fileName string) ([]Token, []*a
 				}
 
 				// If the previous token was a comment we append to it. However,
-				// if there is a new line just before this comment then it
-				// cannot be joined to the previous one.
-				if lastComment != nil {
+				// if there is a new line just before this comment, or there is
+				// code in between, then it cannot be joined to the previous
+				// one.
+				if lastComment != nil && len(tokens) == tokensAtLastComment {
 					lastComment.Comment += "\n" + token.Value
 
 					// If we are including comments as tokens we will need to
@@ -95,6 +108,7 @@ func TokenizeString(str string, options Options, fileName string) ([]Token, []*a
 					}
 					lastComment = comments[len(comments)-1]
 				}
+				tokensAtLastComment = len(tokens)
 
 				if hasNewLine {
 					pos.nextLine()
@@ -174,6 +188,17 @@ func TokenizeString(str string, options Options, fileName string) ([]Token, []*a
 			found = true
 			token.Kind = token.Value
 
+		case '?':
+			token.Value = string(c)
+			token.Pos = pos
+			if i < runesLen-1 && runes[i+1] == c {
+				token.Value += string(runes[i+1])
+				i++
+			}
+
+			found = true
+			token.Kind = token.Value
+
 		case '(', ')', '[', ']', '{', '}',
 			'*', '%', '=', '!', '>', '<', ',', ';', ':', '.':
 			token.Value = string(c)
@@ -229,10 +254,53 @@ func TokenizeString(str string, options Options, fileName string) ([]Token, []*a
 		pos.CharacterNumber = 1
 	}
 	tokens = append(tokens, NewToken(TokenEOF, "", pos))
+	contextualKeywords(tokens)
 
 	return tokens, comments, nil
 }
 
+// contextualKeywords turns the test-only keywords back into identifiers unless
+// they start a block at the top level of the file. This allows words like
+// "setup" to still be used as variable, function and field names.
+func contextualKeywords(tokens []Token) {
+	depth := 0
+	for i, token := range tokens {
+		switch token.Kind {
+		case TokenCurlyOpen, TokenParenOpen, TokenSquareOpen,
+			TokenInterpolateStart:
+			depth++
+
+		case TokenCurlyClose, TokenParenClose, TokenSquareClose,
+			TokenInterpolateEnd:
+			depth--
+
+		case TokenBench, TokenFuzz, TokenSetup, TokenTeardown:
+			// Bench and fuzz are followed by their name. Setup and teardown
+			// are followed by their body.
+			next := TokenStringLiteral
+			if token.Kind == TokenSetup || token.Kind == TokenTeardown {
+				next = TokenCurlyOpen
+			}
+
+			if depth != 0 || nextKind(tokens, i) != next {
+				tokens[i].Kind = TokenIdentifier
+			}
+		}
+	}
+}
+
+// nextKind returns the kind of the first token after offset that is not a
+// comment.
+func nextKind(tokens []Token, offset int) string {
+	for _, token := range tokens[offset+1:] {
+		if token.Kind != TokenComment {
+			return token.Kind
+		}
+	}
+
+	return TokenEOF
+}
+
 func interpolate(s string, pos Pos, fileName string) ([]Token, error) {
 	pos.CharacterNumber++
 	tokens := []Token{
@@ -386,15 +454,19 @@ func tokenWord(word string, pos Pos) Token {
 
 		// Control flow
 		"break", "case", "continue", "else", "if", "for", "switch", "in", "is",
+		"select",
 
 		// Testing
-		"test", "assert",
+		"test", "assert", "bench", "fuzz", "setup", "teardown",
 
 		// Types
-		"any", "bool", "char", "data", "number", "string",
+		"any", "bool", "char", "data", "number", "string", "chan",
 
 		// Statements
-		"func", "return", "import",
+		"func", "return", "import", "enum", "spawn",
+
+		// Values
+		"nil",
 
 		// Errors
 		"try", "raise", "on", "finally":
@@ -440,7 +512,8 @@ func appendToken(tokens []Token, token Token, endOfLine *int, pos *Pos) []Token
 	// ahead). However, we cannot move the position forward at that time because
 	// it would affect any non-appended token. So we have to adjust it here.
 	if len(token.Value) > 1 &&
-		(token.Value[1] == '=' || token.Value[1] == '+' || token.Value[1] == '-') {
+		(token.Value[1] == '=' || token.Value[1] == '+' || token.Value[1] == '-' ||
+			token.Value[1] == '?') {
 		pos.CharacterNumber++
 	}
 
@@ -354,6 +354,30 @@ func TestTokenizeString(t *testing.T) {
 				IncludeComments: false,
 			},
 		},
+		"code-between-comments": {
+			str: "// hello\na // world",
+			expected: []lexer.Token{
+				{lexer.TokenComment, " hello", false, pos(1)},
+				{lexer.TokenIdentifier, "a", false, pos2(2, 1)},
+				{lexer.TokenComment, " world", false, pos2(2, 3)},
+				{lexer.TokenEOF, "", false, pos2(2, 11)},
+			},
+			comments: []*ast.Comment{
+				{Comment: " hello", Pos: "a.ok:1:1"},
+				{Comment: " world", Pos: "a.ok:2:3"},
+			},
+		},
+		"comment-directly-after-word": {
+			str: "a// world",
+			expected: []lexer.Token{
+				{lexer.TokenIdentifier, "a", false, pos(1)},
+				{lexer.TokenComment, " world", false, pos(2)},
+				{lexer.TokenEOF, "", false, pos(10)},
+			},
+			comments: []*ast.Comment{
+				{Comment: " world", Pos: "a.ok:1:2"},
+			},
+		},
 		"operator-between-comments-2": {
 			str: "// hello\n}// world",
 			expected: []lexer.Token{
@@ -948,6 +972,104 @@ func TestTokenizeString(t *testing.T) {
 				{lexer.TokenEOF, "", false, pos(5)},
 			},
 		},
+		"bench": {
+			str: `bench "a"`,
+			expected: []lexer.Token{
+				{lexer.TokenBench, "bench", false, pos(1)},
+				{lexer.TokenStringLiteral, "a", false, pos(7)},
+				{lexer.TokenEOF, "", false, pos(10)},
+			},
+		},
+		"fuzz": {
+			str: `fuzz "a"`,
+			expected: []lexer.Token{
+				{lexer.TokenFuzz, "fuzz", false, pos(1)},
+				{lexer.TokenStringLiteral, "a", false, pos(6)},
+				{lexer.TokenEOF, "", false, pos(9)},
+			},
+		},
+		"setup": {
+			str: `setup {}`,
+			expected: []lexer.Token{
+				{lexer.TokenSetup, "setup", false, pos(1)},
+				{lexer.TokenCurlyOpen, "{", false, pos(7)},
+				{lexer.TokenCurlyClose, "}", false, pos(8)},
+				{lexer.TokenEOF, "", false, pos(9)},
+			},
+		},
+		"teardown": {
+			str: `teardown {}`,
+			expected: []lexer.Token{
+				{lexer.TokenTeardown, "teardown", false, pos(1)},
+				{lexer.TokenCurlyOpen, "{", false, pos(10)},
+				{lexer.TokenCurlyClose, "}", false, pos(11)},
+				{lexer.TokenEOF, "", false, pos(12)},
+			},
+		},
+		"setup-comment": {
+			str: "setup // a\n{}",
+			expected: []lexer.Token{
+				{lexer.TokenSetup, "setup", false, pos(1)},
+				{lexer.TokenComment, " a", false, pos(7)},
+				{lexer.TokenCurlyOpen, "{", false, pos2(2, 1)},
+				{lexer.TokenCurlyClose, "}", false, pos2(2, 2)},
+				{lexer.TokenEOF, "", false, pos2(2, 3)},
+			},
+			comments: []*ast.Comment{
+				{Comment: " a", Pos: "a.ok:1:7"},
+			},
+		},
+		"bench-identifier": {
+			str: `bench`,
+			expected: []lexer.Token{
+				{lexer.TokenIdentifier, "bench", false, pos(1)},
+				{lexer.TokenEOF, "", false, pos(6)},
+			},
+		},
+		"fuzz-identifier": {
+			str: `fuzz = 1`,
+			expected: []lexer.Token{
+				{lexer.TokenIdentifier, "fuzz", false, pos(1)},
+				{lexer.TokenAssign, "=", false, pos(6)},
+				{lexer.TokenNumberLiteral, "1", false, pos(8)},
+				{lexer.TokenEOF, "", false, pos(9)},
+			},
+		},
+		"setup-identifier": {
+			str: `func setup() {}`,
+			expected: []lexer.Token{
+				{lexer.TokenFunc, "func", false, pos(1)},
+				{lexer.TokenIdentifier, "setup", false, pos(6)},
+				{lexer.TokenParenOpen, "(", false, pos(11)},
+				{lexer.TokenParenClose, ")", false, pos(12)},
+				{lexer.TokenCurlyOpen, "{", false, pos(14)},
+				{lexer.TokenCurlyClose, "}", false, pos(15)},
+				{lexer.TokenEOF, "", false, pos(16)},
+			},
+		},
+		"teardown-identifier": {
+			str: `{ if teardown {} }`,
+			expected: []lexer.Token{
+				{lexer.TokenCurlyOpen, "{", false, pos(1)},
+				{lexer.TokenIf, "if", false, pos(3)},
+				{lexer.TokenIdentifier, "teardown", false, pos(6)},
+				{lexer.TokenCurlyOpen, "{", false, pos(15)},
+				{lexer.TokenCurlyClose, "}", false, pos(16)},
+				{lexer.TokenCurlyClose, "}", false, pos(18)},
+				{lexer.TokenEOF, "", false, pos(19)},
+			},
+		},
+		"bench-identifier-call": {
+			str: `print(bench "a")`,
+			expected: []lexer.Token{
+				{lexer.TokenIdentifier, "print", false, pos(1)},
+				{lexer.TokenParenOpen, "(", false, pos(6)},
+				{lexer.TokenIdentifier, "bench", false, pos(7)},
+				{lexer.TokenStringLiteral, "a", false, pos(13)},
+				{lexer.TokenParenClose, ")", false, pos(16)},
+				{lexer.TokenEOF, "", false, pos(17)},
+			},
+		},
 		"assert": {
 			str: `assert`,
 			expected: []lexer.Token{
@@ -962,6 +1084,58 @@ func TestTokenizeString(t *testing.T) {
 				{lexer.TokenEOF, "", false, pos(7)},
 			},
 		},
+		"enum": {
+			str: `enum`,
+			expected: []lexer.Token{
+				{lexer.TokenEnum, "enum", false, pos(1)},
+				{lexer.TokenEOF, "", false, pos(5)},
+			},
+		},
+		"spawn": {
+			str: `spawn`,
+			expected: []lexer.Token{
+				{lexer.TokenSpawn, "spawn", false, pos(1)},
+				{lexer.TokenEOF, "", false, pos(6)},
+			},
+		},
+		"select": {
+			str: `select`,
+			expected: []lexer.Token{
+				{lexer.TokenSelect, "select", false, pos(1)},
+				{lexer.TokenEOF, "", false, pos(7)},
+			},
+		},
+		"chan": {
+			str: `chan`,
+			expected: []lexer.Token{
+				{lexer.TokenChan, "chan", false, pos(1)},
+				{lexer.TokenEOF, "", false, pos(5)},
+			},
+		},
+		"nil": {
+			str: `nil`,
+			expected: []lexer.Token{
+				{lexer.TokenNil, "nil", false, pos(1)},
+				{lexer.TokenEOF, "", false, pos(4)},
+			},
+		},
+		"optional-type": {
+			str: `?string`,
+			expected: []lexer.Token{
+				{lexer.TokenQuestion, "?", false, pos(1)},
+				{lexer.TokenString, "string", false, pos(2)},
+				{lexer.TokenEOF, "", false, pos(8)},
+			},
+		},
+		"nil-coalesce": {
+			str: `a??b`,
+			expected: []lexer.Token{
+				{lexer.TokenIdentifier, "a", false, pos(1)},
+				{lexer.TokenNilCoalesce, "??", false, pos(2)},
+				{lexer.TokenIdentifier, "b", false, pos(4)},
+				{lexer.TokenEOF, "", false, pos(5)},
+			},
+		},
 		".": {
 			str: `.`,
 			expected: []lexer.Token{
@@ -0,0 +1,559 @@
+package lsp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/fs"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+)
+
+// analysis is the result of parsing and compiling a package.
+type analysis struct {
+	parser *parser.Parser
+
+	// errs contains the parser errors, or compiler errors if the package could
+	// be parsed.
+	errs []error
+
+	// funcs contains all of the compiled functions and tests indexed by their
+	// position (the position of the "func" or "test" keyword). It will be empty
+	// if the package did not compile.
+	funcs map[string]*vm.CompiledFunc
+}
+
+// analyze parses and compiles the package in dir. Files that are open in the
+// client are used instead of the files on disk.
+func (s *Server) analyze(dir string) (a *analysis) {
+	a = &analysis{
+		funcs: map[string]*vm.CompiledFunc{},
+	}
+
+	// Some invalid code can cause the parser or compiler to panic.
+	defer func() {
+		if r := recover(); r != nil {
+			a.errs = []error{fmt.Errorf("%v", r)}
+		}
+
+		s.analyses[dir] = a
+		if len(a.errs) == 0 {
+			s.compiled[dir] = a
+		}
+	}()
+
+	anonFunctionName := 10000
+	a.parser = s.parseDirectory(dir, anonFunctionName)
+	a.errs = a.parser.Errors()
+	if len(a.errs) > 0 {
+		return
+	}
+
+	var file *vm.File
+	file, _, a.errs = compiler.CompilePackage(a.parser, s.rootPath, dir,
+		&anonFunctionName, false)
+	if len(a.errs) > 0 {
+		return
+	}
+
+	for _, symbol := range file.Symbols {
+		if symbol.Func != nil {
+			a.funcs[symbol.Func.Pos] = symbol.Func
+		}
+	}
+
+	for _, test := range file.Tests {
+		a.funcs[test.Pos] = test.CompiledFunc
+	}
+
+	return
+}
+
+// analysis returns the analysis for the package in dir. If the package
+// currently has errors, the last analysis that compiled is returned so that
+// types can still be resolved.
+func (s *Server) analysis(dir string) *analysis {
+	if a := s.compiled[dir]; a != nil {
+		return a
+	}
+
+	if a := s.analyses[dir]; a != nil {
+		return a
+	}
+
+	return s.analyze(dir)
+}
+
+// parseDirectory is the same as parser.ParseDirectory (including tests) except
+// that open documents are used.
+func (s *Server) parseDirectory(dir string, anonFunctionName int) *parser.Parser {
+	p := parser.NewParser(anonFunctionName)
+
+	fileNames, err := s.packageFiles(dir)
+	if err != nil {
+		// This will record the error.
+		p.ParseDirectory(dir, true)
+
+		return p
+	}
+
+	for _, fileName := range fileNames {
+		text, err := s.text(fileName)
+		if err != nil {
+			continue
+		}
+
+		p.ParseString(text, fileName)
+	}
+
+	return p
+}
+
+// packageFiles returns the ".ok" and ".okt" files in dir, including open files
+// that have not been saved yet.
+func (s *Server) packageFiles(dir string) ([]string, error) {
+	files := map[string]bool{}
+	for filePath := range s.documents {
+		if filepath.Dir(filePath) == dir && isSourceFile(filePath) {
+			files[filePath] = true
+		}
+	}
+
+	infos, err := fs.Filesystem.ReadDir(dir)
+	if err != nil && len(files) == 0 {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		filePath := path.Join(dir, info.Name())
+		if !info.IsDir() && isSourceFile(filePath) {
+			files[filePath] = true
+		}
+	}
+
+	var fileNames []string
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	return fileNames, nil
+}
+
+func isSourceFile(filePath string) bool {
+	ext := path.Ext(filePath)
+
+	return ext == ".ok" || ext == ".okt"
+}
+
+// text returns the contents of an open document, otherwise the file is read.
+// The standard library is also available through the same paths used by the
+// compiler.
+func (s *Server) text(filePath string) (string, error) {
+	if text, ok := s.documents[filePath]; ok {
+		return text, nil
+	}
+
+	f, err := fs.Filesystem.OpenFile(filePath, os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+
+	return string(data), err
+}
+
+// importDir follows the same rules as the compiler for finding the directory of
+// an imported package.
+func (s *Server) importDir(importPath string) string {
+	if !strings.Contains(importPath, "/") {
+		return "/" + importPath
+	}
+
+	return path.Join(s.rootPath, importPath)
+}
+
+// parsePackage parses an imported package without compiling it. Functions are
+// registered as constants the same way compiler.CompilePackage does so that
+// they are included in the interface built by Parser.Package.
+func (s *Server) parsePackage(importPath string) *parser.Parser {
+	p := s.parseDirectory(s.importDir(importPath), 0)
+	for _, fn := range p.Funcs() {
+		if fn.Name != "" {
+			p.Constants[fn.Name] = &ast.Literal{
+				Kind:  fn.Type(),
+				Value: fn.UniqueName,
+				Pos:   fn.Position(),
+			}
+		}
+	}
+
+	return p
+}
+
+// docs returns the comments attached to functions, indexed by function name.
+func docs(p *parser.Parser) map[string]string {
+	docs := map[string]string{}
+	for _, comment := range p.Comments() {
+		if comment.Func != "" {
+			docs[comment.Func] = comment.String()
+		}
+	}
+
+	return docs
+}
+
+// funcByName only finds package-level functions.
+func funcByName(p *parser.Parser, name string) *ast.Func {
+	for _, uniqueName := range p.SortedFuncNames() {
+		if fn := p.Funcs()[uniqueName]; fn.Name == name {
+			return fn
+		}
+	}
+
+	return nil
+}
+
+// document is the tokens of a single file.
+type document struct {
+	filePath string
+	tokens   []lexer.Token
+}
+
+func (s *Server) document(filePath string) *document {
+	text, err := s.text(filePath)
+	if err != nil {
+		return &document{filePath: filePath}
+	}
+
+	// The tokens up to an error are still useful.
+	tokens, _, _ := lexer.TokenizeString(text, lexer.Options{}, filePath)
+
+	return &document{
+		filePath: filePath,
+		tokens:   tokens,
+	}
+}
+
+// tokenAt returns the offset of the identifier at (or directly before) the
+// position, or -1.
+func (d *document) tokenAt(pos Position) int {
+	line, col := pos.Line+1, pos.Character+1
+	for i, token := range d.tokens {
+		if token.Kind == lexer.TokenIdentifier && token.Pos.LineNumber == line &&
+			col >= token.Pos.CharacterNumber &&
+			col <= token.Pos.CharacterNumber+tokenLength(token) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// tokenBefore returns the offset of the last token that starts before the
+// position, or -1.
+func (d *document) tokenBefore(pos Position) int {
+	line, col := pos.Line+1, pos.Character+1
+	found := -1
+	for i, token := range d.tokens {
+		if token.Kind == lexer.TokenEOF ||
+			token.Pos.LineNumber > line ||
+			(token.Pos.LineNumber == line && token.Pos.CharacterNumber >= col) {
+			break
+		}
+
+		found = i
+	}
+
+	return found
+}
+
+// imports returns the import paths indexed by the variable name. They are read
+// from the tokens so that they are available even if the file cannot be
+// parsed.
+func (d *document) imports() map[string]string {
+	imports := map[string]string{}
+	for i := 0; i+1 < len(d.tokens); i++ {
+		if d.tokens[i].Kind == lexer.TokenImport &&
+			d.tokens[i+1].Kind == lexer.TokenStringLiteral {
+			importPath := d.tokens[i+1].Value
+			imports[path.Base(importPath)] = importPath
+		}
+	}
+
+	return imports
+}
+
+// tokenLength is the number of characters of the token in the source. The
+// value of a quoted literal does not include the quotes.
+func tokenLength(token lexer.Token) int {
+	length := len([]rune(token.Value))
+	switch token.Kind {
+	case lexer.TokenCharLiteral, lexer.TokenDataLiteral,
+		lexer.TokenStringLiteral:
+		length += 2
+	}
+
+	return length
+}
+
+func tokenRange(token lexer.Token) Range {
+	start := Position{
+		Line:      token.Pos.LineNumber - 1,
+		Character: token.Pos.CharacterNumber - 1,
+	}
+
+	return Range{
+		Start: start,
+		End: Position{
+			Line:      start.Line,
+			Character: start.Character + tokenLength(token),
+		},
+	}
+}
+
+// scope is the range of a function (or test) in a file.
+type scope struct {
+	// pos is the position of the "func" or "test" keyword. This is the same
+	// as CompiledFunc.Pos.
+	pos string
+
+	// start and end are the offsets of the keyword and the closing curly
+	// bracket.
+	start, end int
+}
+
+// scopes returns all of the functions and tests, from the innermost to the
+// outermost, that contain the token at offset.
+func (d *document) scopes(offset int) []scope {
+	var scopes []scope
+	for i, token := range d.tokens {
+		if i > offset {
+			break
+		}
+
+		if token.Kind != lexer.TokenFunc && token.Kind != lexer.TokenTest &&
+			token.Kind != lexer.TokenBench && token.Kind != lexer.TokenFuzz {
+			continue
+		}
+
+		if end := d.blockEnd(i); end >= offset {
+			scopes = append([]scope{{
+				pos:   token.Pos.String(),
+				start: i,
+				end:   end,
+			}}, scopes...)
+		}
+	}
+
+	return scopes
+}
+
+// blockEnd returns the offset of the curly bracket that closes the function or
+// test that starts at offset. -1 is returned if offset is not the start of a
+// function, such as the "func" in a function type.
+func (d *document) blockEnd(offset int) int {
+	i := offset + 1
+	if d.tokens[offset].Kind == lexer.TokenTest ||
+		d.tokens[offset].Kind == lexer.TokenBench ||
+		d.tokens[offset].Kind == lexer.TokenFuzz {
+		i++
+
+		// Skip over the arguments of a fuzz target.
+		if d.kind(i) == lexer.TokenParenOpen {
+			i = d.closing(i) + 1
+		}
+
+		// Skip over the cases of a table test. A curly bracket directly after
+		// "in" is a map literal rather than the body.
+		if d.kind(i) == lexer.TokenFor {
+			for i < len(d.tokens) &&
+				(d.kind(i) != lexer.TokenCurlyOpen || d.kind(i-1) == lexer.TokenIn) {
+				switch d.kind(i) {
+				case lexer.TokenParenOpen, lexer.TokenSquareOpen,
+					lexer.TokenCurlyOpen:
+					i = d.closing(i)
+				}
+				i++
+			}
+		}
+	} else {
+		if d.kind(i) == lexer.TokenIdentifier {
+			i++
+		}
+
+		if d.kind(i) != lexer.TokenParenOpen {
+			return -1
+		}
+		i = d.closing(i) + 1
+
+		// Skip over the return types to find the body.
+	returnTypes:
+		for i > 0 && i < len(d.tokens) {
+			switch d.kind(i) {
+			case lexer.TokenParenOpen:
+				i = d.closing(i) + 1
+
+			case lexer.TokenCurlyOpen:
+				// "{}" followed by a type on the same line is a map type,
+				// otherwise it is an empty body.
+				if d.kind(i+1) == lexer.TokenCurlyClose &&
+					!d.tokens[i+1].IsEndOfLine && i+2 < len(d.tokens) &&
+					isTypeStart(d.kind(i+2)) {
+					i += 2
+					continue
+				}
+
+				break returnTypes
+
+			case lexer.TokenSquareOpen, lexer.TokenSquareClose, lexer.TokenDot,
+				lexer.TokenIdentifier, lexer.TokenFunc, lexer.TokenAny,
+				lexer.TokenBool, lexer.TokenChar, lexer.TokenData,
+				lexer.TokenNumber, lexer.TokenString:
+				i++
+
+			default:
+				return -1
+			}
+		}
+	}
+
+	if d.kind(i) != lexer.TokenCurlyOpen {
+		return -1
+	}
+
+	return d.closing(i)
+}
+
+func isTypeStart(kind string) bool {
+	switch kind {
+	case lexer.TokenSquareOpen, lexer.TokenCurlyOpen, lexer.TokenIdentifier,
+		lexer.TokenFunc, lexer.TokenAny, lexer.TokenBool, lexer.TokenChar,
+		lexer.TokenData, lexer.TokenNumber, lexer.TokenString:
+		return true
+	}
+
+	return false
+}
+
+func (d *document) kind(offset int) string {
+	if offset < 0 || offset >= len(d.tokens) {
+		return lexer.TokenEOF
+	}
+
+	return d.tokens[offset].Kind
+}
+
+// closing returns the offset of the bracket that closes the bracket at offset.
+// If it is never closed, the last offset is returned.
+func (d *document) closing(offset int) int {
+	depth := 0
+	for i := offset; i < len(d.tokens); i++ {
+		switch d.tokens[i].Kind {
+		case lexer.TokenParenOpen, lexer.TokenSquareOpen, lexer.TokenCurlyOpen:
+			depth++
+
+		case lexer.TokenParenClose, lexer.TokenSquareClose,
+			lexer.TokenCurlyClose:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(d.tokens) - 1
+}
+
+// variable finds the type of a variable from the innermost function that
+// contains the token at offset. The scope that declared it is also returned.
+func (a *analysis) variable(d *document, offset int, name string) (*types.Type, *scope) {
+	scopes := d.scopes(offset)
+
+	// Variables prefixed with "^" come from the parent scope.
+	if strings.HasPrefix(name, "^") {
+		name = name[1:]
+		if len(scopes) > 0 {
+			scopes = scopes[1:]
+		}
+	}
+
+	for i, sc := range scopes {
+		fn := a.funcs[sc.pos]
+		if fn == nil {
+			continue
+		}
+
+		if ty, ok := fn.GetTypeForVariable(name, nil); ok {
+			return ty, &scopes[i]
+		}
+	}
+
+	return nil, nil
+}
+
+var positionRegexp = regexp.MustCompile(`^(.+):(\d+):(\d+)$`)
+
+// location converts a position from the parser, like "/foo/bar.ok:12:3". The
+// length is the number of characters that will be included in the range.
+func (s *Server) location(pos string, length int) *Location {
+	matches := positionRegexp.FindStringSubmatch(pos)
+	if matches == nil {
+		return nil
+	}
+
+	line, _ := strconv.Atoi(matches[2])
+	col, _ := strconv.Atoi(matches[3])
+
+	filePath, err := s.materialize(matches[1])
+	if err != nil {
+		return nil
+	}
+
+	return &Location{
+		URI: pathToURI(filePath),
+		Range: Range{
+			Start: Position{Line: line - 1, Character: col - 1},
+			End:   Position{Line: line - 1, Character: col - 1 + length},
+		},
+	}
+}
+
+// materialize makes sure a file can be opened by the client. Files from the
+// standard library are embedded in the ok binary so they are written to a
+// temporary directory.
+func (s *Server) materialize(filePath string) (string, error) {
+	if _, err := os.Stat(filePath); err == nil {
+		return filePath, nil
+	}
+
+	if _, ok := s.documents[filePath]; ok {
+		return filePath, nil
+	}
+
+	text, err := s.text(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	tempPath := filepath.Join(os.TempDir(), "ok-lsp", filePath)
+	err = os.MkdirAll(filepath.Dir(tempPath), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return tempPath, ioutil.WriteFile(tempPath, []byte(text), 0644)
+}
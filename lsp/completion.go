@@ -0,0 +1,180 @@
+package lsp
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/util"
+)
+
+func (s *Server) completion(params TextDocumentPositionParams) []CompletionItem {
+	filePath := uriToPath(params.TextDocument.URI)
+	d := s.document(filePath)
+	dir := filepath.Dir(filePath)
+	a := s.analysis(dir)
+
+	// Types come from the last analysis that compiled, but functions and
+	// constants can be found as long as the package could be parsed.
+	latest := s.analyses[dir]
+	if latest == nil || latest.parser == nil {
+		latest = a
+	}
+
+	// The word being completed may be partially typed, like "math.Ab".
+	offset := d.tokenBefore(params.Position)
+	dot := offset
+	if offset >= 0 && d.tokens[offset].Kind == lexer.TokenIdentifier {
+		dot = offset - 1
+	}
+
+	if dot >= 1 && d.tokens[dot].Kind == lexer.TokenDot &&
+		d.tokens[dot-1].Kind == lexer.TokenIdentifier {
+		return s.memberCompletion(a, d, dot-1)
+	}
+
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+	add := func(item CompletionItem) {
+		if !seen[item.Label] {
+			seen[item.Label] = true
+			items = append(items, item)
+		}
+	}
+
+	// Variables in the current function (and any parent functions).
+	if offset >= 0 {
+		for _, sc := range d.scopes(offset) {
+			for i := sc.start; i <= sc.end; i++ {
+				token := d.tokens[i]
+				if token.Kind != lexer.TokenIdentifier || i == offset ||
+					latest.parser.Constants[token.Value] != nil {
+					continue
+				}
+
+				if ty, _ := a.variable(d, offset, token.Value); ty != nil {
+					add(CompletionItem{
+						Label:  token.Value,
+						Kind:   completionKindVariable,
+						Detail: ty.String(),
+					})
+				}
+			}
+		}
+	}
+
+	var importNames []string
+	for name := range d.imports() {
+		importNames = append(importNames, name)
+	}
+	sort.Strings(importNames)
+
+	for _, name := range importNames {
+		add(CompletionItem{
+			Label:  name,
+			Kind:   completionKindModule,
+			Detail: d.imports()[name],
+		})
+	}
+
+	funcDocs := docs(latest.parser)
+	for _, uniqueName := range latest.parser.SortedFuncNames() {
+		if fn := latest.parser.Funcs()[uniqueName]; fn.Name != "" {
+			add(funcCompletion(fn, funcDocs[fn.Name]))
+		}
+	}
+
+	var constantNames []string
+	for name, c := range latest.parser.Constants {
+		if name[0] != '_' && !c.IsGlobal && c.Kind.Kind != types.KindFunc {
+			constantNames = append(constantNames, name)
+		}
+	}
+	sort.Strings(constantNames)
+
+	for _, name := range constantNames {
+		add(CompletionItem{
+			Label:  name,
+			Kind:   completionKindConstant,
+			Detail: latest.parser.Constants[name].Kind.String(),
+		})
+	}
+
+	return items
+}
+
+// memberCompletion completes the public members of a package (from the
+// interface that Parser.Package builds) or an object.
+func (s *Server) memberCompletion(a *analysis, d *document, offset int) []CompletionItem {
+	name := d.tokens[offset].Value
+	items := []CompletionItem{}
+
+	if importPath, ok := d.imports()[name]; ok {
+		pkg := s.parsePackage(importPath)
+		pkgType := pkg.Package(path.Base(importPath)).Returns[0]
+		funcDocs := docs(pkg)
+
+		for _, member := range pkgType.SortedPropertyNames() {
+			if !util.IsPublic(member) {
+				continue
+			}
+
+			if fn := funcByName(pkg, member); fn != nil {
+				items = append(items, funcCompletion(fn, funcDocs[member]))
+				continue
+			}
+
+			items = append(items, CompletionItem{
+				Label:  member,
+				Kind:   completionKindConstant,
+				Detail: pkgType.Properties[member].String(),
+			})
+		}
+
+		return items
+	}
+
+	ty, _ := a.variable(d, offset, name)
+	if ty == nil {
+		return items
+	}
+
+	for _, member := range ty.SortedPropertyNames() {
+		if !util.IsPublic(member) {
+			continue
+		}
+
+		kind := completionKindVariable
+		if ty.Properties[member].Kind == types.KindFunc {
+			kind = completionKindFunction
+		}
+
+		items = append(items, CompletionItem{
+			Label:  member,
+			Kind:   kind,
+			Detail: ty.Properties[member].String(),
+		})
+	}
+
+	return items
+}
+
+func funcCompletion(fn *ast.Func, doc string) CompletionItem {
+	item := CompletionItem{
+		Label:  fn.Name,
+		Kind:   completionKindFunction,
+		Detail: fn.String(),
+	}
+
+	if doc != "" {
+		item.Documentation = &MarkupContent{
+			Kind:  "markdown",
+			Value: doc,
+		}
+	}
+
+	return item
+}
@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"path/filepath"
+
+	"github.com/elliotchance/ok/lexer"
+)
+
+func (s *Server) definition(params TextDocumentPositionParams) *Location {
+	filePath := uriToPath(params.TextDocument.URI)
+	d := s.document(filePath)
+	offset := d.tokenAt(params.Position)
+	if offset < 0 {
+		return nil
+	}
+
+	a := s.analysis(filepath.Dir(filePath))
+	name := d.tokens[offset].Value
+	imports := d.imports()
+
+	// A member of another package, like "math.Abs".
+	if offset >= 2 && d.tokens[offset-1].Kind == lexer.TokenDot &&
+		d.tokens[offset-2].Kind == lexer.TokenIdentifier {
+		importPath, ok := imports[d.tokens[offset-2].Value]
+		if !ok {
+			return nil
+		}
+
+		pkg := s.parsePackage(importPath)
+		if fn := funcByName(pkg, name); fn != nil {
+			return s.location(fn.Pos, len("func"))
+		}
+
+		if c, ok := pkg.Constants[name]; ok {
+			return s.location(c.Pos, 0)
+		}
+
+		return nil
+	}
+
+	// A variable is defined by the first time it appears in the function that
+	// it belongs to. This will either be an argument or the first assignment.
+	if ty, sc := a.variable(d, offset, name); ty != nil {
+		for i := sc.start; i <= sc.end; i++ {
+			token := d.tokens[i]
+			if token.Kind == lexer.TokenIdentifier &&
+				(token.Value == name || "^"+token.Value == name) {
+				return &Location{
+					URI:   pathToURI(filePath),
+					Range: tokenRange(token),
+				}
+			}
+		}
+	}
+
+	if fn := funcByName(a.parser, name); fn != nil {
+		return s.location(fn.Pos, len("func"))
+	}
+
+	// The definition of a package is the start of its first file.
+	if importPath, ok := imports[name]; ok {
+		fileNames, err := s.packageFiles(s.importDir(importPath))
+		if err != nil || len(fileNames) == 0 {
+			return nil
+		}
+
+		return s.location(fileNames[0]+":1:1", 0)
+	}
+
+	if c, ok := a.parser.Constants[name]; ok && !c.IsGlobal {
+		return s.location(c.Pos, 0)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package lsp
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var errorRegexp = regexp.MustCompile(`^(.+?):(\d+):(\d+):? (.*)$`)
+
+// diagnose publishes the errors for the package that contains filePath. Errors
+// are published for every file in the package, since a change in one file can
+// cause errors in another.
+func (s *Server) diagnose(filePath string) error {
+	if !isSourceFile(filePath) {
+		return nil
+	}
+
+	dir := filepath.Dir(filePath)
+	a := s.analyze(dir)
+
+	diagnostics := map[string][]Diagnostic{}
+	for _, err := range a.errs {
+		errorFile, diagnostic := s.diagnostic(err.Error(), dir, filePath)
+		diagnostics[errorFile] = append(diagnostics[errorFile], diagnostic)
+	}
+
+	var files []string
+	for file := range s.diagnosed {
+		if filepath.Dir(file) == dir {
+			files = append(files, file)
+		}
+	}
+	for file := range diagnostics {
+		if !s.diagnosed[file] {
+			files = append(files, file)
+		}
+	}
+
+	for _, file := range files {
+		// An empty array (rather than null) is required to clear the
+		// diagnostics.
+		fileDiagnostics := diagnostics[file]
+		if fileDiagnostics == nil {
+			fileDiagnostics = []Diagnostic{}
+		}
+
+		err := s.notify("textDocument/publishDiagnostics",
+			PublishDiagnosticsParams{
+				URI:         pathToURI(file),
+				Diagnostics: fileDiagnostics,
+			})
+		if err != nil {
+			return err
+		}
+
+		s.diagnosed[file] = len(diagnostics[file]) > 0
+		if !s.diagnosed[file] {
+			delete(s.diagnosed, file)
+		}
+	}
+
+	return nil
+}
+
+// diagnostic converts an error message from the parser or compiler. Messages
+// are prefixed with a position, like "/foo/bar.ok:12:3 undefined variable: a".
+// Errors without a position, or that belong to another package, are placed at
+// the start of defaultFile.
+func (s *Server) diagnostic(message, dir, defaultFile string) (string, Diagnostic) {
+	diagnostic := Diagnostic{
+		Severity: severityError,
+		Source:   "ok",
+		Message:  message,
+	}
+
+	matches := errorRegexp.FindStringSubmatch(message)
+	if matches == nil || filepath.Dir(matches[1]) != dir {
+		return defaultFile, diagnostic
+	}
+
+	line, _ := strconv.Atoi(matches[2])
+	col, _ := strconv.Atoi(matches[3])
+	diagnostic.Message = strings.TrimSpace(matches[4])
+	diagnostic.Range = Range{
+		Start: Position{Line: line - 1, Character: col - 1},
+		End:   Position{Line: line - 1, Character: col},
+	}
+
+	// Highlight the whole token if there is one at the position.
+	for _, token := range s.document(matches[1]).tokens {
+		if token.Pos.LineNumber == line && token.Pos.CharacterNumber == col &&
+			tokenLength(token) > 0 {
+			diagnostic.Range = tokenRange(token)
+			break
+		}
+	}
+
+	return matches[1], diagnostic
+}
@@ -0,0 +1,4 @@
+// Package lsp implements a language server for ok using the Language Server
+// Protocol over stdio. It provides diagnostics, hover, go to definition and
+// completion for ".ok" and ".okt" files.
+package lsp
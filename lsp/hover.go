@@ -0,0 +1,88 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/elliotchance/ok/lexer"
+)
+
+func (s *Server) hover(params TextDocumentPositionParams) *Hover {
+	filePath := uriToPath(params.TextDocument.URI)
+	d := s.document(filePath)
+	offset := d.tokenAt(params.Position)
+	if offset < 0 {
+		return nil
+	}
+
+	contents := s.describe(d, offset)
+	if contents == "" {
+		return nil
+	}
+
+	tokenRange := tokenRange(d.tokens[offset])
+
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: contents,
+		},
+		Range: &tokenRange,
+	}
+}
+
+// describe returns the markdown for the identifier at offset. An empty string
+// is returned if it cannot be resolved.
+func (s *Server) describe(d *document, offset int) string {
+	a := s.analysis(filepath.Dir(d.filePath))
+	name := d.tokens[offset].Value
+	imports := d.imports()
+
+	// A member of a package or object, like "math.Abs" or "person.Name".
+	if offset >= 2 && d.tokens[offset-1].Kind == lexer.TokenDot &&
+		d.tokens[offset-2].Kind == lexer.TokenIdentifier {
+		object := d.tokens[offset-2].Value
+		if importPath, ok := imports[object]; ok {
+			pkg := s.parsePackage(importPath)
+			if fn := funcByName(pkg, name); fn != nil {
+				return code(fn.String()) + docs(pkg)[name]
+			}
+
+			if c, ok := pkg.Constants[name]; ok {
+				return code(fmt.Sprintf("%s %s", name, c.Kind))
+			}
+
+			return ""
+		}
+
+		if ty, _ := a.variable(d, offset-2, object); ty != nil {
+			if property := ty.Properties[name]; property != nil {
+				return code(fmt.Sprintf("%s %s", name, property))
+			}
+		}
+
+		return ""
+	}
+
+	if ty, _ := a.variable(d, offset, name); ty != nil {
+		return code(fmt.Sprintf("%s %s", name, ty))
+	}
+
+	if fn := funcByName(a.parser, name); fn != nil {
+		return code(fn.String()) + docs(a.parser)[name]
+	}
+
+	if importPath, ok := imports[name]; ok {
+		return code(fmt.Sprintf("import %q", importPath))
+	}
+
+	if c, ok := a.parser.Constants[name]; ok && !c.IsGlobal {
+		return code(fmt.Sprintf("%s %s", name, c.Kind))
+	}
+
+	return ""
+}
+
+func code(s string) string {
+	return "```ok\n" + s + "\n```\n"
+}
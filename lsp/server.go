@@ -0,0 +1,285 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Server is a language server that communicates with a single client.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// rootPath is the same as $OKPATH. Imports are relative to it.
+	rootPath string
+
+	// documents contains the text of the files that are open in the client,
+	// indexed by their absolute path. These are used instead of the files on
+	// disk.
+	documents map[string]string
+
+	// analyses contains the most recent analysis for each package directory.
+	// compiled is the most recent analysis that compiled without errors. It is
+	// used to resolve types while the package has errors.
+	analyses, compiled map[string]*analysis
+
+	// diagnosed is the files that diagnostics have been published for. They
+	// need to be cleared when the errors are fixed.
+	diagnosed map[string]bool
+}
+
+// NewServer creates a server that reads requests from in and writes responses
+// to out. If rootPath is empty, the root of the workspace that is provided by
+// the client will be used.
+func NewServer(in io.Reader, out io.Writer, rootPath string) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		rootPath:  rootPath,
+		documents: map[string]string{},
+		analyses:  map[string]*analysis{},
+		compiled:  map[string]*analysis{},
+		diagnosed: map[string]bool{},
+	}
+}
+
+// Serve handles requests until the client sends "exit" or closes the input.
+func (s *Server) Serve() error {
+	for {
+		req, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(req)
+
+		// Notifications do not get a response, even for errors.
+		if req.ID == nil {
+			continue
+		}
+
+		if err != nil {
+			code := codeInvalidParams
+			if err == errMethodNotFound {
+				code = codeMethodNotFound
+			}
+
+			err = s.write(errorResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error: responseError{
+					Code:    code,
+					Message: err.Error(),
+				},
+			})
+		} else {
+			err = s.write(response{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Result:  result,
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+var errMethodNotFound = fmt.Errorf("method not found")
+
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return s.initialize(params), nil
+
+	case "initialized", "shutdown", "$/cancelRequest":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.change(params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		// Only full synchronization is supported, so the last change contains
+		// the whole document.
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+
+		return nil, s.change(params.TextDocument.URI,
+			params.ContentChanges[len(params.ContentChanges)-1].Text)
+
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.diagnose(uriToPath(params.TextDocument.URI))
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		// The file on disk is now the source of truth.
+		filePath := uriToPath(params.TextDocument.URI)
+		delete(s.documents, filePath)
+
+		return nil, s.diagnose(filePath)
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return s.hover(params), nil
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return s.definition(params), nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return s.completion(params), nil
+	}
+
+	// Notifications that are not supported can be ignored.
+	if req.ID == nil {
+		return nil, nil
+	}
+
+	return nil, errMethodNotFound
+}
+
+func (s *Server) initialize(params InitializeParams) interface{} {
+	if s.rootPath == "" && params.RootURI != "" {
+		s.rootPath = uriToPath(params.RootURI)
+	}
+
+	if s.rootPath == "" {
+		s.rootPath, _ = os.Getwd()
+	}
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync":   textDocumentSyncFull,
+			"hoverProvider":      true,
+			"definitionProvider": true,
+			"completionProvider": map[string]interface{}{
+				"triggerCharacters": []string{"."},
+			},
+		},
+		"serverInfo": map[string]string{
+			"name": "ok",
+		},
+	}
+}
+
+func (s *Server) change(uri, text string) error {
+	filePath := uriToPath(uri)
+	s.documents[filePath] = text
+
+	return s.diagnose(filePath)
+}
+
+// read reads a single message. Each message has a header that contains the
+// length of the JSON body that follows.
+func (s *Server) read() (*request, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %v", err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+
+	var req *request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (s *Server) write(message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+
+	return err
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return filepath.Clean(u.Path)
+}
+
+func pathToURI(filePath string) string {
+	return (&url.URL{Scheme: "file", Path: filePath}).String()
+}
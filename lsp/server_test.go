@@ -0,0 +1,309 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/elliotchance/ok/lsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fooSource = `// Double returns twice the value.
+func Double(x number) number {
+    return x * 2
+}
+`
+
+	mainSource = `import "lib/foo"
+
+// Greet says hello.
+func Greet(name string) string {
+    return "hi " + name
+}
+
+func main() {
+    total = foo.Double(3)
+    print(Greet("bob"), total)
+}
+`
+)
+
+func newWorkspace(t *testing.T) string {
+	rootPath, err := ioutil.TempDir("", "ok-lsp-test")
+	require.NoError(t, err)
+
+	for filePath, source := range map[string]string{
+		"lib/foo/foo.ok": fooSource,
+		"app/main.ok":    mainSource,
+	} {
+		filePath = filepath.Join(rootPath, filePath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(source), 0644))
+	}
+
+	return rootPath
+}
+
+// serve sends the messages and returns the responses (indexed by ID) and the
+// notifications that were received.
+func serve(t *testing.T, rootPath string, messages ...map[string]interface{}) (
+	map[float64]map[string]interface{},
+	[]map[string]interface{},
+) {
+	in := bytes.NewBuffer(nil)
+	for _, message := range messages {
+		message["jsonrpc"] = "2.0"
+		body, err := json.Marshal(message)
+		require.NoError(t, err)
+		fmt.Fprintf(in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, lsp.NewServer(in, out, rootPath).Serve())
+
+	responses := map[float64]map[string]interface{}{}
+	var notifications []map[string]interface{}
+	reader := bufio.NewReader(out)
+	for {
+		header, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		require.NoError(t, err)
+
+		body := make([]byte, length)
+		_, err = io.ReadFull(reader, body)
+		require.NoError(t, err)
+
+		var message map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &message))
+
+		if id, ok := message["id"].(float64); ok {
+			responses[id] = message
+		} else {
+			notifications = append(notifications, message)
+		}
+	}
+
+	return responses, notifications
+}
+
+func position(uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position": map[string]interface{}{
+			"line":      line,
+			"character": character,
+		},
+	}
+}
+
+func TestServer(t *testing.T) {
+	rootPath := newWorkspace(t)
+	defer os.RemoveAll(rootPath)
+
+	mainURI := "file://" + filepath.Join(rootPath, "app", "main.ok")
+	fooURI := "file://" + filepath.Join(rootPath, "lib", "foo", "foo.ok")
+
+	for testName, test := range map[string]struct {
+		method   string
+		line     int
+		char     int
+		expected interface{}
+	}{
+		"hover-variable": {
+			method: "textDocument/hover",
+			line:   8,
+			char:   6,
+			expected: map[string]interface{}{
+				"kind":  "markdown",
+				"value": "```ok\ntotal number\n```\n",
+			},
+		},
+		"hover-func": {
+			method: "textDocument/hover",
+			line:   9,
+			char:   11,
+			expected: map[string]interface{}{
+				"kind":  "markdown",
+				"value": "```ok\nfunc Greet(name string) string\n```\nGreet says hello.",
+			},
+		},
+		"hover-package-func": {
+			method: "textDocument/hover",
+			line:   8,
+			char:   18,
+			expected: map[string]interface{}{
+				"kind":  "markdown",
+				"value": "```ok\nfunc Double(x number) number\n```\nDouble returns twice the value.",
+			},
+		},
+		"hover-import": {
+			method: "textDocument/hover",
+			line:   8,
+			char:   13,
+			expected: map[string]interface{}{
+				"kind":  "markdown",
+				"value": "```ok\nimport \"lib/foo\"\n```\n",
+			},
+		},
+		"hover-nothing": {
+			method: "textDocument/hover",
+			line:   0,
+			char:   2,
+		},
+		"definition-variable": {
+			method: "textDocument/definition",
+			line:   9,
+			char:   25,
+			expected: map[string]interface{}{
+				"uri": mainURI,
+				"range": map[string]interface{}{
+					"start": map[string]interface{}{"line": 8.0, "character": 4.0},
+					"end":   map[string]interface{}{"line": 8.0, "character": 9.0},
+				},
+			},
+		},
+		"definition-nothing": {
+			method: "textDocument/definition",
+			line:   9,
+			char:   1,
+		},
+		"definition-func": {
+			method: "textDocument/definition",
+			line:   9,
+			char:   11,
+			expected: map[string]interface{}{
+				"uri": mainURI,
+				"range": map[string]interface{}{
+					"start": map[string]interface{}{"line": 3.0, "character": 0.0},
+					"end":   map[string]interface{}{"line": 3.0, "character": 4.0},
+				},
+			},
+		},
+		"definition-package-func": {
+			method: "textDocument/definition",
+			line:   8,
+			char:   18,
+			expected: map[string]interface{}{
+				"uri": fooURI,
+				"range": map[string]interface{}{
+					"start": map[string]interface{}{"line": 1.0, "character": 0.0},
+					"end":   map[string]interface{}{"line": 1.0, "character": 4.0},
+				},
+			},
+		},
+		"completion-package-members": {
+			method: "textDocument/completion",
+			line:   8,
+			char:   16,
+			expected: []interface{}{
+				map[string]interface{}{
+					"label":  "Double",
+					"kind":   3.0,
+					"detail": "func Double(x number) number",
+					"documentation": map[string]interface{}{
+						"kind":  "markdown",
+						"value": "Double returns twice the value.",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			responses, _ := serve(t, rootPath,
+				map[string]interface{}{
+					"id":     1,
+					"method": "initialize",
+					"params": map[string]interface{}{},
+				},
+				map[string]interface{}{
+					"id":     2,
+					"method": test.method,
+					"params": position(mainURI, test.line, test.char),
+				},
+			)
+
+			require.Contains(t, responses, 2.0)
+			result := responses[2]["result"]
+			if test.method == "textDocument/hover" && result != nil {
+				result = result.(map[string]interface{})["contents"]
+			}
+
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	rootPath := newWorkspace(t)
+	defer os.RemoveAll(rootPath)
+
+	mainURI := "file://" + filepath.Join(rootPath, "app", "main.ok")
+
+	for testName, test := range map[string]struct {
+		text     string
+		expected []interface{}
+	}{
+		"no-errors": {
+			text: mainSource,
+		},
+		"compiler-error": {
+			text: "func main() {\n    x = \"a\" + 1\n}\n",
+			expected: []interface{}{
+				map[string]interface{}{
+					"range": map[string]interface{}{
+						"start": map[string]interface{}{"line": 1.0, "character": 8.0},
+						"end":   map[string]interface{}{"line": 1.0, "character": 11.0},
+					},
+					"severity": 1.0,
+					"source":   "ok",
+					"message":  "cannot perform string + number",
+				},
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			_, notifications := serve(t, rootPath,
+				map[string]interface{}{
+					"method": "textDocument/didOpen",
+					"params": map[string]interface{}{
+						"textDocument": map[string]interface{}{
+							"uri":  mainURI,
+							"text": test.text,
+						},
+					},
+				},
+			)
+
+			// Diagnostics are only published for files that have (or had)
+			// errors.
+			if test.expected == nil {
+				assert.Empty(t, notifications)
+				return
+			}
+
+			require.Len(t, notifications, 1)
+			assert.Equal(t, "textDocument/publishDiagnostics",
+				notifications[0]["method"])
+
+			params := notifications[0]["params"].(map[string]interface{})
+			assert.Equal(t, mainURI, params["uri"])
+			assert.Equal(t, test.expected, params["diagnostics"])
+		})
+	}
+}
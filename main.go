@@ -9,10 +9,16 @@ import (
 
 	"github.com/elliotchance/ok/cmd/asm"
 	"github.com/elliotchance/ok/cmd/build"
+	"github.com/elliotchance/ok/cmd/clean"
+	"github.com/elliotchance/ok/cmd/debug"
 	"github.com/elliotchance/ok/cmd/doc"
+	"github.com/elliotchance/ok/cmd/format"
+	"github.com/elliotchance/ok/cmd/lsp"
+	"github.com/elliotchance/ok/cmd/repl"
 	"github.com/elliotchance/ok/cmd/run"
 	"github.com/elliotchance/ok/cmd/test"
 	"github.com/elliotchance/ok/cmd/version"
+	"github.com/elliotchance/ok/cmd/vet"
 )
 
 type command interface {
@@ -23,10 +29,16 @@ type command interface {
 var commands = map[string]command{
 	"asm":     &asm.Command{},
 	"build":   &build.Command{},
+	"clean":   &clean.Command{},
+	"debug":   &debug.Command{},
 	"doc":     &doc.Command{},
+	"fmt":     &format.Command{},
+	"lsp":     &lsp.Command{},
+	"repl":    &repl.Command{},
 	"run":     &run.Command{},
 	"test":    &test.Command{},
 	"version": &version.Command{},
+	"vet":     &vet.Command{},
 }
 
 func main() {
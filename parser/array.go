@@ -18,6 +18,12 @@ func consumeArray(parser *Parser, offset int) (*ast.Array, int, error) {
 	var ty *types.Type
 	ty, offset, err = consumeType(parser, offset)
 	if err == nil {
+		// "[]Stack [a]" would be consumed as the type "[]Stack[a]" without
+		// any elements.
+		if parser.tokens[offset].Kind != lexer.TokenSquareOpen {
+			ty, offset, err = consumeTypeWith(parser, originalOffset, false)
+		}
+
 		node.Kind = ty
 	}
 
@@ -21,13 +21,15 @@ func consumeAssert(parser *Parser, offset int) (*ast.Assert, int, error) {
 		return nil, originalOffset, err
 	}
 
-	offset, err = consume(parser, offset, []string{lexer.TokenParenClose})
+	var message ast.Node
+	message, offset, err = consumeAssertMessage(parser, offset)
 	if err != nil {
 		return nil, originalOffset, err
 	}
 
 	assert := &ast.Assert{
-		Pos: parser.pos(originalOffset),
+		Pos:     parser.pos(originalOffset),
+		Message: message,
 	}
 	if e, ok := expr.(*ast.Binary); ok {
 		assert.Expr = e
@@ -65,7 +67,8 @@ func consumeAssertRaise(parser *Parser, offset int) (*ast.AssertRaise, int, erro
 		return nil, originalOffset, err
 	}
 
-	offset, err = consume(parser, offset, []string{lexer.TokenParenClose})
+	var message ast.Node
+	message, offset, err = consumeAssertMessage(parser, offset)
 	if err != nil {
 		return nil, originalOffset, err
 	}
@@ -73,6 +76,7 @@ func consumeAssertRaise(parser *Parser, offset int) (*ast.AssertRaise, int, erro
 	assert := &ast.AssertRaise{
 		TypeOrValue: exprRight,
 		Pos:         parser.pos(originalOffset),
+		Message:     message,
 	}
 	if call, ok := exprLeft.(*ast.Call); ok {
 		assert.Call = call
@@ -83,3 +87,26 @@ func consumeAssertRaise(parser *Parser, offset int) (*ast.AssertRaise, int, erro
 
 	return assert, offset, nil
 }
+
+// consumeAssertMessage consumes the optional message and the closing
+// parenthesis of an assertion. The message will be nil if there is not one.
+func consumeAssertMessage(parser *Parser, offset int) (ast.Node, int, error) {
+	originalOffset := offset
+	var err error
+	var message ast.Node
+
+	offset, err = consume(parser, offset, []string{lexer.TokenComma})
+	if err == nil {
+		message, offset, err = consumeExpr(parser, offset, unlimitedTokens)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+	}
+
+	offset, err = consume(parser, offset, []string{lexer.TokenParenClose})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	return message, offset, nil
+}
@@ -27,6 +27,17 @@ func TestAssert(t *testing.T) {
 				),
 			},
 		},
+		"message": {
+			str: `assert(123 == 456, "must match")`,
+			expected: &ast.Assert{
+				Expr: asttest.NewBinary(
+					asttest.NewLiteralNumber("123"),
+					lexer.TokenEqual,
+					asttest.NewLiteralNumber("456"),
+				),
+				Message: asttest.NewLiteralString("must match"),
+			},
+		},
 		"not-binary": {
 			str:      "assert(false)",
 			expected: &ast.Assert{},
@@ -57,6 +68,16 @@ func TestAssert(t *testing.T) {
 				},
 			},
 		},
+		"raise-message": {
+			str: `assert(foo() raise Bar, "must raise")`,
+			expected: &ast.AssertRaise{
+				Call: &ast.Call{
+					Expr: &ast.Identifier{Name: "foo"},
+				},
+				TypeOrValue: &ast.Identifier{Name: "Bar"},
+				Message:     asttest.NewLiteralString("must raise"),
+			},
+		},
 	} {
 		t.Run(testName, func(t *testing.T) {
 			str := fmt.Sprintf("func main() { %s }", test.str)
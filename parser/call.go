@@ -3,6 +3,7 @@ package parser
 import (
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/types"
 )
 
 func consumeTypeCast(parser *Parser, offset int) (*ast.Call, int, error) {
@@ -12,6 +13,29 @@ func consumeTypeCast(parser *Parser, offset int) (*ast.Call, int, error) {
 	// This is picked up as a function call rather than a unary operation,
 	// although maybe moving it to unary and refactoring array/map makes more
 	// sense?
+	//
+	// An optional cast, such as "?string nil", keeps the type because it may be
+	// any type.
+	if parser.tokens[offset].Kind == lexer.TokenQuestion {
+		optional, offset, err := consumeType(parser, offset)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		expr, offset, err := consumeExpr(parser, offset, 1)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		call := &ast.Call{
+			Expr:          &ast.Identifier{Name: lexer.TokenQuestion},
+			TypeArguments: []*types.Type{optional.Element},
+			Arguments:     []ast.Node{expr},
+		}
+
+		return call, offset, nil
+	}
+
 	var ty lexer.Token
 	var err error
 	ty, offset, err = consumeOneOf(parser, offset, typeTokens)
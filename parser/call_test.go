@@ -7,6 +7,7 @@ import (
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/ast/asttest"
 	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/types"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -64,6 +65,25 @@ func TestCall(t *testing.T) {
 				},
 			},
 		},
+		"type-arguments": {
+			str: `Pair[number, []string]()`,
+			expected: &ast.Call{
+				Expr: &ast.Identifier{Name: "Pair"},
+				TypeArguments: []*types.Type{
+					types.Number,
+					types.StringArray,
+				},
+			},
+		},
+		"index-call": {
+			str: `fns[i]()`,
+			expected: &ast.Call{
+				Expr: &ast.Key{
+					Expr: &ast.Identifier{Name: "fns"},
+					Key:  &ast.Identifier{Name: "i"},
+				},
+			},
+		},
 	} {
 		t.Run(testName, func(t *testing.T) {
 			str := fmt.Sprintf("func main() { %s }", test.str)
@@ -78,3 +98,47 @@ func TestCall(t *testing.T) {
 		})
 	}
 }
+
+func TestCall_TypeArguments(t *testing.T) {
+	for testName, test := range map[string]struct {
+		str      string
+		expected *ast.Call
+	}{
+		"generic-func": {
+			str: `Stack[Point]()`,
+			expected: &ast.Call{
+				Expr: &ast.Identifier{Name: "Stack"},
+				TypeArguments: []*types.Type{
+					types.NewInterface("Point", map[string]*types.Type{}),
+				},
+			},
+		},
+		"not-generic-func": {
+			str: `Point[Stack]()`,
+			expected: &ast.Call{
+				Expr: &ast.Key{
+					Expr: &ast.Identifier{Name: "Point"},
+					Key:  &ast.Identifier{Name: "Stack"},
+				},
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			str := fmt.Sprintf("func main() { %s }\n", test.str) +
+				"func Point() Point {}\n" +
+				"func Stack[T]() Stack {}\n"
+			p := parser.NewParser(0)
+			p.ParseString(str, "a.ok")
+			assert.Empty(t, p.Errors().String())
+
+			err := p.ResolveTypes(types.Registry{}, nil)
+			assert.NoError(t, err)
+
+			for _, fn := range p.Funcs() {
+				if fn.Name == "main" {
+					asttest.AssertEqual(t, test.expected, fn.Statements[0])
+				}
+			}
+		})
+	}
+}
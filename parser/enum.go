@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/types"
+)
+
+func consumeEnum(parser *Parser, offset int) (*ast.Enum, int, error) {
+	originalOffset := offset
+	var err error
+
+	offset, err = consume(parser, offset, []string{
+		lexer.TokenEnum, lexer.TokenIdentifier, lexer.TokenCurlyOpen})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	node := &ast.Enum{
+		Name: parser.tokens[originalOffset+1].Value,
+		Pos:  parser.pos(originalOffset),
+	}
+
+	// Values can be separated by commas or new lines.
+	seen := map[string]bool{}
+	for parser.tokens[offset].Kind != lexer.TokenCurlyClose {
+		if len(node.Values) > 0 && parser.tokens[offset].Kind == lexer.TokenComma {
+			offset++ // skip ","
+		}
+
+		var value *ast.Identifier
+		value, offset, err = consumeIdentifier(parser, offset)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		if seen[value.Name] {
+			return nil, originalOffset, fmt.Errorf("%s has duplicate value %s",
+				node.Name, value.Name)
+		}
+
+		seen[value.Name] = true
+		node.Values = append(node.Values, value.Name)
+	}
+
+	offset++ // skip "}"
+
+	if len(node.Values) == 0 {
+		return nil, originalOffset, fmt.Errorf("%s must have at least one value",
+			node.Name)
+	}
+
+	return node, offset, nil
+}
+
+// isEnum returns true if name has been declared as an enum.
+func (parser *Parser) isEnum(name string) bool {
+	c, ok := parser.Constants[name]
+
+	return ok && c.Kind != nil && c.Kind.Kind == types.KindEnum
+}
+
+// isDeclared returns true if name is already used by a constant, enum or
+// function in the package.
+func (parser *Parser) isDeclared(name string) bool {
+	if _, ok := parser.Constants[name]; ok {
+		return true
+	}
+
+	for _, fn := range parser.funcs {
+		if fn.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
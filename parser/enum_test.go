@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/types"
+)
+
+func TestEnum(t *testing.T) {
+	for testName, test := range map[string]struct {
+		str       string
+		constants map[string]*ast.Literal
+		errs      []error
+	}{
+		"one-value": {
+			str: "enum Level { Debug }",
+			constants: map[string]*ast.Literal{
+				"Level": {Kind: types.NewEnum("Level", []string{"Debug"})},
+			},
+		},
+		"commas": {
+			str: "enum Level { Debug, Info, Warn }",
+			constants: map[string]*ast.Literal{
+				"Level": {
+					Kind: types.NewEnum("Level", []string{"Debug", "Info", "Warn"}),
+				},
+			},
+		},
+		"new-lines": {
+			str: "enum Level {\n    Debug\n    Info\n}",
+			constants: map[string]*ast.Literal{
+				"Level": {Kind: types.NewEnum("Level", []string{"Debug", "Info"})},
+			},
+		},
+		"empty": {
+			str: "enum Level {}",
+			errs: []error{
+				errors.New("a.ok:1:1 Level must have at least one value"),
+			},
+		},
+		"duplicate": {
+			str: "enum Level { Debug, Info, Debug }",
+			errs: []error{
+				errors.New("a.ok:1:1 Level has duplicate value Debug"),
+			},
+		},
+		"redeclared": {
+			str: "enum Level { Debug }\nenum Level { Info }",
+			constants: map[string]*ast.Literal{
+				"Level": {Kind: types.NewEnum("Level", []string{"Debug"})},
+			},
+			errs: []error{
+				errors.New("a.ok:2:1 Level is already declared"),
+			},
+		},
+		"constant-before": {
+			str: "Level = 1\nenum Level { Debug }",
+			constants: map[string]*ast.Literal{
+				"Level": asttest.NewLiteralNumber("1"),
+			},
+			errs: []error{
+				errors.New("a.ok:2:1 Level is already declared"),
+			},
+		},
+		"constant-after": {
+			str: "enum Level { Debug }\nLevel = 1",
+			constants: map[string]*ast.Literal{
+				"Level": {Kind: types.NewEnum("Level", []string{"Debug"})},
+			},
+			errs: []error{
+				errors.New("a.ok:2:1 Level is already declared"),
+			},
+		},
+		"func-before": {
+			str: "func Level() {}\nenum Level { Debug }",
+			errs: []error{
+				errors.New("a.ok:2:1 Level is already declared"),
+			},
+		},
+		"func-after": {
+			str: "enum Level { Debug }\nfunc Level() {}",
+			constants: map[string]*ast.Literal{
+				"Level": {Kind: types.NewEnum("Level", []string{"Debug"})},
+			},
+			errs: []error{
+				errors.New("a.ok:2:1 Level is already declared"),
+			},
+		},
+		"missing-name": {
+			str: "enum { Debug }",
+			errs: []error{
+				errors.New("a.ok:1:1 expecting identifier after enum, but found {"),
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			p := parser.NewParser(0)
+			p.ParseString(test.str, "a.ok")
+
+			assertEqualErrors(t, test.errs, p.Errors())
+			if test.constants == nil {
+				test.constants = map[string]*ast.Literal{}
+			}
+			asttest.AssertEqual(t, test.constants, p.Constants)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/ast/asttest"
 	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/types"
 )
 
 // unlimitedTokens is just some very large amount to be used when you do not
@@ -40,7 +41,7 @@ func consumeExpr(parser *Parser, offset, maxTokens int) (ast.Node, int, error) {
 				lexer.TokenDivide, lexer.TokenRemainder,
 
 				// Logical
-				lexer.TokenAnd, lexer.TokenOr,
+				lexer.TokenAnd, lexer.TokenOr, lexer.TokenNilCoalesce,
 
 				// Comparison
 				lexer.TokenEqual, lexer.TokenNotEqual,
@@ -56,6 +57,19 @@ func consumeExpr(parser *Parser, offset, maxTokens int) (ast.Node, int, error) {
 				offset++
 				continue
 
+			case lexer.TokenIs:
+				// Only "x is nil" and "x is not nil" are expressions. Checking
+				// for a type can only be the condition of an if.
+				var isNil ast.Node
+				isNil, offset, err = consumeIsNil(parser, offset)
+				if err != nil {
+					break
+				}
+
+				parts = append(parts, tok, isNil)
+				consumed++
+				continue
+
 			default:
 				break
 			}
@@ -192,6 +206,26 @@ func consumeChainedExpr(parser *Parser, offset int) (ast.Node, int, error) {
 		}
 
 		if tok.Kind == lexer.TokenSquareOpen {
+			// Explicit type arguments for a generic function, such as
+			// "Stack[number]()". A single name is ambiguous with accessing
+			// an element, like "funcs[i]()", so that is left as a key and
+			// resolved later (see Parser.resolveTypeArguments).
+			typeArgs, typeArgsOffset, err := consumeTypeArguments(parser, offset)
+			if err == nil &&
+				parser.tokens[typeArgsOffset].Kind == lexer.TokenParenOpen &&
+				!isName(typeArgs) {
+				var call *ast.Call
+				call, offset, err = consumeCall(parser, typeArgsOffset)
+				if err != nil {
+					return nil, originalOffset, err
+				}
+
+				call.Expr = expr
+				call.TypeArguments = typeArgs
+				expr = call
+				continue
+			}
+
 			offset++ // skip "["
 
 			var key ast.Node
@@ -241,6 +275,14 @@ func consumeChainedExpr(parser *Parser, offset int) (ast.Node, int, error) {
 	return expr, offset, nil
 }
 
+// isName returns true if the types are a single name that could also be a
+// variable.
+func isName(tys []*types.Type) bool {
+	return len(tys) == 1 &&
+		tys[0].Kind == types.KindUnresolvedInterface &&
+		len(tys[0].TypeArguments) == 0
+}
+
 func consumeExprs(parser *Parser, offset int) ([]ast.Node, int, error) {
 	// There must always be one expression.
 	var expr ast.Node
@@ -288,15 +330,18 @@ var operatorPrecedence = map[string]int{
 	lexer.TokenGreaterThanEqual: 4,
 	lexer.TokenLessThan:         4,
 	lexer.TokenLessThanEqual:    4,
+	lexer.TokenIs:               4,
+
+	lexer.TokenNilCoalesce: 5,
 
-	lexer.TokenPlus:  5,
-	lexer.TokenMinus: 5,
+	lexer.TokenPlus:  6,
+	lexer.TokenMinus: 6,
 
-	lexer.TokenTimes:     6,
-	lexer.TokenDivide:    6,
-	lexer.TokenRemainder: 6,
+	lexer.TokenTimes:     7,
+	lexer.TokenDivide:    7,
+	lexer.TokenRemainder: 7,
 
-	lexer.TokenDot: 7,
+	lexer.TokenDot: 8,
 }
 
 func reduceExpr(parts []interface{}) ast.Node {
@@ -305,11 +350,8 @@ func reduceExpr(parts []interface{}) ast.Node {
 	}
 
 	if len(parts) == 3 {
-		return &ast.Binary{
-			Left:  parts[0].(ast.Node),
-			Op:    parts[1].(lexer.Token).Kind,
-			Right: parts[2].(ast.Node),
-		}
+		return newBinary(parts[0].(ast.Node), parts[1].(lexer.Token),
+			parts[2].(ast.Node))
 	}
 
 	// We have to find the lowest precedence token to split on.
@@ -324,9 +366,26 @@ func reduceExpr(parts []interface{}) ast.Node {
 		}
 	}
 
+	return newBinary(reduceExpr(parts[:winner]), parts[winner].(lexer.Token),
+		reduceExpr(parts[winner+1:]))
+}
+
+func newBinary(left ast.Node, op lexer.Token, right ast.Node) ast.Node {
+	// "x is not nil" is the same as "not (x is nil)". See consumeIsNil.
+	if unary, ok := right.(*ast.Unary); ok && op.Kind == lexer.TokenIs {
+		unary.Expr = &ast.Binary{
+			Left:  left,
+			Op:    op.Kind,
+			Right: unary.Expr,
+		}
+		unary.Pos = left.Position()
+
+		return unary
+	}
+
 	return &ast.Binary{
-		Left:  reduceExpr(parts[:winner]),
-		Op:    parts[winner].(lexer.Token).Kind,
-		Right: reduceExpr(parts[winner+1:]),
+		Left:  left,
+		Op:    op.Kind,
+		Right: right,
 	}
 }
@@ -69,6 +69,80 @@ func TestExpr(t *testing.T) {
 				Right: asttest.NewLiteralNumber("-4"),
 			},
 		},
+		"literal-nil": {
+			str:      `nil`,
+			expected: asttest.NewLiteralNil(),
+		},
+		"nil-coalesce": {
+			str: `a ?? b ?? 3 + 2`,
+			expected: &ast.Binary{
+				Left: &ast.Identifier{Name: "a"},
+				Op:   lexer.TokenNilCoalesce,
+				Right: &ast.Binary{
+					Left: &ast.Identifier{Name: "b"},
+					Op:   lexer.TokenNilCoalesce,
+					Right: &ast.Binary{
+						Left:  asttest.NewLiteralNumber("3"),
+						Op:    lexer.TokenPlus,
+						Right: asttest.NewLiteralNumber("2"),
+					},
+				},
+			},
+		},
+		"is-nil": {
+			str: `a is nil`,
+			expected: &ast.Binary{
+				Left:  &ast.Identifier{Name: "a"},
+				Op:    lexer.TokenIs,
+				Right: &ast.Identifier{Name: "nil"},
+			},
+		},
+		"is-not-nil-and": {
+			str: `a is not nil and len(a) > 0`,
+			expected: &ast.Binary{
+				Left: &ast.Unary{
+					Op: lexer.TokenNot,
+					Expr: &ast.Binary{
+						Left:  &ast.Identifier{Name: "a"},
+						Op:    lexer.TokenIs,
+						Right: &ast.Identifier{Name: "nil"},
+					},
+				},
+				Op: lexer.TokenAnd,
+				Right: &ast.Binary{
+					Left: &ast.Call{
+						Expr:      &ast.Identifier{Name: "len"},
+						Arguments: []ast.Node{&ast.Identifier{Name: "a"}},
+					},
+					Op:    lexer.TokenGreaterThan,
+					Right: asttest.NewLiteralNumber("0"),
+				},
+			},
+		},
+		"is-nil-argument": {
+			str: `print(a.b is nil)`,
+			expected: &ast.Call{
+				Expr: &ast.Identifier{Name: "print"},
+				Arguments: []ast.Node{
+					&ast.Binary{
+						Left: &ast.Key{
+							Expr: &ast.Identifier{Name: "a"},
+							Key:  asttest.NewLiteralString("b"),
+						},
+						Op:    lexer.TokenIs,
+						Right: &ast.Identifier{Name: "nil"},
+					},
+				},
+			},
+		},
+		"optional-cast": {
+			str: `?string nil`,
+			expected: &ast.Call{
+				Expr:          &ast.Identifier{Name: "?"},
+				TypeArguments: []*types.Type{types.String},
+				Arguments:     []ast.Node{asttest.NewLiteralNil()},
+			},
+		},
 		"numbers-plus": {
 			str: `3 + 2`,
 			expected: &ast.Binary{
@@ -28,6 +28,11 @@ func consumeFunc(parser *Parser, offset int) (_ *ast.Func, _ int, anon bool, fin
 	offset, err = consume(parser, offset, []string{lexer.TokenIdentifier})
 	if err == nil {
 		fn.Name = parser.tokens[offset-1].Value
+
+		fn.TypeParameters, offset, err = consumeTypeParameters(parser, offset)
+		if err != nil {
+			return nil, originalOffset, anon, err
+		}
 	} else {
 		anon = true
 	}
@@ -74,6 +79,41 @@ func consumeFunc(parser *Parser, offset int) (_ *ast.Func, _ int, anon bool, fin
 	return fn, offset, anon, nil
 }
 
+// consumeTypeParameters consumes the optional type parameters of a generic
+// function, such as "[T, U]".
+func consumeTypeParameters(parser *Parser, offset int) ([]string, int, error) {
+	originalOffset := offset
+
+	if parser.tokens[offset].Kind != lexer.TokenSquareOpen {
+		return nil, offset, nil
+	}
+	offset++ // skip "["
+
+	var names []string
+	for {
+		var err error
+		offset, err = consume(parser, offset, []string{lexer.TokenIdentifier})
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		names = append(names, parser.tokens[offset-1].Value)
+
+		if parser.tokens[offset].Kind != lexer.TokenComma {
+			break
+		} else {
+			offset++ // skip ","
+		}
+	}
+
+	offset, err := consume(parser, offset, []string{lexer.TokenSquareClose})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	return names, offset, nil
+}
+
 func consumeArguments(parser *Parser, offset int) ([]*ast.Argument, int, error) {
 	originalOffset := offset
 
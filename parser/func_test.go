@@ -284,6 +284,34 @@ func TestFunc(t *testing.T) {
 				},
 			},
 		},
+		"type-parameters": {
+			str: "func Map[T, U](items []T, fn func(T) U) []U {}",
+			expected: map[string]*ast.Func{
+				"1": {
+					Name:           "Map",
+					TypeParameters: []string{"T", "U"},
+					Arguments: []*ast.Argument{
+						{Name: "items", Type: types.TypeFromString("[]T")},
+						{Name: "fn", Type: types.TypeFromString("func(T) U")},
+					},
+					Returns: []*types.Type{
+						types.TypeFromString("[]U"),
+					},
+				},
+			},
+		},
+		"type-arguments": {
+			str: "func foo(s Stack[number], m {}Pair[string, []bool]) {}",
+			expected: map[string]*ast.Func{
+				"1": {
+					Name: "foo",
+					Arguments: []*ast.Argument{
+						{Name: "s", Type: types.TypeFromString("Stack[number]")},
+						{Name: "m", Type: types.TypeFromString("{}Pair[string, []bool]")},
+					},
+				},
+			},
+		},
 	} {
 		t.Run(testName, func(t *testing.T) {
 			p := parser.NewParser(0)
@@ -19,14 +19,17 @@ func consumeIf(parser *Parser, offset int) (*ast.If, int, error) {
 		Pos: parser.pos(originalOffset),
 	}
 
-	// Only if is allowed to contain the special binary condition using "is".
+	// Only if is allowed to contain the special binary condition using "is"
+	// with a type. It must be the whole condition, since the compiler will
+	// treat the variable as that type within the True scope.
 	//
 	// TODO(elliot): In this future this could go anywhere a boolean is
-	//  accepted. However, right now its more difficult because the compiler
-	//  will treat the variable as that type within the True scope.
+	//  accepted.
+	conditionOffset := offset
 	node.Condition, offset, err = consumeIs(parser, offset)
-	if err != nil {
-		node.Condition, offset, err = consumeExpr(parser, offset, unlimitedTokens)
+	if err != nil || parser.tokens[offset].Kind != lexer.TokenCurlyOpen {
+		node.Condition, offset, err = consumeExpr(parser, conditionOffset,
+			unlimitedTokens)
 		if err != nil {
 			return nil, offset, err
 		}
@@ -51,7 +54,8 @@ func consumeIf(parser *Parser, offset int) (*ast.If, int, error) {
 	return node, offset, nil
 }
 
-func consumeIs(parser *Parser, offset int) (*ast.Binary, int, error) {
+// consumeIs consumes "x is T" or "x is not T". T may also be nil.
+func consumeIs(parser *Parser, offset int) (ast.Node, int, error) {
 	originalOffset := offset
 	var err error
 	var left ast.Node
@@ -65,15 +69,61 @@ func consumeIs(parser *Parser, offset int) (*ast.Binary, int, error) {
 		return nil, originalOffset, err
 	}
 
-	var ty *types.Type
-	ty, offset, err = consumeType(parser, offset)
+	not, offset, _ := consumeOneOf(parser, offset, []string{lexer.TokenNot})
+
+	ty := types.Nil
+	offset, err = consume(parser, offset, []string{lexer.TokenNil})
 	if err != nil {
-		return nil, originalOffset, err
+		ty, offset, err = consumeType(parser, offset)
+		if err != nil {
+			return nil, originalOffset, err
+		}
 	}
 
-	return &ast.Binary{
+	var node ast.Node = &ast.Binary{
 		Left:  left,
 		Op:    lexer.TokenIs,
 		Right: &ast.Identifier{Name: ty.String()},
-	}, offset, nil
+	}
+
+	if not.Kind != "" {
+		node = &ast.Unary{
+			Op:   lexer.TokenNot,
+			Expr: node,
+			Pos:  left.Position(),
+		}
+	}
+
+	return node, offset, nil
+}
+
+// consumeIsNil consumes the "is nil" or "is not nil" that follows an
+// expression. The result is the right side of the "is" binary expression. For
+// "is not nil" it is wrapped in a "not" that is applied to the whole binary
+// expression by newBinary.
+func consumeIsNil(parser *Parser, offset int) (ast.Node, int, error) {
+	originalOffset := offset
+	var err error
+
+	offset, err = consume(parser, offset, []string{lexer.TokenIs})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	not, offset, _ := consumeOneOf(parser, offset, []string{lexer.TokenNot})
+
+	offset, err = consume(parser, offset, []string{lexer.TokenNil})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	var node ast.Node = &ast.Identifier{Name: types.Nil.String()}
+	if not.Kind != "" {
+		node = &ast.Unary{
+			Op:   lexer.TokenNot,
+			Expr: node,
+		}
+	}
+
+	return node, offset, nil
 }
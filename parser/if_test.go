@@ -127,6 +127,56 @@ func TestIf(t *testing.T) {
 				},
 			},
 		},
+		"if-is-nil": {
+			str: "if a is nil { }",
+			expected: &ast.If{
+				Condition: &ast.Binary{
+					Left:  &ast.Identifier{Name: "a"},
+					Op:    lexer.TokenIs,
+					Right: &ast.Identifier{Name: "nil"},
+				},
+			},
+		},
+		"if-is-not-nil": {
+			str: "if a is not nil { }",
+			expected: &ast.If{
+				Condition: &ast.Unary{
+					Op: lexer.TokenNot,
+					Expr: &ast.Binary{
+						Left:  &ast.Identifier{Name: "a"},
+						Op:    lexer.TokenIs,
+						Right: &ast.Identifier{Name: "nil"},
+					},
+				},
+			},
+		},
+		"if-is-not-nil-and": {
+			str: "if a is not nil and b { }",
+			expected: &ast.If{
+				Condition: &ast.Binary{
+					Left: &ast.Unary{
+						Op: lexer.TokenNot,
+						Expr: &ast.Binary{
+							Left:  &ast.Identifier{Name: "a"},
+							Op:    lexer.TokenIs,
+							Right: &ast.Identifier{Name: "nil"},
+						},
+					},
+					Op:    lexer.TokenAnd,
+					Right: &ast.Identifier{Name: "b"},
+				},
+			},
+		},
+		"if-is-optional": {
+			str: "if a is ?number { }",
+			expected: &ast.If{
+				Condition: &ast.Binary{
+					Left:  &ast.Identifier{Name: "a"},
+					Op:    lexer.TokenIs,
+					Right: &ast.Identifier{Name: "?number"},
+				},
+			},
+		},
 	} {
 		t.Run(testName, func(t *testing.T) {
 			str := fmt.Sprintf("func main() { %s }", test.str)
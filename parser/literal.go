@@ -93,6 +93,13 @@ func consumeLiteral(parser *Parser, offset int) (*ast.Literal, int, error) {
 		}
 	}
 
+	if t := parser.tokens[offset]; t.Kind == lexer.TokenNil && unary.Kind == "" {
+		return &ast.Literal{
+			Kind: types.Nil,
+			Pos:  parser.pos(originalOffset),
+		}, offset + 1, nil
+	}
+
 	if unary.Kind != "" {
 
 	}
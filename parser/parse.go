@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 
@@ -33,6 +34,7 @@ func (parser *Parser) ParseString(s string, fileName string) {
 
 	var offset int
 	for {
+		originalOffset := offset
 		switch parser.tokens[offset].Kind {
 		case lexer.TokenIdentifier:
 			var name string
@@ -44,8 +46,13 @@ func (parser *Parser) ParseString(s string, fileName string) {
 				goto done
 			}
 
-			// TODO(elliot): Check for redefinition.
-			parser.Constants[name] = value
+			if parser.isEnum(name) {
+				parser.appendErrorAt(parser.pos(originalOffset),
+					fmt.Sprintf("%s is already declared", name))
+			} else {
+				// TODO(elliot): Check for redefinition.
+				parser.Constants[name] = value
+			}
 
 		case lexer.TokenFunc:
 			// TODO(elliot): Check for already declared functions.
@@ -58,9 +65,33 @@ func (parser *Parser) ParseString(s string, fileName string) {
 				goto done
 			}
 
+			if fn.Name != "" && parser.isEnum(fn.Name) {
+				parser.appendErrorf(fn, "%s is already declared", fn.Name)
+			}
+
 			parser.funcs[fn.UniqueName] = fn
 
-		case lexer.TokenTest:
+		case lexer.TokenEnum:
+			var enum *ast.Enum
+			enum, offset, err = consumeEnum(parser, offset)
+			if err != nil {
+				parser.appendErrorAt(parser.pos(offset), err.Error())
+
+				goto done
+			}
+
+			if parser.isDeclared(enum.Name) {
+				parser.appendErrorf(enum, "%s is already declared", enum.Name)
+
+				continue
+			}
+
+			parser.Constants[enum.Name] = &ast.Literal{
+				Kind: enum.Type(),
+				Pos:  enum.Pos,
+			}
+
+		case lexer.TokenTest, lexer.TokenBench, lexer.TokenFuzz:
 			var t *ast.Test
 			t, offset, err = consumeTest(parser, offset)
 			if err != nil {
@@ -70,6 +101,16 @@ func (parser *Parser) ParseString(s string, fileName string) {
 			}
 			parser.tests = append(parser.tests, t)
 
+		case lexer.TokenSetup, lexer.TokenTeardown:
+			var hook *ast.Hook
+			hook, offset, err = consumeHook(parser, offset)
+			if err != nil {
+				parser.appendError(hook, err.Error())
+
+				goto done
+			}
+			parser.hooks = append(parser.hooks, hook)
+
 		case lexer.TokenImport:
 			var imp *ast.Import
 			imp, offset, err = consumeImport(parser, offset)
@@ -79,6 +120,7 @@ func (parser *Parser) ParseString(s string, fileName string) {
 				goto done
 			}
 			parser.imports[imp.VariableName] = imp.PackageName
+			parser.importDecls = append(parser.importDecls, imp)
 
 		case lexer.TokenEOF:
 			goto done
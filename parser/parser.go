@@ -15,11 +15,17 @@ type Parser struct {
 	errors        []error
 	funcs         map[string]*ast.Func
 	tests         []*ast.Test
+	hooks         []*ast.Hook
 	finalizers    map[string][]*ast.Finally
 	functionNames []string
 	imports       map[string]string
+	importDecls   []*ast.Import
 	comments      []*ast.Comment
 
+	// typeParameters are the type parameters of the generic functions that are
+	// having their types resolved.
+	typeParameters []string
+
 	// TODO(elliot): The anonFunctionName is a pretty hacky way to ensure
 	//  separate parsers do not issue the same anonymous function names. This is
 	//  a problem because the result from different parsers are merged together.
@@ -31,6 +37,9 @@ type Parser struct {
 	// Constants are variables defined at the package level. They cannot be
 	// modified and only allow literals for values.
 	//
+	// An enum is also a constant. Its Kind is the enum type and it does not
+	// have a value.
+	//
 	// TODO(elliot): We should allow for expressions that can be resolved at
 	//  compile time, such as "3600 * 24".
 	Constants map[string]*ast.Literal
@@ -164,10 +173,22 @@ func (parser *Parser) Tests() []*ast.Test {
 	return parser.tests
 }
 
+// Hooks returns the setup and teardown blocks in the order they were parsed.
+func (parser *Parser) Hooks() []*ast.Hook {
+	return parser.hooks
+}
+
 func (parser *Parser) Imports() map[string]string {
 	return parser.imports
 }
 
+// ImportDecls returns each of the imports in the order they were parsed. Unlike
+// Imports, the same package may appear more than once if it is imported by
+// multiple files.
+func (parser *Parser) ImportDecls() []*ast.Import {
+	return parser.importDecls
+}
+
 // Comments returns all comments collected from parsing all inputs.
 func (parser *Parser) Comments() []*ast.Comment {
 	return parser.comments
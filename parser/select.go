@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/lexer"
+)
+
+func consumeSelectCase(parser *Parser, offset int) (*ast.SelectCase, int, error) {
+	var err error
+	originalOffset := offset
+
+	offset, err = consume(parser, offset, []string{lexer.TokenCase})
+	if err != nil {
+		return nil, offset, err
+	}
+
+	node := &ast.SelectCase{
+		Pos: parser.pos(originalOffset),
+	}
+
+	// The value received may be assigned to a variable.
+	var assign *ast.Assign
+	assign, offset, err = consumeAssign(parser, offset)
+	if err == nil {
+		node.Op = assign
+
+		_, isIdent := assign.Lefts[0].(*ast.Identifier)
+		_, isCall := assign.Rights[0].(*ast.Call)
+		if len(assign.Lefts) != 1 || len(assign.Rights) != 1 || !isIdent ||
+			!isCall {
+			parser.appendError(node,
+				"select case can only assign one variable from Receive")
+		}
+	} else {
+		node.Op, offset, err = consumeExpr(parser, offset, unlimitedTokens)
+		if err != nil {
+			return nil, offset, err
+		}
+
+		if _, ok := node.Op.(*ast.Call); !ok {
+			parser.appendError(node,
+				"select case must call Send or Receive on a channel")
+		}
+	}
+
+	node.Statements, offset, err = consumeBlock(parser, offset)
+	if err != nil {
+		return nil, offset, err
+	}
+
+	return node, offset, nil
+}
+
+func consumeSelect(parser *Parser, offset int) (*ast.Select, int, error) {
+	var err error
+	originalOffset := offset
+
+	offset, err = consume(parser, offset, []string{lexer.TokenSelect,
+		lexer.TokenCurlyOpen})
+	if err != nil {
+		return nil, offset, err
+	}
+
+	node := &ast.Select{
+		Pos: parser.pos(originalOffset),
+	}
+
+	for {
+		// Else is optional, but it must be the last.
+		if parser.tokens[offset].Kind == lexer.TokenElse {
+			offset++ // skip else
+
+			node.Else, offset, err = consumeBlock(parser, offset)
+			if err != nil {
+				return nil, offset, err
+			}
+
+			// An empty else still means the select does not wait.
+			if node.Else == nil {
+				node.Else = []ast.Node{}
+			}
+		}
+
+		var selectCase *ast.SelectCase
+		selectCase, offset, err = consumeSelectCase(parser, offset)
+		if err != nil {
+			break
+		}
+
+		node.Cases = append(node.Cases, selectCase)
+	}
+
+	offset, err = consume(parser, offset, []string{lexer.TokenCurlyClose})
+	if err != nil {
+		return nil, offset, err
+	}
+
+	return node, offset, nil
+}
@@ -0,0 +1,129 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/parser"
+)
+
+func TestSelect(t *testing.T) {
+	receive := &ast.Call{
+		Expr: &ast.Key{
+			Expr: &ast.Identifier{Name: "a"},
+			Key:  asttest.NewLiteralString("Receive"),
+		},
+	}
+	send := &ast.Call{
+		Expr: &ast.Key{
+			Expr: &ast.Identifier{Name: "b"},
+			Key:  asttest.NewLiteralString("Send"),
+		},
+		Arguments: []ast.Node{asttest.NewLiteralNumber("1")},
+	}
+
+	for testName, test := range map[string]struct {
+		str      string
+		expected *ast.Func
+		errs     []error
+	}{
+		"empty": {
+			str:      "func main() { select {} }",
+			expected: newFunc(&ast.Select{}),
+		},
+		"receive": {
+			str: "func main() { select { case a.Receive() { x() } } }",
+			expected: newFunc(
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{
+							Op: receive,
+							Statements: []ast.Node{
+								&ast.Call{Expr: &ast.Identifier{Name: "x"}},
+							},
+						},
+					},
+				},
+			),
+		},
+		"assign-and-send": {
+			str: "func main() { select { case v = a.Receive() {} case b.Send(1) {} } }",
+			expected: newFunc(
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{
+							Op: &ast.Assign{
+								Lefts:  []ast.Node{&ast.Identifier{Name: "v"}},
+								Rights: []ast.Node{receive},
+							},
+						},
+						{
+							Op: send,
+						},
+					},
+				},
+			),
+		},
+		"else": {
+			str: "func main() { select { case b.Send(1) {} else {} } }",
+			expected: newFunc(
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{
+							Op: send,
+						},
+					},
+					Else: []ast.Node{},
+				},
+			),
+		},
+		"assign-two": {
+			str: "func main() { select { case v, w = a.Receive() {} } }",
+			expected: newFunc(
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{
+							Op: &ast.Assign{
+								Lefts: []ast.Node{
+									&ast.Identifier{Name: "v"},
+									&ast.Identifier{Name: "w"},
+								},
+								Rights: []ast.Node{receive},
+							},
+						},
+					},
+				},
+			),
+			errs: []error{
+				errors.New("a.ok:1:24 select case can only assign one variable from Receive"),
+			},
+		},
+		"not-a-call": {
+			str: "func main() { select { case a {} } }",
+			expected: newFunc(
+				&ast.Select{
+					Cases: []*ast.SelectCase{
+						{
+							Op: &ast.Identifier{Name: "a"},
+						},
+					},
+				},
+			),
+			errs: []error{
+				errors.New("a.ok:1:24 select case must call Send or Receive on a channel"),
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			p := parser.NewParser(0)
+			p.ParseString(test.str, "a.ok")
+
+			assertEqualErrors(t, test.errs, p.Errors())
+			asttest.AssertEqual(t, map[string]*ast.Func{
+				"1": test.expected,
+			}, p.Funcs())
+		})
+	}
+}
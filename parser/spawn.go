@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/lexer"
+)
+
+func consumeSpawn(parser *Parser, offset int) (*ast.Spawn, int, error) {
+	var err error
+	originalOffset := offset
+
+	offset, err = consume(parser, offset, []string{lexer.TokenSpawn})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	node := &ast.Spawn{
+		Pos: parser.pos(originalOffset),
+	}
+
+	var expr ast.Node
+	expr, offset, err = consumeExpr(parser, offset, unlimitedTokens)
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	if call, ok := expr.(*ast.Call); ok {
+		node.Call = call
+	} else {
+		parser.appendError(node, "spawn must be followed by a function call")
+	}
+
+	return node, offset, nil
+}
@@ -0,0 +1,63 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/parser"
+)
+
+func TestSpawn(t *testing.T) {
+	for testName, test := range map[string]struct {
+		str      string
+		expected *ast.Func
+		errs     []error
+	}{
+		"no-arguments": {
+			str: "func main() { spawn work() }",
+			expected: newFunc(
+				&ast.Spawn{
+					Call: &ast.Call{
+						Expr: &ast.Identifier{Name: "work"},
+					},
+				},
+			),
+		},
+		"arguments": {
+			str: `func main() { spawn jobs.Run(1, "a") }`,
+			expected: newFunc(
+				&ast.Spawn{
+					Call: &ast.Call{
+						Expr: &ast.Key{
+							Expr: &ast.Identifier{Name: "jobs"},
+							Key:  asttest.NewLiteralString("Run"),
+						},
+						Arguments: []ast.Node{
+							asttest.NewLiteralNumber("1"),
+							asttest.NewLiteralString("a"),
+						},
+					},
+				},
+			),
+		},
+		"not-a-call": {
+			str:      "func main() { spawn work }",
+			expected: newFunc(&ast.Spawn{}),
+			errs: []error{
+				errors.New("a.ok:1:15 spawn must be followed by a function call"),
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			p := parser.NewParser(0)
+			p.ParseString(test.str, "a.ok")
+
+			assertEqualErrors(t, test.errs, p.Errors())
+			asttest.AssertEqual(t, map[string]*ast.Func{
+				"1": test.expected,
+			}, p.Funcs())
+		})
+	}
+}
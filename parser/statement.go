@@ -103,6 +103,18 @@ func consumeStatement(parser *Parser, offset int) (_ ast.Node, _ int, hoist bool
 		return switchStmt, offset, hoist, nil
 	}
 
+	var selectStmt *ast.Select
+	selectStmt, offset, err = consumeSelect(parser, offset)
+	if err == nil {
+		return selectStmt, offset, hoist, nil
+	}
+
+	var spawn *ast.Spawn
+	spawn, offset, err = consumeSpawn(parser, offset)
+	if err == nil {
+		return spawn, offset, hoist, nil
+	}
+
 	var expr ast.Node
 	expr, offset, err = consumeExpr(parser, offset, unlimitedTokens)
 	if err == nil {
@@ -67,6 +67,12 @@ func consumeSwitch(parser *Parser, offset int) (*ast.Switch, int, error) {
 			if err != nil {
 				return nil, offset, err
 			}
+
+			// An empty else is still needed to know that the switch does not
+			// have to handle every value of an enum.
+			if node.Else == nil {
+				node.Else = []ast.Node{}
+			}
 		}
 
 		var caseStmt *ast.Case
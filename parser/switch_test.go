@@ -91,7 +91,7 @@ func TestSwitch(t *testing.T) {
 							},
 						},
 					},
-					Else: []ast.Node(nil),
+					Else: []ast.Node{},
 				},
 			),
 		},
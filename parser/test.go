@@ -5,19 +5,56 @@ import (
 	"github.com/elliotchance/ok/lexer"
 )
 
+// consumeTest consumes a test, benchmark or fuzz target, depending on the
+// keyword.
 func consumeTest(parser *Parser, offset int) (*ast.Test, int, error) {
 	originalOffset := offset
 	var err error
 
+	keyword := lexer.TokenTest
+	switch parser.tokens[offset].Kind {
+	case lexer.TokenBench, lexer.TokenFuzz:
+		keyword = parser.tokens[offset].Kind
+	}
+
 	offset, err = consume(parser, offset, []string{
-		lexer.TokenTest, lexer.TokenStringLiteral})
+		keyword, lexer.TokenStringLiteral})
 	if err != nil {
 		return nil, originalOffset, err
 	}
 
 	t := &ast.Test{
-		Name: parser.tokens[offset-1].Value,
-		Pos:  parser.pos(originalOffset),
+		Name:    parser.tokens[offset-1].Value,
+		Pos:     parser.pos(originalOffset),
+		IsBench: keyword == lexer.TokenBench,
+		IsFuzz:  keyword == lexer.TokenFuzz,
+	}
+
+	// Only fuzz targets have arguments, and they are required.
+	if t.IsFuzz {
+		offset, err = consume(parser, offset, []string{lexer.TokenParenOpen})
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		t.Arguments, offset, err = consumeArguments(parser, offset)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		offset, err = consume(parser, offset, []string{lexer.TokenParenClose})
+		if err != nil {
+			return nil, originalOffset, err
+		}
+	}
+
+	// Only tests may have cases.
+	if keyword == lexer.TokenTest &&
+		parser.tokens[offset].Kind == lexer.TokenFor {
+		t.Cases, offset, err = consumeIn(parser, offset+1)
+		if err != nil {
+			return nil, originalOffset, err
+		}
 	}
 
 	t.Statements, offset, err = consumeBlock(parser, offset)
@@ -27,3 +64,21 @@ func consumeTest(parser *Parser, offset int) (*ast.Test, int, error) {
 
 	return t, offset, nil
 }
+
+// consumeHook consumes a setup or teardown block.
+func consumeHook(parser *Parser, offset int) (*ast.Hook, int, error) {
+	originalOffset := offset
+	var err error
+
+	hook := &ast.Hook{
+		IsTeardown: parser.tokens[offset].Kind == lexer.TokenTeardown,
+		Pos:        parser.pos(originalOffset),
+	}
+
+	hook.Statements, offset, err = consumeBlock(parser, offset+1)
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	return hook, offset, nil
+}
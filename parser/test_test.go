@@ -6,6 +6,7 @@ import (
 	"github.com/elliotchance/ok/ast"
 	"github.com/elliotchance/ok/ast/asttest"
 	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/types"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +23,50 @@ func TestTest(t *testing.T) {
 				Name: "foo bar",
 			},
 		},
+		"bench": {
+			str: `bench "foo bar" {}`,
+			expected: &ast.Test{
+				Name:    "foo bar",
+				IsBench: true,
+			},
+		},
+		"fuzz": {
+			str: `fuzz "foo"(s string, n number) {}`,
+			expected: &ast.Test{
+				Name:   "foo",
+				IsFuzz: true,
+				Arguments: []*ast.Argument{
+					{Name: "s", Type: types.String},
+					{Name: "n", Type: types.Number},
+				},
+			},
+		},
+		"cases": {
+			str: `test "foo" for c, name in cases {}`,
+			expected: &ast.Test{
+				Name: "foo",
+				Cases: &ast.In{
+					Value: "c",
+					Key:   "name",
+					Expr:  &ast.Identifier{Name: "cases"},
+				},
+			},
+		},
+		"cases-without-key": {
+			str: `test "foo" for c in [1, 2] {}`,
+			expected: &ast.Test{
+				Name: "foo",
+				Cases: &ast.In{
+					Value: "c",
+					Expr: &ast.Array{
+						Elements: []ast.Node{
+							asttest.NewLiteralNumber("1"),
+							asttest.NewLiteralNumber("2"),
+						},
+					},
+				},
+			},
+		},
 	} {
 		t.Run(testName, func(t *testing.T) {
 			p := parser.NewParser(0)
@@ -33,3 +78,73 @@ func TestTest(t *testing.T) {
 		})
 	}
 }
+
+func TestHook(t *testing.T) {
+	for testName, test := range map[string]struct {
+		str      string
+		expected []*ast.Hook
+	}{
+		"setup": {
+			str: `setup { a = 1 }`,
+			expected: []*ast.Hook{
+				{
+					Statements: []ast.Node{
+						&ast.Assign{
+							Lefts: []ast.Node{
+								&ast.Identifier{Name: "a"},
+							},
+							Rights: []ast.Node{
+								asttest.NewLiteralNumber("1"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"setup-and-teardown": {
+			str: `setup {}
+teardown {}`,
+			expected: []*ast.Hook{
+				{},
+				{IsTeardown: true},
+			},
+		},
+		"setup-variable": {
+			str: `setup { setup = 1 }`,
+			expected: []*ast.Hook{
+				{
+					Statements: []ast.Node{
+						&ast.Assign{
+							Lefts: []ast.Node{
+								&ast.Identifier{Name: "setup"},
+							},
+							Rights: []ast.Node{
+								asttest.NewLiteralNumber("1"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"setup-func": {
+			str: `func setup() {}
+setup { setup() }`,
+			expected: []*ast.Hook{
+				{
+					Statements: []ast.Node{
+						&ast.Call{Expr: &ast.Identifier{Name: "setup"}},
+					},
+				},
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			p := parser.NewParser(0)
+			p.ParseString(test.str, "a.ok")
+
+			assert.Nil(t, p.Errors())
+			asttest.AssertEqual(t, test.expected, p.Hooks())
+			assert.Len(t, p.Tests(), 0)
+		})
+	}
+}
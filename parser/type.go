@@ -17,7 +17,18 @@ var typeTokens = []string{
 	lexer.TokenString,
 }
 
-func consumeType(parser *Parser, offset int) (ty *types.Type, _ int, _ error) {
+func consumeType(parser *Parser, offset int) (*types.Type, int, error) {
+	return consumeTypeWith(parser, offset, true)
+}
+
+// consumeTypeWith can disable the type arguments of an object. This is only
+// needed when the type is followed by something that starts with "[", such
+// as the elements of "[]Stack [a, b]".
+func consumeTypeWith(
+	parser *Parser,
+	offset int,
+	typeArguments bool,
+) (ty *types.Type, _ int, _ error) {
 	originalOffset := offset
 	var defers []func()
 	defer func() {
@@ -81,6 +92,30 @@ done:
 		return types.NewFunc(args, fn.Returns), offset, nil
 	}
 
+	// An optional, such as "?string".
+	offset, err = consume(parser, offset, []string{lexer.TokenQuestion})
+	if err == nil {
+		var element *types.Type
+		element, offset, err = consumeTypeWith(parser, offset, typeArguments)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		return types.NewOptional(element), offset, nil
+	}
+
+	// A channel, such as "chan number".
+	offset, err = consume(parser, offset, []string{lexer.TokenChan})
+	if err == nil {
+		var element *types.Type
+		element, offset, err = consumeTypeWith(parser, offset, typeArguments)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		return types.NewChannel(element), offset, nil
+	}
+
 	var t lexer.Token
 	t, offset, err = consumeOneOf(parser, offset, typeTokens)
 	if err != nil {
@@ -104,12 +139,61 @@ done:
 			ident.Name += "." + ident2.Name
 		}
 
-		t.Kind = ident.Name
+		ty = types.TypeFromString(ident.Name)
+
+		// Type arguments for an object created by a generic constructor,
+		// such as "Stack[number]".
+		if typeArguments &&
+			parser.tokens[offset].Kind == lexer.TokenSquareOpen &&
+			parser.tokens[offset+1].Kind != lexer.TokenSquareClose {
+			var typeArgs []*types.Type
+			typeArgs, offset, err = consumeTypeArguments(parser, offset)
+			if err == nil {
+				ty.TypeArguments = typeArgs
+			}
+		}
+
+		return ty, offset, nil
 	}
 
 	return types.TypeFromString(strings.Split(t.Kind, " ")[0]), offset, nil
 }
 
+// consumeTypeArguments consumes one or more types between "[" and "]". The
+// original offset is returned if they cannot be consumed.
+func consumeTypeArguments(parser *Parser, offset int) ([]*types.Type, int, error) {
+	originalOffset := offset
+
+	offset, err := consume(parser, offset, []string{lexer.TokenSquareOpen})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	var tys []*types.Type
+	for {
+		var ty *types.Type
+		ty, offset, err = consumeType(parser, offset)
+		if err != nil {
+			return nil, originalOffset, err
+		}
+
+		tys = append(tys, ty)
+
+		if parser.tokens[offset].Kind != lexer.TokenComma {
+			break
+		} else {
+			offset++ // skip ","
+		}
+	}
+
+	offset, err = consume(parser, offset, []string{lexer.TokenSquareClose})
+	if err != nil {
+		return nil, originalOffset, err
+	}
+
+	return tys, offset, nil
+}
+
 func consumeTypes(parser *Parser, offset int, allowEmpty bool) ([]*types.Type, int, error) {
 	originalOffset := offset
 	var tys []*types.Type
@@ -73,6 +73,22 @@ func TestType(t *testing.T) {
 			str:      "[]func() (Foo,bar) []",
 			expected: &ast.Array{Kind: types.TypeFromString("[]func() (Foo, bar)")},
 		},
+		"optional-array": {
+			str:      "[]?number []",
+			expected: &ast.Array{Kind: types.TypeFromString("[]?number")},
+		},
+		"optional-map": {
+			str:      "{}? []Person {}",
+			expected: &ast.Map{Kind: types.TypeFromString("{}?[]Person")},
+		},
+		"channel-array": {
+			str:      "[]chan number []",
+			expected: &ast.Array{Kind: types.TypeFromString("[]chan number")},
+		},
+		"channel-map": {
+			str:      "{}chan []Person {}",
+			expected: &ast.Map{Kind: types.TypeFromString("{}chan []Person")},
+		},
 		"any-string": {
 			str: `any "foo"`,
 			expected: &ast.Call{
@@ -30,6 +30,18 @@ func (parser *Parser) ResolveTypes(
 		}
 	}
 
+	// Tests are not walked like functions, but the arguments of a fuzz target
+	// are needed to generate the values.
+	for _, test := range parser.tests {
+		for _, arg := range test.Arguments {
+			var err error
+			arg.Type, err = parser.ResolveType(test, arg.Type, registry, imports)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	for key := range parser.finalizers {
 		for i := range parser.finalizers[key] {
 			err := parser.resolveTypes(parser.finalizers[key][i], registry, imports)
@@ -52,6 +64,22 @@ func (parser *Parser) resolveTypes(
 		return nil
 	}
 
+	switch n := node.(type) {
+	case *ast.Func:
+		// Type parameters are visible to any function literals as well.
+		if len(n.TypeParameters) > 0 {
+			typeParameters := parser.typeParameters
+			parser.typeParameters = append(
+				append([]string(nil), typeParameters...), n.TypeParameters...)
+			defer func() {
+				parser.typeParameters = typeParameters
+			}()
+		}
+
+	case *ast.Call:
+		parser.resolveTypeArguments(n, imports)
+	}
+
 	var err error
 	for i := 0; i < elem.NumField(); i++ {
 		switch t := elem.Field(i).Interface().(type) {
@@ -116,7 +144,15 @@ func (parser *Parser) resolveTypes(
 				}
 			}
 
-		case nil, bool, string, int,
+		case []*ast.SelectCase:
+			for i := range t {
+				err = parser.resolveTypes(t[i], registry, imports)
+				if err != nil {
+					return err
+				}
+			}
+
+		case nil, bool, string, int, []string,
 			[]*ast.Literal, map[string]*ast.Literal,
 			*os.File, *bufio.Reader:
 			// These are all types that may appear in the parsers AST nodes that
@@ -148,7 +184,7 @@ func (parser *Parser) ResolveType(
 	var err error
 
 	switch typ.Kind {
-	case types.KindArray, types.KindMap:
+	case types.KindArray, types.KindMap, types.KindChannel, types.KindOptional:
 		typ.Element, err = parser.ResolveType(node, typ.Element, registry, imports)
 		if err != nil {
 			return nil, err
@@ -181,11 +217,32 @@ func (parser *Parser) ResolveType(
 		}
 
 	case types.KindUnresolvedInterface:
+		if len(typ.TypeArguments) == 0 && parser.isTypeParameter(typ.Name) {
+			return types.NewTypeParameter(typ.Name), nil
+		}
+
+		for i := range typ.TypeArguments {
+			typ.TypeArguments[i], err = parser.ResolveType(node,
+				typ.TypeArguments[i], registry, imports)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		// Check for imported type.
 		parts := strings.Split(typ.Name, ".")
 		if len(parts) == 2 {
 			// TODO(elliot): Check all these exist in the path.
-			return imports[parts[0]].Properties[parts[1]].Returns[0], nil
+			ty := imports[parser.imports[parts[0]]].Properties[parts[1]]
+			if ty.Kind == types.KindEnum {
+				return ty, nil
+			}
+
+			return instantiateInterface(node, typ, ty)
+		}
+
+		if c, ok := parser.Constants[typ.Name]; ok && c.Kind.Kind == types.KindEnum {
+			return c.Kind, nil
 		}
 
 		// Find the constructor.
@@ -201,13 +258,118 @@ func (parser *Parser) ResolveType(
 			return nil, fmt.Errorf("cannot find constructor for %s", typ.Name)
 		}
 
-		ty, err := constructorFn.Interface()
+		_, err := constructorFn.Interface()
 		if err != nil {
 			return typ, fmt.Errorf("%v %s", node.Position(), err)
 		}
 
-		return types.NewInterface(typ.Name, ty), nil
+		return instantiateInterface(node, typ, constructorFn.Type())
 	}
 
 	return typ, nil
 }
+
+// instantiateInterface returns the interface created by a constructor. The
+// type arguments of typ are applied if the constructor is generic.
+func instantiateInterface(
+	node ast.Node,
+	typ, constructor *types.Type,
+) (*types.Type, error) {
+	ty := constructor.Returns[0]
+
+	if len(typ.TypeArguments) == 0 {
+		return ty, nil
+	}
+
+	if len(constructor.TypeParameters) != len(typ.TypeArguments) {
+		return nil, fmt.Errorf("%v %s expects %d type arguments, got %d",
+			node.Position(), typ.Name, len(constructor.TypeParameters),
+			len(typ.TypeArguments))
+	}
+
+	typeArguments := map[string]*types.Type{}
+	for i, name := range constructor.TypeParameters {
+		typeArguments[name] = typ.TypeArguments[i]
+	}
+
+	return ty.Substitute(typeArguments), nil
+}
+
+func (parser *Parser) isTypeParameter(name string) bool {
+	for _, typeParameter := range parser.typeParameters {
+		if typeParameter == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveTypeArguments finds calls like "Stack[Point]()" that had to be parsed
+// as accessing an element (see consumeChainedExpr). Now that all functions are
+// known it can be decided if it was actually a generic function.
+func (parser *Parser) resolveTypeArguments(
+	call *ast.Call,
+	imports map[string]*types.Type,
+) {
+	key, ok := call.Expr.(*ast.Key)
+	if !ok || len(call.TypeArguments) > 0 {
+		return
+	}
+
+	name := typeName(key.Key)
+	if name == "" || !parser.isGenericFunc(key.Expr, imports) {
+		return
+	}
+
+	call.Expr = key.Expr
+	call.TypeArguments = []*types.Type{types.TypeFromString(name)}
+}
+
+// typeName returns the name of an object type when the expression can also be
+// read as a type, such as "Point" or "geometry.Point".
+func typeName(expr ast.Node) string {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return e.Name
+
+	case *ast.Key:
+		pkg, ok := e.Expr.(*ast.Identifier)
+		key, ok2 := e.Key.(*ast.Literal)
+		if ok && ok2 && key.Kind.Kind == types.KindString {
+			return pkg.Name + "." + key.Value
+		}
+	}
+
+	return ""
+}
+
+func (parser *Parser) isGenericFunc(
+	expr ast.Node,
+	imports map[string]*types.Type,
+) bool {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		for _, fn := range parser.funcs {
+			if fn.Name == e.Name {
+				return len(fn.TypeParameters) > 0
+			}
+		}
+
+		// The builtin function to create a channel, such as
+		// "channel[Point]()".
+		return e.Name == "channel"
+
+	case *ast.Key:
+		parts := strings.Split(typeName(e), ".")
+		if len(parts) == 2 {
+			if pkg, ok := imports[parser.imports[parts[0]]]; ok {
+				fn := pkg.Properties[parts[1]]
+
+				return fn != nil && len(fn.TypeParameters) > 0
+			}
+		}
+	}
+
+	return false
+}
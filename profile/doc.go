@@ -0,0 +1,7 @@
+// Package profile writes a vm.Profile in the pprof format so that it can be
+// read by "go tool pprof".
+//
+// The format is a gzipped protocol buffer described by profile.proto in
+// https://github.com/google/pprof. Only the parts that are needed are encoded
+// here to avoid a dependency.
+package profile
@@ -0,0 +1,66 @@
+package profile
+
+// buffer encodes protocol buffer messages.
+type buffer struct {
+	data []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *buffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *buffer) tag(field, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+// uint64 skips zero values, the same as proto3.
+func (b *buffer) uint64(field int, x uint64) {
+	if x != 0 {
+		b.tag(field, wireVarint)
+		b.varint(x)
+	}
+}
+
+func (b *buffer) int64(field int, x int64) {
+	b.uint64(field, uint64(x))
+}
+
+func (b *buffer) bool(field int, x bool) {
+	if x {
+		b.uint64(field, 1)
+	}
+}
+
+func (b *buffer) bytes(field int, x []byte) {
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(x)))
+	b.data = append(b.data, x...)
+}
+
+func (b *buffer) string(field int, x string) {
+	b.bytes(field, []byte(x))
+}
+
+func (b *buffer) message(field int, encode func(b *buffer)) {
+	var message buffer
+	encode(&message)
+	b.bytes(field, message.data)
+}
+
+// packed encodes repeated integers.
+func (b *buffer) packed(field int, xs []uint64) {
+	var packed buffer
+	for _, x := range xs {
+		packed.varint(x)
+	}
+	b.bytes(field, packed.data)
+}
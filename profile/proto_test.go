@@ -0,0 +1,55 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	for testName, test := range map[string]struct {
+		encode   func(b *buffer)
+		expected []byte
+	}{
+		"varint-small": {
+			encode:   func(b *buffer) { b.varint(1) },
+			expected: []byte{0x01},
+		},
+		"varint-large": {
+			encode:   func(b *buffer) { b.varint(300) },
+			expected: []byte{0xac, 0x02},
+		},
+		"uint64": {
+			encode:   func(b *buffer) { b.uint64(1, 150) },
+			expected: []byte{0x08, 0x96, 0x01},
+		},
+		"uint64-zero": {
+			encode:   func(b *buffer) { b.uint64(1, 0) },
+			expected: nil,
+		},
+		"bool": {
+			encode:   func(b *buffer) { b.bool(7, true) },
+			expected: []byte{0x38, 0x01},
+		},
+		"string": {
+			encode:   func(b *buffer) { b.string(2, "testing") },
+			expected: []byte{0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g'},
+		},
+		"message": {
+			encode: func(b *buffer) {
+				b.message(3, func(b *buffer) { b.uint64(1, 150) })
+			},
+			expected: []byte{0x1a, 0x03, 0x08, 0x96, 0x01},
+		},
+		"packed": {
+			encode:   func(b *buffer) { b.packed(4, []uint64{3, 270, 86942}) },
+			expected: []byte{0x22, 0x06, 0x03, 0x8e, 0x02, 0x9e, 0xa7, 0x05},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			var b buffer
+			test.encode(&b)
+			assert.Equal(t, test.expected, b.data)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliotchance/ok/vm"
+)
+
+// Field numbers from profile.proto.
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileMapping           = 3
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	mappingID             = 1
+	mappingFilename       = 5
+	mappingHasFunctions   = 7
+	mappingHasFilenames   = 8
+	mappingHasLineNumbers = 9
+
+	locationID        = 1
+	locationMappingID = 2
+	locationLine      = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+type location struct {
+	id, functionID uint64
+	line           int64
+}
+
+type function struct {
+	id             uint64
+	name, filename int64
+	systemName     int64
+	startLine      int64
+}
+
+// writer holds the tables that are referenced by samples.
+type writer struct {
+	strings     []string
+	stringIDs   map[string]int64
+	functions   []*function
+	functionIDs map[string]*function
+	locations   []*location
+	locationIDs map[string]*location
+}
+
+// Write writes the profile in the pprof format. There are two sample types,
+// the number of instructions executed and the wall time. The wall time is the
+// default.
+func Write(w io.Writer, p *vm.Profile, start time.Time, duration time.Duration) error {
+	pw := &writer{
+		stringIDs:   map[string]int64{},
+		functionIDs: map[string]*function{},
+		locationIDs: map[string]*location{},
+	}
+
+	// The first string must be empty.
+	pw.string("")
+
+	// Samples are sorted for consistent output.
+	var keys []string
+	for key := range p.Samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b buffer
+	b.message(profileSampleType, pw.valueType("instructions", "count"))
+	b.message(profileSampleType, pw.valueType("wall", "nanoseconds"))
+
+	for _, key := range keys {
+		sample := p.Samples[key]
+
+		var locationIDs []uint64
+		for _, frame := range sample.Frames {
+			locationIDs = append(locationIDs, pw.location(frame).id)
+		}
+
+		b.message(profileSample, func(b *buffer) {
+			b.packed(sampleLocationID, locationIDs)
+			b.packed(sampleValue, []uint64{
+				uint64(sample.Instructions),
+				uint64(sample.Duration.Nanoseconds()),
+			})
+		})
+	}
+
+	// Locations are not real addresses, so there is a single mapping that
+	// says the functions and lines are already known.
+	mappingName := pw.string("ok")
+	b.message(profileMapping, func(b *buffer) {
+		b.uint64(mappingID, 1)
+		b.int64(mappingFilename, mappingName)
+		b.bool(mappingHasFunctions, true)
+		b.bool(mappingHasFilenames, true)
+		b.bool(mappingHasLineNumbers, true)
+	})
+
+	for _, l := range pw.locations {
+		l := l
+		b.message(profileLocation, func(b *buffer) {
+			b.uint64(locationID, l.id)
+			b.uint64(locationMappingID, 1)
+			b.message(locationLine, func(b *buffer) {
+				b.uint64(lineFunctionID, l.functionID)
+				b.int64(lineLine, l.line)
+			})
+		})
+	}
+
+	for _, f := range pw.functions {
+		f := f
+		b.message(profileFunction, func(b *buffer) {
+			b.uint64(functionID, f.id)
+			b.int64(functionName, f.name)
+			b.int64(functionSystemName, f.systemName)
+			b.int64(functionFilename, f.filename)
+			b.int64(functionStartLine, f.startLine)
+		})
+	}
+
+	// The default sample type refers to the string table, so it must be added
+	// before the string table is written.
+	wall := pw.string("wall")
+	for _, s := range pw.strings {
+		b.string(profileStringTable, s)
+	}
+
+	b.int64(profileTimeNanos, start.UnixNano())
+	b.int64(profileDurationNanos, duration.Nanoseconds())
+	b.int64(profileDefaultSampleType, wall)
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b.data); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+func (pw *writer) string(s string) int64 {
+	if id, ok := pw.stringIDs[s]; ok {
+		return id
+	}
+
+	id := int64(len(pw.strings))
+	pw.strings = append(pw.strings, s)
+	pw.stringIDs[s] = id
+
+	return id
+}
+
+func (pw *writer) valueType(ty, unit string) func(b *buffer) {
+	tyID, unitID := pw.string(ty), pw.string(unit)
+
+	return func(b *buffer) {
+		b.int64(valueTypeType, tyID)
+		b.int64(valueTypeUnit, unitID)
+	}
+}
+
+func (pw *writer) location(frame vm.ProfileFrame) *location {
+	f := pw.function(frame)
+	_, line := splitPos(frame.Pos)
+	if line == 0 {
+		line = f.startLine
+	}
+
+	key := strconv.FormatUint(f.id, 10) + ":" + strconv.FormatInt(line, 10)
+	if l, ok := pw.locationIDs[key]; ok {
+		return l
+	}
+
+	l := &location{
+		id:         uint64(len(pw.locations) + 1),
+		functionID: f.id,
+		line:       line,
+	}
+	pw.locations = append(pw.locations, l)
+	pw.locationIDs[key] = l
+
+	return l
+}
+
+func (pw *writer) function(frame vm.ProfileFrame) *function {
+	// The unique names of functions are only unique within a package, but
+	// profiles can contain several packages.
+	key := frame.Func.Pos + "|" + frame.Func.Name
+	if f, ok := pw.functionIDs[key]; ok {
+		return f
+	}
+
+	filePath, startLine := splitPos(frame.Func.Pos)
+	f := &function{
+		id:         uint64(len(pw.functions) + 1),
+		name:       pw.string(funcName(frame)),
+		systemName: pw.string(frame.Func.UniqueName),
+		filename:   pw.string(filePath),
+		startLine:  startLine,
+	}
+	pw.functions = append(pw.functions, f)
+	pw.functionIDs[key] = f
+
+	return f
+}
+
+// funcName qualifies the name of the function with the package, like
+// "strings.Join". Function literals are named "func" and the initialization of
+// a package is the package name.
+func funcName(frame vm.ProfileFrame) string {
+	name := frame.Name
+	if name == "" {
+		name = "func"
+	}
+
+	root := frame.Func
+	for root.Parent != nil {
+		root = root.Parent
+	}
+
+	// Packages are named like "foo__bar" to be used as a variable.
+	packageName := strings.ReplaceAll(root.Name, "__", "/")
+
+	switch {
+	case root == frame.Func && root.Name != "":
+		return packageName
+
+	case root != frame.Func && root.Name != "":
+		return packageName + "." + name
+	}
+
+	return name
+}
+
+// splitPos returns the file path and line number of a position like
+// "/foo/bar.ok:12:3".
+func splitPos(pos string) (string, int64) {
+	parts := strings.Split(pos, ":")
+	if len(parts) < 3 {
+		return pos, 0
+	}
+
+	line, _ := strconv.ParseInt(parts[len(parts)-2], 10, 64)
+
+	return strings.Join(parts[:len(parts)-2], ":"), line
+}
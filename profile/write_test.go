@@ -0,0 +1,166 @@
+package profile_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/ok/compiler"
+	"github.com/elliotchance/ok/profile"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	pkg := &vm.CompiledFunc{
+		Name: "foo__bar",
+		Pos:  "/ok/foo/bar/main.ok:1:1",
+	}
+	add := &vm.CompiledFunc{
+		Name:   "add",
+		Pos:    "/ok/foo/bar/main.ok:3:1",
+		Parent: pkg,
+	}
+	literal := &vm.CompiledFunc{
+		Pos:    "/ok/foo/bar/main.ok:4:10",
+		Parent: add,
+	}
+
+	p := vm.NewProfile()
+	p.Samples["a"] = &vm.ProfileSample{
+		Frames: []vm.ProfileFrame{
+			{Func: literal, Pos: "/ok/foo/bar/main.ok:5:9"},
+			{Func: add, Name: "add", Pos: "/ok/foo/bar/main.ok:4:5"},
+			{Func: pkg, Name: "foo__bar"},
+		},
+		Instructions: 12,
+		Duration:     time.Millisecond,
+	}
+
+	out := bytes.NewBuffer(nil)
+	err := profile.Write(out, p, time.Unix(0, 0), time.Second)
+	require.NoError(t, err)
+
+	r, err := gzip.NewReader(out)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	for _, s := range []string{
+		"instructions", "count", "wall", "nanoseconds",
+		"foo/bar", "foo/bar.add", "foo/bar.func", "/ok/foo/bar/main.ok",
+	} {
+		assert.Contains(t, string(data), s)
+	}
+}
+
+// runProfile compiles the package "x/q" in okPath, loads it from the cache and
+// returns the profile of running it, and its uncompressed data.
+func runProfile(t *testing.T, okPath string) (*vm.Profile, string) {
+	defer os.Setenv("OKCACHE", os.Getenv("OKCACHE"))
+	require.NoError(t, os.Setenv("OKCACHE", filepath.Join(okPath, "cache")))
+
+	// Imported packages are only resolved correctly from $OKPATH.
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(okPath))
+	defer os.Chdir(wd)
+
+	file, pkgType, errs := compiler.Compile(okPath, "x/q", false, new(int),
+		false)
+	require.Empty(t, errs)
+
+	// The functions loaded from the cache must still know their parents to be
+	// named.
+	require.NoError(t, vm.Store(file, "x-q"))
+	file, err = vm.Load("x-q")
+	require.NoError(t, err)
+
+	p := vm.NewProfile()
+	m := vm.NewVM("no-package")
+	m.Stdout = ioutil.Discard
+	m.Profile = p
+	require.NoError(t, m.LoadFile(file))
+	require.NoError(t, m.Run("$"+pkgType.Name))
+	p.Stop()
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, profile.Write(out, p, time.Unix(0, 0), time.Second))
+
+	r, err := gzip.NewReader(out)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	return p, string(data)
+}
+
+func TestWrite_Cache(t *testing.T) {
+	okPath, err := ioutil.TempDir("", "ok")
+	require.NoError(t, err)
+	defer os.RemoveAll(okPath)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(okPath, "x", "q"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(okPath, "x", "q", "main.ok"),
+		[]byte(`func fib(n number) number {
+    if n < 2 {
+        return n
+    }
+
+    return fib(n - 1) + fib(n - 2)
+}
+
+func main() {
+    print(fib(5))
+}
+`), 0644))
+
+	_, data := runProfile(t, okPath)
+	for _, s := range []string{"x/q.fib", "x/q.main"} {
+		assert.Contains(t, data, s)
+	}
+}
+
+func TestWrite_Import(t *testing.T) {
+	okPath, err := ioutil.TempDir("", "ok")
+	require.NoError(t, err)
+	defer os.RemoveAll(okPath)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(okPath, "x", "q"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(okPath, "x", "r"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(okPath, "x", "q", "main.ok"),
+		[]byte(`import "x/r"
+
+func main() {
+    print(r.Double(5))
+}
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(okPath, "x", "r", "r.ok"),
+		[]byte(`func Double(n number) number {
+    return n * 2
+}
+`), 0644))
+
+	p, data := runProfile(t, okPath)
+	for _, s := range []string{"x/q.main", "x/r.Double"} {
+		assert.Contains(t, data, s)
+	}
+
+	// The packages have been initialized before main is called, so main must
+	// be the outermost function.
+	var found bool
+	for _, sample := range p.Samples {
+		for i, frame := range sample.Frames {
+			if frame.Func.Name == "main" {
+				found = true
+				assert.Equal(t, len(sample.Frames)-1, i)
+			}
+		}
+	}
+	assert.True(t, found)
+}
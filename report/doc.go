@@ -0,0 +1,7 @@
+// Package report records the results of "ok test" so that they can be read by
+// other tools, such as CI dashboards.
+//
+// Results can be streamed as JSON events, in a similar format to
+// "go test -json", and written as a JUnit XML file once all of the tests have
+// finished.
+package report
@@ -0,0 +1,46 @@
+package report
+
+import "time"
+
+// These are the values for Event.Action.
+const (
+	// ActionRun is sent when a test starts. Pos is the position of the test.
+	ActionRun = "run"
+
+	// ActionAssert is sent for every assertion. Output is the assertion, such
+	// as "assert(3 == 4)", and Failed will be true if it did not pass.
+	ActionAssert = "assert"
+
+	// ActionError is sent when a test finishes with an unhandled error.
+	// Output is the error and Stack is where it was raised.
+	ActionError = "error"
+
+	// ActionOutput is sent for each line that is printed. This includes the
+	// failure messages that are usually printed by "ok test".
+	ActionOutput = "output"
+
+	// ActionPass and ActionFail are sent when a test finishes. They are also
+	// sent, without a Test, when all of the tests in a package finish.
+	ActionPass = "pass"
+	ActionFail = "fail"
+)
+
+// Event is a single line of the JSON output. It has the same fields as
+// "go test -json", with some additions for assertions and errors.
+type Event struct {
+	Time    time.Time
+	Action  string
+	Package string   `json:",omitempty"`
+	Test    string   `json:",omitempty"`
+	Pos     string   `json:",omitempty"`
+	Elapsed *float64 `json:",omitempty"`
+	Output  string   `json:",omitempty"`
+	Failed  bool     `json:",omitempty"`
+	Stack   []string `json:",omitempty"`
+}
+
+func seconds(d time.Duration) *float64 {
+	s := d.Seconds()
+
+	return &s
+}
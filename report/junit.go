@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	SystemOut *junitOutput    `xml:"system-out,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      string        `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",cdata"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+// WriteJUnit writes the results of all packages in the JUnit XML format. Each
+// package is a test suite.
+func (r *Recorder) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{}
+	var elapsed time.Duration
+	for _, pkg := range r.Packages {
+		suite := junitTestSuite{
+			Name:      pkg.Name,
+			Time:      junitTime(pkg.Elapsed),
+			SystemOut: newJUnitOutput(pkg.Output),
+		}
+
+		for _, test := range pkg.Tests {
+			file, line := splitPos(test.Pos)
+			testCase := junitTestCase{
+				Name:      test.Name,
+				ClassName: pkg.Name,
+				File:      file,
+				Line:      line,
+				Time:      junitTime(test.Elapsed),
+				SystemOut: newJUnitOutput(test.Output),
+			}
+
+			if len(test.Failures) > 0 {
+				var lines []string
+				for _, failure := range test.Failures {
+					lines = append(lines, fmt.Sprintf("%s: %s failed",
+						failure.Pos, failure.Message))
+				}
+
+				testCase.Failure = &junitFailure{
+					Message: test.Failures[0].Message + " failed",
+					Text:    strings.Join(lines, "\n"),
+				}
+				suite.Failures++
+			}
+
+			if test.Error != nil {
+				testCase.Error = &junitFailure{
+					Message: test.Error.Message,
+					Text:    strings.Join(test.Error.Stack, "\n"),
+				}
+				suite.Errors++
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+		elapsed += pkg.Elapsed
+	}
+	suites.Time = junitTime(elapsed)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func newJUnitOutput(output string) *junitOutput {
+	if output == "" {
+		return nil
+	}
+
+	return &junitOutput{Text: output}
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// splitPos returns the file and line of a position like "a.okt:12:5".
+func splitPos(pos string) (string, string) {
+	parts := strings.Split(pos, ":")
+	if len(parts) < 3 {
+		return pos, ""
+	}
+
+	return strings.Join(parts[:len(parts)-2], ":"), parts[len(parts)-2]
+}
@@ -0,0 +1,267 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/elliotchance/ok/vm"
+)
+
+// Package is the results of the tests in a single package.
+type Package struct {
+	Name    string
+	Tests   []*Result
+	Passed  bool
+	Elapsed time.Duration
+
+	// Output is anything printed outside of a test.
+	Output string
+
+	startTime time.Time
+}
+
+// Result is the outcome of a single test.
+type Result struct {
+	Name    string
+	Pos     string
+	Passed  bool
+	Elapsed time.Duration
+
+	// Failures are the assertions that failed, in the order that they
+	// happened.
+	Failures []*Failure
+
+	// Error is the unhandled error that the test finished with, if any.
+	Error *Failure
+
+	// Output is anything printed while the test was running.
+	Output string
+}
+
+// Failure is a failed assertion or unhandled error.
+type Failure struct {
+	// Pos is the position of the assertion. It is empty for an error, see
+	// Stack instead.
+	Pos string
+
+	Message string
+
+	// Stack is the innermost function first. It is only used for errors.
+	Stack []string
+}
+
+// Recorder implements vm.TestReporter. It keeps the results for every package
+// and will also stream them as events, if required.
+type Recorder struct {
+	Packages []*Package
+
+	events  *json.Encoder
+	current *Result
+	output  []byte
+
+	// now can be replaced in tests.
+	now func() time.Time
+}
+
+// NewRecorder creates a recorder. If events is not nil, each event will be
+// written to it as a line of JSON.
+func NewRecorder(events io.Writer) *Recorder {
+	r := &Recorder{
+		now: time.Now,
+	}
+
+	if events != nil {
+		r.events = json.NewEncoder(events)
+	}
+
+	return r
+}
+
+// StartPackage must be called before the tests for each package are run.
+func (r *Recorder) StartPackage(name string) {
+	r.Packages = append(r.Packages, &Package{
+		Name:      name,
+		startTime: r.now(),
+	})
+}
+
+// FinishPackage must be called after the tests for each package have run.
+func (r *Recorder) FinishPackage(passed bool) {
+	r.flushOutput()
+
+	pkg := r.pkg()
+	pkg.Passed = passed
+	pkg.Elapsed = r.now().Sub(pkg.startTime)
+
+	action := ActionPass
+	if !passed {
+		action = ActionFail
+	}
+
+	r.emit(&Event{
+		Action:  action,
+		Elapsed: seconds(pkg.Elapsed),
+	})
+}
+
+// TestStarted implements vm.TestReporter.
+func (r *Recorder) TestStarted(test *vm.CompiledTest) {
+	r.current = &Result{
+		Name: test.TestName,
+		Pos:  relativePos(test.Pos),
+	}
+	r.pkg().Tests = append(r.pkg().Tests, r.current)
+
+	r.emit(&Event{
+		Action: ActionRun,
+		Pos:    r.current.Pos,
+	})
+}
+
+// Assertion implements vm.TestReporter.
+func (r *Recorder) Assertion(
+	test *vm.CompiledTest,
+	pos string,
+	passed bool,
+	message string,
+) {
+	pos = relativePos(pos)
+	if !passed {
+		r.current.Failures = append(r.current.Failures, &Failure{
+			Pos:     pos,
+			Message: message,
+		})
+	}
+
+	r.emit(&Event{
+		Action: ActionAssert,
+		Pos:    pos,
+		Output: message,
+		Failed: !passed,
+	})
+}
+
+// TestErrored implements vm.TestReporter.
+func (r *Recorder) TestErrored(
+	test *vm.CompiledTest,
+	message string,
+	stack []string,
+) {
+	r.current.Error = &Failure{
+		Message: message,
+		Stack:   stack,
+	}
+
+	r.emit(&Event{
+		Action: ActionError,
+		Output: message,
+		Stack:  stack,
+	})
+}
+
+// TestFinished implements vm.TestReporter.
+func (r *Recorder) TestFinished(
+	test *vm.CompiledTest,
+	passed bool,
+	elapsed time.Duration,
+) {
+	r.flushOutput()
+
+	r.current.Passed = passed
+	r.current.Elapsed = elapsed
+
+	action := ActionPass
+	if !passed {
+		action = ActionFail
+	}
+
+	r.emit(&Event{
+		Action:  action,
+		Elapsed: seconds(elapsed),
+	})
+	r.current = nil
+}
+
+// Output returns a writer for anything printed while running tests. Each line
+// is kept with the test that is running (or the package) and is also sent as
+// an "output" event.
+func (r *Recorder) Output() io.Writer {
+	return outputWriter{r}
+}
+
+type outputWriter struct {
+	r *Recorder
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	w.r.output = append(w.r.output, p...)
+
+	for {
+		i := bytes.IndexByte(w.r.output, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.r.writeOutput(string(w.r.output[:i+1]))
+		w.r.output = w.r.output[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// flushOutput sends a partial line that would otherwise end up in the next
+// test.
+func (r *Recorder) flushOutput() {
+	if len(r.output) > 0 {
+		r.writeOutput(string(r.output))
+		r.output = nil
+	}
+}
+
+func (r *Recorder) writeOutput(line string) {
+	if r.current != nil {
+		r.current.Output += line
+	} else if len(r.Packages) > 0 {
+		r.pkg().Output += line
+	}
+
+	r.emit(&Event{
+		Action: ActionOutput,
+		Output: line,
+	})
+}
+
+// relativePos removes the current directory from pos, the same as the failure
+// messages printed by "ok test".
+func relativePos(pos string) string {
+	wd, _ := os.Getwd()
+
+	return strings.TrimPrefix(pos, wd)
+}
+
+func (r *Recorder) pkg() *Package {
+	return r.Packages[len(r.Packages)-1]
+}
+
+// emit fills in the time, package and test of the event before writing it.
+func (r *Recorder) emit(event *Event) {
+	if r.events == nil {
+		return
+	}
+
+	event.Time = r.now()
+	if len(r.Packages) > 0 {
+		event.Package = r.pkg().Name
+	}
+	if r.current != nil {
+		event.Test = r.current.Name
+	}
+
+	// There is nothing useful that can be done if the events cannot be
+	// written.
+	_ = r.events.Encode(event)
+}
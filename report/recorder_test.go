@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// record runs the same events that the VM would for a package with a passing
+// test, a failing test and a test with an unhandled error.
+func record(r *Recorder) {
+	passing := &vm.CompiledTest{
+		CompiledFunc: &vm.CompiledFunc{Pos: "/ok/a.okt:1:1"},
+		TestName:     "passing",
+	}
+	failing := &vm.CompiledTest{
+		CompiledFunc: &vm.CompiledFunc{Pos: "/ok/a.okt:5:1"},
+		TestName:     "failing",
+	}
+	erroring := &vm.CompiledTest{
+		CompiledFunc: &vm.CompiledFunc{Pos: "/ok/b.okt:3:1"},
+		TestName:     "erroring",
+	}
+
+	r.StartPackage("foo")
+
+	r.TestStarted(passing)
+	r.Assertion(passing, "/ok/a.okt:2:5", true, "assert(1 == 1)")
+	r.TestFinished(passing, true, time.Millisecond)
+
+	r.TestStarted(failing)
+	fmt.Fprint(r.Output(), "hello")
+	fmt.Fprint(r.Output(), " world\npartial")
+	r.Assertion(failing, "/ok/a.okt:6:5", false, "assert(1 == 2)")
+	r.TestFinished(failing, false, 2*time.Millisecond)
+
+	r.TestStarted(erroring)
+	r.TestErrored(erroring, `Error: "uh oh"`,
+		[]string{"bar() at /ok/b.ok:2:5", `test "erroring"() at /ok/b.okt:4:5`})
+	r.TestFinished(erroring, false, 1500*time.Microsecond)
+
+	fmt.Fprintln(r.Output(), "foo: 2 failed 1 passed 2 asserts (4 ms)")
+	r.FinishPackage(false)
+}
+
+func TestRecorder_Events(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	r := NewRecorder(out)
+	r.now = func() time.Time {
+		return time.Unix(0, 0).UTC()
+	}
+	record(r)
+
+	prefix := `{"Time":"1970-01-01T00:00:00Z",`
+	assert.Equal(t, prefix+`"Action":"run","Package":"foo","Test":"passing","Pos":"/ok/a.okt:1:1"}
+`+prefix+`"Action":"assert","Package":"foo","Test":"passing","Pos":"/ok/a.okt:2:5","Output":"assert(1 == 1)"}
+`+prefix+`"Action":"pass","Package":"foo","Test":"passing","Elapsed":0.001}
+`+prefix+`"Action":"run","Package":"foo","Test":"failing","Pos":"/ok/a.okt:5:1"}
+`+prefix+`"Action":"output","Package":"foo","Test":"failing","Output":"hello world\n"}
+`+prefix+`"Action":"assert","Package":"foo","Test":"failing","Pos":"/ok/a.okt:6:5","Output":"assert(1 == 2)","Failed":true}
+`+prefix+`"Action":"output","Package":"foo","Test":"failing","Output":"partial"}
+`+prefix+`"Action":"fail","Package":"foo","Test":"failing","Elapsed":0.002}
+`+prefix+`"Action":"run","Package":"foo","Test":"erroring","Pos":"/ok/b.okt:3:1"}
+`+prefix+`"Action":"error","Package":"foo","Test":"erroring","Output":"Error: \"uh oh\"","Stack":["bar() at /ok/b.ok:2:5","test \"erroring\"() at /ok/b.okt:4:5"]}
+`+prefix+`"Action":"fail","Package":"foo","Test":"erroring","Elapsed":0.0015}
+`+prefix+`"Action":"output","Package":"foo","Output":"foo: 2 failed 1 passed 2 asserts (4 ms)\n"}
+`+prefix+`"Action":"fail","Package":"foo","Elapsed":0}
+`, out.String())
+}
+
+func TestRecorder_WriteJUnit(t *testing.T) {
+	r := NewRecorder(nil)
+	r.now = func() time.Time {
+		return time.Unix(0, 0)
+	}
+	record(r)
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, r.WriteJUnit(out))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" errors="1" time="0.000">
+  <testsuite name="foo" tests="3" failures="1" errors="1" time="0.000">
+    <testcase name="passing" classname="foo" file="/ok/a.okt" line="1" time="0.001"></testcase>
+    <testcase name="failing" classname="foo" file="/ok/a.okt" line="5" time="0.002">
+      <failure message="assert(1 == 2) failed"><![CDATA[/ok/a.okt:6:5: assert(1 == 2) failed]]></failure>
+      <system-out><![CDATA[hello world
+partial]]></system-out>
+    </testcase>
+    <testcase name="erroring" classname="foo" file="/ok/b.okt" line="3" time="0.002">
+      <error message="Error: &#34;uh oh&#34;"><![CDATA[bar() at /ok/b.ok:2:5
+test "erroring"() at /ok/b.okt:4:5]]></error>
+    </testcase>
+    <system-out><![CDATA[foo: 2 failed 1 passed 2 asserts (4 ms)
+]]></system-out>
+  </testsuite>
+</testsuites>
+`, out.String())
+}
@@ -7,6 +7,7 @@ var (
 	Data   = TypeFromString("data")
 	Number = TypeFromString("number")
 	String = TypeFromString("string")
+	Nil    = TypeFromString("nil")
 
 	AnyArray    = NewArray(Any)
 	BoolArray   = NewArray(Bool)
@@ -0,0 +1,185 @@
+package types
+
+// NewTypeParameter creates the type of a type parameter, such as "T" in
+// "func First[T](items []T) T".
+func NewTypeParameter(name string) *Type {
+	return &Type{
+		Kind: KindTypeParameter,
+		Name: name,
+	}
+}
+
+// Generic returns a copy of the function type with type parameters. Any
+// unresolved interface with the same name as one of the type parameters is
+// replaced with the type parameter.
+func (t *Type) Generic(typeParameters []string) *Type {
+	isTypeParameter := map[string]bool{}
+	for _, name := range typeParameters {
+		isTypeParameter[name] = true
+	}
+
+	ty := t.replace(func(ty *Type) *Type {
+		if ty.Kind == KindUnresolvedInterface && isTypeParameter[ty.Name] &&
+			len(ty.TypeArguments) == 0 {
+			return NewTypeParameter(ty.Name)
+		}
+
+		return nil
+	})
+	ty.TypeParameters = append([]string(nil), typeParameters...)
+
+	return ty
+}
+
+// Instantiate returns a copy of a generic function type where each of the type
+// parameters is replaced with its type argument. The returned type is not
+// generic.
+func (t *Type) Instantiate(typeArguments map[string]*Type) *Type {
+	ty := t.Substitute(typeArguments)
+	ty.TypeParameters = nil
+
+	return ty
+}
+
+// Substitute returns a copy of the type where each type parameter that has a
+// type argument is replaced with the type argument.
+func (t *Type) Substitute(typeArguments map[string]*Type) *Type {
+	return t.replace(func(ty *Type) *Type {
+		if ty.Kind == KindTypeParameter {
+			return typeArguments[ty.Name]
+		}
+
+		return nil
+	})
+}
+
+// Erase replaces every type parameter with "any". Type parameters only exist
+// when compiling, so this is the type that a value has at runtime.
+func (t *Type) Erase() *Type {
+	return t.replace(func(ty *Type) *Type {
+		if ty.Kind == KindTypeParameter {
+			return Any
+		}
+
+		return nil
+	})
+}
+
+// replace returns a copy of the type where t, and every type within t, is
+// replaced by the result of fn. The type is copied as normal when fn returns
+// nil.
+func (t *Type) replace(fn func(*Type) *Type) *Type {
+	if t == nil {
+		return nil
+	}
+
+	if ty := fn(t); ty != nil {
+		return ty.Copy()
+	}
+
+	ty := &Type{
+		Kind:    t.Kind,
+		Name:    t.Name,
+		Ref:     t.Ref,
+		Element: t.Element.replace(fn),
+	}
+
+	if len(t.TypeParameters) > 0 {
+		ty.TypeParameters = append([]string(nil), t.TypeParameters...)
+	}
+
+	if len(t.Values) > 0 {
+		ty.Values = append([]string(nil), t.Values...)
+	}
+
+	for _, v := range t.Arguments {
+		ty.Arguments = append(ty.Arguments, v.replace(fn))
+	}
+
+	for _, v := range t.Returns {
+		ty.Returns = append(ty.Returns, v.replace(fn))
+	}
+
+	for _, v := range t.TypeArguments {
+		ty.TypeArguments = append(ty.TypeArguments, v.replace(fn))
+	}
+
+	if len(t.Properties) > 0 {
+		ty.Properties = map[string]*Type{}
+
+		for k, v := range t.Properties {
+			ty.Properties[k] = v.replace(fn)
+		}
+	}
+
+	return ty
+}
+
+// Infer returns the type arguments for a call to a generic function by matching
+// the type of each argument with the type of its parameter. The first argument
+// that contains a type parameter decides its type. Type parameters that cannot
+// be inferred are not included.
+//
+// The arguments are not checked. That is done with Accepts after the function
+// type has been instantiated.
+func (registry Registry) Infer(params, args []*Type) map[string]*Type {
+	typeArguments := map[string]*Type{}
+	for i := 0; i < len(params) && i < len(args); i++ {
+		registry.infer(params[i], args[i], typeArguments)
+	}
+
+	return typeArguments
+}
+
+func (registry Registry) infer(param, arg *Type, typeArguments map[string]*Type) {
+	if param == nil || arg == nil {
+		return
+	}
+
+	if param.Ref != "" {
+		param = registry.Get(param.Ref)
+	}
+
+	if arg.Ref != "" {
+		arg = registry.Get(arg.Ref)
+	}
+
+	if param.Kind == KindTypeParameter {
+		if _, ok := typeArguments[param.Name]; !ok && !arg.isUnknown() {
+			typeArguments[param.Name] = arg
+		}
+
+		return
+	}
+
+	// A value that is not optional can be passed to an optional parameter.
+	if param.Kind == KindOptional && arg.Kind != KindOptional {
+		if arg.Kind != KindNil {
+			registry.infer(param.Element, arg, typeArguments)
+		}
+
+		return
+	}
+
+	if param.Kind != arg.Kind {
+		return
+	}
+
+	registry.infer(param.Element, arg.Element, typeArguments)
+
+	for i := 0; i < len(param.Arguments) && i < len(arg.Arguments); i++ {
+		registry.infer(param.Arguments[i], arg.Arguments[i], typeArguments)
+	}
+
+	for i := 0; i < len(param.Returns) && i < len(arg.Returns); i++ {
+		registry.infer(param.Returns[i], arg.Returns[i], typeArguments)
+	}
+
+	if param.Name == arg.Name {
+		for i := 0; i < len(param.TypeArguments) &&
+			i < len(arg.TypeArguments); i++ {
+			registry.infer(param.TypeArguments[i], arg.TypeArguments[i],
+				typeArguments)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/ok/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Infer(t *testing.T) {
+	for testName, test := range map[string]struct {
+		params, args []string
+		expected     map[string]string
+	}{
+		"none": {
+			params:   []string{"number"},
+			args:     []string{"number"},
+			expected: map[string]string{},
+		},
+		"type-parameter": {
+			params:   []string{"T"},
+			args:     []string{"string"},
+			expected: map[string]string{"T": "string"},
+		},
+		"element": {
+			params:   []string{"[]T", "{}U"},
+			args:     []string{"[]number", "{}bool"},
+			expected: map[string]string{"T": "number", "U": "bool"},
+		},
+		"func": {
+			params:   []string{"[]T", "func(T) U"},
+			args:     []string{"[]number", "func(number) string"},
+			expected: map[string]string{"T": "number", "U": "string"},
+		},
+		"first-wins": {
+			params:   []string{"T", "T"},
+			args:     []string{"number", "string"},
+			expected: map[string]string{"T": "number"},
+		},
+		"type-arguments": {
+			params:   []string{"Stack[T]"},
+			args:     []string{"Stack[[]char]"},
+			expected: map[string]string{"T": "[]char"},
+		},
+		"optional": {
+			params:   []string{"?T", "?U", "?T"},
+			args:     []string{"nil", "?bool", "number"},
+			expected: map[string]string{"T": "number", "U": "bool"},
+		},
+		"wrong-kind": {
+			params:   []string{"[]T"},
+			args:     []string{"{}number"},
+			expected: map[string]string{},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			var params, args []*types.Type
+			for _, param := range test.params {
+				params = append(params, types.TypeFromString(param))
+			}
+			for _, arg := range test.args {
+				args = append(args, types.TypeFromString(arg))
+			}
+
+			// The parameters must be converted into type parameters.
+			fn := types.NewFunc(params, nil).Generic([]string{"T", "U"})
+
+			typeArguments := types.Registry{}.Infer(fn.Arguments, args)
+			actual := map[string]string{}
+			for name, ty := range typeArguments {
+				actual[name] = ty.String()
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestType_Instantiate(t *testing.T) {
+	fn := types.TypeFromString("func[T, U]([]T, func(T) U) Stack[U]")
+	assert.Equal(t, "func[T, U]([]T, func(T) U) Stack[U]", fn.String())
+
+	actual := fn.Instantiate(map[string]*types.Type{
+		"T": types.Number,
+		"U": types.String,
+	})
+	assert.Equal(t, "func([]number, func(number) string) Stack[string]",
+		actual.String())
+
+	// The original type is not modified.
+	assert.Equal(t, "func[T, U]([]T, func(T) U) Stack[U]", fn.String())
+}
+
+func TestType_Erase(t *testing.T) {
+	ty := types.TypeFromString("func[T]({}T) func(T) T").Arguments[0]
+	assert.Equal(t, "{}T", ty.String())
+	assert.Equal(t, "{}any", ty.Erase().String())
+}
@@ -24,6 +24,27 @@ const (
 	KindArray
 	KindMap
 	KindFunc
+
+	// KindTypeParameter is a type parameter of a generic function, such as "T"
+	// in "func First[T](items []T) T". Name is the name of the type parameter.
+	KindTypeParameter
+
+	// KindEnum is a type declared with "enum". Name is the name of the enum
+	// and Values contains the name of each value, in the order they were
+	// declared.
+	KindEnum
+
+	// KindChannel is a channel, such as "chan number". Element is the type of
+	// the values sent through the channel.
+	KindChannel
+
+	// KindOptional is a value that may be nil, such as "?string". Element is
+	// the type of the value when it is not nil.
+	KindOptional
+
+	// KindNil is the type of the nil literal. It is accepted by any optional
+	// type.
+	KindNil
 )
 
 func kindFromString(s string) Kind {
@@ -45,6 +66,9 @@ func kindFromString(s string) Kind {
 
 	case "string":
 		return KindString
+
+	case "nil":
+		return KindNil
 	}
 
 	return KindUnresolvedInterface
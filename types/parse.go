@@ -16,7 +16,7 @@ func tokenize(ty string) []string {
 			continue
 		}
 
-		if ty[i] == '(' || ty[i] == ')' || ty[i] == ',' ||
+		if ty[i] == '(' || ty[i] == ')' || ty[i] == ',' || ty[i] == '?' ||
 			ty[i] == '[' || ty[i] == ']' ||
 			ty[i] == '{' || ty[i] == '}' {
 			if word != "" {
@@ -61,6 +61,20 @@ func parseType(tokens []string, offset int) (*Type, int) {
 			Kind:    KindMap,
 			Element: ty,
 		}, offset
+
+	case "chan":
+		offset++
+		var ty *Type
+		ty, offset = parseType(tokens, offset)
+
+		return NewChannel(ty), offset
+
+	case "?":
+		offset++
+		var ty *Type
+		ty, offset = parseType(tokens, offset)
+
+		return NewOptional(ty), offset
 	}
 
 	ty := &Type{
@@ -70,15 +84,47 @@ func parseType(tokens []string, offset int) (*Type, int) {
 	if ty.Kind == KindUnresolvedInterface {
 		ty.Name = tokens[offset]
 	}
+	offset++
+
+	// Type arguments, such as "Stack[number]".
+	if ty.Kind == KindUnresolvedInterface && offset < len(tokens) &&
+		tokens[offset] == "[" {
+		offset++ // skip "["
+		for tokens[offset] != "]" {
+			if tokens[offset] == "," {
+				offset++
+				continue
+			}
+
+			var typeArg *Type
+			typeArg, offset = parseType(tokens, offset)
+			ty.TypeArguments = append(ty.TypeArguments, typeArg)
+		}
+		offset++ // skip "]"
+	}
 
-	return ty, offset + 1
+	return ty, offset
 }
 
 func parseFunc(tokens []string, offset int) (*Type, int) {
 	ty := &Type{
 		Kind: KindFunc,
 	}
-	offset += 2 // skip "func" "("
+	offset++ // skip "func"
+
+	var typeParameters []string
+	if tokens[offset] == "[" {
+		offset++ // skip "["
+		for tokens[offset] != "]" {
+			if tokens[offset] != "," {
+				typeParameters = append(typeParameters, tokens[offset])
+			}
+			offset++
+		}
+		offset++ // skip "]"
+	}
+
+	offset++ // skip "("
 	for tokens[offset] != ")" {
 		if tokens[offset] == "," {
 			offset++
@@ -113,5 +159,9 @@ func parseFunc(tokens []string, offset int) (*Type, int) {
 		}
 	}
 
+	if len(typeParameters) > 0 {
+		ty = ty.Generic(typeParameters)
+	}
+
 	return ty, offset
 }
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"strings"
 )
 
 // A Registry holds a collection of unique types that can are referenced
@@ -22,7 +23,8 @@ func (registry Registry) EqualTypeSlices(a, b []*Type) bool {
 	return true
 }
 
-// EqualTypes ignores only the Name.
+// EqualTypes ignores only the Name, except for type parameters and enums which
+// are identified by their name.
 func (registry Registry) EqualTypes(a, b *Type) bool {
 	// This covers cases where we compare Element.
 	if a == nil && b == nil {
@@ -45,6 +47,22 @@ func (registry Registry) EqualTypes(a, b *Type) bool {
 		return false
 	}
 
+	if (a.Kind == KindTypeParameter || a.Kind == KindEnum) && a.Name != b.Name {
+		return false
+	}
+
+	if strings.Join(a.Values, ",") != strings.Join(b.Values, ",") {
+		return false
+	}
+
+	if strings.Join(a.TypeParameters, ",") != strings.Join(b.TypeParameters, ",") {
+		return false
+	}
+
+	if !registry.EqualTypeSlices(a.TypeArguments, b.TypeArguments) {
+		return false
+	}
+
 	if !registry.EqualTypes(a.Element, b.Element) {
 		return false
 	}
@@ -111,6 +129,15 @@ func (registry Registry) Add(ty *Type) (string, error) {
 		ty.Arguments[i] = NewRef(newType)
 	}
 
+	for i := range ty.TypeArguments {
+		newType, err := registry.Add(ty.TypeArguments[i])
+		if err != nil {
+			return "", err
+		}
+
+		ty.TypeArguments[i] = NewRef(newType)
+	}
+
 	for _, i := range ty.SortedPropertyNames() {
 		newType, err := registry.Add(ty.Properties[i])
 		if err != nil {
@@ -144,9 +171,139 @@ func (registry Registry) Get(key string) *Type {
 		ty.Returns[i] = registry.Get(ty.Returns[i].Ref)
 	}
 
+	for i := range ty.TypeArguments {
+		ty.TypeArguments[i] = registry.Get(ty.TypeArguments[i].Ref)
+	}
+
 	for i := range ty.Properties {
 		ty.Properties[i] = registry.Get(ty.Properties[i].Ref)
 	}
 
 	return ty
 }
+
+// Accepts returns true if a value of type arg can be used where a value of type
+// param is expected, such as passing an argument to a function.
+//
+// Any value is accepted by "any". An object is accepted by an interface if it
+// has all of the properties of the interface, with acceptable types. Types
+// that have not been resolved can only be compared by name, so they are
+// accepted by any interface. Objects created by the same generic constructor
+// must also have the same type arguments, so a "Stack[string]" is not accepted
+// by a "Stack[number]". An enum only accepts values of the same enum. A
+// channel only accepts a channel with the same element type. An optional
+// accepts nil and anything its element accepts, but an optional value is never
+// accepted where a value is required.
+func (registry Registry) Accepts(param, arg *Type) bool {
+	return registry.accepts(param, arg, map[[2]string]bool{})
+}
+
+// accepts uses checking to avoid infinite recursion for interfaces that refer
+// to themselves, such as a method that returns the same object. A pair of
+// interfaces that is already being checked is assumed to be acceptable.
+func (registry Registry) accepts(
+	param, arg *Type,
+	checking map[[2]string]bool,
+) bool {
+	if param == nil || arg == nil {
+		return true
+	}
+
+	if param.Ref != "" {
+		param = registry.Get(param.Ref)
+	}
+
+	if arg.Ref != "" {
+		arg = registry.Get(arg.Ref)
+	}
+
+	if param.Kind == KindAny || param.isUnknown() || arg.isUnknown() {
+		return true
+	}
+
+	if param.Kind == KindOptional {
+		switch arg.Kind {
+		case KindNil:
+			return true
+
+		case KindOptional:
+			return registry.accepts(param.Element, arg.Element, checking)
+		}
+
+		return registry.accepts(param.Element, arg, checking)
+	}
+
+	if param.isInterface() && arg.isInterface() {
+		if param.Name == arg.Name && len(param.TypeArguments) > 0 &&
+			len(arg.TypeArguments) > 0 {
+			return registry.EqualTypeSlices(param.TypeArguments,
+				arg.TypeArguments)
+		}
+
+		pair := [2]string{param.Name, arg.Name}
+		if param.Name == arg.Name || checking[pair] ||
+			param.Kind == KindUnresolvedInterface ||
+			arg.Kind == KindUnresolvedInterface {
+			return true
+		}
+
+		checking[pair] = true
+		for name, property := range param.Properties {
+			argProperty, ok := arg.Properties[name]
+			if !ok || !registry.accepts(property, argProperty, checking) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if param.Kind != arg.Kind {
+		return false
+	}
+
+	switch param.Kind {
+	case KindTypeParameter, KindEnum:
+		return param.Name == arg.Name
+
+	case KindArray, KindMap:
+		return registry.accepts(param.Element, arg.Element, checking)
+
+	case KindChannel:
+		// Values are both sent and received, so the elements must be the
+		// same.
+		return registry.EqualTypes(param.Element, arg.Element)
+
+	case KindFunc:
+		if len(param.Arguments) != len(arg.Arguments) ||
+			len(param.Returns) != len(arg.Returns) {
+			return false
+		}
+
+		// The arguments are reversed because the function will be called
+		// with the arguments of param.
+		for i := range param.Arguments {
+			if !registry.accepts(arg.Arguments[i], param.Arguments[i], checking) {
+				return false
+			}
+		}
+
+		for i := range param.Returns {
+			if !registry.accepts(param.Returns[i], arg.Returns[i], checking) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (t *Type) isInterface() bool {
+	return t.Kind == KindUnresolvedInterface || t.Kind == KindResolvedInterface
+}
+
+// isUnknown is true for an empty type. KindUnresolvedInterface is the zero
+// value for Kind, so a type without a name is not an interface.
+func (t *Type) isUnknown() bool {
+	return t.Kind == KindUnresolvedInterface && t.Name == ""
+}
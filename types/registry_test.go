@@ -125,6 +125,17 @@ func TestRegistry_Add(t *testing.T) {
 			}),
 		}, registry)
 	})
+
+	t.Run("enums with same values", func(t *testing.T) {
+		registry := types.Registry{}
+		registry.Add(types.NewEnum("Level", []string{"Low", "High"}))
+		registry.Add(types.NewEnum("Priority", []string{"Low", "High"}))
+		registry.Add(types.NewEnum("Level", []string{"Low", "High"}))
+		assert.Equal(t, types.Registry{
+			"0": types.NewEnum("Level", []string{"Low", "High"}),
+			"1": types.NewEnum("Priority", []string{"Low", "High"}),
+		}, registry)
+	})
 }
 
 func TestRegistry_Get(t *testing.T) {
@@ -154,3 +165,78 @@ func TestRegistry_Get(t *testing.T) {
 			registry.Get("2"))
 	})
 }
+
+func TestRegistry_Accepts(t *testing.T) {
+	greeter := types.NewInterface("Greeter", map[string]*types.Type{
+		"Greet": types.NewFunc(nil, []*types.Type{types.String}),
+	})
+	person := types.NewInterface("Person", map[string]*types.Type{
+		"Name":  types.String,
+		"Greet": types.NewFunc(nil, []*types.Type{types.String}),
+	})
+	counter := types.NewInterface("Counter", map[string]*types.Type{
+		"Greet": types.NewFunc(nil, []*types.Type{types.Number}),
+	})
+
+	stack := func(typeArgument *types.Type) *types.Type {
+		ty := types.NewInterface("Stack", map[string]*types.Type{
+			"Pop": types.NewFunc(nil, []*types.Type{typeArgument}),
+		})
+		ty.TypeArguments = []*types.Type{typeArgument}
+
+		return ty
+	}
+
+	level := types.NewEnum("Level", []string{"Low", "High"})
+	priority := types.NewEnum("Priority", []string{"Low", "High"})
+
+	for testName, test := range map[string]struct {
+		param, arg *types.Type
+		expected   bool
+	}{
+		"same":                  {types.Number, types.Number, true},
+		"different":             {types.Number, types.String, false},
+		"any":                   {types.Any, types.String, true},
+		"any-arg":               {types.String, types.Any, false},
+		"array":                 {types.NumberArray, types.NumberArray, true},
+		"array-element":         {types.NumberArray, types.StringArray, false},
+		"array-any":             {types.AnyArray, types.StringArray, true},
+		"map-array":             {types.NumberMap, types.NumberArray, false},
+		"interface":             {greeter, person, true},
+		"interface-missing":     {person, greeter, false},
+		"interface-wrong-type":  {greeter, counter, false},
+		"unresolved-interface":  {types.NewUnresolvedInterface("Greeter"), counter, true},
+		"interface-basic":       {greeter, types.String, false},
+		"func":                  {types.NewFunc([]*types.Type{types.Number}, nil), types.NewFunc([]*types.Type{types.Number}, nil), true},
+		"func-argument-count":   {types.NewFunc([]*types.Type{types.Number}, nil), types.NewFunc(nil, nil), false},
+		"func-return-type":      {types.NewFunc(nil, []*types.Type{types.Number}), types.NewFunc(nil, []*types.Type{types.String}), false},
+		"func-accepts-argument": {types.NewFunc([]*types.Type{types.Number}, nil), types.NewFunc([]*types.Type{types.Any}, nil), true},
+		"type-parameter":        {types.NewTypeParameter("T"), types.NewTypeParameter("T"), true},
+		"type-parameter-other":  {types.NewTypeParameter("T"), types.NewTypeParameter("U"), false},
+		"type-parameter-basic":  {types.NewTypeParameter("T"), types.Number, false},
+		"type-parameter-any":    {types.Any, types.NewTypeParameter("T"), true},
+		"type-arguments":        {stack(types.Number), stack(types.Number), true},
+		"type-arguments-differ": {stack(types.Number), stack(types.String), false},
+		"enum":                  {level, level, true},
+		"enum-other":            {level, priority, false},
+		"enum-string":           {level, types.String, false},
+		"enum-any":              {types.Any, level, true},
+		"channel":               {types.NewChannel(types.Number), types.NewChannel(types.Number), true},
+		"channel-element":       {types.NewChannel(types.Any), types.NewChannel(types.Number), false},
+		"channel-array":         {types.NumberArray, types.NewChannel(types.Number), false},
+		"optional":              {types.NewOptional(types.String), types.NewOptional(types.String), true},
+		"optional-value":        {types.NewOptional(types.String), types.String, true},
+		"optional-nil":          {types.NewOptional(types.String), types.Nil, true},
+		"optional-element":      {types.NewOptional(types.Any), types.NewOptional(types.Number), true},
+		"optional-wrong-value":  {types.NewOptional(types.String), types.Number, false},
+		"optional-required":     {types.String, types.NewOptional(types.String), false},
+		"optional-any":          {types.Any, types.NewOptional(types.String), true},
+		"nil-required":          {types.String, types.Nil, false},
+		"optional-interface":    {types.NewOptional(types.ErrorInterface), types.ErrorInterface, true},
+		"interface-optional":    {types.ErrorInterface, types.NewOptional(types.ErrorInterface), false},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, types.Registry{}.Accepts(test.param, test.arg))
+		})
+	}
+}
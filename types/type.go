@@ -13,7 +13,7 @@ type Type struct {
 	// Name is used as the descriptive name for the object.
 	Name string `json:",omitempty"`
 
-	// Element is used when Kind is an Array or Map.
+	// Element is used when Kind is an Array, Map, Channel or Optional.
 	Element *Type `json:",omitempty"`
 
 	// Argument and Returns are used when Kind is a Func. Either may be nil.
@@ -22,6 +22,17 @@ type Type struct {
 	// Properties is used for KindInterface
 	Properties map[string]*Type `json:",omitempty"`
 
+	// TypeParameters is used when Kind is a Func to contain the names of the
+	// type parameters of a generic function.
+	TypeParameters []string `json:",omitempty"`
+
+	// TypeArguments is used for an interface that was created by a generic
+	// constructor, such as "Stack[number]".
+	TypeArguments []*Type `json:",omitempty"`
+
+	// Values is used when Kind is an Enum.
+	Values []string `json:",omitempty"`
+
 	// Ref is used when types are flattened for a Registry. It will point to an
 	// index of another type in the registry.
 	Ref string `json:",omitempty"`
@@ -64,6 +75,18 @@ func (t *Type) Copy() *Type {
 		ty.Returns = append(ty.Returns, v.Copy())
 	}
 
+	for _, v := range t.TypeArguments {
+		ty.TypeArguments = append(ty.TypeArguments, v.Copy())
+	}
+
+	if len(t.TypeParameters) > 0 {
+		ty.TypeParameters = append([]string(nil), t.TypeParameters...)
+	}
+
+	if len(t.Values) > 0 {
+		ty.Values = append([]string(nil), t.Values...)
+	}
+
 	if len(t.Properties) > 0 {
 		ty.Properties = map[string]*Type{}
 
@@ -105,13 +128,26 @@ func (t *Type) String() string {
 	case KindMap:
 		return "{}" + t.Element.String()
 
+	case KindChannel:
+		return "chan " + t.Element.String()
+
+	case KindOptional:
+		return "?" + t.Element.String()
+
+	case KindNil:
+		return "nil"
+
 	case KindFunc:
 		var args []string
 		for _, arg := range t.Arguments {
 			args = append(args, arg.String())
 		}
 
-		s := "func(" + strings.Join(args, ", ") + ")"
+		s := "func"
+		if len(t.TypeParameters) > 0 {
+			s += "[" + strings.Join(t.TypeParameters, ", ") + "]"
+		}
+		s += "(" + strings.Join(args, ", ") + ")"
 
 		switch len(t.Returns) {
 		case 0:
@@ -132,6 +168,15 @@ func (t *Type) String() string {
 		return s
 	}
 
+	if len(t.TypeArguments) > 0 {
+		var typeArgs []string
+		for _, typeArg := range t.TypeArguments {
+			typeArgs = append(typeArgs, typeArg.String())
+		}
+
+		return t.Name + "[" + strings.Join(typeArgs, ", ") + "]"
+	}
+
 	return t.Name
 }
 
@@ -149,6 +194,24 @@ func NewMap(element *Type) *Type {
 	}
 }
 
+// NewChannel creates the type of a channel that sends values of the element
+// type.
+func NewChannel(element *Type) *Type {
+	return &Type{
+		Kind:    KindChannel,
+		Element: element,
+	}
+}
+
+// NewOptional creates the type of a value that is either nil or a value of the
+// element type.
+func NewOptional(element *Type) *Type {
+	return &Type{
+		Kind:    KindOptional,
+		Element: element,
+	}
+}
+
 func NewRef(ref string) *Type {
 	return &Type{
 		Ref: ref,
@@ -171,6 +234,27 @@ func TypeFromString(s string) *Type {
 	return ty
 }
 
+// NewEnum creates the type for an enum with its values in the order they were
+// declared.
+func NewEnum(name string, values []string) *Type {
+	return &Type{
+		Kind:   KindEnum,
+		Name:   name,
+		Values: values,
+	}
+}
+
+// HasValue returns true if the enum contains a value with the name.
+func (t *Type) HasValue(name string) bool {
+	for _, value := range t.Values {
+		if value == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func NewUnresolvedInterface(name string) *Type {
 	return &Type{
 		Kind: KindUnresolvedInterface,
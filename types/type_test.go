@@ -22,6 +22,16 @@ func TestTypeFromString(t *testing.T) {
 		"[]bool":    {Kind: types.KindArray, Element: &types.Type{Kind: types.KindBool}},
 		"{} string": {Kind: types.KindMap, Element: &types.Type{Kind: types.KindString}},
 
+		// channels
+		"chan number":   {Kind: types.KindChannel, Element: &types.Type{Kind: types.KindNumber}},
+		"[]chan []char": {Kind: types.KindArray, Element: types.NewChannel(types.TypeFromString("[]char"))},
+
+		// optionals
+		"nil":       {Kind: types.KindNil},
+		"?string":   {Kind: types.KindOptional, Element: &types.Type{Kind: types.KindString}},
+		"? []bool":  {Kind: types.KindOptional, Element: types.BoolArray},
+		"[]?number": {Kind: types.KindArray, Element: types.NewOptional(types.Number)},
+
 		// functions
 		"func()": {
 			Kind: types.KindFunc,
@@ -100,6 +110,30 @@ func TestTypeFromString(t *testing.T) {
 				},
 			},
 		},
+
+		// generics
+		"func[T, U]([]T, func(T) U) []U": {
+			Kind: types.KindFunc,
+			Arguments: []*types.Type{
+				types.NewArray(types.NewTypeParameter("T")),
+				types.NewFunc(
+					[]*types.Type{types.NewTypeParameter("T")},
+					[]*types.Type{types.NewTypeParameter("U")},
+				),
+			},
+			Returns: []*types.Type{
+				types.NewArray(types.NewTypeParameter("U")),
+			},
+			TypeParameters: []string{"T", "U"},
+		},
+		"Pair[number, []Person]": {
+			Kind: types.KindUnresolvedInterface,
+			Name: "Pair",
+			TypeArguments: []*types.Type{
+				types.Number,
+				types.NewArray(types.NewUnresolvedInterface("Person")),
+			},
+		},
 	} {
 		t.Run(typeString, func(t *testing.T) {
 			assert.Equal(t, tt, types.TypeFromString(typeString))
@@ -121,6 +155,15 @@ func TestType_String(t *testing.T) {
 		"[]bool":   {Kind: types.KindArray, Element: &types.Type{Kind: types.KindBool}},
 		"{}string": {Kind: types.KindMap, Element: &types.Type{Kind: types.KindString}},
 
+		// channels
+		"chan number":   {Kind: types.KindChannel, Element: &types.Type{Kind: types.KindNumber}},
+		"{}chan string": {Kind: types.KindMap, Element: types.NewChannel(types.String)},
+
+		// optionals
+		"nil":         {Kind: types.KindNil},
+		"?string":     {Kind: types.KindOptional, Element: &types.Type{Kind: types.KindString}},
+		"{}?[]number": {Kind: types.KindMap, Element: types.NewOptional(types.NumberArray)},
+
 		// functions
 		"func()": {
 			Kind: types.KindFunc,
@@ -149,6 +192,17 @@ func TestType_String(t *testing.T) {
 			},
 		},
 		"PersonA": {Kind: types.KindResolvedInterface, Name: "PersonA"},
+
+		// generics
+		"func[T](T) []T": types.NewFunc(
+			[]*types.Type{types.NewTypeParameter("T")},
+			[]*types.Type{types.NewArray(types.NewTypeParameter("T"))},
+		).Generic([]string{"T"}),
+		"Stack[number]": {
+			Kind:          types.KindResolvedInterface,
+			Name:          "Stack",
+			TypeArguments: []*types.Type{types.Number},
+		},
 	} {
 		t.Run(typeString, func(t *testing.T) {
 			assert.Equal(t, typeString, tt.String())
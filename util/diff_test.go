@@ -0,0 +1,41 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	for testName, test := range map[string]struct {
+		a, b     string
+		expected string
+	}{
+		"same": {"a\nb\n", "a\nb\n", ""},
+		"changed-line": {
+			"a\nb\nc\n", "a\nB\nc\n",
+			"--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		"added-line": {
+			"a\nc\n", "a\nb\nc\n",
+			"--- a\n+++ b\n@@ -1,2 +1,3 @@\n a\n+b\n c\n",
+		},
+		"removed-everything": {
+			"a\nb\n", "",
+			"--- a\n+++ b\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		"context": {
+			"1\n2\n3\n4\n5\n6\n7\n8\n", "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			"--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		"separate-hunks": {
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, Diff("a", "b", test.a, test.b))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/elliotchance/ok/fs"
+)
+
+// SourceFingerprint describes the ".ok" files (and ".okt" files when
+// includeTests is true) in a directory by their names, sizes and modification
+// times. The fingerprint will be different if any of the files are added,
+// removed or modified. An empty string is returned if the directory cannot be
+// read.
+func SourceFingerprint(dir string, includeTests bool) string {
+	files, err := fs.Filesystem.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var lines []string
+	for _, f := range files {
+		ext := path.Ext(f.Name())
+		if ext == ".ok" || (includeTests && ext == ".okt") {
+			lines = append(lines, fmt.Sprintf("%s %d %d", f.Name(), f.Size(),
+				f.ModTime().UnixNano()))
+		}
+	}
+	sort.Strings(lines)
+
+	return dir + "\n" + strings.Join(lines, "\n")
+}
+
+// SourceHash is the SHA-256 of the names and contents of the ".ok" files (and
+// ".okt" files when includeTests is true) in a directory. Unlike
+// SourceFingerprint, the hash only changes if the source has actually changed.
+// An empty string is returned if the directory or any of the files cannot be
+// read.
+func SourceHash(dir string, includeTests bool) string {
+	files, err := fs.Filesystem.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var fileNames []string
+	for _, f := range files {
+		ext := path.Ext(f.Name())
+		if ext == ".ok" || (includeTests && ext == ".okt") {
+			fileNames = append(fileNames, f.Name())
+		}
+	}
+	sort.Strings(fileNames)
+
+	h := sha256.New()
+	for _, fileName := range fileNames {
+		f, err := fs.Filesystem.OpenFile(path.Join(dir, fileName), os.O_RDONLY,
+			0777)
+		if err != nil {
+			return ""
+		}
+
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return ""
+		}
+
+		fmt.Fprintf(h, "%s %d\n", fileName, len(data))
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,6 @@
+// Package vet reports suspicious code that is still valid, such as variables
+// that are never used or statements that can never be reached.
+//
+// The checks only need the parsed package, so they will work even if the
+// package does not compile.
+package vet
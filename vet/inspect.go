@@ -0,0 +1,184 @@
+package vet
+
+import (
+	"github.com/elliotchance/ok/ast"
+)
+
+// inspect visits n and then each of its children, in the order they appear in
+// the source. The children of a node are not visited if f returns false.
+func inspect(n ast.Node, f func(ast.Node) bool) {
+	if n == nil || !f(n) {
+		return
+	}
+
+	for _, child := range children(n) {
+		inspect(child, f)
+	}
+}
+
+func inspectAll(nodes []ast.Node, f func(ast.Node) bool) {
+	for _, n := range nodes {
+		inspect(n, f)
+	}
+}
+
+// children returns the direct children of a node. Each of the optional fields
+// must be checked for nil so that a typed nil is not returned as a node.
+func children(n ast.Node) []ast.Node {
+	var nodes []ast.Node
+
+	switch n := n.(type) {
+	case *ast.Assign:
+		nodes = append(nodes, n.Lefts...)
+		nodes = append(nodes, n.Rights...)
+
+	case *ast.Func:
+		nodes = n.Statements
+
+	case *ast.Key:
+		nodes = []ast.Node{n.Expr, n.Key}
+
+	case *ast.Call:
+		nodes = append([]ast.Node{n.Expr}, n.Arguments...)
+
+	case *ast.Binary:
+		nodes = []ast.Node{n.Left, n.Right}
+
+	case *ast.Unary:
+		nodes = []ast.Node{n.Expr}
+
+	case *ast.Group:
+		nodes = []ast.Node{n.Expr}
+
+	case *ast.Interpolate:
+		nodes = n.Parts
+
+	case *ast.Array:
+		nodes = n.Elements
+
+	case *ast.Map:
+		for _, element := range n.Elements {
+			nodes = append(nodes, element)
+		}
+
+	case *ast.KeyValue:
+		nodes = []ast.Node{n.Key, n.Value}
+
+	case *ast.Assert:
+		if n.Expr != nil {
+			nodes = append(nodes, n.Expr)
+		}
+		if n.Message != nil {
+			nodes = append(nodes, n.Message)
+		}
+
+	case *ast.AssertRaise:
+		if n.Call != nil {
+			nodes = append(nodes, n.Call)
+		}
+		if n.TypeOrValue != nil {
+			nodes = append(nodes, n.TypeOrValue)
+		}
+		if n.Message != nil {
+			nodes = append(nodes, n.Message)
+		}
+
+	case *ast.Raise:
+		nodes = []ast.Node{n.Err}
+
+	case *ast.Return:
+		nodes = n.Exprs
+
+	case *ast.In:
+		nodes = []ast.Node{n.Expr}
+
+	case *ast.If:
+		nodes = append([]ast.Node{n.Condition}, n.True...)
+		nodes = append(nodes, n.False...)
+
+	case *ast.For:
+		for _, node := range []ast.Node{n.Init, n.Condition, n.Next} {
+			if node != nil {
+				nodes = append(nodes, node)
+			}
+		}
+		nodes = append(nodes, n.Statements...)
+
+	case *ast.Switch:
+		if n.Expr != nil {
+			nodes = append(nodes, n.Expr)
+		}
+		for _, c := range n.Cases {
+			nodes = append(nodes, c.Conditions...)
+			nodes = append(nodes, c.Statements...)
+		}
+		nodes = append(nodes, n.Else...)
+
+	case *ast.Select:
+		for _, c := range n.Cases {
+			nodes = append(nodes, c.Op)
+			nodes = append(nodes, c.Statements...)
+		}
+		nodes = append(nodes, n.Else...)
+
+	case *ast.Spawn:
+		if n.Call != nil {
+			nodes = append(nodes, n.Call)
+		}
+
+	case *ast.ErrorScope:
+		nodes = append(nodes, n.Statements...)
+		for _, on := range n.On {
+			nodes = append(nodes, on.Statements...)
+		}
+		if n.Finally != nil {
+			nodes = append(nodes, n.Finally.Statements...)
+		}
+	}
+
+	return nodes
+}
+
+// blocks returns the lists of statements that belong directly to a statement.
+// The body of a try is not included when excludeTry is true.
+func blocks(n ast.Node, excludeTry bool) [][]ast.Node {
+	switch n := n.(type) {
+	case *ast.If:
+		return [][]ast.Node{n.True, n.False}
+
+	case *ast.For:
+		return [][]ast.Node{n.Statements}
+
+	case *ast.Switch:
+		var blocks [][]ast.Node
+		for _, c := range n.Cases {
+			blocks = append(blocks, c.Statements)
+		}
+
+		return append(blocks, n.Else)
+
+	case *ast.Select:
+		var blocks [][]ast.Node
+		for _, c := range n.Cases {
+			blocks = append(blocks, c.Statements)
+		}
+
+		return append(blocks, n.Else)
+
+	case *ast.ErrorScope:
+		var blocks [][]ast.Node
+		if !excludeTry {
+			blocks = append(blocks, n.Statements)
+		}
+		for _, on := range n.On {
+			blocks = append(blocks, on.Statements)
+		}
+		if n.Finally != nil {
+			blocks = append(blocks, n.Finally.Statements)
+		}
+
+		return blocks
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package vet
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The names of each check. These are included in the JSON output so that
+// tools can filter or group the issues.
+const (
+	CheckUnusedVariable   = "unusedvariable"
+	CheckUnusedImport     = "unusedimport"
+	CheckUnreadAssignment = "unreadassignment"
+	CheckUnreachable      = "unreachable"
+	CheckFinallyReturn    = "finallyreturn"
+	CheckShadowedConstant = "shadowedconstant"
+	CheckUnmatchedHandler = "unmatchedhandler"
+)
+
+// Issue is a single problem found by Check.
+type Issue struct {
+	// Pos is the position of the problem, like "main.ok:12:5".
+	Pos string
+
+	// Check is one of the Check constants.
+	Check string
+
+	Message string
+}
+
+// String returns the issue in the same format as compiler errors.
+func (issue *Issue) String() string {
+	return issue.Pos + ": " + issue.Message
+}
+
+// sortIssues orders by file, then line, then column. Comparing the positions
+// as strings would put line 10 before line 9.
+func sortIssues(issues []*Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		fileA, lineA, columnA := splitPos(issues[i].Pos)
+		fileB, lineB, columnB := splitPos(issues[j].Pos)
+
+		if fileA != fileB {
+			return fileA < fileB
+		}
+
+		if lineA != lineB {
+			return lineA < lineB
+		}
+
+		return columnA < columnB
+	})
+}
+
+func splitPos(pos string) (file string, line, column int) {
+	parts := strings.Split(pos, ":")
+	if len(parts) < 3 {
+		return pos, 0, 0
+	}
+
+	line, _ = strconv.Atoi(parts[len(parts)-2])
+	column, _ = strconv.Atoi(parts[len(parts)-1])
+
+	return strings.Join(parts[:len(parts)-2], ":"), line, column
+}
@@ -0,0 +1,512 @@
+package vet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/lexer"
+	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/util"
+)
+
+// scope is the variables of a function or test. Variables are scoped to the
+// whole function, not to the block they are declared in.
+type scope struct {
+	parent *scope
+
+	// isConstructor is set because the public variables of a constructor are
+	// properties of the object, so they can be used outside of the function.
+	isConstructor bool
+
+	// hasFinally is set when the function contains a finally block, which will
+	// run when the function returns.
+	hasFinally bool
+
+	arguments map[string]bool
+
+	// declared contains the position of the first assignment for each
+	// variable. names is the order they were declared in.
+	declared map[string]string
+	names    []string
+
+	reads map[string]bool
+
+	// captured contains the variables that are read by a nested function
+	// through "^". They may be read at any time after they are assigned.
+	captured map[string]bool
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{
+		parent:    parent,
+		arguments: map[string]bool{},
+		declared:  map[string]string{},
+		reads:     map[string]bool{},
+		captured:  map[string]bool{},
+	}
+}
+
+func (s *scope) has(name string) bool {
+	_, ok := s.declared[name]
+
+	return ok || s.arguments[name]
+}
+
+// tracked returns true if the variable should be checked for being unused.
+func (s *scope) tracked(name string) bool {
+	_, ok := s.declared[name]
+
+	return ok && name != "_" && !(s.isConstructor && util.IsPublic(name))
+}
+
+type checker struct {
+	constants map[string]*ast.Literal
+	issues    []*Issue
+
+	// packages contains the names that may refer to an imported package. That
+	// is any identifier, or the prefix of any type name like "time.Time".
+	packages map[string]bool
+}
+
+// Check returns the issues found in a parsed package. The issues are ordered
+// by their position.
+func Check(p *parser.Parser) []*Issue {
+	c := &checker{
+		constants: p.Constants,
+		packages:  map[string]bool{},
+	}
+
+	for _, name := range p.SortedFuncNames() {
+		c.function(nil, p.Funcs()[name])
+	}
+
+	for _, test := range p.Tests() {
+		s := newScope(nil)
+		for _, arg := range test.Arguments {
+			s.arguments[arg.Name] = true
+			c.checkConstant(s, arg.Name, test.Pos)
+			c.typeRef(arg.Type)
+		}
+		if test.Cases != nil {
+			c.walk(s, test.Cases)
+		}
+		c.statements(s, test.Statements)
+		c.finish(s, test.Statements)
+	}
+
+	// The variables in setup and teardown blocks are shared with every test,
+	// so they are not checked for being unused.
+	for _, hook := range p.Hooks() {
+		c.statements(newScope(nil), hook.Statements)
+	}
+
+	for _, imp := range p.ImportDecls() {
+		if !c.packages[imp.VariableName] {
+			c.addIssue(imp.Pos, CheckUnusedImport,
+				"%q imported but not used", imp.PackageName)
+		}
+	}
+
+	sortIssues(c.issues)
+
+	return c.issues
+}
+
+func (c *checker) addIssue(pos, check, format string, args ...interface{}) {
+	c.issues = append(c.issues, &Issue{
+		Pos:     pos,
+		Check:   check,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) function(parent *scope, fn *ast.Func) {
+	s := newScope(parent)
+	s.isConstructor = fn.IsConstructor()
+
+	for _, arg := range fn.Arguments {
+		s.arguments[arg.Name] = true
+		c.checkConstant(s, arg.Name, fn.Pos)
+		c.typeRef(arg.Type)
+	}
+
+	for _, ty := range fn.Returns {
+		c.typeRef(ty)
+	}
+
+	c.statements(s, fn.Statements)
+	c.finish(s, fn.Statements)
+}
+
+// finish reports the problems with variables, once all of the statements of
+// the function have been seen.
+func (c *checker) finish(s *scope, statements []ast.Node) {
+	for _, name := range s.names {
+		if s.tracked(name) && !s.reads[name] {
+			c.addIssue(s.declared[name], CheckUnusedVariable,
+				"%s declared but not used", name)
+		}
+	}
+
+	c.unreadAssignments(s, statements, true)
+}
+
+func (c *checker) statements(s *scope, statements []ast.Node) {
+	c.unreachable(statements)
+	for _, stmt := range statements {
+		c.walk(s, stmt)
+	}
+}
+
+func (c *checker) walk(s *scope, n ast.Node) {
+	inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Func:
+			c.function(s, n)
+
+			return false
+
+		case *ast.Assign:
+			for i, left := range n.Lefts {
+				ident, ok := left.(*ast.Identifier)
+				if !ok {
+					c.walk(s, left)
+					continue
+				}
+
+				// Nested functions are hoisted into an assignment that does
+				// not have a position.
+				pos := ident.Pos
+				if fn, ok := n.Rights[0].(*ast.Func); ok && pos == "" && i == 0 {
+					pos = fn.Pos
+				}
+				c.declare(s, ident.Name, pos)
+			}
+
+			for _, right := range n.Rights {
+				c.walk(s, right)
+			}
+
+			return false
+
+		case *ast.Identifier:
+			c.read(s, n.Name)
+
+		case *ast.In:
+			c.declare(s, n.Value, n.Pos)
+			if n.Key != "" {
+				c.declare(s, n.Key, n.Pos)
+			}
+
+		case *ast.Binary:
+			// The right side of "is" is a type, not a variable.
+			if n.Op == lexer.TokenIs {
+				c.walk(s, n.Left)
+				if ident, ok := n.Right.(*ast.Identifier); ok {
+					c.typeName(ident.Name)
+				}
+
+				return false
+			}
+
+		case *ast.Array:
+			c.typeRef(n.Kind)
+
+		case *ast.Map:
+			c.typeRef(n.Kind)
+
+		case *ast.If, *ast.For, *ast.Switch, *ast.Select:
+			for _, block := range blocks(n, false) {
+				c.unreachable(block)
+			}
+
+		case *ast.ErrorScope:
+			for _, block := range blocks(n, false) {
+				c.unreachable(block)
+			}
+
+			c.handlers(n)
+			if n.Finally != nil {
+				s.hasFinally = true
+				c.finallyReturns(n.Finally)
+			}
+		}
+
+		return true
+	})
+}
+
+func (c *checker) declare(s *scope, name, pos string) {
+	// Assigning to a variable in the parent scope does not declare it.
+	if name == "" || name[0] == '^' {
+		return
+	}
+
+	if !s.has(name) {
+		c.checkConstant(s, name, pos)
+		s.declared[name] = pos
+		s.names = append(s.names, name)
+	}
+}
+
+func (c *checker) read(s *scope, name string) {
+	if name[0] != '^' {
+		s.reads[name] = true
+		c.packages[name] = true
+
+		return
+	}
+
+	// "^" refers to the closest function that has the variable. Nested
+	// functions are hoisted so the variable may not have been declared yet, in
+	// which case it must belong to the parent.
+	name = name[1:]
+	owner := s.parent
+	for owner != nil && !owner.has(name) {
+		owner = owner.parent
+	}
+
+	if owner == nil {
+		owner = s.parent
+	}
+
+	if owner != nil {
+		owner.reads[name] = true
+		owner.captured[name] = true
+	}
+}
+
+// checkConstant reports a variable with the same name as a constant. The
+// constant will always be used when the name is read, so the variable can
+// never be read. The public variables of a constructor are not checked because
+// they are read as properties of the object.
+func (c *checker) checkConstant(s *scope, name, pos string) {
+	if s.isConstructor && util.IsPublic(name) {
+		return
+	}
+
+	if _, ok := c.constants[name]; ok {
+		c.addIssue(pos, CheckShadowedConstant,
+			"%s shadows the package constant %s, which will be used instead",
+			name, name)
+	}
+}
+
+func (c *checker) typeRef(ty *types.Type) {
+	if ty == nil {
+		return
+	}
+
+	c.typeName(ty.Name)
+	c.typeRef(ty.Element)
+
+	for _, arg := range ty.Arguments {
+		c.typeRef(arg)
+	}
+
+	for _, r := range ty.Returns {
+		c.typeRef(r)
+	}
+
+	for _, property := range ty.Properties {
+		c.typeRef(property)
+	}
+}
+
+// typeName records the package of a type name like "time.Duration" or
+// "[]time.Duration".
+func (c *checker) typeName(name string) {
+	name = strings.TrimLeft(name, "[]{}?")
+	if i := strings.Index(name, "."); i > 0 {
+		c.packages[name[:i]] = true
+	}
+}
+
+// unreachable reports the first statement after a return, raise, break or
+// continue.
+func (c *checker) unreachable(statements []ast.Node) {
+	for i := 1; i < len(statements); i++ {
+		switch statements[i-1].(type) {
+		case *ast.Return, *ast.Raise, *ast.Break, *ast.Continue:
+			c.addIssue(position(statements[i]), CheckUnreachable,
+				"unreachable code")
+
+			return
+		}
+	}
+}
+
+// finallyReturns reports returns in a finally block. They are ignored by the
+// VM when the finally block runs.
+func (c *checker) finallyReturns(finally *ast.Finally) {
+	inspectAll(finally.Statements, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Func:
+			return false
+
+		case *ast.Return:
+			c.addIssue(n.Pos, CheckFinallyReturn,
+				"return in finally block is ignored")
+		}
+
+		return true
+	})
+}
+
+// handlers reports handlers that come after a handler for the same type, or
+// after a handler for error.Error. The first matching handler is always used
+// and error.Error will match any error.
+func (c *checker) handlers(errorScope *ast.ErrorScope) {
+	seen := map[string]bool{}
+	catchAll := ""
+	for _, on := range errorScope.On {
+		c.typeName(on.Type)
+
+		switch {
+		case catchAll != "":
+			c.addIssue(on.Pos, CheckUnmatchedHandler,
+				"on %s can never match because on %s handles all errors",
+				on.Type, catchAll)
+
+		case seen[on.Type]:
+			c.addIssue(on.Pos, CheckUnmatchedHandler,
+				"on %s can never match because it is already handled",
+				on.Type)
+		}
+
+		seen[on.Type] = true
+		if catchAll == "" && (on.Type == "error.Error" || on.Type == "Error") {
+			catchAll = on.Type
+		}
+	}
+}
+
+// unreadAssignments reports assignments that are always replaced before they
+// are read. isBody is true for the statements of the function itself, where
+// reaching the end of the statements also means returning from the function.
+//
+// The statements in a try are not checked because any of them may raise an
+// error that is handled by code that reads the previous value.
+func (c *checker) unreadAssignments(
+	s *scope,
+	statements []ast.Node,
+	isBody bool,
+) {
+	for i, stmt := range statements {
+		for _, block := range blocks(stmt, true) {
+			c.unreadAssignments(s, block, false)
+		}
+
+		assign, ok := stmt.(*ast.Assign)
+		if !ok {
+			continue
+		}
+
+		for j, left := range assign.Lefts {
+			ident, ok := left.(*ast.Identifier)
+			if !ok || !s.tracked(ident.Name) || !s.reads[ident.Name] ||
+				s.captured[ident.Name] {
+				continue
+			}
+
+			// The first assignment decides the type of the variable, so
+			// something like 'x = any "foo"' is needed even if it is never
+			// read.
+			if ident.Pos == s.declared[ident.Name] &&
+				len(assign.Rights) == len(assign.Lefts) &&
+				isAnyCast(assign.Rights[j]) {
+				continue
+			}
+
+			if c.isReplaced(s, ident.Name, statements[i+1:], isBody) {
+				c.addIssue(ident.Pos, CheckUnreadAssignment,
+					"value assigned to %s is never read", ident.Name)
+			}
+		}
+	}
+}
+
+// isReplaced returns true if the variable is assigned again, or the function
+// returns, before the variable is read.
+func (c *checker) isReplaced(
+	s *scope,
+	name string,
+	statements []ast.Node,
+	isBody bool,
+) bool {
+	// A finally block may read the variable after the function returns.
+	returns := !s.hasFinally
+
+	for _, stmt := range statements {
+		if reads(stmt, name) {
+			return false
+		}
+
+		switch stmt := stmt.(type) {
+		case *ast.Assign:
+			for _, left := range stmt.Lefts {
+				if ident, ok := left.(*ast.Identifier); ok && ident.Name == name {
+					return true
+				}
+			}
+
+		case *ast.Return, *ast.Raise:
+			return returns
+		}
+	}
+
+	return isBody && returns
+}
+
+// position returns the start of the statement. A call is positioned at the
+// "(" rather than at the function name.
+func position(n ast.Node) string {
+	if call, ok := n.(*ast.Call); ok && call.Expr.Position() != "" {
+		return call.Expr.Position()
+	}
+
+	return n.Position()
+}
+
+func isAnyCast(n ast.Node) bool {
+	call, ok := n.(*ast.Call)
+	if !ok {
+		return false
+	}
+
+	ident, ok := call.Expr.(*ast.Identifier)
+
+	return ok && ident.Name == lexer.TokenAny
+}
+
+// reads returns true if the variable is read anywhere within n.
+func reads(n ast.Node, name string) (found bool) {
+	inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Func:
+			return false
+
+		case *ast.Assign:
+			for _, left := range n.Lefts {
+				if _, ok := left.(*ast.Identifier); !ok {
+					found = found || reads(left, name)
+				}
+			}
+
+			for _, right := range n.Rights {
+				found = found || reads(right, name)
+			}
+
+			return false
+
+		case *ast.Identifier:
+			found = found || n.Name == name
+		}
+
+		return !found
+	})
+
+	return
+}
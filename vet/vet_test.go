@@ -0,0 +1,278 @@
+package vet_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/ok/parser"
+	"github.com/elliotchance/ok/vet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	for testName, test := range map[string]struct {
+		source   string
+		expected []*vet.Issue
+	}{
+		"no-issues": {
+			source: `import "strings"
+func main() {
+    x = strings.ToUpper("a")
+    print(x)
+}`,
+		},
+		"unused-variable": {
+			source: `func main() {
+    x = 1
+    y = 2
+    print(y)
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:2:5", vet.CheckUnusedVariable, "x declared but not used"},
+			},
+		},
+		"unused-loop-variable": {
+			source: `func main() {
+    for v, k in [1, 2] {
+        print(k)
+    }
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:2:9", vet.CheckUnusedVariable, "v declared but not used"},
+			},
+		},
+		"unused-nested-func": {
+			source: `func main() {
+    func helper() {}
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:2:5", vet.CheckUnusedVariable, "helper declared but not used"},
+			},
+		},
+		"used-by-closure": {
+			source: `func main() {
+    x = 1
+    func helper() {
+        print(^x)
+    }
+    helper()
+}`,
+		},
+		"constructor-properties": {
+			source: `func Person(Name string) Person {
+    Age = 12
+    func Greet() string {
+        return ^Name
+    }
+}`,
+		},
+		"unused-import": {
+			source: `import "strings"
+import "time"
+func main(d time.Duration) {
+    print(d)
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:1:1", vet.CheckUnusedImport, `"strings" imported but not used`},
+			},
+		},
+		"import-used-by-handler": {
+			source: `import "error"
+func main() {
+    try {
+        print("hi")
+    } on error.Error {
+        print(err)
+    }
+}`,
+		},
+		"import-used-by-is": {
+			source: `import "time"
+func main(x any) {
+    if x is time.Time {
+        print(x)
+    }
+}`,
+		},
+		"assigned-again": {
+			source: `func main() {
+    x = 1
+    x = 2
+    print(x)
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:2:5", vet.CheckUnreadAssignment, "value assigned to x is never read"},
+			},
+		},
+		"assigned-before-return": {
+			source: `func main() number {
+    x = 1
+    print(x)
+    x = 2
+    return 3
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:4:5", vet.CheckUnreadAssignment, "value assigned to x is never read"},
+			},
+		},
+		"assigned-and-read-in-loop": {
+			source: `func main() {
+    first = true
+    for i = 0; i < 2; ++i {
+        print(first)
+        first = false
+    }
+}`,
+		},
+		"assigned-in-try": {
+			source: `func main() {
+    x = 1
+    try {
+        x = 2
+        foo()
+        x = 3
+    } on error.Error {
+        print(x)
+    }
+}`,
+		},
+		"assigned-before-finally": {
+			source: `func main() {
+    x = 1
+    print(x)
+    try {
+        print("hi")
+    } finally {
+        print(x)
+    }
+    x = 2
+}`,
+		},
+		"assigned-any": {
+			source: `func main() {
+    x = any "foo"
+    x = 12.3
+    print(x)
+}`,
+		},
+		"unreachable": {
+			source: `func main() number {
+    return 1
+    print("a")
+    print("b")
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:3:5", vet.CheckUnreachable, "unreachable code"},
+			},
+		},
+		"unreachable-in-loop": {
+			source: `func main() {
+    for {
+        break
+        print("a")
+    }
+    if true {
+        raise error.Error("a")
+        print("b")
+    }
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:4:9", vet.CheckUnreachable, "unreachable code"},
+				{"a.ok:8:9", vet.CheckUnreachable, "unreachable code"},
+			},
+		},
+		"return-in-finally": {
+			source: `func main() number {
+    try {
+        print("hi")
+    } finally {
+        if true {
+            return 2
+        }
+    }
+    return 1
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:6:13", vet.CheckFinallyReturn, "return in finally block is ignored"},
+			},
+		},
+		"shadowed-constant": {
+			source: `Limit = 3
+func main(Limit number) {
+    print(Limit)
+}
+func other() {
+    Limit = 4
+    print(Limit)
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:2:1", vet.CheckShadowedConstant, "Limit shadows the package constant Limit, which will be used instead"},
+				{"a.ok:6:5", vet.CheckShadowedConstant, "Limit shadows the package constant Limit, which will be used instead"},
+			},
+		},
+		"duplicate-handler": {
+			source: `func main() {
+    try {
+        foo()
+    } on MyError {
+        print(err)
+    } on MyError {
+        print(err)
+    }
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:6:7", vet.CheckUnmatchedHandler, "on MyError can never match because it is already handled"},
+			},
+		},
+		"handler-after-catch-all": {
+			source: `func main() {
+    try {
+        foo()
+    } on error.Error {
+        print(err)
+    } on MyError {
+        print(err)
+    }
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:6:7", vet.CheckUnmatchedHandler, "on MyError can never match because on error.Error handles all errors"},
+			},
+		},
+		"test": {
+			source: `test "foo" {
+    x = 1
+    assert(1 == 1)
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:2:5", vet.CheckUnusedVariable, "x declared but not used"},
+			},
+		},
+		"table-test": {
+			source: `test "foo" for c, name in {"a": 1} {
+    assert(c == 1)
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:1:16", vet.CheckUnusedVariable, "name declared but not used"},
+			},
+		},
+		"setup": {
+			source: `import "strings"
+setup {
+    x = strings.ToUpper("a")
+    return
+    print(x)
+}`,
+			expected: []*vet.Issue{
+				{"a.ok:5:5", vet.CheckUnreachable, "unreachable code"},
+			},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			p := parser.NewParser(0)
+			p.ParseString(test.source, "a.ok")
+			require.Nil(t, p.Errors())
+
+			assert.Equal(t, test.expected, vet.Check(p))
+		})
+	}
+}
@@ -9,14 +9,38 @@ type Assert struct {
 	Left, Right, Final Register
 	Op                 string
 	Pos                string
+
+	// Message is optional. It is a string to be shown if the assertion fails.
+	Message Register
 }
 
 // Execute implements the Instruction interface for the VM.
 func (ins *Assert) Execute(_ *int, vm *VM) error {
 	pass := vm.Get(ins.Final).Value == "true"
-	left := renderLiteral(vm.Get(ins.Left), true)
-	right := renderLiteral(vm.Get(ins.Right), true)
-	vm.assert(pass, left, ins.Op, right, ins.Pos)
+	leftValue, rightValue := vm.Get(ins.Left), vm.Get(ins.Right)
+	left := renderLiteral(leftValue, true)
+	right := renderLiteral(rightValue, true)
+
+	// Large arrays, maps and objects are difficult to compare when they are
+	// printed in full. Instead they are shortened, followed by each
+	// difference.
+	var diff []string
+	if !pass && ins.Op == "==" && isComposite(leftValue) &&
+		isComposite(rightValue) {
+		diff = diffLiterals("", leftValue, rightValue)
+		if len(diff) > 0 {
+			left = shorten(left)
+			right = shorten(right)
+		}
+	}
+
+	message := ""
+	if ins.Message != "" {
+		message = vm.Get(ins.Message).Value
+	}
+
+	vm.assert(pass, fmt.Sprintf("assert(%s %s %s)", left, ins.Op, right),
+		ins.Pos, message, diff)
 
 	return nil
 }
@@ -25,3 +49,17 @@ func (ins *Assert) Execute(_ *int, vm *VM) error {
 func (ins *Assert) String() string {
 	return fmt.Sprintf("assert(%s %s %s)", ins.Left, ins.Op, ins.Right)
 }
+
+// maxAssertValue is the number of characters of a value shown in a failed
+// assertion when the differences are also shown.
+const maxAssertValue = 40
+
+// shorten cuts a rendered value to maxAssertValue characters.
+func shorten(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxAssertValue {
+		return s
+	}
+
+	return string(runes[:maxAssertValue-3]) + "..."
+}
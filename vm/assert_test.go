@@ -1,13 +1,157 @@
 package vm_test
 
 import (
+	"bytes"
+	"strconv"
 	"testing"
 
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/types"
 	"github.com/elliotchance/ok/vm"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestAssert_Execute(t *testing.T) {
+	for testName, test := range map[string]struct {
+		left, right    *ast.Literal
+		pass           bool
+		message        *ast.Literal
+		expectedOutput string
+	}{
+		"pass": {
+			left:  asttest.NewLiteralNumber("1"),
+			right: asttest.NewLiteralNumber("1"),
+			pass:  true,
+		},
+		"numbers": {
+			left:           asttest.NewLiteralNumber("1"),
+			right:          asttest.NewLiteralNumber("2"),
+			expectedOutput: ": pos: test: assert(1 == 2) failed\n",
+		},
+		"message": {
+			left:           asttest.NewLiteralString("a"),
+			right:          asttest.NewLiteralString("b"),
+			message:        asttest.NewLiteralString("totals must match"),
+			expectedOutput: ": pos: test: assert(\"a\" == \"b\") failed: totals must match\n",
+		},
+		"arrays": {
+			left: &ast.Literal{
+				Kind: types.NumberArray,
+				Array: []*ast.Literal{
+					asttest.NewLiteralNumber("1"),
+					asttest.NewLiteralNumber("2"),
+					asttest.NewLiteralNumber("3"),
+				},
+			},
+			right: &ast.Literal{
+				Kind: types.NumberArray,
+				Array: []*ast.Literal{
+					asttest.NewLiteralNumber("1"),
+					asttest.NewLiteralNumber("5"),
+				},
+			},
+			expectedOutput: ": pos: test: assert([1, 2, 3] == [1, 5]) failed\n" +
+				"    [1]: 2 != 5\n" +
+				"    [2]: only on the left: 3\n",
+		},
+		"maps": {
+			left: &ast.Literal{
+				Kind: types.NumberMap,
+				Map: map[string]*ast.Literal{
+					"a": asttest.NewLiteralNumber("1"),
+					"b": asttest.NewLiteralNumber("2"),
+				},
+			},
+			right: &ast.Literal{
+				Kind: types.NumberMap,
+				Map: map[string]*ast.Literal{
+					"b": asttest.NewLiteralNumber("2"),
+					"c": asttest.NewLiteralNumber("3"),
+				},
+			},
+			expectedOutput: ": pos: test: assert({\"a\": 1, \"b\": 2} == {\"b\": 2, \"c\": 3}) failed\n" +
+				"    [\"a\"]: only on the left: 1\n" +
+				"    [\"c\"]: only on the right: 3\n",
+		},
+		"objects": {
+			left: &ast.Literal{
+				Kind: types.AnyArray,
+				Array: []*ast.Literal{
+					{
+						Kind: types.NewUnresolvedInterface("Person"),
+						Map: map[string]*ast.Literal{
+							"Name": asttest.NewLiteralString("Bob"),
+							"age":  asttest.NewLiteralNumber("1"),
+						},
+					},
+				},
+			},
+			right: &ast.Literal{
+				Kind: types.AnyArray,
+				Array: []*ast.Literal{
+					{
+						Kind: types.NewUnresolvedInterface("Person"),
+						Map: map[string]*ast.Literal{
+							"Name": asttest.NewLiteralString("Bobby"),
+							"age":  asttest.NewLiteralNumber("2"),
+						},
+					},
+				},
+			},
+			expectedOutput: ": pos: test: assert([{\"Name\": \"Bob\"}] == [{\"Name\": \"Bobby\"}]) failed\n" +
+				"    [0].Name: \"Bob\" != \"Bobby\"\n",
+		},
+		"long-arrays": {
+			left:  numbers(1, 20),
+			right: numbers(1, 19),
+			expectedOutput: ": pos: test: assert([1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 1... == " +
+				"[1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 1...) failed\n" +
+				"    [19]: only on the left: 20\n",
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			registers := map[vm.Register]*ast.Literal{
+				"0": test.left,
+				"1": test.right,
+				"2": asttest.NewLiteralBool(test.pass),
+			}
+			ins := &vm.Assert{
+				Left:  "0",
+				Right: "1",
+				Final: "2",
+				Op:    "==",
+				Pos:   "pos",
+			}
+			if test.message != nil {
+				registers["3"] = test.message
+				ins.Message = "3"
+			}
+
+			buf := bytes.NewBuffer(nil)
+			vm := &vm.VM{
+				Stack:           []map[vm.Register]*ast.Literal{registers},
+				TestOutput:      buf,
+				CurrentTestName: "test",
+			}
+			assert.NoError(t, ins.Execute(nil, vm))
+			assert.Equal(t, test.expectedOutput, buf.String())
+		})
+	}
+}
+
 func TestAssert_String(t *testing.T) {
 	ins := &vm.Assert{Left: "0", Right: "1", Final: "2", Op: "==", Pos: "pos"}
 	assert.Equal(t, "assert($0 == $1)", ins.String())
 }
+
+// numbers returns an array of the numbers from start to end.
+func numbers(start, end int) *ast.Literal {
+	array := &ast.Literal{Kind: types.NumberArray}
+	for i := start; i <= end; i++ {
+		array.Array = append(array.Array,
+			asttest.NewLiteralNumber(strconv.Itoa(i)))
+	}
+
+	return array
+}
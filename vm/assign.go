@@ -14,14 +14,7 @@ type Assign struct {
 
 // Execute implements the Instruction interface for the VM.
 func (ins *Assign) Execute(_ *int, vm *VM) error {
-	get := vm.Get(ins.Register)
-	vm.Set(ins.Result, &ast.Literal{
-		Kind:  get.Kind,
-		Value: get.Value,
-		Array: get.Array,
-		Map:   get.Map,
-		Pos:   get.Pos,
-	})
+	vm.Set(ins.Result, vm.Get(ins.Register).Copy())
 
 	return nil
 }
@@ -0,0 +1,208 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/elliotchance/ok/ast"
+)
+
+// maxBenchmarkIterations stops a benchmark that is too fast to measure from
+// running forever.
+const maxBenchmarkIterations = 1e9
+
+// BenchmarkResult is the outcome of running a benchmark.
+type BenchmarkResult struct {
+	// Name is the name of the benchmark, see BenchmarkName.
+	Name string
+
+	// N is the number of times the benchmark body was run.
+	N int
+
+	// Duration and Instructions are the totals for all N iterations.
+	Duration     time.Duration
+	Instructions int
+}
+
+// NsPerOp is the average time for each iteration, in nanoseconds.
+func (r BenchmarkResult) NsPerOp() float64 {
+	if r.N <= 0 {
+		return 0
+	}
+
+	return float64(r.Duration.Nanoseconds()) / float64(r.N)
+}
+
+// InstructionsPerOp is the average number of instructions for each iteration.
+func (r BenchmarkResult) InstructionsPerOp() float64 {
+	if r.N <= 0 {
+		return 0
+	}
+
+	return float64(r.Instructions) / float64(r.N)
+}
+
+// String returns the result in the same format as "go test -bench" so that it
+// can be compared with tools like benchstat.
+func (r BenchmarkResult) String() string {
+	return fmt.Sprintf("%s\t%8d\t%s ns/op\t%s instructions/op",
+		r.Name, r.N, prettyPrint(r.NsPerOp()), prettyPrint(r.InstructionsPerOp()))
+}
+
+// prettyPrint formats a metric with fewer decimal places as it gets larger,
+// the same as "go test -bench".
+func prettyPrint(x float64) string {
+	format := "%10.0f"
+	switch y := math.Abs(x); {
+	case y == 0 || y >= 999.95:
+	case y >= 99.995:
+		format = "%12.1f"
+	case y >= 9.9995:
+		format = "%13.2f"
+	case y >= 0.99995:
+		format = "%14.3f"
+	default:
+		format = "%15.4f"
+	}
+
+	return fmt.Sprintf(format, x)
+}
+
+// BenchmarkName returns the name that is reported for a benchmark. Like Go
+// benchmarks, it starts with "Benchmark" and cannot contain spaces.
+func BenchmarkName(name string) string {
+	name = strings.Join(strings.Fields(name), "_")
+	if name == "" {
+		return "Benchmark"
+	}
+
+	// Tools that read the output expect a name like "BenchmarkFoo" rather
+	// than "Benchmarkfoo".
+	r, size := utf8.DecodeRuneInString(name)
+
+	return "Benchmark" + string(unicode.ToUpper(r)) + name[size:]
+}
+
+// RunBenchmarks will run the benchmarks that match filter. Each benchmark body
+// is run repeatedly until it has taken at least benchTime, the result is
+// printed as each one finishes.
+//
+// A benchmark that fails an assertion (or has an unhandled error) stops
+// immediately and is counted in TestsFailed.
+func (vm *VM) RunBenchmarks(
+	filter *regexp.Regexp,
+	benchTime time.Duration,
+	packageName string,
+) (err error) {
+	defer vm.recoverExit(&err)
+
+	if err := vm.prepareGlobals(); err != nil {
+		return err
+	}
+
+	var benchmarks []*CompiledTest
+	nameWidth := 0
+	for _, t := range vm.tests {
+		if t.IsBench && filter.MatchString(t.TestName) {
+			benchmarks = append(benchmarks, t)
+			if l := len(BenchmarkName(t.TestName)); l > nameWidth {
+				nameWidth = l
+			}
+		}
+	}
+
+	for _, t := range benchmarks {
+		result, err := vm.runBenchmark(t, benchTime, packageName)
+		if err != nil {
+			return err
+		}
+
+		if !vm.CurrentTestPassed {
+			vm.TestsFailed++
+			continue
+		}
+
+		result.Name = fmt.Sprintf("%-*s", nameWidth, result.Name)
+		fmt.Fprintln(vm.TestOutput, result)
+	}
+
+	return nil
+}
+
+// runBenchmark increases the number of iterations until the benchmark runs for
+// at least benchTime. This is the same approach as "go test -bench".
+func (vm *VM) runBenchmark(
+	t *CompiledTest,
+	benchTime time.Duration,
+	packageName string,
+) (BenchmarkResult, error) {
+	vm.CurrentTestPassed = true
+	result, err := vm.runBenchmarkN(t, 1, packageName)
+
+	for err == nil && vm.CurrentTestPassed && result.Duration < benchTime &&
+		result.N < maxBenchmarkIterations {
+		last := int64(result.N)
+
+		// Predict the number of iterations needed, then grow by 20% so that
+		// it does not come in just under the goal. It must not grow too
+		// quickly in case the first iterations were not representative.
+		prevNs := result.Duration.Nanoseconds()
+		if prevNs <= 0 {
+			prevNs = 1
+		}
+		n := benchTime.Nanoseconds() * last / prevNs
+		n += n / 5
+		n = min64(n, 100*last)
+		n = max64(n, last+1)
+		n = min64(n, maxBenchmarkIterations)
+
+		result, err = vm.runBenchmarkN(t, int(n), packageName)
+	}
+
+	return result, err
+}
+
+func (vm *VM) runBenchmarkN(
+	t *CompiledTest,
+	n int,
+	packageName string,
+) (BenchmarkResult, error) {
+	result := BenchmarkResult{
+		Name: BenchmarkName(t.TestName),
+		N:    n,
+	}
+
+	instructionsBefore := vm.InstructionsExecuted
+	start := time.Now()
+	for i := 0; i < n && vm.CurrentTestPassed; i++ {
+		err := vm.runTest(t, map[string]*ast.Literal{}, packageName, nil)
+		if err != nil {
+			return result, err
+		}
+	}
+	result.Duration = time.Since(start)
+	result.Instructions = vm.InstructionsExecuted - instructionsBefore
+
+	return result, nil
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,64 @@
+package vm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliotchance/ok/vm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBenchmarkName(t *testing.T) {
+	for testName, test := range map[string]struct {
+		name     string
+		expected string
+	}{
+		"empty":       {"", "Benchmark"},
+		"lowercase":   {"foo", "BenchmarkFoo"},
+		"uppercase":   {"Foo", "BenchmarkFoo"},
+		"spaces":      {"split  many words", "BenchmarkSplit_many_words"},
+		"surrounding": {" foo ", "BenchmarkFoo"},
+		"unicode":     {"ürün", "BenchmarkÜrün"},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, vm.BenchmarkName(test.name))
+		})
+	}
+}
+
+func TestBenchmarkResult_String(t *testing.T) {
+	for testName, test := range map[string]struct {
+		result   vm.BenchmarkResult
+		expected string
+	}{
+		"large": {
+			vm.BenchmarkResult{
+				Name:         "BenchmarkFoo",
+				N:            1000,
+				Duration:     3 * time.Millisecond,
+				Instructions: 25000,
+			},
+			"BenchmarkFoo\t    1000\t      3000 ns/op\t        25.00 instructions/op",
+		},
+		"small": {
+			vm.BenchmarkResult{
+				Name:         "BenchmarkFoo",
+				N:            3,
+				Duration:     5 * time.Nanosecond,
+				Instructions: 1,
+			},
+			"BenchmarkFoo\t       3\t         1.667 ns/op\t         0.3333 instructions/op",
+		},
+		"zero": {
+			vm.BenchmarkResult{
+				Name: "BenchmarkFoo",
+			},
+			"BenchmarkFoo\t       0\t         0 ns/op\t         0 instructions/op",
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.result.String())
+		})
+	}
+}
@@ -0,0 +1,579 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/elliotchance/ok/types"
+)
+
+// An okc file is the binary form of a File. It starts with a header:
+//
+//	"OKC\x00"        magic
+//	uint16           okcVersion
+//	uint32           opcodesChecksum
+//
+// The rest of the file is made up of varints. Every string is stored once in
+// the string table (a count, followed by the length and bytes of each string)
+// and is referenced everywhere else by its index. The string table is
+// followed by the type table, the symbol table, the globals, the sources and
+// finally the tests.
+//
+// Functions are numbered in the order they appear in the file (the functions
+// of the symbols, followed by the tests). The parent of a function is stored as
+// one more than its number, or zero if it has no parent.
+//
+// Each instruction is its opcode followed by its fields in the order they are
+// declared. The opcodes, and the code that encodes and decodes each
+// instruction, is generated by ops-gen (see vm/ops.go).
+const okcMagic = "OKC\x00"
+
+// okcVersion must be incremented whenever the layout of okc files changes.
+// Changes to instructions are detected with opcodesChecksum instead.
+const okcVersion = 4
+
+// MarshalBinary encodes the file into the okc format.
+func (f *File) MarshalBinary() ([]byte, error) {
+	e := &encoder{stringIndex: map[string]uint64{}}
+	e.file(f)
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	var header [10]byte
+	copy(header[:], okcMagic)
+	binary.BigEndian.PutUint16(header[4:], okcVersion)
+	binary.BigEndian.PutUint32(header[6:], opcodesChecksum)
+
+	out := bytes.NewBuffer(header[:])
+	writeUvarint(out, uint64(len(e.stringTable)))
+	for _, s := range e.stringTable {
+		writeUvarint(out, uint64(len(s)))
+		out.WriteString(s)
+	}
+	out.Write(e.body.Bytes())
+
+	return out.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an okc file that was created with MarshalBinary. An
+// error is returned if the file was created by a different version of the
+// compiler.
+func (f *File) UnmarshalBinary(data []byte) error {
+	if len(data) < 10 || string(data[:4]) != okcMagic {
+		return errors.New("not an okc file")
+	}
+
+	version := binary.BigEndian.Uint16(data[4:])
+	checksum := binary.BigEndian.Uint32(data[6:])
+	if version != okcVersion || checksum != opcodesChecksum {
+		return fmt.Errorf("unsupported okc version %d (%08x)", version, checksum)
+	}
+
+	d := &decoder{data: data[10:]}
+	d.stringTable = make([]string, d.count())
+	for i := range d.stringTable {
+		d.stringTable[i] = string(d.bytes(d.count()))
+	}
+
+	d.file(f)
+	if d.err == nil && len(d.data) > 0 {
+		d.fail(errors.New("unexpected data at the end of the okc file"))
+	}
+
+	return d.err
+}
+
+func writeUvarint(buf *bytes.Buffer, x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], x)])
+}
+
+type encoder struct {
+	body        bytes.Buffer
+	stringIndex map[string]uint64
+	stringTable []string
+	err         error
+
+	// funcs contains the number of each function in the file.
+	funcs map[*CompiledFunc]uint64
+}
+
+func (e *encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *encoder) uvarint(x uint64) {
+	writeUvarint(&e.body, x)
+}
+
+func (e *encoder) int(x int) {
+	var b [binary.MaxVarintLen64]byte
+	e.body.Write(b[:binary.PutVarint(b[:], int64(x))])
+}
+
+func (e *encoder) bool(x bool) {
+	if x {
+		e.uvarint(1)
+	} else {
+		e.uvarint(0)
+	}
+}
+
+func (e *encoder) string(s string) {
+	index, ok := e.stringIndex[s]
+	if !ok {
+		index = uint64(len(e.stringTable))
+		e.stringIndex[s] = index
+		e.stringTable = append(e.stringTable, s)
+	}
+
+	e.uvarint(index)
+}
+
+func (e *encoder) strings(ss []string) {
+	e.uvarint(uint64(len(ss)))
+	for _, s := range ss {
+		e.string(s)
+	}
+}
+
+func (e *encoder) stringMap(m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	e.uvarint(uint64(len(keys)))
+	for _, key := range keys {
+		e.string(key)
+		e.string(m[key])
+	}
+}
+
+func (e *encoder) registers(rs Registers) {
+	e.uvarint(uint64(len(rs)))
+	for _, r := range rs {
+		e.string(string(r))
+	}
+}
+
+// typ encodes a type and all of the types it contains. A nil type is encoded
+// as a zero, otherwise the kind is offset by one.
+func (e *encoder) typ(ty *types.Type) {
+	if ty == nil {
+		e.uvarint(0)
+
+		return
+	}
+
+	e.uvarint(uint64(ty.Kind) + 1)
+	e.string(ty.Name)
+	e.string(ty.Ref)
+	e.typ(ty.Element)
+	e.types(ty.Arguments)
+	e.types(ty.Returns)
+	e.strings(ty.TypeParameters)
+	e.types(ty.TypeArguments)
+	e.strings(ty.Values)
+
+	// Properties are offset by one so that nil can be distinguished from an
+	// interface without any properties.
+	if ty.Properties == nil {
+		e.uvarint(0)
+
+		return
+	}
+
+	e.uvarint(uint64(len(ty.Properties)) + 1)
+	for _, name := range ty.SortedPropertyNames() {
+		e.string(name)
+		e.typ(ty.Properties[name])
+	}
+}
+
+func (e *encoder) types(tys []*types.Type) {
+	e.uvarint(uint64(len(tys)))
+	for _, ty := range tys {
+		e.typ(ty)
+	}
+}
+
+// instructions encodes the instructions followed by their positions. A nil
+// value is encoded as a zero, otherwise the number of instructions is offset by
+// one.
+func (e *encoder) instructions(ins *Instructions) {
+	if ins == nil {
+		e.uvarint(0)
+
+		return
+	}
+
+	e.uvarint(uint64(len(ins.Instructions)) + 1)
+	for _, instruction := range ins.Instructions {
+		encodeInstruction(e, instruction)
+	}
+
+	var offsets []int
+	for offset := range ins.Positions {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+
+	e.uvarint(uint64(len(offsets)))
+	for _, offset := range offsets {
+		e.int(offset)
+		e.string(ins.Positions[offset])
+	}
+}
+
+func (e *encoder) fn(fn *CompiledFunc) {
+	e.strings(fn.Arguments)
+	e.instructions(fn.Instructions)
+	e.int(fn.Registers)
+	e.uvarint(uint64(len(fn.Finally)))
+	for _, finally := range fn.Finally {
+		e.instructions(finally)
+	}
+	e.string(string(fn.Type))
+	e.string(fn.Name)
+	e.string(fn.UniqueName)
+	e.string(fn.Pos)
+
+	parent, ok := e.funcs[fn.Parent]
+	if fn.Parent != nil && !ok {
+		e.fail(fmt.Errorf("parent of %s is not in the file", fn.UniqueName))
+	}
+	e.uvarint(parent)
+}
+
+func (e *encoder) file(f *File) {
+	keys := make([]string, 0, len(f.Types))
+	for key := range f.Types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	e.uvarint(uint64(len(keys)))
+	for _, key := range keys {
+		e.string(key)
+		e.typ(f.Types[key])
+	}
+
+	symbols := make([]string, 0, len(f.Symbols))
+	for key := range f.Symbols {
+		symbols = append(symbols, string(key))
+	}
+	sort.Strings(symbols)
+
+	e.funcs = map[*CompiledFunc]uint64{}
+	for _, key := range symbols {
+		if fn := f.Symbols[SymbolRegister(key)].Func; fn != nil {
+			e.funcs[fn] = uint64(len(e.funcs)) + 1
+		}
+	}
+	for _, test := range f.Tests {
+		e.funcs[test.CompiledFunc] = uint64(len(e.funcs)) + 1
+	}
+
+	e.uvarint(uint64(len(symbols)))
+	for _, key := range symbols {
+		symbol := f.Symbols[SymbolRegister(key)]
+		e.string(key)
+		e.string(string(symbol.Type))
+		e.string(symbol.Value)
+		e.string(symbol.Interface)
+		e.bool(symbol.Func != nil)
+		if symbol.Func != nil {
+			e.fn(symbol.Func)
+		}
+	}
+
+	e.stringMap(f.Globals)
+	e.stringMap(f.Sources)
+
+	e.uvarint(uint64(len(f.Tests)))
+	for _, test := range f.Tests {
+		e.fn(test.CompiledFunc)
+		e.string(test.TestName)
+		e.bool(test.IsBench)
+		e.instructions(test.Setup)
+		e.instructions(test.Teardown)
+		e.instructions(test.Cases)
+		e.string(string(test.CasesResult))
+		e.string(string(test.CaseValue))
+		e.string(string(test.CaseKey))
+		e.bool(test.IsFuzz)
+		e.uvarint(uint64(len(test.FuzzArguments)))
+		for _, arg := range test.FuzzArguments {
+			e.string(arg.Name)
+			e.string(string(arg.Type))
+		}
+		e.stringMap(test.FuzzConstructors)
+	}
+}
+
+// decoder reads the values written by the encoder. Once an error has occurred
+// all values will be zero, so errors only need to be checked at the end.
+type decoder struct {
+	data        []byte
+	stringTable []string
+	err         error
+
+	// funcs and parents are used to link each function to its parent once
+	// all of the functions have been decoded.
+	funcs   []*CompiledFunc
+	parents []uint64
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+		d.data = nil
+	}
+}
+
+func (d *decoder) uvarint() uint64 {
+	x, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail(errors.New("unexpected end of okc file"))
+
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return x
+}
+
+// count reads a length. Every element takes at least one byte, so it cannot be
+// larger than the remaining data (allowing for lengths that are offset by one).
+// This prevents huge allocations from a corrupt file.
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.data))+1 {
+		d.fail(errors.New("unexpected end of okc file"))
+
+		return 0
+	}
+
+	return int(n)
+}
+
+func (d *decoder) bytes(n int) []byte {
+	b := d.data[:n]
+	d.data = d.data[n:]
+
+	return b
+}
+
+func (d *decoder) int() int {
+	x, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail(errors.New("unexpected end of okc file"))
+
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return int(x)
+}
+
+func (d *decoder) bool() bool {
+	return d.uvarint() != 0
+}
+
+func (d *decoder) string() string {
+	index := d.uvarint()
+	if index >= uint64(len(d.stringTable)) {
+		d.fail(fmt.Errorf("invalid string %d in okc file", index))
+
+		return ""
+	}
+
+	return d.stringTable[index]
+}
+
+func (d *decoder) strings() []string {
+	var ss []string
+	for n := d.count(); n > 0; n-- {
+		ss = append(ss, d.string())
+	}
+
+	return ss
+}
+
+func (d *decoder) stringMap() map[string]string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, n)
+	for ; n > 0; n-- {
+		key := d.string()
+		m[key] = d.string()
+	}
+
+	return m
+}
+
+func (d *decoder) registers() Registers {
+	var rs Registers
+	for n := d.count(); n > 0; n-- {
+		rs = append(rs, Register(d.string()))
+	}
+
+	return rs
+}
+
+func (d *decoder) typ() *types.Type {
+	kind := d.uvarint()
+	if kind == 0 {
+		return nil
+	}
+
+	ty := &types.Type{
+		Kind:           types.Kind(kind - 1),
+		Name:           d.string(),
+		Ref:            d.string(),
+		Element:        d.typ(),
+		Arguments:      d.types(),
+		Returns:        d.types(),
+		TypeParameters: d.strings(),
+		TypeArguments:  d.types(),
+		Values:         d.strings(),
+	}
+
+	if n := d.count(); n > 0 {
+		ty.Properties = make(map[string]*types.Type, n-1)
+		for n--; n > 0; n-- {
+			name := d.string()
+			ty.Properties[name] = d.typ()
+		}
+	}
+
+	return ty
+}
+
+func (d *decoder) types() []*types.Type {
+	var tys []*types.Type
+	for n := d.count(); n > 0; n-- {
+		tys = append(tys, d.typ())
+	}
+
+	return tys
+}
+
+func (d *decoder) instructions() *Instructions {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+
+	ins := &Instructions{
+		Instructions: make([]Instruction, 0, n-1),
+	}
+	for n--; n > 0 && d.err == nil; n-- {
+		ins.Instructions = append(ins.Instructions, decodeInstruction(d))
+	}
+
+	if n := d.count(); n > 0 {
+		ins.Positions = make(map[int]string, n)
+		for ; n > 0; n-- {
+			offset := d.int()
+			ins.Positions[offset] = d.string()
+		}
+	}
+
+	return ins
+}
+
+func (d *decoder) fn() *CompiledFunc {
+	fn := &CompiledFunc{
+		Arguments:    d.strings(),
+		Instructions: d.instructions(),
+		Registers:    d.int(),
+	}
+
+	for n := d.count(); n > 0; n-- {
+		fn.Finally = append(fn.Finally, d.instructions())
+	}
+
+	fn.Type = TypeRegister(d.string())
+	fn.Name = d.string()
+	fn.UniqueName = d.string()
+	fn.Pos = d.string()
+
+	d.funcs = append(d.funcs, fn)
+	d.parents = append(d.parents, d.uvarint())
+
+	return fn
+}
+
+func (d *decoder) file(f *File) {
+	f.Types = types.Registry{}
+	for n := d.count(); n > 0; n-- {
+		key := d.string()
+		f.Types[key] = d.typ()
+	}
+
+	f.Symbols = map[SymbolRegister]*Symbol{}
+	for n := d.count(); n > 0; n-- {
+		key := SymbolRegister(d.string())
+		symbol := &Symbol{
+			Type:      TypeRegister(d.string()),
+			Value:     d.string(),
+			Interface: d.string(),
+		}
+		if d.bool() {
+			symbol.Func = d.fn()
+		}
+		f.Symbols[key] = symbol
+	}
+
+	f.Globals = d.stringMap()
+	f.Sources = d.stringMap()
+
+	f.Tests = nil
+	for n := d.count(); n > 0; n-- {
+		test := &CompiledTest{
+			CompiledFunc: d.fn(),
+			TestName:     d.string(),
+			IsBench:      d.bool(),
+			Setup:        d.instructions(),
+			Teardown:     d.instructions(),
+			Cases:        d.instructions(),
+			CasesResult:  Register(d.string()),
+			CaseValue:    Register(d.string()),
+			CaseKey:      Register(d.string()),
+			IsFuzz:       d.bool(),
+		}
+
+		for n := d.count(); n > 0; n-- {
+			test.FuzzArguments = append(test.FuzzArguments, &FuzzArgument{
+				Name: d.string(),
+				Type: TypeRegister(d.string()),
+			})
+		}
+		test.FuzzConstructors = d.stringMap()
+
+		f.Tests = append(f.Tests, test)
+	}
+
+	for i, parent := range d.parents {
+		if parent > uint64(len(d.funcs)) {
+			d.fail(errors.New("invalid parent function"))
+			return
+		}
+
+		if parent > 0 {
+			d.funcs[i].Parent = d.funcs[parent-1]
+		}
+	}
+}
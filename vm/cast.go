@@ -106,3 +106,34 @@ func (ins *CastData) Execute(_ *int, vm *VM) error {
 func (ins *CastData) String() string {
 	return fmt.Sprintf("%s = data %s", ins.Result, ins.X)
 }
+
+// CastEnum returns the value of an enum that has the same name as a string. An
+// error is raised if there is no value with that name.
+type CastEnum struct {
+	X, Result Register
+	Type      TypeRegister
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *CastEnum) Execute(_ *int, vm *VM) error {
+	x := vm.Get(ins.X)
+	ty := vm.Types[ins.Type]
+
+	if !ty.HasValue(x.Value) {
+		vm.Raise(fmt.Sprintf("not a %s: %s", ty.Name, x.Value))
+
+		return nil
+	}
+
+	vm.Set(ins.Result, &ast.Literal{
+		Kind:  ty,
+		Value: x.Value,
+	})
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *CastEnum) String() string {
+	return fmt.Sprintf("%s = %s %s", ins.Result, ins.Type, ins.X)
+}
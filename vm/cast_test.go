@@ -21,3 +21,8 @@ func TestCastChar_String(t *testing.T) {
 	ins := &vm.CastChar{X: "1", Result: "2"}
 	assert.Equal(t, "$2 = char $1", ins.String())
 }
+
+func TestCastEnum_String(t *testing.T) {
+	ins := &vm.CastEnum{X: "1", Result: "2", Type: "3"}
+	assert.Equal(t, "$2 = 3 $1", ins.String())
+}
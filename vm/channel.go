@@ -0,0 +1,200 @@
+package vm
+
+import (
+	"github.com/elliotchance/ok/ast"
+)
+
+// deadlock is raised when a task would block on a channel but there are no
+// other tasks that could unblock it.
+const deadlock = "all tasks are blocked"
+
+// channel is the value of a channel. Only the running task can use a channel,
+// so it does not need to be locked.
+type channel struct {
+	size   int
+	buffer []*ast.Literal
+	closed bool
+
+	// senders and receivers are blocked, in the order they started waiting.
+	senders, receivers []*channelOp
+}
+
+// waiter is a task that is blocked on one or more channel operations. A select
+// waits for the first of several operations.
+type waiter struct {
+	task *task
+	ops  []*channelOp
+
+	// These are set when the waiter is resumed. chosen is the index of the
+	// operation that was performed, value is the value received and closed is
+	// true if the channel was closed instead.
+	done   bool
+	chosen int
+	value  *ast.Literal
+	closed bool
+}
+
+// channelOp is a send or receive that is waiting.
+type channelOp struct {
+	waiter  *waiter
+	channel *channel
+	index   int
+	send    bool
+	value   *ast.Literal
+}
+
+func (w *waiter) resume(op *channelOp, value *ast.Literal, closed bool) {
+	w.done = true
+	w.chosen = op.index
+	w.value = value
+	w.closed = closed
+	w.task.vm.scheduler.add(w.task)
+}
+
+// trySend returns false if the value cannot be sent without blocking.
+func (c *channel) trySend(value *ast.Literal) bool {
+	if op := c.next(&c.receivers); op != nil {
+		op.waiter.resume(op, value, false)
+
+		return true
+	}
+
+	if len(c.buffer) < c.size {
+		c.buffer = append(c.buffer, value)
+
+		return true
+	}
+
+	return false
+}
+
+// tryReceive returns false if there is no value to receive without blocking.
+func (c *channel) tryReceive() (*ast.Literal, bool) {
+	if len(c.buffer) > 0 {
+		value := c.buffer[0]
+		c.buffer = c.buffer[1:]
+
+		// There is now room for a sender that was blocked.
+		if op := c.next(&c.senders); op != nil {
+			c.buffer = append(c.buffer, op.value)
+			op.waiter.resume(op, nil, false)
+		}
+
+		return value, true
+	}
+
+	if op := c.next(&c.senders); op != nil {
+		op.waiter.resume(op, nil, false)
+
+		return op.value, true
+	}
+
+	return nil, false
+}
+
+// next removes and returns the first operation that is still waiting.
+func (c *channel) next(ops *[]*channelOp) *channelOp {
+	for len(*ops) > 0 {
+		op := (*ops)[0]
+		*ops = (*ops)[1:]
+		if !op.waiter.done {
+			return op
+		}
+	}
+
+	return nil
+}
+
+// close resumes all of the tasks that are waiting on the channel.
+func (c *channel) close() {
+	c.closed = true
+	for _, op := range append(c.receivers, c.senders...) {
+		if !op.waiter.done {
+			op.waiter.resume(op, nil, true)
+		}
+	}
+
+	c.receivers = nil
+	c.senders = nil
+}
+
+// remove removes the operations of a waiter that was resumed.
+func (c *channel) remove(w *waiter) {
+	for _, ops := range []*[]*channelOp{&c.senders, &c.receivers} {
+		var remaining []*channelOp
+		for _, op := range *ops {
+			if op.waiter != w {
+				remaining = append(remaining, op)
+			}
+		}
+		*ops = remaining
+	}
+}
+
+// wait blocks the current task until one of the operations of the waiter has
+// been performed by another task. It returns false if there are no other tasks
+// that could perform them.
+func (vm *VM) wait(w *waiter) bool {
+	for _, op := range w.ops {
+		if op.send {
+			op.channel.senders = append(op.channel.senders, op)
+		} else {
+			op.channel.receivers = append(op.channel.receivers, op)
+		}
+	}
+
+	ok := vm.scheduler != nil && vm.scheduler.block(vm.task)
+	w.done = true
+	for _, op := range w.ops {
+		op.channel.remove(w)
+	}
+
+	return ok
+}
+
+// send blocks until the value can be sent.
+func (vm *VM) send(c *channel, value *ast.Literal) {
+	if c.closed {
+		vm.Raise("send on closed channel")
+
+		return
+	}
+
+	if c.trySend(value) {
+		return
+	}
+
+	w := &waiter{task: vm.task}
+	w.ops = []*channelOp{{waiter: w, channel: c, send: true, value: value}}
+	if !vm.wait(w) {
+		vm.Raise(deadlock)
+
+		return
+	}
+
+	if w.closed {
+		vm.Raise("send on closed channel")
+	}
+}
+
+// receive blocks until there is a value to receive. It returns false if the
+// channel is closed, or an error was raised.
+func (vm *VM) receive(c *channel) (*ast.Literal, bool) {
+	if value, ok := c.tryReceive(); ok {
+		return value, true
+	}
+
+	if c.closed {
+		return nil, false
+	}
+
+	w := &waiter{task: vm.task}
+	w.ops = []*channelOp{{waiter: w, channel: c}}
+	if !vm.wait(w) {
+		vm.Raise(deadlock)
+
+		return nil, false
+	}
+
+	return w.value, !w.closed
+}
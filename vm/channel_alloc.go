@@ -0,0 +1,36 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/number"
+)
+
+// ChannelAlloc creates a channel that can buffer Size values.
+type ChannelAlloc struct {
+	Size, Result Register
+	Kind         TypeRegister
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *ChannelAlloc) Execute(_ *int, vm *VM) error {
+	size := number.Int(number.NewNumber(vm.Get(ins.Size).Value))
+	if size < 0 {
+		vm.Raise(fmt.Sprintf("channel size cannot be negative: %d", size))
+
+		return nil
+	}
+
+	vm.Set(ins.Result, &ast.Literal{
+		Kind:    vm.Types[ins.Kind],
+		Channel: &channel{size: size},
+	})
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *ChannelAlloc) String() string {
+	return fmt.Sprintf("%s = %s with size %s", ins.Result, ins.Kind, ins.Size)
+}
@@ -0,0 +1,135 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannel_Execute(t *testing.T) {
+	for testName, test := range map[string]struct {
+		size         string
+		instructions []vm.Instruction
+		expected     *ast.Literal
+		err          string
+	}{
+		"buffered": {
+			size: "2",
+			instructions: []vm.Instruction{
+				&vm.Send{Channel: "4", Value: "1"},
+				&vm.Send{Channel: "4", Value: "2"},
+				&vm.Receive{Channel: "4", Result: "5"},
+			},
+			expected: asttest.NewLiteralNumber("1"),
+		},
+		"receive-after-close": {
+			size: "1",
+			instructions: []vm.Instruction{
+				&vm.Send{Channel: "4", Value: "2"},
+				&vm.CloseChannel{Channel: "4"},
+				&vm.Receive{Channel: "4", Result: "5"},
+			},
+			expected: asttest.NewLiteralNumber("2"),
+		},
+		"receive-closed": {
+			size: "1",
+			instructions: []vm.Instruction{
+				&vm.CloseChannel{Channel: "4"},
+				&vm.Receive{Channel: "4", Result: "5"},
+			},
+			err: "receive from closed channel",
+		},
+		"send-closed": {
+			size: "1",
+			instructions: []vm.Instruction{
+				&vm.CloseChannel{Channel: "4"},
+				&vm.Send{Channel: "4", Value: "1"},
+			},
+			err: "send on closed channel",
+		},
+		"close-closed": {
+			size: "1",
+			instructions: []vm.Instruction{
+				&vm.CloseChannel{Channel: "4"},
+				&vm.CloseChannel{Channel: "4"},
+			},
+			err: "close of closed channel",
+		},
+		"full": {
+			size: "1",
+			instructions: []vm.Instruction{
+				&vm.Send{Channel: "4", Value: "1"},
+				&vm.Send{Channel: "4", Value: "2"},
+			},
+			err: "all tasks are blocked",
+		},
+		"unbuffered": {
+			size: "0",
+			instructions: []vm.Instruction{
+				&vm.Receive{Channel: "4", Result: "5"},
+			},
+			err: "all tasks are blocked",
+		},
+		"negative-size": {
+			size: "-1",
+			err:  "channel size cannot be negative: -1",
+		},
+		"select-else": {
+			size: "1",
+			instructions: []vm.Instruction{
+				&vm.Select{
+					Channels: []vm.Register{"4"},
+					Values:   []vm.Register{""},
+					Else:     true,
+					Chosen:   "5",
+					Value:    "6",
+				},
+			},
+			expected: asttest.NewLiteralNumber("-1"),
+		},
+		"select-send": {
+			size: "1",
+			instructions: []vm.Instruction{
+				&vm.Select{
+					Channels: []vm.Register{"4", "4"},
+					Values:   []vm.Register{"", "1"},
+					Chosen:   "5",
+					Value:    "6",
+				},
+			},
+			expected: asttest.NewLiteralNumber("1"),
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			m := vm.NewVM("pkg")
+			m.Deterministic = true
+			m.Types["chan"] = types.NewChannel(types.Number)
+			m.Stack = []map[vm.Register]*ast.Literal{{
+				"1": asttest.NewLiteralNumber("1"),
+				"2": asttest.NewLiteralNumber("2"),
+				"3": asttest.NewLiteralNumber(test.size),
+			}}
+
+			instructions := append([]vm.Instruction{
+				&vm.ChannelAlloc{Size: "3", Result: "4", Kind: "chan"},
+			}, test.instructions...)
+			for _, ins := range instructions {
+				assert.NoError(t, ins.Execute(nil, m))
+				if m.ErrType != nil {
+					break
+				}
+			}
+
+			if test.err != "" {
+				assert.Equal(t, test.err, m.ErrValue.Map["Error"].Value)
+			} else {
+				assert.Nil(t, m.ErrType)
+				assert.Equal(t, test.expected, m.Stack[0]["5"])
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package vm
+
+import (
+	"fmt"
+)
+
+// CloseChannel closes a channel. Any values in the buffer can still be
+// received.
+type CloseChannel struct {
+	Channel Register
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *CloseChannel) Execute(_ *int, vm *VM) error {
+	c := vm.Get(ins.Channel).Channel.(*channel)
+	if c.closed {
+		vm.Raise("close of closed channel")
+
+		return nil
+	}
+
+	c.close()
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *CloseChannel) String() string {
+	return fmt.Sprintf("%s.Close()", ins.Channel)
+}
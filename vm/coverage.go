@@ -0,0 +1,30 @@
+package vm
+
+// Coverage records how many times each statement is executed. It is enabled by
+// setting VM.Coverage before running.
+type Coverage struct {
+	// Counts is indexed by the position of each statement that has been
+	// executed.
+	Counts map[string]int
+}
+
+// NewCoverage creates an empty coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{
+		Counts: map[string]int{},
+	}
+}
+
+func (c *Coverage) record(ins *Instructions, i int) {
+	if pos, ok := ins.Positions[i]; ok {
+		c.Counts[pos]++
+	}
+}
+
+// Add adds the counts from other, such as the coverage from a test that ran in
+// another VM.
+func (c *Coverage) Add(other *Coverage) {
+	for pos, count := range other.Counts {
+		c.Counts[pos] += count
+	}
+}
@@ -0,0 +1,13 @@
+package vm
+
+// Debugger can be attached to a VM to pause and inspect the program while it is
+// running. See the debugger package.
+type Debugger interface {
+	// BeforeInstruction is called before each instruction is executed. It may
+	// block to pause the program. i is the index of the instruction in ins.
+	BeforeInstruction(vm *VM, ins *Instructions, i int)
+
+	// ErrorRaised is called when an instruction raises an error. The error
+	// will be in ErrType, ErrValue and ErrStack.
+	ErrorRaised(vm *VM)
+}
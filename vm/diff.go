@@ -0,0 +1,156 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/util"
+)
+
+// isComposite returns true for arrays, maps and objects. These are described
+// with a diff rather than their whole value when an assertion fails.
+func isComposite(v *ast.Literal) bool {
+	switch v.Kind.Kind {
+	case types.KindArray, types.KindMap:
+		return true
+
+	case types.KindResolvedInterface, types.KindUnresolvedInterface:
+		return v.Map != nil
+	}
+
+	return false
+}
+
+// diffLiterals returns one line for each difference between two values. Each
+// line starts with the path to the element, like `[3]`, `["foo"]` or `.Name`.
+// Elements that are not composite are compared by value.
+func diffLiterals(path string, left, right *ast.Literal) []string {
+	if left.Kind.Kind != right.Kind.Kind || !isComposite(left) ||
+		!isComposite(right) {
+		if compareValue(left, right) {
+			return nil
+		}
+
+		return []string{fmt.Sprintf("%s: %s != %s", path,
+			renderLiteral(left, true), renderLiteral(right, true))}
+	}
+
+	var diff []string
+	switch left.Kind.Kind {
+	case types.KindArray:
+		for i := 0; i < len(left.Array) || i < len(right.Array); i++ {
+			diff = append(diff, diffElement(fmt.Sprintf("%s[%d]", path, i),
+				element(left.Array, i), element(right.Array, i))...)
+		}
+
+	case types.KindMap:
+		for _, key := range mapKeys(left, right, false) {
+			diff = append(diff, diffElement(fmt.Sprintf("%s[%q]", path, key),
+				left.Map[key], right.Map[key])...)
+		}
+
+	default:
+		for _, key := range mapKeys(left, right, true) {
+			diff = append(diff, diffElement(fmt.Sprintf("%s.%s", path, key),
+				left.Map[key], right.Map[key])...)
+		}
+	}
+
+	return diff
+}
+
+// diffElement compares an element that may only exist on one side.
+func diffElement(path string, left, right *ast.Literal) []string {
+	switch {
+	case left == nil:
+		return []string{fmt.Sprintf("%s: only on the right: %s", path,
+			renderLiteral(right, true))}
+
+	case right == nil:
+		return []string{fmt.Sprintf("%s: only on the left: %s", path,
+			renderLiteral(left, true))}
+	}
+
+	return diffLiterals(path, left, right)
+}
+
+func element(elements []*ast.Literal, i int) *ast.Literal {
+	if i < len(elements) {
+		return elements[i]
+	}
+
+	return nil
+}
+
+// mapKeys returns the sorted keys from both sides. For objects, only the
+// public properties that are not functions are returned. This is the same as
+// what is rendered for an object.
+func mapKeys(left, right *ast.Literal, isObject bool) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]*ast.Literal{left.Map, right.Map} {
+		for key, value := range m {
+			if seen[key] || (isObject && (!util.IsPublic(key) ||
+				value.Kind.Kind == types.KindFunc)) {
+				continue
+			}
+
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// diffLines returns the lines that were removed from expected (starting with
+// "-") and added to actual (starting with "+"). Each line includes its line
+// number from expected or actual.
+func diffLines(expected, actual string) []string {
+	a, b := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, fmt.Sprintf("-%d: %s", i+1, a[i]))
+			i++
+
+		default:
+			diff = append(diff, fmt.Sprintf("+%d: %s", j+1, b[j]))
+			j++
+		}
+	}
+
+	return diff
+}
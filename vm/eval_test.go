@@ -0,0 +1,64 @@
+package vm_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVM_Eval_ReturnOnExit(t *testing.T) {
+	for testName, test := range map[string]struct {
+		instructions []vm.Instruction
+		err          error
+	}{
+		"ok": {
+			instructions: []vm.Instruction{
+				&vm.AssignSymbol{Result: "1", Symbol: "0"},
+			},
+		},
+		"exit": {
+			instructions: []vm.Instruction{
+				&vm.AssignSymbol{Result: "1", Symbol: "0"},
+				&vm.Exit{Status: "1"},
+			},
+			err: &vm.ExitError{Status: 3},
+		},
+		"panic": {
+			instructions: []vm.Instruction{
+				&vm.Len{Argument: "9", Result: "1"},
+			},
+			err: &vm.ExitError{Status: 1},
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			m := vm.NewVM("pkg")
+			m.Stdout = bytes.NewBuffer(nil)
+			m.ReturnOnExit = true
+			require.NoError(t, m.LoadFile(&vm.File{
+				Types: types.Registry{"0": types.Number},
+				Symbols: map[vm.SymbolRegister]*vm.Symbol{
+					"0": {Type: "0", Value: "3"},
+				},
+			}))
+
+			err := m.Eval(&vm.CompiledFunc{
+				Instructions: vm.NewInstructions(test.instructions...),
+			})
+			assert.Equal(t, test.err, err)
+			assert.Len(t, m.Stack, 1)
+
+			// The scope can still be used after exiting.
+			err = m.Eval(&vm.CompiledFunc{
+				Instructions: vm.NewInstructions(
+					&vm.AssignSymbol{Result: "2", Symbol: "0"},
+				),
+			})
+			assert.NoError(t, err)
+			assert.Len(t, m.Stack, 1)
+		})
+	}
+}
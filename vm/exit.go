@@ -14,7 +14,7 @@ type Exit struct {
 
 // Execute implements the Instruction interface for the VM.
 func (ins *Exit) Execute(_ *int, vm *VM) error {
-	os.Exit(number.Int(number.NewNumber(vm.Get(ins.Status).Value)))
+	vm.exit(number.Int(number.NewNumber(vm.Get(ins.Status).Value)))
 
 	return nil
 }
@@ -23,3 +23,37 @@ func (ins *Exit) Execute(_ *int, vm *VM) error {
 func (ins *Exit) String() string {
 	return fmt.Sprintf("runtime.Exit(%s)", ins.Status)
 }
+
+// ExitError is returned by Run or RunTests when the program exits and
+// ReturnOnExit is true.
+type ExitError struct {
+	Status int
+}
+
+// Error implements the error interface.
+func (err *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", err.Status)
+}
+
+// exitStatus is used to unwind the VM when it exits and ReturnOnExit is true.
+type exitStatus int
+
+func (vm *VM) exit(status int) {
+	if !vm.ReturnOnExit {
+		os.Exit(status)
+	}
+
+	panic(exitStatus(status))
+}
+
+// recoverExit must be deferred by the functions that start running a program.
+func (vm *VM) recoverExit(err *error) {
+	if r := recover(); r != nil {
+		status, ok := r.(exitStatus)
+		if !ok {
+			panic(r)
+		}
+
+		*err = &ExitError{Status: int(status)}
+	}
+}
@@ -66,6 +66,20 @@ func (c *CompiledFunc) Append(instruction Instruction) {
 	c.Instructions.Instructions = append(c.Instructions.Instructions, instruction)
 }
 
+// StartStatement records that the next instruction to be appended is the start
+// of the statement at pos.
+func (c *CompiledFunc) StartStatement(pos string) {
+	if pos == "" {
+		return
+	}
+
+	if c.Instructions.Positions == nil {
+		c.Instructions.Positions = map[int]string{}
+	}
+
+	c.Instructions.Positions[len(c.Instructions.Instructions)] = pos
+}
+
 func (c *CompiledFunc) NewVariable(variableName string, kind *types.Type) {
 	// TODO(elliot): Check already registered variables.
 	c.variables[variableName] = kind
@@ -83,3 +97,15 @@ func (c *CompiledFunc) GetTypeForVariable(
 
 	return ty, ok
 }
+
+// Copy returns a copy of the function that can be compiled into without
+// changing the variables of the original.
+func (c *CompiledFunc) Copy() *CompiledFunc {
+	fn := *c
+	fn.variables = map[string]*types.Type{}
+	for name, ty := range c.variables {
+		fn.variables[name] = ty
+	}
+
+	return &fn
+}
@@ -0,0 +1,703 @@
+package vm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/types"
+)
+
+// fuzzDir is the directory, inside the snapshotDir, that contains the corpus
+// of each fuzz target.
+const fuzzDir = "fuzz"
+
+const (
+	// maxFuzzSize is the largest size of the generated values. The size starts
+	// at zero and grows with each input so that small inputs are tried first.
+	maxFuzzSize = 100
+
+	// maxShrinkRuns limits the number of times a failing input is run while
+	// trying to make it smaller.
+	maxShrinkRuns = 1000
+)
+
+var (
+	interestingNumbers = []string{
+		"0", "1", "-1", "0.5", "-0.5", "0.001", "255", "256", "65535",
+		"2147483647", "-2147483648", "1000000000000",
+	}
+
+	interestingRunes = []rune{
+		' ', '\t', '\n', '"', '\\', '{', '}', 'é', 'ß', '世', '🙂', '\u00a0',
+		'\u200b',
+	}
+)
+
+// errConstructorRaised is returned when an object could not be created because
+// its constructor raised an error. The input is not used.
+var errConstructorRaised = errors.New("the constructor raised an error")
+
+// FuzzArgument is an argument of a fuzz target.
+type FuzzArgument struct {
+	Name string
+	Type TypeRegister
+}
+
+// fuzzValue is a generated value for an argument. This is stored in the corpus
+// instead of the literal so that objects can be created again with their
+// constructor.
+type fuzzValue struct {
+	// Value is used for bools, chars, data, numbers and strings.
+	Value string `json:",omitempty"`
+
+	// Elements are the elements of an array, the values of a map (in the same
+	// order as Keys) or the arguments to the constructor of an object.
+	Elements []*fuzzValue `json:",omitempty"`
+	Keys     []string     `json:",omitempty"`
+}
+
+// fuzzer generates, runs and shrinks the inputs for a single fuzz target.
+type fuzzer struct {
+	vm          *VM
+	test        *CompiledTest
+	packageName string
+}
+
+// RunFuzz generates inputs for each fuzz target that matches filter, until
+// fuzzTime has passed or an input fails. A failing input is made as small as
+// possible and written to the corpus. It is then run again so that it is
+// reported (and counted in TestsFailed) the same as when it is replayed by
+// RunTests.
+func (vm *VM) RunFuzz(
+	filter *regexp.Regexp,
+	fuzzTime time.Duration,
+	verbose bool,
+	packageName string,
+) (err error) {
+	defer vm.recoverExit(&err)
+
+	if err := vm.prepareGlobals(); err != nil {
+		return err
+	}
+
+	for _, test := range vm.tests {
+		if !test.IsFuzz || !filter.MatchString(test.TestName) {
+			continue
+		}
+
+		f := &fuzzer{vm: vm, test: test, packageName: packageName}
+		err := f.run(fuzzTime, verbose)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *fuzzer) run(fuzzTime time.Duration, verbose bool) error {
+	deadline := time.Now().Add(fuzzTime)
+	inputs := 0
+	for ; time.Now().Before(deadline); inputs++ {
+		values := f.generate(inputs % (maxFuzzSize + 1))
+		passed, err := f.passes(values)
+		if err != nil {
+			return err
+		}
+
+		if passed {
+			continue
+		}
+
+		values, err = f.shrink(values)
+		if err != nil {
+			return err
+		}
+
+		return f.fail(values, verbose)
+	}
+
+	fmt.Fprintf(f.vm.TestOutput, "%s: %s: %d inputs passed\n",
+		f.packageName, f.test.TestName, inputs)
+
+	return nil
+}
+
+// passes runs the fuzz target without any output. Inputs that cannot be used
+// because a constructor raised an error are treated as passing.
+func (f *fuzzer) passes(values []*fuzzValue) (bool, error) {
+	vm := f.vm
+	stdout, testOutput, reporter := vm.Stdout, vm.TestOutput, vm.TestReporter
+	assertions := vm.TotalAssertions
+	vm.Stdout, vm.TestOutput, vm.TestReporter = ioutil.Discard, ioutil.Discard, nil
+	defer func() {
+		vm.Stdout, vm.TestOutput, vm.TestReporter = stdout, testOutput, reporter
+		vm.TotalAssertions = assertions
+	}()
+
+	variables, err := f.variables(values)
+	if err == errConstructorRaised {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	vm.CurrentTestPassed = true
+	err = vm.runTest(f.test, map[string]*ast.Literal{}, f.packageName,
+		variables)
+
+	return vm.CurrentTestPassed, err
+}
+
+// fail writes the failing input to the corpus and runs it again as a test.
+func (f *fuzzer) fail(values []*fuzzValue, verbose bool) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:])[:16]
+	dir := fuzzCorpusPath(f.test)
+	path := filepath.Join(dir, name)
+	err = os.MkdirAll(dir, 0755)
+	if err == nil {
+		err = ioutil.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	c, err := f.testCase(name, values)
+	if err != nil {
+		return err
+	}
+
+	err = f.vm.runTestCase(c, verbose, f.packageName)
+	if err != nil {
+		return err
+	}
+
+	wd, _ := os.Getwd()
+	fmt.Fprintf(f.vm.TestOutput, "%s: %s: %s: failing input written to %s\n",
+		f.packageName, strings.TrimPrefix(f.test.Pos, wd), f.test.TestName,
+		strings.TrimPrefix(path, wd))
+	for _, arg := range f.test.FuzzArguments {
+		fmt.Fprintf(f.vm.TestOutput, "    %s = %s\n", arg.Name,
+			renderLiteral(c.variables[Register(arg.Name)], true))
+	}
+
+	return nil
+}
+
+// fuzzCorpusPath returns the directory that contains the corpus for a fuzz
+// target.
+func fuzzCorpusPath(test *CompiledTest) string {
+	name := strings.Join(strings.Fields(test.TestName), "_")
+	name = strings.ReplaceAll(name, "/", "_")
+
+	return filepath.Join(testDir(test.Pos), snapshotDir, fuzzDir, name)
+}
+
+// fuzzCorpus returns a test case for each input in the corpus of a fuzz target.
+// The name of each case is the name of the target followed by "/" and the name
+// of the file.
+func (vm *VM) fuzzCorpus(test *CompiledTest) ([]testCase, error) {
+	dir := fuzzCorpusPath(test)
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &fuzzer{vm: vm, test: test}
+	var cases []testCase
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var values []*fuzzValue
+		err = json.Unmarshal(data, &values)
+		if err == nil && len(values) != len(test.FuzzArguments) {
+			err = fmt.Errorf("expected %d values but found %d",
+				len(test.FuzzArguments), len(values))
+		}
+
+		var c testCase
+		if err == nil {
+			c, err = f.testCase(file.Name(), values)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+
+		cases = append(cases, c)
+	}
+
+	return cases, nil
+}
+
+func (f *fuzzer) testCase(name string, values []*fuzzValue) (testCase, error) {
+	variables, err := f.variables(values)
+	if err != nil {
+		return testCase{}, err
+	}
+
+	t := *f.test
+	t.TestName = fmt.Sprintf("%s/%s", f.test.TestName, name)
+
+	return testCase{test: &t, variables: variables}, nil
+}
+
+func (f *fuzzer) variables(values []*fuzzValue) (map[Register]*ast.Literal, error) {
+	variables := map[Register]*ast.Literal{}
+	for i, arg := range f.test.FuzzArguments {
+		literal, err := f.literal(f.vm.Types[arg.Type], values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		variables[Register(arg.Name)] = literal
+	}
+
+	return variables, nil
+}
+
+// literal creates the value of an argument.
+func (f *fuzzer) literal(ty *types.Type, value *fuzzValue) (*ast.Literal, error) {
+	switch ty.Kind {
+	case types.KindBool, types.KindChar, types.KindData, types.KindNumber,
+		types.KindString:
+		return &ast.Literal{Kind: ty, Value: value.Value}, nil
+
+	case types.KindArray:
+		literal := &ast.Literal{Kind: ty}
+		for _, element := range value.Elements {
+			elementLiteral, err := f.literal(ty.Element, element)
+			if err != nil {
+				return nil, err
+			}
+
+			literal.Array = append(literal.Array, elementLiteral)
+		}
+
+		return literal, nil
+
+	case types.KindMap:
+		if len(value.Keys) != len(value.Elements) {
+			return nil, fmt.Errorf("map has %d keys but %d values",
+				len(value.Keys), len(value.Elements))
+		}
+
+		// Like MapAlloc, the keys are also kept in Array.
+		literal := &ast.Literal{Kind: ty, Map: map[string]*ast.Literal{}}
+		for i, key := range value.Keys {
+			elementLiteral, err := f.literal(ty.Element, value.Elements[i])
+			if err != nil {
+				return nil, err
+			}
+
+			literal.Array = append(literal.Array, asttest.NewLiteralString(key))
+			literal.Map[key] = elementLiteral
+		}
+
+		return literal, nil
+	}
+
+	return f.construct(ty, value)
+}
+
+// construct creates an object by calling its constructor.
+func (f *fuzzer) construct(ty *types.Type, value *fuzzValue) (*ast.Literal, error) {
+	argTypes := f.constructorArguments(ty)
+	if len(value.Elements) != len(argTypes) {
+		return nil, fmt.Errorf("%s has %d arguments but found %d", ty,
+			len(argTypes), len(value.Elements))
+	}
+
+	vm := f.vm
+	stackSize := len(vm.Stack)
+	vm.appendStack(stackDescription(f.test.Pos, "fuzz"),
+		map[string]*ast.Literal{}, types.Any)
+	defer func() {
+		vm.Stack = vm.Stack[:stackSize]
+		vm.Return = nil
+	}()
+
+	var args []Register
+	for i, argType := range argTypes {
+		literal, err := f.literal(argType, value.Elements[i])
+		if err != nil {
+			return nil, err
+		}
+
+		register := Register(strconv.Itoa(i + 1))
+		vm.Set(register, literal)
+		args = append(args, register)
+	}
+
+	results, err := vm.call(f.test.FuzzConstructors[ty.Name], args,
+		map[string]*ast.Literal{}, ty, f.test.Pos)
+	if err != nil {
+		return nil, err
+	}
+
+	if vm.ErrType != nil {
+		vm.ErrType = nil
+
+		return nil, errConstructorRaised
+	}
+
+	return vm.Get(results[0]), nil
+}
+
+func (f *fuzzer) constructorArguments(ty *types.Type) []*types.Type {
+	fn := f.vm.fns[f.test.FuzzConstructors[ty.Name]]
+
+	return f.vm.Types[fn.Type].Arguments
+}
+
+func (f *fuzzer) generate(size int) []*fuzzValue {
+	var values []*fuzzValue
+	for _, arg := range f.test.FuzzArguments {
+		values = append(values, f.generateValue(f.vm.Types[arg.Type], size))
+	}
+
+	return values
+}
+
+// generateValue creates a random value. The size limits the length of strings,
+// arrays and maps, and the magnitude of numbers. It is halved for each level
+// of arrays, maps and objects.
+func (f *fuzzer) generateValue(ty *types.Type, size int) *fuzzValue {
+	r := f.vm.rand
+	switch ty.Kind {
+	case types.KindBool:
+		return &fuzzValue{Value: strconv.FormatBool(r.Intn(2) == 0)}
+
+	case types.KindChar:
+		return &fuzzValue{Value: string(f.generateRune())}
+
+	case types.KindNumber:
+		return &fuzzValue{Value: f.generateNumber(size)}
+
+	case types.KindData, types.KindString:
+		return &fuzzValue{Value: f.generateString(size)}
+
+	case types.KindArray:
+		value := &fuzzValue{}
+		for i := r.Intn(size/4 + 1); i > 0; i-- {
+			value.Elements = append(value.Elements,
+				f.generateValue(ty.Element, size/2))
+		}
+
+		return value
+
+	case types.KindMap:
+		value := &fuzzValue{}
+		seen := map[string]bool{}
+		for i := r.Intn(size/4 + 1); i > 0; i-- {
+			key := f.generateString(size / 4)
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			value.Keys = append(value.Keys, key)
+			value.Elements = append(value.Elements,
+				f.generateValue(ty.Element, size/2))
+		}
+
+		return value
+	}
+
+	// Objects are created by their constructor, so it is the arguments of the
+	// constructor that are generated.
+	value := &fuzzValue{}
+	for _, argType := range f.constructorArguments(ty) {
+		value.Elements = append(value.Elements,
+			f.generateValue(argType, size/2))
+	}
+
+	return value
+}
+
+func (f *fuzzer) generateNumber(size int) string {
+	r := f.vm.rand
+	switch r.Intn(4) {
+	case 0:
+		return interestingNumbers[r.Intn(len(interestingNumbers))]
+
+	case 1:
+		places := r.Intn(3) + 1
+		sign := ""
+		if r.Intn(2) == 0 {
+			sign = "-"
+		}
+
+		return fmt.Sprintf("%s%d.%0*d", sign, r.Intn(size+1), places,
+			r.Intn(int(math.Pow10(places))))
+	}
+
+	return strconv.Itoa(r.Intn(2*size+1) - size)
+}
+
+func (f *fuzzer) generateRune() rune {
+	r := f.vm.rand
+	if r.Intn(8) == 0 {
+		return interestingRunes[r.Intn(len(interestingRunes))]
+	}
+
+	// Printable ASCII.
+	return rune(' ' + r.Intn(95))
+}
+
+func (f *fuzzer) generateString(size int) string {
+	runes := make([]rune, f.vm.rand.Intn(size+1))
+	for i := range runes {
+		runes[i] = f.generateRune()
+	}
+
+	return string(runes)
+}
+
+// shrink makes the failing input as small as possible, by replacing one value
+// at a time with a smaller value that still fails.
+func (f *fuzzer) shrink(values []*fuzzValue) ([]*fuzzValue, error) {
+	runs := 0
+	for shrunk := true; shrunk; {
+		shrunk = false
+
+	arguments:
+		for i, arg := range f.test.FuzzArguments {
+			for _, smaller := range f.smaller(f.vm.Types[arg.Type], values[i]) {
+				if runs >= maxShrinkRuns {
+					return values, nil
+				}
+				runs++
+
+				next := append([]*fuzzValue(nil), values...)
+				next[i] = smaller
+				passed, err := f.passes(next)
+				if err != nil {
+					return nil, err
+				}
+
+				if !passed {
+					values = next
+					shrunk = true
+					break arguments
+				}
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// smaller returns the values that are simpler than value, the simplest first.
+func (f *fuzzer) smaller(ty *types.Type, value *fuzzValue) []*fuzzValue {
+	switch ty.Kind {
+	case types.KindBool:
+		if value.Value == "true" {
+			return []*fuzzValue{{Value: "false"}}
+		}
+
+	case types.KindChar:
+		if value.Value != "a" {
+			return []*fuzzValue{{Value: "a"}}
+		}
+
+	case types.KindNumber:
+		return smallerValues(smallerNumbers(value.Value))
+
+	case types.KindData, types.KindString:
+		return smallerValues(smallerStrings(value.Value))
+
+	case types.KindArray, types.KindMap:
+		return f.smallerElements(ty.Element, value)
+
+	case types.KindResolvedInterface, types.KindUnresolvedInterface:
+		var smaller []*fuzzValue
+		for i, argType := range f.constructorArguments(ty) {
+			for _, element := range f.smaller(argType, value.Elements[i]) {
+				next := &fuzzValue{
+					Elements: append([]*fuzzValue(nil), value.Elements...),
+				}
+				next.Elements[i] = element
+				smaller = append(smaller, next)
+			}
+		}
+
+		return smaller
+	}
+
+	return nil
+}
+
+// smallerElements removes elements from an array or map, and then tries each
+// key and element with a smaller value.
+func (f *fuzzer) smallerElements(ty *types.Type, value *fuzzValue) []*fuzzValue {
+	n := len(value.Elements)
+	if n == 0 {
+		return nil
+	}
+
+	// Keys are only copied for maps.
+	without := func(from, to int) *fuzzValue {
+		next := &fuzzValue{}
+		next.Elements = append(next.Elements, value.Elements[:from]...)
+		next.Elements = append(next.Elements, value.Elements[to:]...)
+		if value.Keys != nil {
+			next.Keys = append(next.Keys, value.Keys[:from]...)
+			next.Keys = append(next.Keys, value.Keys[to:]...)
+		}
+
+		return next
+	}
+
+	smaller := []*fuzzValue{{}}
+	if n > 1 {
+		smaller = append(smaller, without(n/2, n), without(0, n/2))
+	}
+
+	for i := 0; i < n; i++ {
+		smaller = append(smaller, without(i, i+1))
+	}
+
+	// Keys must stay unique.
+	keys := map[string]bool{}
+	for _, key := range value.Keys {
+		keys[key] = true
+	}
+	for i, key := range value.Keys {
+		for _, smallerKey := range smallerStrings(key) {
+			if keys[smallerKey] {
+				continue
+			}
+
+			next := &fuzzValue{
+				Elements: value.Elements,
+				Keys:     append([]string(nil), value.Keys...),
+			}
+			next.Keys[i] = smallerKey
+			smaller = append(smaller, next)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		for _, element := range f.smaller(ty, value.Elements[i]) {
+			next := &fuzzValue{
+				Elements: append([]*fuzzValue(nil), value.Elements...),
+				Keys:     value.Keys,
+			}
+			next.Elements[i] = element
+			smaller = append(smaller, next)
+		}
+	}
+
+	return smaller
+}
+
+func smallerValues(values []string) []*fuzzValue {
+	var smaller []*fuzzValue
+	for _, value := range values {
+		smaller = append(smaller, &fuzzValue{Value: value})
+	}
+
+	return smaller
+}
+
+// smallerNumbers returns zero, the absolute value, the integer part, half of the
+// number and the number one closer to zero. Only the numbers that are
+// different are returned.
+func smallerNumbers(s string) []string {
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil || x == 0 {
+		return nil
+	}
+
+	format := func(x float64) string {
+		// Avoid "-0".
+		if x == 0 {
+			return "0"
+		}
+
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+
+	candidates := []string{"0"}
+	if x < 0 {
+		candidates = append(candidates, strings.TrimPrefix(s, "-"))
+	}
+	if strings.Contains(s, ".") {
+		candidates = append(candidates, format(math.Trunc(x)))
+	}
+	if math.Abs(x) >= 2 {
+		candidates = append(candidates, format(math.Trunc(x/2)))
+	}
+	if math.Abs(x) >= 1 {
+		candidates = append(candidates, format(x-math.Copysign(1, x)))
+	}
+
+	var smaller []string
+	for _, candidate := range candidates {
+		if candidate != s {
+			smaller = append(smaller, candidate)
+		}
+	}
+
+	return smaller
+}
+
+// smallerStrings returns the empty string, each half, the string without each
+// character and the string with each character replaced by "a".
+func smallerStrings(s string) []string {
+	runes := []rune(s)
+	n := len(runes)
+	if n == 0 {
+		return nil
+	}
+
+	smaller := []string{""}
+	if n > 1 {
+		smaller = append(smaller, string(runes[:n/2]), string(runes[n/2:]))
+	}
+
+	for i := range runes {
+		smaller = append(smaller, string(runes[:i])+string(runes[i+1:]))
+	}
+
+	for i, r := range runes {
+		if r != 'a' {
+			smaller = append(smaller, string(runes[:i])+"a"+string(runes[i+1:]))
+		}
+	}
+
+	return smaller
+}
@@ -0,0 +1,78 @@
+package vm_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVM_RunFuzz(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fuzz")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// fuzz "short"(s string) { assert(len(s) < 3) }
+	newVM := func(buf *bytes.Buffer) *vm.VM {
+		m := vm.NewVM("pkg")
+		m.TestOutput = buf
+		m.Types["0"] = types.String
+		m.Symbols["limit"] = asttest.NewLiteralNumber("3")
+		require.NoError(t, m.LoadFile(&vm.File{
+			Tests: []*vm.CompiledTest{
+				{
+					CompiledFunc: &vm.CompiledFunc{
+						Instructions: vm.NewInstructions(
+							&vm.Len{Argument: "s", Result: "1"},
+							&vm.AssignSymbol{Symbol: "limit", Result: "2"},
+							&vm.LessThanNumber{Left: "1", Right: "2", Result: "3"},
+							&vm.Assert{Left: "1", Right: "2", Final: "3", Op: "<"},
+						),
+						Pos: filepath.Join(dir, "a.ok:1:1"),
+					},
+					TestName: "short",
+					IsFuzz:   true,
+					FuzzArguments: []*vm.FuzzArgument{
+						{Name: "s", Type: "0"},
+					},
+				},
+			},
+		}))
+
+		return m
+	}
+
+	buf := bytes.NewBuffer(nil)
+	m := newVM(buf)
+	err = m.RunFuzz(regexp.MustCompile(""), time.Minute, false, "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.TestsFailed)
+
+	// The shortest failing string is found by replacing each character.
+	files, err := filepath.Glob(filepath.Join(dir, "testdata", "fuzz", "short", "*"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"Value": "aaa"}]`, string(data))
+	assert.Contains(t, buf.String(), "    s = \"aaa\"\n")
+
+	// The corpus is replayed by RunTests.
+	buf = bytes.NewBuffer(nil)
+	m = newVM(buf)
+	err = m.RunTests(false, regexp.MustCompile(""), "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.TestsFailed)
+	assert.Equal(t, 0, m.TestsPass)
+	assert.Contains(t, buf.String(), ": short/"+filepath.Base(files[0])+
+		": assert(3 < 3) failed\n")
+}
@@ -9,12 +9,12 @@ import (
 
 // Info fetches file information or raises and error if the file does not exist.
 type Info struct {
-	Path    Register   // In
-	Name    Register   // Out
-	Size    Register   // Out
-	Mode    Register   // Out
-	ModTime []Register // Out (6 elements)
-	IsDir   Register   // Out
+	Path    Register  // In
+	Name    Register  // Out
+	Size    Register  // Out
+	Mode    Register  // Out
+	ModTime Registers // Out (6 elements)
+	IsDir   Register  // Out
 }
 
 // Execute implements the Instruction interface for the VM.
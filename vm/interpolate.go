@@ -40,6 +40,11 @@ func renderLiteral(v *ast.Literal, asJSON bool) string {
 		return v.String()
 	}
 
+	// A channel does not have a value that can be shown.
+	if v.Kind.Kind == types.KindChannel {
+		return v.Kind.String()
+	}
+
 	if v.Kind.Kind == types.KindArray {
 		s := "["
 		for j, element := range v.Array {
@@ -55,7 +60,7 @@ func renderLiteral(v *ast.Literal, asJSON bool) string {
 
 	// Literals.
 	switch v.Kind.Kind {
-	case types.KindChar, types.KindString, types.KindData:
+	case types.KindChar, types.KindString, types.KindData, types.KindEnum:
 		if asJSON {
 			// TODO(elliot): This is not escaped correctly.
 			return fmt.Sprintf(`"%s"`, v.Value)
@@ -68,6 +73,9 @@ func renderLiteral(v *ast.Literal, asJSON bool) string {
 
 	case types.KindBool:
 		return v.Value
+
+	case types.KindNil:
+		return types.Nil.String()
 	}
 
 	// Maps or objects are handled the same way. We can recognise maps with:
@@ -106,6 +106,7 @@ func Merge(files ...*File) *File {
 		for _, symbolRegister := range symbolsToRevisit {
 			symbol := merged.Symbols[symbolRegister]
 			instructions := NewInstructions(symbol.Func.Instructions.Instructions...)
+			instructions.Positions = symbol.Func.Instructions.Positions
 			for i := 0; i < len(instructions.Instructions); i++ {
 				ty := reflect.TypeOf(instructions.Instructions[i]).Elem()
 				val := reflect.ValueOf(instructions.Instructions[i]).Elem()
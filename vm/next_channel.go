@@ -0,0 +1,33 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast/asttest"
+)
+
+// NextChannel is used to receive the next value when iterating a channel. It
+// blocks until there is a value, there are no more values once the channel is
+// closed.
+type NextChannel struct {
+	Channel     Register // In (chan): Containing the iterating channel.
+	ValueResult Register // Out (any): Load the value into this register.
+	Result      Register // Out (bool): Still more items?
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *NextChannel) Execute(_ *int, vm *VM) error {
+	value, hasMore := vm.receive(vm.Get(ins.Channel).Channel.(*channel))
+	vm.Set(ins.Result, asttest.NewLiteralBool(hasMore))
+	if hasMore {
+		vm.Set(ins.ValueResult, value)
+	}
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *NextChannel) String() string {
+	return fmt.Sprintf("%s = next from %s; has more %s",
+		ins.ValueResult, ins.Channel, ins.Result)
+}
@@ -1,7 +1,6 @@
 package vm
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -24,6 +23,11 @@ type File struct {
 	// Globals describes the global registers and unique names of the functions
 	// that will initialize each package.
 	Globals map[string]string `json:",omitempty"`
+
+	// Sources contains the hash of the source of each package that was
+	// compiled into this file, by the directory of the package. A file in the
+	// compile cache is only used if none of the sources have changed.
+	Sources map[string]string `json:",omitempty"`
 }
 
 func (f *File) FuncByName(name string) *CompiledFunc {
@@ -36,42 +40,51 @@ func (f *File) FuncByName(name string) *CompiledFunc {
 	return nil
 }
 
-// Store will create or replace the okc file for the provided package name.
-func Store(file *File, packageName string) error {
-	err := os.MkdirAll(Directory, 0755)
+// Store will create or replace the okc file for the cache key.
+func Store(file *File, key string) error {
+	err := os.MkdirAll(Directory(), 0755)
 	if err != nil {
 		return err
 	}
 
-	filePath := PathForPackage(packageName)
-	f, err := os.Create(filePath)
+	data, err := file.MarshalBinary()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	jsonData, err := json.MarshalIndent(file, "", "  ")
+	// The file is written under a temporary name and then renamed so that
+	// another process never sees a partially written file.
+	f, err := ioutil.TempFile(Directory(), key+".*")
 	if err != nil {
 		return err
 	}
 
-	_, err = f.Write(jsonData)
+	err = f.Chmod(0644)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		os.Remove(f.Name())
+
 		return err
 	}
 
-	return nil
+	return os.Rename(f.Name(), PathForKey(key))
 }
 
-func Load(packageName string) (*File, error) {
-	filePath := PathForPackage(packageName)
-	jsonData, err := ioutil.ReadFile(filePath)
+// Load reads the okc file for the cache key. An error is returned if the file
+// does not exist.
+func Load(key string) (*File, error) {
+	data, err := ioutil.ReadFile(PathForKey(key))
 	if err != nil {
 		return nil, err
 	}
 
-	var okcFile *File
-	err = json.Unmarshal(jsonData, &okcFile)
+	okcFile := new(File)
+	err = okcFile.UnmarshalBinary(data)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,123 @@
+package vm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "okc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer os.Setenv("OKCACHE", os.Getenv("OKCACHE"))
+	require.NoError(t, os.Setenv("OKCACHE", dir))
+
+	file := &vm.File{
+		Types: types.Registry{
+			"0": types.NewFunc(nil, nil),
+			"1": types.TypeFromString("func[T]([]T) Stack[T]"),
+			"2": types.NewEnum("Level", []string{"Low", "High"}),
+			"3": types.NewChannel(types.String),
+			"4": types.NewOptional(types.NumberArray),
+		},
+		Symbols: map[vm.SymbolRegister]*vm.Symbol{
+			"0": {
+				Type: "0",
+				Func: &vm.CompiledFunc{
+					Instructions: &vm.Instructions{
+						Instructions: []vm.Instruction{
+							&vm.Info{
+								Path: "1",
+								Name: "2",
+								Size: "3",
+								Mode: "4",
+								ModTime: vm.Registers{
+									"5", "6", "7", "8", "9", "10",
+								},
+								IsDir: "11",
+							},
+							&vm.UnicodeIs{Op: "1", Char: "2", Result: "3"},
+							&vm.Assert{
+								Left:  "1",
+								Right: "2",
+								Final: "3",
+								Op:    "==",
+								Pos:   "my dir/a.ok:4:5",
+							},
+							&vm.Jump{To: -1},
+						},
+						Positions: map[int]string{0: "a.ok:2:5", 1: "a.ok:3:5"},
+					},
+					UniqueName: "1",
+				},
+			},
+		},
+		Tests: []*vm.CompiledTest{
+			{
+				CompiledFunc: &vm.CompiledFunc{
+					Instructions: &vm.Instructions{
+						Instructions: []vm.Instruction{
+							&vm.Assign{Result: "1", Register: "2"},
+						},
+						Positions: map[int]string{0: "a.okt:2:5"},
+					},
+				},
+				TestName: "foo",
+			},
+		},
+		Sources: map[string]string{
+			"/a": "abc",
+		},
+	}
+
+	require.NoError(t, vm.Store(file, "abc"))
+	assert.FileExists(t, vm.PathForKey("abc"))
+
+	loaded, err := vm.Load("abc")
+	require.NoError(t, err)
+	assert.Equal(t, file, loaded)
+
+	_, err = vm.Load("def")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFile_UnmarshalBinary(t *testing.T) {
+	data, err := (&vm.File{
+		Globals: map[string]string{"$main": "1"},
+	}).MarshalBinary()
+	require.NoError(t, err)
+
+	for testName, test := range map[string]struct {
+		data        []byte
+		expectedErr string
+	}{
+		"json": {
+			data:        []byte("{}"),
+			expectedErr: "not an okc file",
+		},
+		"version": {
+			data:        append([]byte("OKC\x00\x00\x00"), data[6:]...),
+			expectedErr: "unsupported okc version 0",
+		},
+		"truncated": {
+			data:        data[:len(data)-1],
+			expectedErr: "unexpected end of okc file",
+		},
+		"extra": {
+			data:        append(append([]byte(nil), data...), 0),
+			expectedErr: "unexpected data at the end of the okc file",
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			err := new(vm.File).UnmarshalBinary(test.data)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedErr)
+		})
+	}
+}
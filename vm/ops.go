@@ -0,0 +1,1278 @@
+// Code generated by ops-gen. DO NOT EDIT.
+
+package vm
+
+import "fmt"
+
+// opcodesChecksum changes when any instruction is added, removed or has
+// its fields changed. It is stored in the header of okc files.
+const opcodesChecksum = 0x144e3fe3
+
+const (
+	opAdd = iota + 1
+	opAnd
+	opAppend
+	opArrayAlloc
+	opArrayGet
+	opArraySet
+	opAssert
+	opAssign
+	opAssignFunc
+	opAssignSymbol
+	opCall
+	opCastChar
+	opCastData
+	opCastEnum
+	opCastNumber
+	opCastString
+	opChannelAlloc
+	opClose
+	opCloseChannel
+	opCombine
+	opConcat
+	opDivide
+	opDynamicCall
+	opEnvGet
+	opEnvSet
+	opEnvUnset
+	opEqual
+	opEqualNumber
+	opExit
+	opFinally
+	opFromUnix
+	opGet
+	opGreaterThanEqualNumber
+	opGreaterThanEqualString
+	opGreaterThanNumber
+	opGreaterThanString
+	opInfo
+	opInterface
+	opInterpolate
+	opIs
+	opJump
+	opJumpUnless
+	opLen
+	opLessThanEqualNumber
+	opLessThanEqualString
+	opLessThanNumber
+	opLessThanString
+	opLog
+	opMapAlloc
+	opMapGet
+	opMapSet
+	opMkdir
+	opMultiply
+	opNextArray
+	opNextChannel
+	opNextMap
+	opNextString
+	opNot
+	opNotEqual
+	opNotEqualNumber
+	opNow
+	opOn
+	opOpen
+	opOr
+	opParentScope
+	opPower
+	opPrint
+	opProps
+	opRaise
+	opRand
+	opReadData
+	opReadString
+	opReceive
+	opRemainder
+	opRemove
+	opRename
+	opReturn
+	opSeek
+	opSelect
+	opSend
+	opSet
+	opSleep
+	opSnapshot
+	opSpawn
+	opStack
+	opStringIndex
+	opSubtract
+	opTimeout
+	opType
+	opUnicodeIs
+	opUnicodeTo
+	opUnix
+	opWrite
+)
+
+func encodeInstruction(e *encoder, ins Instruction) {
+	switch ins := ins.(type) {
+	case *Add:
+		e.uvarint(opAdd)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *And:
+		e.uvarint(opAnd)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Append:
+		e.uvarint(opAppend)
+		e.string(string(ins.A))
+		e.string(string(ins.B))
+		e.string(string(ins.Result))
+
+	case *ArrayAlloc:
+		e.uvarint(opArrayAlloc)
+		e.string(string(ins.Size))
+		e.string(string(ins.Result))
+		e.string(string(ins.Kind))
+
+	case *ArrayGet:
+		e.uvarint(opArrayGet)
+		e.string(string(ins.Array))
+		e.string(string(ins.Index))
+		e.string(string(ins.Result))
+
+	case *ArraySet:
+		e.uvarint(opArraySet)
+		e.string(string(ins.Array))
+		e.string(string(ins.Index))
+		e.string(string(ins.Value))
+
+	case *Assert:
+		e.uvarint(opAssert)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Final))
+		e.string(ins.Op)
+		e.string(ins.Pos)
+		e.string(string(ins.Message))
+
+	case *Assign:
+		e.uvarint(opAssign)
+		e.string(string(ins.Result))
+		e.string(string(ins.Register))
+
+	case *AssignFunc:
+		e.uvarint(opAssignFunc)
+		e.string(string(ins.Result))
+		e.string(string(ins.Type))
+		e.string(ins.UniqueName)
+
+	case *AssignSymbol:
+		e.uvarint(opAssignSymbol)
+		e.string(string(ins.Result))
+		e.string(string(ins.Symbol))
+
+	case *Call:
+		e.uvarint(opCall)
+		e.string(ins.FunctionName)
+		e.registers(ins.Arguments)
+		e.registers(ins.Results)
+		e.string(string(ins.Type))
+		e.string(ins.Pos)
+
+	case *CastChar:
+		e.uvarint(opCastChar)
+		e.string(string(ins.X))
+		e.string(string(ins.Result))
+
+	case *CastData:
+		e.uvarint(opCastData)
+		e.string(string(ins.X))
+		e.string(string(ins.Result))
+
+	case *CastEnum:
+		e.uvarint(opCastEnum)
+		e.string(string(ins.X))
+		e.string(string(ins.Result))
+		e.string(string(ins.Type))
+
+	case *CastNumber:
+		e.uvarint(opCastNumber)
+		e.string(string(ins.X))
+		e.string(string(ins.Result))
+
+	case *CastString:
+		e.uvarint(opCastString)
+		e.string(string(ins.X))
+		e.string(string(ins.Result))
+
+	case *ChannelAlloc:
+		e.uvarint(opChannelAlloc)
+		e.string(string(ins.Size))
+		e.string(string(ins.Result))
+		e.string(string(ins.Kind))
+
+	case *Close:
+		e.uvarint(opClose)
+		e.string(string(ins.Fd))
+
+	case *CloseChannel:
+		e.uvarint(opCloseChannel)
+		e.string(string(ins.Channel))
+
+	case *Combine:
+		e.uvarint(opCombine)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Concat:
+		e.uvarint(opConcat)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Divide:
+		e.uvarint(opDivide)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *DynamicCall:
+		e.uvarint(opDynamicCall)
+		e.string(string(ins.Variable))
+		e.string(string(ins.Arguments))
+		e.string(string(ins.Results))
+		e.string(ins.Pos)
+
+	case *EnvGet:
+		e.uvarint(opEnvGet)
+		e.string(string(ins.Name))
+		e.string(string(ins.Value))
+		e.string(string(ins.Exists))
+
+	case *EnvSet:
+		e.uvarint(opEnvSet)
+		e.string(string(ins.Name))
+		e.string(string(ins.Value))
+
+	case *EnvUnset:
+		e.uvarint(opEnvUnset)
+		e.string(string(ins.Name))
+
+	case *Equal:
+		e.uvarint(opEqual)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *EqualNumber:
+		e.uvarint(opEqualNumber)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Exit:
+		e.uvarint(opExit)
+		e.string(string(ins.Status))
+
+	case *Finally:
+		e.uvarint(opFinally)
+		e.int(ins.Index)
+		e.bool(ins.Run)
+
+	case *FromUnix:
+		e.uvarint(opFromUnix)
+		e.string(string(ins.Seconds))
+		e.string(string(ins.Year))
+		e.string(string(ins.Month))
+		e.string(string(ins.Day))
+		e.string(string(ins.Hour))
+		e.string(string(ins.Minute))
+		e.string(string(ins.Second))
+
+	case *Get:
+		e.uvarint(opGet)
+		e.string(string(ins.Object))
+		e.string(string(ins.Prop))
+		e.string(string(ins.Result))
+
+	case *GreaterThanEqualNumber:
+		e.uvarint(opGreaterThanEqualNumber)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *GreaterThanEqualString:
+		e.uvarint(opGreaterThanEqualString)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *GreaterThanNumber:
+		e.uvarint(opGreaterThanNumber)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *GreaterThanString:
+		e.uvarint(opGreaterThanString)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Info:
+		e.uvarint(opInfo)
+		e.string(string(ins.Path))
+		e.string(string(ins.Name))
+		e.string(string(ins.Size))
+		e.string(string(ins.Mode))
+		e.registers(ins.ModTime)
+		e.string(string(ins.IsDir))
+
+	case *Interface:
+		e.uvarint(opInterface)
+		e.string(string(ins.Value))
+		e.string(string(ins.Result))
+
+	case *Interpolate:
+		e.uvarint(opInterpolate)
+		e.string(string(ins.Result))
+		e.registers(ins.Args)
+
+	case *Is:
+		e.uvarint(opIs)
+		e.string(string(ins.Value))
+		e.string(string(ins.Type))
+		e.string(string(ins.Result))
+
+	case *Jump:
+		e.uvarint(opJump)
+		e.int(ins.To)
+
+	case *JumpUnless:
+		e.uvarint(opJumpUnless)
+		e.string(string(ins.Condition))
+		e.int(ins.To)
+
+	case *Len:
+		e.uvarint(opLen)
+		e.string(string(ins.Argument))
+		e.string(string(ins.Result))
+
+	case *LessThanEqualNumber:
+		e.uvarint(opLessThanEqualNumber)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *LessThanEqualString:
+		e.uvarint(opLessThanEqualString)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *LessThanNumber:
+		e.uvarint(opLessThanNumber)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *LessThanString:
+		e.uvarint(opLessThanString)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Log:
+		e.uvarint(opLog)
+		e.string(string(ins.X))
+		e.string(string(ins.Result))
+
+	case *MapAlloc:
+		e.uvarint(opMapAlloc)
+		e.string(string(ins.Kind))
+		e.string(string(ins.Size))
+		e.string(string(ins.Result))
+
+	case *MapGet:
+		e.uvarint(opMapGet)
+		e.string(string(ins.Map))
+		e.string(string(ins.Key))
+		e.string(string(ins.Result))
+
+	case *MapSet:
+		e.uvarint(opMapSet)
+		e.string(string(ins.Map))
+		e.string(string(ins.Key))
+		e.string(string(ins.Value))
+
+	case *Mkdir:
+		e.uvarint(opMkdir)
+		e.string(string(ins.Path))
+
+	case *Multiply:
+		e.uvarint(opMultiply)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *NextArray:
+		e.uvarint(opNextArray)
+		e.string(string(ins.Array))
+		e.string(string(ins.Cursor))
+		e.string(string(ins.KeyResult))
+		e.string(string(ins.ValueResult))
+		e.string(string(ins.Result))
+
+	case *NextChannel:
+		e.uvarint(opNextChannel)
+		e.string(string(ins.Channel))
+		e.string(string(ins.ValueResult))
+		e.string(string(ins.Result))
+
+	case *NextMap:
+		e.uvarint(opNextMap)
+		e.string(string(ins.Map))
+		e.string(string(ins.Cursor))
+		e.string(string(ins.KeyResult))
+		e.string(string(ins.ValueResult))
+		e.string(string(ins.Result))
+
+	case *NextString:
+		e.uvarint(opNextString)
+		e.string(string(ins.Str))
+		e.string(string(ins.Cursor))
+		e.string(string(ins.KeyResult))
+		e.string(string(ins.ValueResult))
+		e.string(string(ins.Result))
+
+	case *Not:
+		e.uvarint(opNot)
+		e.string(string(ins.Left))
+		e.string(string(ins.Result))
+
+	case *NotEqual:
+		e.uvarint(opNotEqual)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *NotEqualNumber:
+		e.uvarint(opNotEqualNumber)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Now:
+		e.uvarint(opNow)
+		e.string(string(ins.Year))
+		e.string(string(ins.Month))
+		e.string(string(ins.Day))
+		e.string(string(ins.Hour))
+		e.string(string(ins.Minute))
+		e.string(string(ins.Second))
+
+	case *On:
+		e.uvarint(opOn)
+		e.string(string(ins.Type))
+		e.bool(ins.Finished)
+
+	case *Open:
+		e.uvarint(opOpen)
+		e.string(string(ins.Path))
+		e.string(string(ins.Result))
+
+	case *Or:
+		e.uvarint(opOr)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *ParentScope:
+		e.uvarint(opParentScope)
+		e.string(string(ins.X))
+
+	case *Power:
+		e.uvarint(opPower)
+		e.string(string(ins.Base))
+		e.string(string(ins.Power))
+		e.string(string(ins.Result))
+
+	case *Print:
+		e.uvarint(opPrint)
+		e.registers(ins.Arguments)
+
+	case *Props:
+		e.uvarint(opProps)
+		e.string(string(ins.Value))
+		e.string(string(ins.Result))
+
+	case *Raise:
+		e.uvarint(opRaise)
+		e.string(string(ins.Err))
+		e.string(string(ins.Type))
+		e.string(ins.Pos)
+
+	case *Rand:
+		e.uvarint(opRand)
+		e.string(string(ins.Result))
+
+	case *ReadData:
+		e.uvarint(opReadData)
+		e.string(string(ins.Fd))
+		e.string(string(ins.Size))
+		e.string(string(ins.Data))
+
+	case *ReadString:
+		e.uvarint(opReadString)
+		e.string(string(ins.Fd))
+		e.string(string(ins.Size))
+		e.string(string(ins.Str))
+
+	case *Receive:
+		e.uvarint(opReceive)
+		e.string(string(ins.Channel))
+		e.string(string(ins.Result))
+
+	case *Remainder:
+		e.uvarint(opRemainder)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Remove:
+		e.uvarint(opRemove)
+		e.string(string(ins.Path))
+
+	case *Rename:
+		e.uvarint(opRename)
+		e.string(string(ins.OldPath))
+		e.string(string(ins.NewPath))
+
+	case *Return:
+		e.uvarint(opReturn)
+		e.registers(ins.Results)
+
+	case *Seek:
+		e.uvarint(opSeek)
+		e.string(string(ins.Fd))
+		e.string(string(ins.Offset))
+		e.string(string(ins.Whence))
+		e.string(string(ins.NewOffset))
+
+	case *Select:
+		e.uvarint(opSelect)
+		e.registers(ins.Channels)
+		e.registers(ins.Values)
+		e.bool(ins.Else)
+		e.string(string(ins.Chosen))
+		e.string(string(ins.Value))
+
+	case *Send:
+		e.uvarint(opSend)
+		e.string(string(ins.Channel))
+		e.string(string(ins.Value))
+
+	case *Set:
+		e.uvarint(opSet)
+		e.string(string(ins.Object))
+		e.string(string(ins.Prop))
+		e.string(string(ins.Value))
+		e.string(string(ins.Result))
+
+	case *Sleep:
+		e.uvarint(opSleep)
+		e.string(string(ins.Seconds))
+
+	case *Snapshot:
+		e.uvarint(opSnapshot)
+		e.string(string(ins.Name))
+		e.string(string(ins.Value))
+
+	case *Spawn:
+		e.uvarint(opSpawn)
+		e.string(string(ins.Function))
+		e.registers(ins.Arguments)
+		e.string(ins.Pos)
+
+	case *Stack:
+		e.uvarint(opStack)
+		e.string(string(ins.Stack))
+
+	case *StringIndex:
+		e.uvarint(opStringIndex)
+		e.string(string(ins.Str))
+		e.string(string(ins.Index))
+		e.string(string(ins.Result))
+
+	case *Subtract:
+		e.uvarint(opSubtract)
+		e.string(string(ins.Left))
+		e.string(string(ins.Right))
+		e.string(string(ins.Result))
+
+	case *Timeout:
+		e.uvarint(opTimeout)
+		e.string(string(ins.Seconds))
+
+	case *Type:
+		e.uvarint(opType)
+		e.string(string(ins.Value))
+		e.string(string(ins.Result))
+
+	case *UnicodeIs:
+		e.uvarint(opUnicodeIs)
+		e.string(string(ins.Op))
+		e.string(string(ins.Char))
+		e.string(string(ins.Result))
+
+	case *UnicodeTo:
+		e.uvarint(opUnicodeTo)
+		e.string(string(ins.Op))
+		e.string(string(ins.Char))
+		e.string(string(ins.Result))
+
+	case *Unix:
+		e.uvarint(opUnix)
+		e.string(string(ins.Time))
+		e.string(string(ins.Result))
+
+	case *Write:
+		e.uvarint(opWrite)
+		e.string(string(ins.Data))
+		e.string(string(ins.Fd))
+
+	default:
+		e.fail(fmt.Errorf("cannot encode instruction %T", ins))
+	}
+}
+
+func decodeInstruction(d *decoder) Instruction {
+	switch op := d.uvarint(); op {
+	case opAdd:
+		return &Add{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opAnd:
+		return &And{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opAppend:
+		return &Append{
+			A:      Register(d.string()),
+			B:      Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opArrayAlloc:
+		return &ArrayAlloc{
+			Size:   Register(d.string()),
+			Result: Register(d.string()),
+			Kind:   TypeRegister(d.string()),
+		}
+
+	case opArrayGet:
+		return &ArrayGet{
+			Array:  Register(d.string()),
+			Index:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opArraySet:
+		return &ArraySet{
+			Array: Register(d.string()),
+			Index: Register(d.string()),
+			Value: Register(d.string()),
+		}
+
+	case opAssert:
+		return &Assert{
+			Left:    Register(d.string()),
+			Right:   Register(d.string()),
+			Final:   Register(d.string()),
+			Op:      d.string(),
+			Pos:     d.string(),
+			Message: Register(d.string()),
+		}
+
+	case opAssign:
+		return &Assign{
+			Result:   Register(d.string()),
+			Register: Register(d.string()),
+		}
+
+	case opAssignFunc:
+		return &AssignFunc{
+			Result:     Register(d.string()),
+			Type:       TypeRegister(d.string()),
+			UniqueName: d.string(),
+		}
+
+	case opAssignSymbol:
+		return &AssignSymbol{
+			Result: Register(d.string()),
+			Symbol: SymbolRegister(d.string()),
+		}
+
+	case opCall:
+		return &Call{
+			FunctionName: d.string(),
+			Arguments:    d.registers(),
+			Results:      d.registers(),
+			Type:         TypeRegister(d.string()),
+			Pos:          d.string(),
+		}
+
+	case opCastChar:
+		return &CastChar{
+			X:      Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opCastData:
+		return &CastData{
+			X:      Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opCastEnum:
+		return &CastEnum{
+			X:      Register(d.string()),
+			Result: Register(d.string()),
+			Type:   TypeRegister(d.string()),
+		}
+
+	case opCastNumber:
+		return &CastNumber{
+			X:      Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opCastString:
+		return &CastString{
+			X:      Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opChannelAlloc:
+		return &ChannelAlloc{
+			Size:   Register(d.string()),
+			Result: Register(d.string()),
+			Kind:   TypeRegister(d.string()),
+		}
+
+	case opClose:
+		return &Close{
+			Fd: Register(d.string()),
+		}
+
+	case opCloseChannel:
+		return &CloseChannel{
+			Channel: Register(d.string()),
+		}
+
+	case opCombine:
+		return &Combine{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opConcat:
+		return &Concat{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opDivide:
+		return &Divide{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opDynamicCall:
+		return &DynamicCall{
+			Variable:  Register(d.string()),
+			Arguments: Register(d.string()),
+			Results:   Register(d.string()),
+			Pos:       d.string(),
+		}
+
+	case opEnvGet:
+		return &EnvGet{
+			Name:   Register(d.string()),
+			Value:  Register(d.string()),
+			Exists: Register(d.string()),
+		}
+
+	case opEnvSet:
+		return &EnvSet{
+			Name:  Register(d.string()),
+			Value: Register(d.string()),
+		}
+
+	case opEnvUnset:
+		return &EnvUnset{
+			Name: Register(d.string()),
+		}
+
+	case opEqual:
+		return &Equal{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opEqualNumber:
+		return &EqualNumber{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opExit:
+		return &Exit{
+			Status: Register(d.string()),
+		}
+
+	case opFinally:
+		return &Finally{
+			Index: d.int(),
+			Run:   d.bool(),
+		}
+
+	case opFromUnix:
+		return &FromUnix{
+			Seconds: Register(d.string()),
+			Year:    Register(d.string()),
+			Month:   Register(d.string()),
+			Day:     Register(d.string()),
+			Hour:    Register(d.string()),
+			Minute:  Register(d.string()),
+			Second:  Register(d.string()),
+		}
+
+	case opGet:
+		return &Get{
+			Object: Register(d.string()),
+			Prop:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opGreaterThanEqualNumber:
+		return &GreaterThanEqualNumber{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opGreaterThanEqualString:
+		return &GreaterThanEqualString{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opGreaterThanNumber:
+		return &GreaterThanNumber{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opGreaterThanString:
+		return &GreaterThanString{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opInfo:
+		return &Info{
+			Path:    Register(d.string()),
+			Name:    Register(d.string()),
+			Size:    Register(d.string()),
+			Mode:    Register(d.string()),
+			ModTime: d.registers(),
+			IsDir:   Register(d.string()),
+		}
+
+	case opInterface:
+		return &Interface{
+			Value:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opInterpolate:
+		return &Interpolate{
+			Result: Register(d.string()),
+			Args:   d.registers(),
+		}
+
+	case opIs:
+		return &Is{
+			Value:  Register(d.string()),
+			Type:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opJump:
+		return &Jump{
+			To: d.int(),
+		}
+
+	case opJumpUnless:
+		return &JumpUnless{
+			Condition: Register(d.string()),
+			To:        d.int(),
+		}
+
+	case opLen:
+		return &Len{
+			Argument: Register(d.string()),
+			Result:   Register(d.string()),
+		}
+
+	case opLessThanEqualNumber:
+		return &LessThanEqualNumber{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opLessThanEqualString:
+		return &LessThanEqualString{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opLessThanNumber:
+		return &LessThanNumber{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opLessThanString:
+		return &LessThanString{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opLog:
+		return &Log{
+			X:      Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opMapAlloc:
+		return &MapAlloc{
+			Kind:   TypeRegister(d.string()),
+			Size:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opMapGet:
+		return &MapGet{
+			Map:    Register(d.string()),
+			Key:    Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opMapSet:
+		return &MapSet{
+			Map:   Register(d.string()),
+			Key:   Register(d.string()),
+			Value: Register(d.string()),
+		}
+
+	case opMkdir:
+		return &Mkdir{
+			Path: Register(d.string()),
+		}
+
+	case opMultiply:
+		return &Multiply{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opNextArray:
+		return &NextArray{
+			Array:       Register(d.string()),
+			Cursor:      Register(d.string()),
+			KeyResult:   Register(d.string()),
+			ValueResult: Register(d.string()),
+			Result:      Register(d.string()),
+		}
+
+	case opNextChannel:
+		return &NextChannel{
+			Channel:     Register(d.string()),
+			ValueResult: Register(d.string()),
+			Result:      Register(d.string()),
+		}
+
+	case opNextMap:
+		return &NextMap{
+			Map:         Register(d.string()),
+			Cursor:      Register(d.string()),
+			KeyResult:   Register(d.string()),
+			ValueResult: Register(d.string()),
+			Result:      Register(d.string()),
+		}
+
+	case opNextString:
+		return &NextString{
+			Str:         Register(d.string()),
+			Cursor:      Register(d.string()),
+			KeyResult:   Register(d.string()),
+			ValueResult: Register(d.string()),
+			Result:      Register(d.string()),
+		}
+
+	case opNot:
+		return &Not{
+			Left:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opNotEqual:
+		return &NotEqual{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opNotEqualNumber:
+		return &NotEqualNumber{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opNow:
+		return &Now{
+			Year:   Register(d.string()),
+			Month:  Register(d.string()),
+			Day:    Register(d.string()),
+			Hour:   Register(d.string()),
+			Minute: Register(d.string()),
+			Second: Register(d.string()),
+		}
+
+	case opOn:
+		return &On{
+			Type:     TypeRegister(d.string()),
+			Finished: d.bool(),
+		}
+
+	case opOpen:
+		return &Open{
+			Path:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opOr:
+		return &Or{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opParentScope:
+		return &ParentScope{
+			X: Register(d.string()),
+		}
+
+	case opPower:
+		return &Power{
+			Base:   Register(d.string()),
+			Power:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opPrint:
+		return &Print{
+			Arguments: d.registers(),
+		}
+
+	case opProps:
+		return &Props{
+			Value:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opRaise:
+		return &Raise{
+			Err:  Register(d.string()),
+			Type: TypeRegister(d.string()),
+			Pos:  d.string(),
+		}
+
+	case opRand:
+		return &Rand{
+			Result: Register(d.string()),
+		}
+
+	case opReadData:
+		return &ReadData{
+			Fd:   Register(d.string()),
+			Size: Register(d.string()),
+			Data: Register(d.string()),
+		}
+
+	case opReadString:
+		return &ReadString{
+			Fd:   Register(d.string()),
+			Size: Register(d.string()),
+			Str:  Register(d.string()),
+		}
+
+	case opReceive:
+		return &Receive{
+			Channel: Register(d.string()),
+			Result:  Register(d.string()),
+		}
+
+	case opRemainder:
+		return &Remainder{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opRemove:
+		return &Remove{
+			Path: Register(d.string()),
+		}
+
+	case opRename:
+		return &Rename{
+			OldPath: Register(d.string()),
+			NewPath: Register(d.string()),
+		}
+
+	case opReturn:
+		return &Return{
+			Results: d.registers(),
+		}
+
+	case opSeek:
+		return &Seek{
+			Fd:        Register(d.string()),
+			Offset:    Register(d.string()),
+			Whence:    Register(d.string()),
+			NewOffset: Register(d.string()),
+		}
+
+	case opSelect:
+		return &Select{
+			Channels: d.registers(),
+			Values:   d.registers(),
+			Else:     d.bool(),
+			Chosen:   Register(d.string()),
+			Value:    Register(d.string()),
+		}
+
+	case opSend:
+		return &Send{
+			Channel: Register(d.string()),
+			Value:   Register(d.string()),
+		}
+
+	case opSet:
+		return &Set{
+			Object: Register(d.string()),
+			Prop:   Register(d.string()),
+			Value:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opSleep:
+		return &Sleep{
+			Seconds: Register(d.string()),
+		}
+
+	case opSnapshot:
+		return &Snapshot{
+			Name:  Register(d.string()),
+			Value: Register(d.string()),
+		}
+
+	case opSpawn:
+		return &Spawn{
+			Function:  Register(d.string()),
+			Arguments: d.registers(),
+			Pos:       d.string(),
+		}
+
+	case opStack:
+		return &Stack{
+			Stack: Register(d.string()),
+		}
+
+	case opStringIndex:
+		return &StringIndex{
+			Str:    Register(d.string()),
+			Index:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opSubtract:
+		return &Subtract{
+			Left:   Register(d.string()),
+			Right:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opTimeout:
+		return &Timeout{
+			Seconds: Register(d.string()),
+		}
+
+	case opType:
+		return &Type{
+			Value:  Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opUnicodeIs:
+		return &UnicodeIs{
+			Op:     Register(d.string()),
+			Char:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opUnicodeTo:
+		return &UnicodeTo{
+			Op:     Register(d.string()),
+			Char:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opUnix:
+		return &Unix{
+			Time:   Register(d.string()),
+			Result: Register(d.string()),
+		}
+
+	case opWrite:
+		return &Write{
+			Data: Register(d.string()),
+			Fd:   Register(d.string()),
+		}
+
+	default:
+		d.fail(fmt.Errorf("unknown opcode %d", op))
+
+		return nil
+	}
+}
@@ -0,0 +1,211 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RunTestsParallel is the same as RunTests except that each test runs in its
+// own VM, with up to n tests running at the same time. The VMs share the
+// compiled code but have their own globals, so a test cannot affect the tests
+// that run after it. Anything that happens to the process (such as setting an
+// environment variable) is still shared.
+//
+// The output and results are reported in the same order as RunTests, once each
+// test has finished. A test that exits (or crashes the VM) is reported as a
+// failure of that test.
+//
+// All of the cases of a table test (or the corpus of a fuzz target) run in the
+// same VM because the cases are not known until they are evaluated.
+//
+// A profile or debugger can only follow one VM, so the tests are run with
+// RunTests when either is set, or n is less than 2.
+func (vm *VM) RunTestsParallel(
+	n int,
+	verbose bool,
+	filter *regexp.Regexp,
+	packageName string,
+) error {
+	if n < 2 || vm.Profile != nil || vm.Debugger != nil {
+		return vm.RunTests(verbose, filter, packageName)
+	}
+
+	var tests []*CompiledTest
+	for _, test := range vm.tests {
+		if !test.IsBench && (test.Cases != nil || test.IsFuzz ||
+			filter.MatchString(test.TestName)) {
+			tests = append(tests, test)
+		}
+	}
+
+	children := make([]*VM, len(tests))
+	errs := make([]error, len(tests))
+	done := make([]chan struct{}, len(tests))
+	running := make(chan struct{}, n)
+	for i, test := range tests {
+		children[i] = vm.newTestVM(test)
+		done[i] = make(chan struct{})
+
+		go func(i int) {
+			running <- struct{}{}
+			errs[i] = children[i].RunTests(verbose, filter, packageName)
+			<-running
+			close(done[i])
+		}(i)
+	}
+
+	for i, child := range children {
+		<-done[i]
+
+		recording := child.TestOutput.(*recording)
+		if exitErr, ok := errs[i].(*ExitError); ok {
+			if test := recording.started; test != nil {
+				wd, _ := os.Getwd()
+				fmt.Fprintf(recording, "%s: %s: %s: %s\n", packageName,
+					strings.TrimPrefix(test.Pos, wd), child.CurrentTestName,
+					exitErr)
+			} else {
+				fmt.Fprintln(recording, exitErr)
+			}
+			recording.unfinished()
+			child.TestsFailed++
+			errs[i] = nil
+		}
+
+		recording.replay(vm)
+		vm.TestsPass += child.TestsPass
+		vm.TestsFailed += child.TestsFailed
+		vm.TotalAssertions += child.TotalAssertions
+		vm.InstructionsExecuted += child.InstructionsExecuted
+		if vm.Coverage != nil {
+			vm.Coverage.Add(child.Coverage)
+		}
+
+		if errs[i] != nil {
+			return errs[i]
+		}
+	}
+
+	return nil
+}
+
+// newTestVM creates a VM to run a single test. It has the same code loaded as
+// vm, but none of the state.
+func (vm *VM) newTestVM(test *CompiledTest) *VM {
+	child := NewVM(vm.pkg)
+	child.fns = vm.fns
+	child.tests = []*CompiledTest{test}
+	child.GlobalsToLoad = vm.GlobalsToLoad
+	child.UpdateSnapshots = vm.UpdateSnapshots
+	child.TestTimeout = vm.TestTimeout
+	child.MaxInstructions = vm.MaxInstructions
+	child.Deterministic = vm.Deterministic
+	child.ReturnOnExit = true
+
+	// Types may be added while running.
+	for k, v := range vm.Types {
+		child.Types[k] = v
+	}
+
+	for k, v := range vm.Symbols {
+		child.Symbols[k] = v
+	}
+
+	if vm.Coverage != nil {
+		child.Coverage = NewCoverage()
+	}
+
+	r := &recording{}
+	child.Stdout = r
+	child.TestOutput = r
+	child.TestReporter = r
+
+	return child
+}
+
+// recording is the output and TestReporter for a test that is running in
+// parallel. Everything is recorded in order so that it can be replayed once the
+// test has finished.
+type recording struct {
+	events []func(vm *VM)
+
+	// started is the test that has started but not yet finished.
+	started *CompiledTest
+}
+
+// Write implements io.Writer.
+func (r *recording) Write(data []byte) (int, error) {
+	s := string(data)
+	r.events = append(r.events, func(vm *VM) {
+		fmt.Fprint(vm.TestOutput, s)
+	})
+
+	return len(data), nil
+}
+
+// TestStarted implements TestReporter.
+func (r *recording) TestStarted(test *CompiledTest) {
+	r.started = test
+	r.report(func(reporter TestReporter) {
+		reporter.TestStarted(test)
+	})
+}
+
+// Assertion implements TestReporter.
+func (r *recording) Assertion(
+	test *CompiledTest,
+	pos string,
+	passed bool,
+	message string,
+) {
+	r.report(func(reporter TestReporter) {
+		reporter.Assertion(test, pos, passed, message)
+	})
+}
+
+// TestErrored implements TestReporter.
+func (r *recording) TestErrored(
+	test *CompiledTest,
+	message string,
+	stack []string,
+) {
+	r.report(func(reporter TestReporter) {
+		reporter.TestErrored(test, message, stack)
+	})
+}
+
+// TestFinished implements TestReporter.
+func (r *recording) TestFinished(
+	test *CompiledTest,
+	passed bool,
+	elapsed time.Duration,
+) {
+	r.started = nil
+	r.report(func(reporter TestReporter) {
+		reporter.TestFinished(test, passed, elapsed)
+	})
+}
+
+// unfinished reports the test that was running when the VM exited as failed.
+func (r *recording) unfinished() {
+	if r.started != nil {
+		r.TestFinished(r.started, false, 0)
+	}
+}
+
+func (r *recording) report(f func(reporter TestReporter)) {
+	r.events = append(r.events, func(vm *VM) {
+		if vm.TestReporter != nil {
+			f(vm.TestReporter)
+		}
+	})
+}
+
+func (r *recording) replay(vm *VM) {
+	for _, event := range r.events {
+		event(vm)
+	}
+}
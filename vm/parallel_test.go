@@ -0,0 +1,147 @@
+package vm_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/ok/types"
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newParallelVM returns a VM that has the tests loaded. The symbols "true" and
+// "false" can be used for assertions, and "3" for exiting. Any other symbol is
+// a string of its own name.
+func newParallelVM(t *testing.T, tests ...*vm.CompiledTest) (*vm.VM, *bytes.Buffer) {
+	symbols := map[vm.SymbolRegister]*vm.Symbol{
+		"true":  {Type: "bool", Value: "true"},
+		"false": {Type: "bool", Value: "false"},
+		"3":     {Type: "number", Value: "3"},
+	}
+	for _, test := range tests {
+		symbols[vm.SymbolRegister(test.TestName)] = &vm.Symbol{
+			Type:  "string",
+			Value: test.TestName,
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	m := vm.NewVM("pkg")
+	m.Stdout = buf
+	m.TestOutput = buf
+	require.NoError(t, m.LoadFile(&vm.File{
+		Tests: tests,
+		Types: types.Registry{
+			"bool":   types.Bool,
+			"number": types.Number,
+			"string": types.String,
+		},
+		Symbols: symbols,
+	}))
+
+	return m, buf
+}
+
+func newParallelTest(name string, ins ...vm.Instruction) *vm.CompiledTest {
+	return &vm.CompiledTest{
+		CompiledFunc: &vm.CompiledFunc{
+			Instructions: vm.NewInstructions(ins...),
+			Pos:          "a.ok:1:1",
+		},
+		TestName: name,
+	}
+}
+
+// printName prints the name of the test. Some tests run many more instructions
+// than others so that they do not finish in order.
+func printName(name string, padding int) []vm.Instruction {
+	var ins []vm.Instruction
+	for i := 0; i < padding; i++ {
+		ins = append(ins, &vm.AssignSymbol{Result: "2", Symbol: "true"})
+	}
+
+	return append(ins,
+		&vm.AssignSymbol{Result: "1", Symbol: vm.SymbolRegister(name)},
+		&vm.Print{Arguments: vm.Registers{"1"}},
+	)
+}
+
+func assertion(pass bool) []vm.Instruction {
+	final := vm.SymbolRegister("true")
+	if !pass {
+		final = "false"
+	}
+
+	return []vm.Instruction{
+		&vm.AssignSymbol{Result: "1", Symbol: "true"},
+		&vm.AssignSymbol{Result: "2", Symbol: final},
+		&vm.Assert{Left: "1", Right: "1", Final: "2", Op: "==", Pos: "a.ok:2:1"},
+	}
+}
+
+func TestVM_RunTestsParallel_Order(t *testing.T) {
+	var tests []*vm.CompiledTest
+	var expected string
+	for i, name := range strings.Split("abcdefghijklmnop", "") {
+		tests = append(tests, newParallelTest(name,
+			printName(name, (16-i)*1000)...))
+		expected += name + "\n"
+	}
+
+	m, buf := newParallelVM(t, tests...)
+	err := m.RunTestsParallel(4, false, regexp.MustCompile(""), "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, expected, buf.String())
+	assert.Equal(t, 16, m.TestsPass)
+}
+
+func TestVM_RunTestsParallel_Results(t *testing.T) {
+	var passes, fails []vm.Instruction
+	for i := 0; i < 3; i++ {
+		passes = append(passes, assertion(true)...)
+	}
+	fails = append(fails, assertion(true)...)
+	fails = append(fails, assertion(false)...)
+
+	m, buf := newParallelVM(t,
+		newParallelTest("a", passes...),
+		newParallelTest("b", fails...),
+		newParallelTest("c", assertion(true)...),
+		newParallelTest("d", fails...),
+	)
+	err := m.RunTestsParallel(2, false, regexp.MustCompile(""), "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, "pkg: a.ok:2:1: b: assert(true == true) failed\n"+
+		"pkg: a.ok:2:1: d: assert(true == true) failed\n", buf.String())
+	assert.Equal(t, 2, m.TestsPass)
+	assert.Equal(t, 2, m.TestsFailed)
+	assert.Equal(t, 8, m.TotalAssertions)
+}
+
+func TestVM_RunTestsParallel_Exit(t *testing.T) {
+	m, buf := newParallelVM(t,
+		newParallelTest("a", printName("a", 0)...),
+		newParallelTest("b", append(printName("b", 0),
+			&vm.AssignSymbol{Result: "3", Symbol: "3"},
+			&vm.Exit{Status: "3"},
+		)...),
+		newParallelTest("c", append(printName("c", 0),
+			&vm.Len{Argument: "9", Result: "1"},
+		)...),
+		newParallelTest("d", append(printName("d", 0), assertion(true)...)...),
+	)
+	err := m.RunTestsParallel(4, false, regexp.MustCompile(""), "pkg")
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output,
+		"a\nb\npkg: a.ok:1:1: b: exit status 3\nc\nVM panicked"), output)
+	assert.True(t, strings.HasSuffix(output,
+		"pkg: a.ok:1:1: c: exit status 1\nd\n"), output)
+	assert.Equal(t, 2, m.TestsPass)
+	assert.Equal(t, 2, m.TestsFailed)
+	assert.Equal(t, 1, m.TotalAssertions)
+}
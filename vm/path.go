@@ -1,16 +1,61 @@
 package vm
 
 import (
-	"path"
-	"strings"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
 )
 
-// TODO(elliot): This should be configurable through an environment variable.
-const Directory = "/tmp/okc"
+// Directory returns the directory that contains the compile cache. It can be
+// configured with the $OKCACHE environment variable, otherwise "okc" in the
+// temporary directory is used.
+func Directory() string {
+	if dir := os.Getenv("OKCACHE"); dir != "" {
+		return dir
+	}
 
-// PathForPackage returns the absolute path of where the JSON file should exist
-// for a package name. However, the path returned may not exist.
-func PathForPackage(packageName string) string {
-	return path.Join(Directory,
-		strings.ReplaceAll(packageName, "/", "__")+".json")
+	return filepath.Join(os.TempDir(), "okc")
+}
+
+// PathForKey returns the path of the okc file for a cache key. However, the
+// path returned may not exist.
+func PathForKey(key string) string {
+	return filepath.Join(Directory(), key+".okc")
+}
+
+// tempFile matches the temporary files that are left behind if Store is
+// interrupted. The compiler uses a SHA-256 (in hex) as the key.
+var tempFile = regexp.MustCompile(`^[0-9a-f]{64}\.[0-9]+$`)
+
+// Clean removes the okc files (and any temporary files created by Store) from
+// the compile cache. Nothing else is removed since $OKCACHE may point to a
+// directory that contains other files. The paths of the removed files are
+// returned.
+func Clean() ([]string, error) {
+	files, err := ioutil.ReadDir(Directory())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, file := range files {
+		name := file.Name()
+		if !file.Mode().IsRegular() ||
+			(filepath.Ext(name) != ".okc" && !tempFile.MatchString(name)) {
+			continue
+		}
+
+		path := filepath.Join(Directory(), name)
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, path)
+	}
+
+	return removed, nil
 }
@@ -0,0 +1,62 @@
+package vm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClean(t *testing.T) {
+	dir, err := ioutil.TempDir("", "okc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer os.Setenv("OKCACHE", os.Getenv("OKCACHE"))
+	require.NoError(t, os.Setenv("OKCACHE", dir))
+
+	key := strings.Repeat("ab", 32)
+	for _, name := range []string{
+		key + ".okc",
+		key + ".123456",
+		"notes.txt",
+		"main.ok",
+		"abc.123",
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "src.okc"), 0755))
+
+	removed, err := vm.Clean()
+	require.NoError(t, err)
+	sort.Strings(removed)
+	assert.Equal(t, []string{
+		filepath.Join(dir, key+".123456"),
+		filepath.Join(dir, key+".okc"),
+	}, removed)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	assert.Equal(t, []string{"abc.123", "main.ok", "notes.txt", "src.okc"}, names)
+}
+
+func TestClean_MissingDirectory(t *testing.T) {
+	defer os.Setenv("OKCACHE", os.Getenv("OKCACHE"))
+	require.NoError(t, os.Setenv("OKCACHE", filepath.Join(os.TempDir(),
+		"ok-missing-cache")))
+
+	removed, err := vm.Clean()
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+}
@@ -0,0 +1,163 @@
+package vm
+
+import (
+	"strings"
+	"time"
+
+	"github.com/elliotchance/ok/ast"
+)
+
+// Profile counts the instructions executed, and the wall time spent, for each
+// call stack. It is enabled by setting VM.Profile before running. See the
+// profile package for writing it in the pprof format.
+type Profile struct {
+	// Samples is indexed by a key that represents the call stack.
+	Samples map[string]*ProfileSample
+
+	// funcs finds the function that instructions belong to. It is created
+	// for each VM, since a profile can be shared by several VMs.
+	vm    *VM
+	funcs map[*Instructions]*CompiledFunc
+
+	frames  []ProfileFrame
+	last    *ProfileSample
+	lastNow time.Time
+}
+
+// ProfileSample is a unique call stack.
+type ProfileSample struct {
+	// Frames starts with the innermost function.
+	Frames []ProfileFrame
+
+	Instructions int64
+	Duration     time.Duration
+}
+
+// ProfileFrame is a function on the call stack.
+type ProfileFrame struct {
+	// Func will be nil if the instructions do not belong to a known function.
+	Func *CompiledFunc
+
+	// Name is the description of the function, like "math.Abs" or
+	// `test "my test"`.
+	Name string
+
+	// Pos is the statement being executed.
+	Pos string
+
+	// stack identifies the frame in VM.Stack.
+	stack *ast.Literal
+}
+
+// NewProfile creates an empty profile.
+func NewProfile() *Profile {
+	return &Profile{
+		Samples: map[string]*ProfileSample{},
+	}
+}
+
+// Stop must be called when the program has finished to account for the time
+// spent in the last instruction.
+func (p *Profile) Stop() {
+	p.addDuration(time.Now())
+	p.last = nil
+}
+
+func (p *Profile) addDuration(now time.Time) {
+	if p.last != nil {
+		p.last.Duration += now.Sub(p.lastNow)
+	}
+	p.lastNow = now
+}
+
+func (p *Profile) record(vm *VM, ins *Instructions, i int) {
+	// The time since the previous instruction belongs to the previous
+	// instruction.
+	p.addDuration(time.Now())
+
+	if p.vm != vm {
+		p.vm = vm
+		p.frames = nil
+		p.funcs = map[*Instructions]*CompiledFunc{}
+		for _, fn := range vm.fns {
+			p.funcs[fn.Instructions] = fn
+			for _, finally := range fn.Finally {
+				p.funcs[finally] = fn
+			}
+		}
+
+		for _, test := range vm.tests {
+			for _, instructions := range test.InstructionSets() {
+				p.funcs[instructions] = test.CompiledFunc
+			}
+		}
+	}
+
+	// Frames that are no longer on the stack are removed, and new frames are
+	// added.
+	n := 0
+	for n < len(p.frames) && n < len(vm.Stack) &&
+		p.frames[n].stack == vm.Stack[n][StackRegister] {
+		n++
+	}
+	changed := n != len(p.frames) || n != len(vm.Stack)
+	p.frames = p.frames[:n]
+
+	for _, registers := range vm.Stack[n:] {
+		stack := registers[StackRegister]
+		name := ""
+		if parts := strings.SplitN(stack.Value, "|", 2); len(parts) == 2 {
+			name = parts[1]
+		}
+
+		p.frames = append(p.frames, ProfileFrame{
+			Name:  name,
+			stack: stack,
+		})
+	}
+
+	top := &p.frames[len(p.frames)-1]
+	if fn, ok := p.funcs[ins]; ok && fn != top.Func {
+		top.Func = fn
+		changed = true
+	}
+	if pos := ins.Pos(i); pos != "" && pos != top.Pos {
+		top.Pos = pos
+		changed = true
+	}
+
+	// Most instructions are in the same statement as the previous instruction.
+	if !changed && p.last != nil {
+		p.last.Instructions++
+		return
+	}
+
+	// The frames that have not run any instructions, or are packages that
+	// have already been initialized, are not included.
+	var key strings.Builder
+	var frames []ProfileFrame
+	for i := len(p.frames) - 1; i >= 0; i-- {
+		if p.frames[i].Func == nil || vm.packageFrames[p.frames[i].stack] {
+			continue
+		}
+
+		frames = append(frames, p.frames[i])
+		key.WriteString(p.frames[i].Func.Pos)
+		key.WriteByte('|')
+		key.WriteString(p.frames[i].Func.Name)
+		key.WriteByte('|')
+		key.WriteString(p.frames[i].Pos)
+		key.WriteByte('\n')
+	}
+
+	sample := p.Samples[key.String()]
+	if sample == nil {
+		sample = &ProfileSample{
+			Frames: frames,
+		}
+		p.Samples[key.String()] = sample
+	}
+
+	sample.Instructions++
+	p.last = sample
+}
@@ -0,0 +1,30 @@
+package vm
+
+import (
+	"fmt"
+)
+
+// Receive receives the next value from a channel. It blocks until there is a
+// value. An error is raised if the channel is closed.
+type Receive struct {
+	Channel, Result Register
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *Receive) Execute(_ *int, vm *VM) error {
+	value, ok := vm.receive(vm.Get(ins.Channel).Channel.(*channel))
+	switch {
+	case ok:
+		vm.Set(ins.Result, value)
+
+	case vm.ErrType == nil:
+		vm.Raise("receive from closed channel")
+	}
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *Receive) String() string {
+	return fmt.Sprintf("%s = %s.Receive()", ins.Result, ins.Channel)
+}
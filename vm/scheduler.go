@@ -0,0 +1,262 @@
+package vm
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/types"
+)
+
+// timeSlice is the number of instructions a task can execute before it lets
+// the other tasks run.
+const timeSlice = 1000
+
+// scheduler runs tasks one at a time. A task is a function call that was
+// spawned. It runs in its own VM, so that it has its own stack and error state,
+// but it shares the code and globals of the VM that spawned it.
+//
+// Only one task is running. It lets the next task run when it is blocked on a
+// channel, has finished or has executed timeSlice instructions. Tasks run in
+// the order that they became ready. Unless the scheduler is deterministic, a
+// task also lets the other tasks run while it sleeps, which makes the order
+// depend on timing.
+type scheduler struct {
+	deterministic bool
+
+	mu       sync.Mutex
+	running  bool
+	ready    []*task
+	sleeping int
+
+	// done is closed when the tasks are stopped, or when a task exits the
+	// program with status. Any task that is waiting will never run again.
+	done   chan struct{}
+	status *exitStatus
+	stop   sync.Once
+}
+
+// task is a VM that is run by the scheduler.
+type task struct {
+	vm   *VM
+	wake chan struct{}
+
+	// main is true for the VM that spawned the first task.
+	main bool
+}
+
+func newScheduler(deterministic bool) *scheduler {
+	return &scheduler{
+		deterministic: deterministic,
+		running:       true,
+		done:          make(chan struct{}),
+	}
+}
+
+func newTask(vm *VM) *task {
+	return &task{
+		vm:   vm,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// add makes a task ready to run. It is used for new tasks and for tasks that
+// were blocked.
+func (s *scheduler) add(t *task) {
+	s.mu.Lock()
+	s.ready = append(s.ready, t)
+	s.mu.Unlock()
+}
+
+// next lets the task that has been ready the longest run. Nothing is running
+// when there are no tasks ready. s.mu must be locked.
+func (s *scheduler) next() {
+	s.running = len(s.ready) > 0
+	if s.running {
+		t := s.ready[0]
+		s.ready = s.ready[1:]
+		t.wake <- struct{}{}
+	}
+}
+
+// wait blocks until it is the turn of the task to run.
+func (s *scheduler) wait(t *task) {
+	select {
+	case <-t.wake:
+
+	case <-s.done:
+		// The main task must unwind to where the program was started so that
+		// the exit status can be returned.
+		if t.main && s.status != nil {
+			panic(*s.status)
+		}
+
+		runtime.Goexit()
+	}
+}
+
+// yield lets the tasks that are ready run before t continues.
+func (s *scheduler) yield(t *task) {
+	s.mu.Lock()
+	if len(s.ready) == 0 {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.ready = append(s.ready, t)
+	s.next()
+	s.mu.Unlock()
+	s.wait(t)
+}
+
+// block lets the other tasks run until t is added again. It returns false,
+// without blocking, when there is no task that could add t.
+func (s *scheduler) block(t *task) bool {
+	s.mu.Lock()
+	if len(s.ready) == 0 && s.sleeping == 0 {
+		s.mu.Unlock()
+
+		return false
+	}
+
+	s.next()
+	s.mu.Unlock()
+	s.wait(t)
+
+	return true
+}
+
+// sleep lets the other tasks run while t sleeps. A deterministic scheduler
+// does not switch tasks.
+func (s *scheduler) sleep(t *task, d time.Duration) {
+	if s.deterministic {
+		time.Sleep(d)
+
+		return
+	}
+
+	s.mu.Lock()
+	s.sleeping++
+	s.next()
+	s.mu.Unlock()
+
+	time.Sleep(d)
+
+	s.mu.Lock()
+	s.sleeping--
+	select {
+	case <-s.done:
+		s.mu.Unlock()
+		s.wait(t)
+
+	default:
+		if !s.running {
+			s.running = true
+			s.mu.Unlock()
+
+			return
+		}
+
+		s.ready = append(s.ready, t)
+		s.mu.Unlock()
+		s.wait(t)
+	}
+}
+
+// finish lets the next task run because the current task has finished.
+func (s *scheduler) finish() {
+	s.mu.Lock()
+	s.next()
+	s.mu.Unlock()
+}
+
+// abandon stops all of the tasks that are waiting. status is only set when a
+// task exits the program.
+func (s *scheduler) abandon(status *exitStatus) {
+	s.stop.Do(func() {
+		s.status = status
+		close(s.done)
+	})
+}
+
+// spawn calls a function in a new task. The task will not start until the
+// current task is blocked, finishes or yields.
+func (vm *VM) spawn(fn *ast.Literal, args []*ast.Literal, pos string) {
+	if vm.scheduler == nil {
+		vm.scheduler = newScheduler(vm.Deterministic)
+		vm.task = newTask(vm)
+		vm.task.main = true
+	}
+
+	child := NewVM(vm.pkg)
+	child.fns = vm.fns
+	child.Types = vm.Types
+	child.Symbols = vm.Symbols
+	child.Globals = vm.Globals
+	child.Stdout = vm.Stdout
+	child.TestOutput = vm.TestOutput
+	child.Coverage = vm.Coverage
+	child.ReturnOnExit = vm.ReturnOnExit
+	child.UpdateSnapshots = vm.UpdateSnapshots
+	child.Deterministic = vm.Deterministic
+	child.rand = vm.rand
+	child.scheduler = vm.scheduler
+	child.task = newTask(child)
+	child.root = vm.root
+	if child.root == nil {
+		child.root = vm
+	}
+
+	// The arguments are copied into the first scope of the task, so that they
+	// can be passed to the function like any other call.
+	child.appendStack(stackDescription(pos, "spawn"),
+		map[string]*ast.Literal{}, types.Any)
+	var arguments []Register
+	for i, arg := range args {
+		register := Register(strconv.Itoa(i + 1))
+		child.Set(register, arg)
+		arguments = append(arguments, register)
+	}
+
+	vm.scheduler.add(child.task)
+	go child.runTask(fn, arguments, pos)
+}
+
+// runTask is the goroutine of a task.
+func (vm *VM) runTask(fn *ast.Literal, arguments []Register, pos string) {
+	defer func() {
+		if r := recover(); r != nil {
+			status, ok := r.(exitStatus)
+			if !ok {
+				panic(r)
+			}
+
+			vm.scheduler.abandon(&status)
+		}
+	}()
+
+	vm.scheduler.wait(vm.task)
+
+	_, err := vm.call(fn.Value, arguments, fn.Map, types.Any, pos)
+	if err != nil {
+		fmt.Fprintln(vm.Stdout, err)
+		vm.exit(1)
+	}
+	vm.catchUnhandledError()
+
+	vm.scheduler.finish()
+}
+
+// stopTasks abandons the tasks that have not finished. They will never run
+// again.
+func (vm *VM) stopTasks() {
+	if vm.scheduler != nil {
+		vm.scheduler.abandon(nil)
+		vm.scheduler = nil
+		vm.task = nil
+	}
+}
@@ -0,0 +1,135 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+)
+
+// Select performs one of several channel operations. It blocks until one of
+// them can be performed, unless there is an else. When more than one operation
+// is ready the first is chosen if the VM is deterministic, otherwise one is
+// chosen at random.
+type Select struct {
+	// Values contains the value to send for each channel. It is empty for a
+	// receive.
+	Channels Registers // In (chan)
+	Values   Registers // In (any)
+	Else     bool
+
+	// Chosen is the index of the operation that was performed, or -1 for the
+	// else. Value contains the value if it was a receive.
+	Chosen Register // Out (number)
+	Value  Register // Out (any)
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *Select) Execute(_ *int, vm *VM) error {
+	order := make([]int, len(ins.Channels))
+	for i := range order {
+		order[i] = i
+	}
+
+	if !vm.Deterministic {
+		vm.rand.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	}
+
+	w := &waiter{task: vm.task}
+	for _, i := range order {
+		c := vm.Get(ins.Channels[i]).Channel.(*channel)
+		if ins.Values[i] != "" {
+			value := vm.Get(ins.Values[i])
+			if c.closed {
+				vm.Raise("send on closed channel")
+
+				return nil
+			}
+
+			if c.trySend(value) {
+				ins.choose(vm, i, nil)
+
+				return nil
+			}
+
+			w.ops = append(w.ops, &channelOp{
+				waiter:  w,
+				channel: c,
+				index:   i,
+				send:    true,
+				value:   value,
+			})
+		} else {
+			if value, ok := c.tryReceive(); ok {
+				ins.choose(vm, i, value)
+
+				return nil
+			}
+
+			if c.closed {
+				vm.Raise("receive from closed channel")
+
+				return nil
+			}
+
+			w.ops = append(w.ops, &channelOp{
+				waiter:  w,
+				channel: c,
+				index:   i,
+			})
+		}
+	}
+
+	if ins.Else {
+		ins.choose(vm, -1, nil)
+
+		return nil
+	}
+
+	if !vm.wait(w) {
+		vm.Raise(deadlock)
+
+		return nil
+	}
+
+	if w.closed {
+		if ins.Values[w.chosen] != "" {
+			vm.Raise("send on closed channel")
+		} else {
+			vm.Raise("receive from closed channel")
+		}
+
+		return nil
+	}
+
+	ins.choose(vm, w.chosen, w.value)
+
+	return nil
+}
+
+func (ins *Select) choose(vm *VM, chosen int, value *ast.Literal) {
+	vm.Set(ins.Chosen, asttest.NewLiteralNumber(fmt.Sprintf("%d", chosen)))
+	if value != nil {
+		vm.Set(ins.Value, value)
+	}
+}
+
+// String is the human-readable description of the instruction.
+func (ins *Select) String() string {
+	s := fmt.Sprintf("%s, %s = select", ins.Chosen, ins.Value)
+	for i, c := range ins.Channels {
+		if ins.Values[i] != "" {
+			s += fmt.Sprintf(" %s.Send(%s)", c, ins.Values[i])
+		} else {
+			s += fmt.Sprintf(" %s.Receive()", c)
+		}
+	}
+
+	if ins.Else {
+		s += " else"
+	}
+
+	return s
+}
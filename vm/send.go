@@ -0,0 +1,23 @@
+package vm
+
+import (
+	"fmt"
+)
+
+// Send sends a value to a channel. It blocks until the value is received, or
+// there is room in the buffer of the channel.
+type Send struct {
+	Channel, Value Register
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *Send) Execute(_ *int, vm *VM) error {
+	vm.send(vm.Get(ins.Channel).Channel.(*channel), vm.Get(ins.Value))
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *Send) String() string {
+	return fmt.Sprintf("%s.Send(%s)", ins.Channel, ins.Value)
+}
@@ -16,7 +16,14 @@ type Sleep struct {
 func (ins *Sleep) Execute(_ *int, vm *VM) error {
 	seconds := number.NewNumber(vm.Get(ins.Seconds).Value)
 	duration := number.Multiply(seconds, number.NewNumber("1000000000"))
-	time.Sleep(time.Duration(number.Int64(duration)))
+	d := time.Duration(number.Int64(duration))
+
+	// Other tasks can run while this one is sleeping.
+	if vm.scheduler != nil {
+		vm.scheduler.sleep(vm.task, d)
+	} else {
+		time.Sleep(d)
+	}
 
 	return nil
 }
@@ -0,0 +1,106 @@
+package vm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// snapshotDir is the directory, relative to the package, that contains the
+// snapshots.
+const snapshotDir = "testdata"
+
+// Snapshot compares a value to the snapshot stored in a file. It is counted as
+// an assertion of the current test. When UpdateSnapshots is set, the file is
+// written with the value instead.
+//
+// The position of the assertion is the position of the call to the function
+// that executed the instruction (testing.Snapshot) rather than the instruction
+// itself.
+type Snapshot struct {
+	Name  Register // In (string): The name of the snapshot.
+	Value Register // In (string): The actual value.
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *Snapshot) Execute(_ *int, vm *VM) error {
+	if vm.currentTest == nil {
+		vm.Raise("snapshots can only be used in tests")
+
+		return nil
+	}
+
+	name := vm.Get(ins.Name).Value
+	actual := vm.Get(ins.Value).Value
+	path := snapshotPath(vm.currentTest.Pos, name)
+	pos := strings.Split(vm.Stack[len(vm.Stack)-1][StackRegister].Value, "|")[0]
+	message := fmt.Sprintf("snapshot(%q)", name)
+
+	if vm.UpdateSnapshots {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(actual), 0644)
+		}
+
+		if err != nil {
+			vm.Raise(err.Error())
+
+			return nil
+		}
+
+		vm.assert(true, message, pos, "", nil)
+
+		return nil
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		vm.assert(false, message, pos,
+			"snapshot does not exist, run \"ok test -update\" to create it", nil)
+
+		return nil
+	}
+
+	if err != nil {
+		vm.Raise(err.Error())
+
+		return nil
+	}
+
+	if string(expected) == actual {
+		vm.assert(true, message, pos, "", nil)
+
+		return nil
+	}
+
+	vm.assert(false, message, pos, "", diffLines(string(expected), actual))
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *Snapshot) String() string {
+	return fmt.Sprintf("testing.Snapshot(%s, %s)", ins.Name, ins.Value)
+}
+
+// snapshotPath returns the file for a snapshot. testPos is the position of the
+// test, the snapshot is in the snapshotDir next to the file that contains the
+// test.
+func snapshotPath(testPos, name string) string {
+	return filepath.Join(testDir(testPos), snapshotDir, name+".snapshot")
+}
+
+// testDir returns the directory of the file that contains the test at testPos.
+func testDir(testPos string) string {
+	// Remove the line and column.
+	file := testPos
+	for i := 0; i < 2; i++ {
+		if j := strings.LastIndex(file, ":"); j >= 0 {
+			file = file[:j]
+		}
+	}
+
+	return filepath.Dir(file)
+}
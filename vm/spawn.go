@@ -0,0 +1,35 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+)
+
+// Spawn calls a function in a new task. The arguments are evaluated by the
+// current task, but the function does not start until the current task lets
+// other tasks run.
+type Spawn struct {
+	Function  Register // In (func)
+	Arguments Registers
+
+	// Pos is used to append to the call stack.
+	Pos string
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *Spawn) Execute(_ *int, vm *VM) error {
+	var args []*ast.Literal
+	for _, arg := range ins.Arguments {
+		args = append(args, vm.Get(arg))
+	}
+
+	vm.spawn(vm.Get(ins.Function), args, ins.Pos)
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *Spawn) String() string {
+	return fmt.Sprintf("spawn %s%s", ins.Function, ins.Arguments)
+}
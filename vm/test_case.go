@@ -0,0 +1,87 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/elliotchance/ok/ast"
+	"github.com/elliotchance/ok/ast/asttest"
+	"github.com/elliotchance/ok/types"
+)
+
+// testCase is a single run of a test. A table test will have one testCase for
+// each element.
+type testCase struct {
+	test      *CompiledTest
+	variables map[Register]*ast.Literal
+}
+
+// testCases evaluates the cases of a table test. Each case has its own name,
+// which is the name of the test followed by "/" and the array index or map
+// key. This allows each case to be filtered and reported separately.
+//
+// The cases of a fuzz target are the inputs in its corpus, see fuzzCorpus.
+//
+// A test that is not a table test is returned as the only case. If the cases
+// cannot be evaluated (because an error was raised) the error is reported, the
+// test is counted as failed and no cases are returned. This is reported even if
+// the test would not match the filter because the names of the cases are not
+// known.
+func (vm *VM) testCases(test *CompiledTest, packageName string) ([]testCase, error) {
+	if test.IsFuzz {
+		return vm.fuzzCorpus(test)
+	}
+
+	if test.Cases == nil {
+		return []testCase{{test: test}}, nil
+	}
+
+	vm.CurrentTestName = test.TestName
+	vm.currentTest = test
+
+	stackDesc := stackDescription(test.Pos,
+		fmt.Sprintf("test \"%s\"", test.TestName))
+	vm.appendStack(stackDesc, map[string]*ast.Literal{}, types.Any)
+	_, err := vm.runInstructions(test.TestName, test.Cases, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if vm.ErrType != nil {
+		if vm.TestReporter != nil {
+			vm.TestReporter.TestStarted(test)
+		}
+
+		vm.testErrored(test, packageName)
+		vm.Stack = vm.Stack[:len(vm.Stack)-1]
+		vm.TestsFailed++
+
+		if vm.TestReporter != nil {
+			vm.TestReporter.TestFinished(test, false, 0)
+		}
+
+		return nil, nil
+	}
+
+	cases := vm.Get(test.CasesResult)
+	vm.Stack = vm.Stack[:len(vm.Stack)-1]
+
+	var result []testCase
+	for i, element := range cases.Array {
+		key, value := asttest.NewLiteralNumber(fmt.Sprintf("%d", i)), element
+		if cases.Kind.Kind == types.KindMap {
+			key, value = element, cases.Map[element.Value]
+		}
+
+		t := *test
+		t.TestName = fmt.Sprintf("%s/%s", test.TestName, key.Value)
+
+		variables := map[Register]*ast.Literal{test.CaseValue: value}
+		if test.CaseKey != "" {
+			variables[test.CaseKey] = key
+		}
+
+		result = append(result, testCase{test: &t, variables: variables})
+	}
+
+	return result, nil
+}
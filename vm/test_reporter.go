@@ -0,0 +1,23 @@
+package vm
+
+import "time"
+
+// TestReporter can be attached to a VM to receive the results of each test as
+// it runs. See the report package.
+type TestReporter interface {
+	// TestStarted is called before each test.
+	TestStarted(test *CompiledTest)
+
+	// Assertion is called for every assertion. message is the assertion
+	// itself, like "assert(3 == 4)", and pos is where it is. A failed
+	// assertion also includes its description and the differences between
+	// the values, each on a new line.
+	Assertion(test *CompiledTest, pos string, passed bool, message string)
+
+	// TestErrored is called when a test finishes with an unhandled error. The
+	// stack is the innermost function first.
+	TestErrored(test *CompiledTest, message string, stack []string)
+
+	// TestFinished is called after each test.
+	TestFinished(test *CompiledTest, passed bool, elapsed time.Duration)
+}
@@ -0,0 +1,151 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/elliotchance/ok/number"
+)
+
+// deadlineInterval is the number of instructions executed between checking the
+// deadline of a test. Checking the time for every instruction would be too
+// slow.
+const deadlineInterval = 1000
+
+// timeout is raised (as a panic) when a test runs out of time or instructions.
+// It is recovered by runTest, so that the test is abandoned no matter how deep
+// the calls are.
+type timeout struct {
+	message string
+
+	// stack is from captureCallStack, at the instruction that was about to be
+	// executed.
+	stack []string
+}
+
+// startLimits sets the deadline and the instruction limit of a test that is
+// about to start.
+func (vm *VM) startLimits() {
+	vm.testStarted = time.Now()
+	vm.setTimeout(vm.TestTimeout)
+
+	vm.instructionLimit = 0
+	if vm.MaxInstructions > 0 {
+		vm.instructionLimit = vm.InstructionsExecuted + vm.MaxInstructions
+	}
+}
+
+// stopLimits removes the deadline and the instruction limit.
+func (vm *VM) stopLimits() {
+	vm.deadline = time.Time{}
+	vm.instructionLimit = 0
+}
+
+// setTimeout changes the deadline of the current test. The timeout is measured
+// from the start of the test. A timeout of zero removes the deadline.
+func (vm *VM) setTimeout(d time.Duration) {
+	vm.timeout = d
+	vm.deadline = time.Time{}
+	if d > 0 {
+		vm.deadline = vm.testStarted.Add(d)
+	}
+}
+
+// checkLimits panics with a timeout if the current test has exceeded its
+// deadline or instruction limit. It must be called after InstructionsExecuted
+// is incremented.
+func (vm *VM) checkLimits(ins *Instructions, i int) {
+	switch {
+	case vm.instructionLimit > 0 &&
+		vm.InstructionsExecuted > vm.instructionLimit:
+		panic(&timeout{
+			message: fmt.Sprintf("exceeded %d instructions", vm.MaxInstructions),
+			stack:   vm.captureCallStack(ins.Pos(i)),
+		})
+
+	case !vm.deadline.IsZero() &&
+		vm.InstructionsExecuted%deadlineInterval == 0 &&
+		time.Now().After(vm.deadline):
+		panic(&timeout{
+			message: fmt.Sprintf("timed out after %s", vm.timeout),
+			stack:   vm.captureCallStack(ins.Pos(i)),
+		})
+	}
+}
+
+// runTestInstructions runs the instructions of a test. If the test runs out of
+// time or instructions the timeout is returned, rather than panicking.
+func (vm *VM) runTestInstructions(
+	test *CompiledTest,
+	ins *Instructions,
+) (t *timeout, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if t, ok = r.(*timeout); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	_, err = vm.runInstructions(test.TestName, ins, false)
+
+	return nil, err
+}
+
+// testTimedOut reports a test that ran out of time or instructions. The stack
+// and finally blocks are rolled back to the sizes they were before the test
+// started. Any error that was being raised is discarded since the test was
+// abandoned.
+func (vm *VM) testTimedOut(
+	test *CompiledTest,
+	packageName string,
+	t *timeout,
+	stackSize, finallySize int,
+) {
+	wd, _ := os.Getwd()
+	fmt.Fprintf(vm.TestOutput, "%s: %s: %s: %s\n",
+		packageName, strings.TrimPrefix(test.Pos, wd), vm.CurrentTestName,
+		t.message)
+	stack := describeStack(t.stack)
+	for i, s := range stack {
+		fmt.Fprintln(vm.TestOutput, "", "", len(stack)-i, s)
+	}
+
+	if vm.TestReporter != nil {
+		vm.TestReporter.TestErrored(test, t.message, stack)
+	}
+
+	vm.Stack = vm.Stack[:stackSize]
+	vm.FinallyBlocks = vm.FinallyBlocks[:finallySize]
+	vm.ErrType = nil
+	vm.Return = nil
+	vm.CurrentTestPassed = false
+}
+
+// Timeout replaces the timeout of the current test. See VM.TestTimeout.
+type Timeout struct {
+	Seconds Register // In (number): Zero will remove the timeout.
+}
+
+// Execute implements the Instruction interface for the VM.
+func (ins *Timeout) Execute(_ *int, vm *VM) error {
+	if vm.currentTest == nil || vm.currentTest.IsBench {
+		vm.Raise("a timeout can only be set in tests")
+
+		return nil
+	}
+
+	seconds := number.NewNumber(vm.Get(ins.Seconds).Value)
+	duration := number.Multiply(seconds, number.NewNumber("1000000000"))
+	vm.setTimeout(time.Duration(number.Int64(duration)))
+
+	return nil
+}
+
+// String is the human-readable description of the instruction.
+func (ins *Timeout) String() string {
+	return fmt.Sprintf("testing.Timeout(%s)", ins.Seconds)
+}
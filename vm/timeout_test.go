@@ -0,0 +1,95 @@
+package vm_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/ok/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVM_RunTests_Limits(t *testing.T) {
+	for testName, test := range map[string]struct {
+		timeout         time.Duration
+		maxInstructions int
+		expectedOutput  string
+	}{
+		"timeout": {
+			timeout: 10 * time.Millisecond,
+			expectedOutput: "pkg: a.ok:1:1: loops: timed out after 10ms\n" +
+				"  2 loop() at a.ok:6:5\n" +
+				"  1 test \"loops\"() at a.ok:2:5\n",
+		},
+		"instructions": {
+			maxInstructions: 100,
+			expectedOutput: "pkg: a.ok:1:1: loops: exceeded 100 instructions\n" +
+				"  2 loop() at a.ok:6:5\n" +
+				"  1 test \"loops\"() at a.ok:2:5\n",
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			newTest := func(name string, ins ...vm.Instruction) *vm.CompiledTest {
+				return &vm.CompiledTest{
+					CompiledFunc: &vm.CompiledFunc{
+						Instructions: &vm.Instructions{
+							Instructions: ins,
+							Positions:    map[int]string{0: "a.ok:2:5"},
+						},
+						Pos: "a.ok:1:1",
+					},
+					TestName: name,
+				}
+			}
+
+			// The packages are initialized before the tests run, but they must
+			// not appear in the stack.
+			newFunc := func(name string, ins ...vm.Instruction) *vm.Symbol {
+				return &vm.Symbol{
+					Func: &vm.CompiledFunc{
+						Name:       name,
+						UniqueName: name,
+						Instructions: &vm.Instructions{
+							Instructions: ins,
+							Positions:    map[int]string{0: "a.ok:6:5"},
+						},
+						Pos: "a.ok:5:1",
+					},
+				}
+			}
+			newPackage := func(name string) *vm.Symbol {
+				return newFunc(name, &vm.Return{Results: vm.Registers{"1"}})
+			}
+
+			buf := bytes.NewBuffer(nil)
+			m := vm.NewVM("pkg")
+			m.TestOutput = buf
+			m.TestTimeout = test.timeout
+			m.MaxInstructions = test.maxInstructions
+			require.NoError(t, m.LoadFile(&vm.File{
+				Tests: []*vm.CompiledTest{
+					newTest("loops", &vm.Call{
+						FunctionName: "loop",
+						Pos:          "a.ok:2:5",
+					}),
+					newTest("finishes"),
+				},
+				Symbols: map[vm.SymbolRegister]*vm.Symbol{
+					"0": newFunc("loop", &vm.Jump{To: -1}),
+					"1": newPackage("a"),
+					"2": newPackage("b"),
+					"3": newPackage("c"),
+				},
+				Globals: map[string]string{"$a": "a", "$b": "b", "$c": "c"},
+			}))
+
+			err := m.RunTests(false, regexp.MustCompile(""), "pkg")
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, buf.String())
+			assert.Equal(t, 1, m.TestsFailed)
+			assert.Equal(t, 1, m.TestsPass)
+		})
+	}
+}
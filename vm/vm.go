@@ -25,10 +25,45 @@ const (
 	StackRegister = "__stack"
 )
 
-// CompiledTest is a runnable test.
+// CompiledTest is a runnable test, benchmark or fuzz target.
 type CompiledTest struct {
 	*CompiledFunc
 	TestName string
+	IsBench  bool `json:",omitempty"`
+
+	// Setup and Teardown are the setup and teardown blocks of the package.
+	// They run in the same scope as the test.
+	Setup    *Instructions `json:",omitempty"`
+	Teardown *Instructions `json:",omitempty"`
+
+	// Cases is only set for table tests. It evaluates the cases into
+	// CasesResult. The test is run once for each element with the value (and
+	// optionally the key) assigned to CaseValue and CaseKey.
+	Cases       *Instructions `json:",omitempty"`
+	CasesResult Register      `json:",omitempty"`
+	CaseValue   Register      `json:",omitempty"`
+	CaseKey     Register      `json:",omitempty"`
+
+	// IsFuzz is true for a fuzz target. The values of FuzzArguments are
+	// generated by RunFuzz, or replayed from the corpus by RunTests.
+	// FuzzConstructors is the unique name of the constructor function for each
+	// object type that may need to be generated.
+	IsFuzz           bool              `json:",omitempty"`
+	FuzzArguments    []*FuzzArgument   `json:",omitempty"`
+	FuzzConstructors map[string]string `json:",omitempty"`
+}
+
+// InstructionSets returns all of the non-nil instructions of the test in a
+// consistent order. The test itself is always first.
+func (t *CompiledTest) InstructionSets() []*Instructions {
+	sets := []*Instructions{t.Instructions}
+	for _, instructions := range []*Instructions{t.Setup, t.Teardown, t.Cases} {
+		if instructions != nil {
+			sets = append(sets, instructions)
+		}
+	}
+
+	return sets
 }
 
 // VM is an instance of a virtual machine to run ok instructions.
@@ -47,6 +82,19 @@ type VM struct {
 	TotalAssertions        int
 	CurrentTestName        string
 	CurrentTestPassed      bool
+	currentTest            *CompiledTest
+
+	// TestOutput is where the test names (when verbose), failed assertions and
+	// unhandled errors are printed while running tests.
+	TestOutput io.Writer
+
+	// TestReporter is optional. When set, it will receive the result of each
+	// test and assertion.
+	TestReporter TestReporter
+
+	// InstructionsExecuted is the total number of instructions that have been
+	// run.
+	InstructionsExecuted int
 
 	// ErrType will be non-empty once an error is raised. It contains the type
 	// to match for a handler. ErrValue contains the actual error, and ErrStack
@@ -69,23 +117,81 @@ type VM struct {
 
 	Globals       map[string]*ast.Literal
 	GlobalsToLoad map[string]string
+
+	// packageFrames are the frames (by their StackRegister) of packages that
+	// have been initialized. See prepareGlobals.
+	packageFrames map[*ast.Literal]bool
+
+	// Debugger is optional. When set, it can pause the VM between
+	// instructions.
+	Debugger Debugger
+
+	// Coverage is optional. When set, it will record the statements that are
+	// executed.
+	Coverage *Coverage
+
+	// Profile is optional. When set, it will record the instructions executed
+	// and time spent in each function.
+	Profile *Profile
+
+	// ReturnOnExit will return an *ExitError from Run (or RunTests) when the
+	// program exits or has an unhandled error, rather than exiting the
+	// process.
+	ReturnOnExit bool
+
+	// UpdateSnapshots will write the snapshots rather than comparing them.
+	// See Snapshot.
+	UpdateSnapshots bool
+
+	// TestTimeout is the maximum time each test can run for, including its
+	// setup and teardown. A test can replace its own timeout with the Timeout
+	// instruction. MaxInstructions is the maximum number of instructions each test can
+	// execute. A test that exceeds either is abandoned and reported as timed
+	// out. Zero means there is no limit. Benchmarks are not limited.
+	TestTimeout     time.Duration
+	MaxInstructions int
+
+	// Deterministic makes spawned tasks run in the same order every time. See
+	// scheduler.
+	Deterministic bool
+
+	// scheduler and task are set once a task has been spawned. Each task has
+	// its own VM, they all share the same scheduler.
+	scheduler *scheduler
+	task      *task
+
+	// root is the VM that started the program (or tests). It is only set for
+	// the VM of a task, so that assertions are reported to the root.
+	root *VM
+
+	// The limits of the current test. See startLimits.
+	testStarted, deadline time.Time
+	timeout               time.Duration
+	instructionLimit      int
 }
 
 // NewVM will create a new VM ready to run the provided instructions.
 func NewVM(pkg string) *VM {
 	return &VM{
-		fns:     make(map[string]*CompiledFunc),
-		pkg:     pkg,
-		Stdout:  os.Stdout,
-		rand:    rand.New(rand.NewSource(int64(time.Now().Nanosecond()))),
-		Types:   map[TypeRegister]*types.Type{},
-		Symbols: map[SymbolRegister]*ast.Literal{},
-		Globals: map[string]*ast.Literal{},
+		fns:        make(map[string]*CompiledFunc),
+		pkg:        pkg,
+		Stdout:     os.Stdout,
+		TestOutput: os.Stdout,
+		rand:       rand.New(rand.NewSource(int64(time.Now().Nanosecond()))),
+		Types:      map[TypeRegister]*types.Type{},
+		Symbols:    map[SymbolRegister]*ast.Literal{},
+		Globals:    map[string]*ast.Literal{},
 	}
 }
 
 func (vm *VM) prepareGlobals() error {
 	for name, uniqueName := range vm.GlobalsToLoad {
+		// Packages are only initialized once, even if the same file is loaded
+		// again (see Eval).
+		if _, ok := vm.Globals[name]; ok {
+			continue
+		}
+
 		registers, err := vm.call(uniqueName, nil,
 			map[string]*ast.Literal{}, types.Any, "unknown")
 		if err != nil {
@@ -95,6 +201,13 @@ func (vm *VM) prepareGlobals() error {
 		vm.Return = nil
 
 		vm.Set(Register(name), vm.Get(registers[0]))
+
+		// The frame is left on the stack, but the package has been initialized
+		// so it is not the caller of anything that runs after it.
+		if vm.packageFrames == nil {
+			vm.packageFrames = map[*ast.Literal]bool{}
+		}
+		vm.packageFrames[vm.Stack[len(vm.Stack)-1][StackRegister]] = true
 	}
 
 	return nil
@@ -106,14 +219,17 @@ func (vm *VM) prepareGlobals() error {
 // TODO(elliot): Change missing main into an error in the future. It was done
 //  this way so I could use "ok run" like an "ok compile" (that didn't exist at
 //  the time) for compiling the standard libraries.
-func (vm *VM) Run(mainPackage string) error {
+func (vm *VM) Run(mainPackage string) (err error) {
+	defer vm.recoverExit(&err)
+	defer vm.stopTasks()
+
 	if err := vm.prepareGlobals(); err != nil {
 		return err
 	}
 
 	// Now we can call the main() function.
 	mainFunction := vm.Globals[mainPackage].Map["main"].Value
-	_, err := vm.call(mainFunction, nil, map[string]*ast.Literal{},
+	_, err = vm.call(mainFunction, nil, map[string]*ast.Literal{},
 		types.Any, "")
 	if err != nil {
 		return err
@@ -124,36 +240,154 @@ func (vm *VM) Run(mainPackage string) error {
 }
 
 // RunTests will run the tests only.
-func (vm *VM) RunTests(verbose bool, filter *regexp.Regexp, packageName string) error {
+func (vm *VM) RunTests(
+	verbose bool,
+	filter *regexp.Regexp,
+	packageName string,
+) (err error) {
+	defer vm.recoverExit(&err)
+
 	if err := vm.prepareGlobals(); err != nil {
 		return err
 	}
 
-	for _, t := range vm.tests {
-		if !filter.MatchString(t.TestName) {
+	for _, test := range vm.tests {
+		if test.IsBench {
 			continue
 		}
 
-		if verbose {
-			fmt.Println("#", t.TestName)
-		}
-
-		vm.CurrentTestPassed = true
-		err := vm.runTest(t, map[string]*ast.Literal{}, packageName)
+		cases, err := vm.testCases(test, packageName)
 		if err != nil {
 			return err
 		}
 
-		if vm.CurrentTestPassed {
-			vm.TestsPass++
-		} else {
-			vm.TestsFailed++
+		for _, c := range cases {
+			if !filter.MatchString(c.test.TestName) {
+				continue
+			}
+
+			err := vm.runTestCase(c, verbose, packageName)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// runTestCase runs and reports a single test, counting it as passed or failed.
+func (vm *VM) runTestCase(c testCase, verbose bool, packageName string) error {
+	t := c.test
+	if verbose {
+		fmt.Fprintln(vm.TestOutput, "#", t.TestName)
+	}
+
+	if vm.TestReporter != nil {
+		vm.TestReporter.TestStarted(t)
+	}
+
+	vm.CurrentTestPassed = true
+	startTime := time.Now()
+	err := vm.runTest(t, map[string]*ast.Literal{}, packageName, c.variables)
+	if err != nil {
+		return err
+	}
+
+	if vm.TestReporter != nil {
+		vm.TestReporter.TestFinished(t, vm.CurrentTestPassed,
+			time.Since(startTime))
+	}
+
+	if vm.CurrentTestPassed {
+		vm.TestsPass++
+	} else {
+		vm.TestsFailed++
+	}
+
+	return nil
+}
+
+// Eval runs a function in a scope that is kept between calls. The function is
+// not called, its instructions run directly in the scope so that variables set
+// by one Eval are available to the next. This allows code to be compiled and
+// run incrementally, such as in "ok repl".
+//
+// Unlike Run, an unhandled error is returned rather than exiting. If
+// ReturnOnExit is true, exiting (including a panic in the VM) is also returned
+// as an *ExitError and the scope can still be used by the next Eval.
+func (vm *VM) Eval(fn *CompiledFunc) (err error) {
+	defer vm.recoverExit(&err)
+
+	// Any packages loaded since the last Eval need to be initialized. This must
+	// not leave anything on the stack above the scope.
+	depth := len(vm.Stack)
+	if err := vm.prepareGlobals(); err != nil {
+		return err
+	}
+	vm.Stack = vm.Stack[:depth]
+
+	if len(vm.Stack) == 0 {
+		vm.appendStack(stackDescription(fn.Pos, fn.Name),
+			map[string]*ast.Literal{}, types.Any)
+	}
+
+	// Exiting part way through a function call leaves its frames on the stack.
+	depth = len(vm.Stack)
+	defer func() {
+		vm.Stack = vm.Stack[:depth]
+	}()
+
+	var finallyBlocks []*FinallyBlock
+	for _, ins := range fn.Finally {
+		finallyBlocks = append(finallyBlocks, &FinallyBlock{
+			Run:          false,
+			Instructions: ins,
+		})
+	}
+	vm.FinallyBlocks = append(vm.FinallyBlocks, finallyBlocks)
+	defer func() {
+		vm.FinallyBlocks = vm.FinallyBlocks[:len(vm.FinallyBlocks)-1]
+	}()
+
+	_, err = vm.runInstructions(fn.Name, fn.Instructions, false)
+	vm.Return = nil
+	if err != nil {
+		return err
+	}
+
+	for _, fb := range finallyBlocks {
+		if fb.Run {
+			_, err := vm.runInstructions(fn.Name, fb.Instructions, true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if vm.ErrType != nil {
+		err := fmt.Errorf("%s: %v", vm.ErrType, vm.ErrValue.Map["Error"])
+		vm.ErrType = nil
+		vm.ErrValue = nil
+		vm.ErrStack = nil
+
+		return err
+	}
+
+	return nil
+}
+
+// Inspect renders the value of a register in the current scope the same way it
+// would appear in an array or map. That is, strings are quoted.
+func (vm *VM) Inspect(register Register) string {
+	return InspectLiteral(vm.Get(register))
+}
+
+// InspectLiteral returns the same representation of a value as Inspect.
+func InspectLiteral(value *ast.Literal) string {
+	return renderLiteral(value, true)
+}
+
 func (vm *VM) appendStack(stackDescription string, parentScope map[string]*ast.Literal, returnType *types.Type) {
 	registers := map[Register]*ast.Literal{
 		Register(StackRegister): asttest.NewLiteralString(stackDescription),
@@ -234,7 +468,7 @@ func (vm *VM) call(
 func (vm *VM) dumpMemory() {
 	// TODO(elliot): It would be nice to have both of these sorted.
 
-	fmt.Printf("Registers:\n")
+	fmt.Fprintf(vm.Stdout, "Registers:\n")
 
 	var keys []string
 	for key := range vm.Stack[len(vm.Stack)-1] {
@@ -244,13 +478,13 @@ func (vm *VM) dumpMemory() {
 
 	for _, n := range keys {
 		if n == StateRegister {
-			fmt.Printf("  %s: <State>\n", n)
+			fmt.Fprintf(vm.Stdout, "  %s: <State>\n", n)
 		} else {
-			fmt.Printf("  %s: %v\n", n, vm.Stack[len(vm.Stack)-1][Register(n)])
+			fmt.Fprintf(vm.Stdout, "  %s: %v\n", n, vm.Stack[len(vm.Stack)-1][Register(n)])
 		}
 	}
 
-	fmt.Printf("\nState:\n")
+	fmt.Fprintf(vm.Stdout, "\nState:\n")
 
 	keys = nil
 	for key := range vm.Stack[len(vm.Stack)-1][StateRegister].Map {
@@ -259,28 +493,38 @@ func (vm *VM) dumpMemory() {
 	sort.Strings(keys)
 
 	for _, n := range keys {
-		fmt.Printf("  %s: %v\n", n, vm.Stack[len(vm.Stack)-1][StateRegister].Map[n])
+		fmt.Fprintf(vm.Stdout, "  %s: %v\n", n, vm.Stack[len(vm.Stack)-1][StateRegister].Map[n])
 	}
 
-	fmt.Printf("\nStack:\n")
+	fmt.Fprintf(vm.Stdout, "\nStack:\n")
 
 	for _, v := range vm.Stack {
-		fmt.Printf("  %s\n", v[StackRegister].Value)
+		fmt.Fprintf(vm.Stdout, "  %s\n", v[StackRegister].Value)
 	}
 }
 
 func (vm *VM) recoverPanic(funcName string, ins *Instructions, i *int) func() {
 	return func() {
 		if r := recover(); r != nil {
+			// Exiting is not a panic, it needs to reach recoverExit.
+			if _, ok := r.(exitStatus); ok {
+				panic(r)
+			}
+
+			// A timeout must reach runTestInstructions.
+			if _, ok := r.(*timeout); ok {
+				panic(r)
+			}
+
 			// i+1 because the first instruction shown in "ok asm" is #1.
-			fmt.Printf("VM panicked in function %s at instruction #%d: %s\n\n",
+			fmt.Fprintf(vm.Stdout, "VM panicked in function %s at instruction #%d: %s\n\n",
 				funcName, *i+1, ins.Instructions[*i].String())
 			vm.dumpMemory()
 
-			fmt.Println()
-			fmt.Println(r)
-			fmt.Println(string(debug.Stack()))
-			os.Exit(1)
+			fmt.Fprintln(vm.Stdout)
+			fmt.Fprintln(vm.Stdout, r)
+			fmt.Fprintln(vm.Stdout, string(debug.Stack()))
+			vm.exit(1)
 		}
 	}
 }
@@ -293,6 +537,7 @@ func (vm *VM) runInstructions(
 	i := 0
 	defer vm.recoverPanic(funcName, ins, &i)()
 
+	instructions := ins
 	totalInstructions := len(ins.Instructions)
 	for ; i < totalInstructions; i++ {
 		ins := ins.Instructions[i]
@@ -333,11 +578,38 @@ func (vm *VM) runInstructions(
 			continue
 		}
 
+		vm.InstructionsExecuted++
+
+		if vm.instructionLimit > 0 || !vm.deadline.IsZero() {
+			vm.checkLimits(instructions, i)
+		}
+
+		if vm.scheduler != nil && vm.InstructionsExecuted%timeSlice == 0 {
+			vm.scheduler.yield(vm.task)
+		}
+
+		if vm.Coverage != nil {
+			vm.Coverage.record(instructions, i)
+		}
+
+		if vm.Profile != nil {
+			vm.Profile.record(vm, instructions, i)
+		}
+
+		if vm.Debugger != nil {
+			vm.Debugger.BeforeInstruction(vm, instructions, i)
+		}
+
+		hadError := vm.ErrType != nil
 		err := ins.Execute(&i, vm)
 		if err != nil {
 			return nil, err
 		}
 
+		if vm.Debugger != nil && !hadError && vm.ErrType != nil {
+			vm.Debugger.ErrorRaised(vm)
+		}
+
 		if vm.Return != nil && !inFinally {
 			return vm.Return, nil
 		}
@@ -346,22 +618,42 @@ func (vm *VM) runInstructions(
 	return nil, nil
 }
 
-func (vm *VM) printStack() {
-	wd, _ := os.Getwd()
-	reverse(vm.ErrStack)
+func (vm *VM) printStack(w io.Writer) {
+	fmt.Fprintln(w, vm.errorMessage())
+	stack := vm.errorStack()
+	for i, s := range stack {
+		fmt.Fprintln(w, "", "", len(stack)-i, s)
+	}
+}
+
+// errorMessage describes the current error, including its type.
+func (vm *VM) errorMessage() string {
+	return fmt.Sprintf("%s: %v", vm.ErrType, vm.ErrValue.Map["Error"])
+}
 
-	fmt.Printf("%s: %v\n", vm.ErrType, vm.ErrValue.Map["Error"])
-	stackLen := len(vm.ErrStack[:len(vm.ErrStack)-2])
-	for i, s := range vm.ErrStack[:len(vm.ErrStack)-2] {
-		parts := strings.Split(strings.TrimPrefix(s, wd), "|")
-		fmt.Println("", "", stackLen-i, parts[1]+"()", "at", parts[0])
+// errorStack describes where the current error was raised, the innermost
+// function first.
+func (vm *VM) errorStack() []string {
+	return describeStack(vm.ErrStack)
+}
+
+// describeStack describes a stack from captureCallStack, the innermost
+// function first.
+func describeStack(captured []string) []string {
+	wd, _ := os.Getwd()
+	var stack []string
+	for i := len(captured) - 1; i >= 0; i-- {
+		parts := strings.Split(strings.TrimPrefix(captured[i], wd), "|")
+		stack = append(stack, parts[1]+"() at "+parts[0])
 	}
+
+	return stack
 }
 
 func (vm *VM) catchUnhandledError() {
 	if vm.ErrType != nil {
-		vm.printStack()
-		os.Exit(1)
+		vm.printStack(vm.Stdout)
+		vm.exit(1)
 	}
 }
 
@@ -369,30 +661,75 @@ func stackDescription(pos, funcName string) string {
 	return fmt.Sprintf("%s|%s", pos, funcName)
 }
 
+// runTest runs the setup, the test and then the teardown in the same scope.
+// The teardown will run even if the setup or test raised an error. variables
+// are assigned before anything runs, they are the value and key for each case
+// of a table test.
+//
+// A test that runs out of time or instructions is abandoned without running
+// the rest of the test or the teardown, since it may have stopped anywhere. The
+// teardown counts towards the limits of the test.
 func (vm *VM) runTest(
 	test *CompiledTest,
 	parentScope map[string]*ast.Literal,
 	packageName string,
+	variables map[Register]*ast.Literal,
 ) error {
 	vm.CurrentTestName = test.TestName
+	vm.currentTest = test
+
+	// Tasks spawned by the test do not outlive it.
+	defer vm.stopTasks()
+
+	keyword := "test"
+	if test.IsBench {
+		keyword = "bench"
+	}
 
 	stackDesc := stackDescription(test.Pos,
-		fmt.Sprintf("test \"%s\"", test.TestName))
+		fmt.Sprintf("%s \"%s\"", keyword, test.TestName))
+	stackSize, finallySize := len(vm.Stack), len(vm.FinallyBlocks)
 	vm.appendStack(stackDesc, parentScope, types.Any)
-	_, err := vm.runInstructions(test.TestName, test.Instructions, false)
-	if err != nil {
-		return err
+
+	for register, value := range variables {
+		vm.Set(register, value)
 	}
 
-	// The test finished with an unhandled exception?
-	if vm.ErrType != nil {
-		wd, _ := os.Getwd()
-		fmt.Printf("%s: %s: %s: unhandled error\n",
-			packageName, strings.TrimPrefix(test.Pos, wd), vm.CurrentTestName)
-		vm.printStack()
+	if !test.IsBench {
+		vm.startLimits()
+		defer vm.stopLimits()
+	}
 
-		vm.ErrType = nil
-		vm.CurrentTestPassed = false
+	for _, instructions := range []*Instructions{test.Setup, test.Instructions} {
+		if instructions == nil || vm.ErrType != nil {
+			continue
+		}
+
+		t, err := vm.runTestInstructions(test, instructions)
+		if err != nil {
+			return err
+		}
+
+		if t != nil {
+			vm.testTimedOut(test, packageName, t, stackSize, finallySize)
+
+			return nil
+		}
+	}
+	vm.testErrored(test, packageName)
+
+	if test.Teardown != nil {
+		t, err := vm.runTestInstructions(test, test.Teardown)
+		if err != nil {
+			return err
+		}
+
+		if t != nil {
+			vm.testTimedOut(test, packageName, t, stackSize, finallySize)
+
+			return nil
+		}
+		vm.testErrored(test, packageName)
 	}
 
 	vm.catchUnhandledError()
@@ -401,18 +738,72 @@ func (vm *VM) runTest(
 	// handled in Call for functions that are not tests.
 	vm.Stack = vm.Stack[:len(vm.Stack)-1]
 
-	return err
+	return nil
+}
+
+// testErrored reports and clears an unhandled error that was raised by a test,
+// or its setup or teardown.
+func (vm *VM) testErrored(test *CompiledTest, packageName string) {
+	if vm.ErrType == nil {
+		return
+	}
+
+	wd, _ := os.Getwd()
+	fmt.Fprintf(vm.TestOutput, "%s: %s: %s: unhandled error\n",
+		packageName, strings.TrimPrefix(test.Pos, wd), vm.CurrentTestName)
+	vm.printStack(vm.TestOutput)
+
+	if vm.TestReporter != nil && !test.IsBench {
+		vm.TestReporter.TestErrored(test, vm.errorMessage(),
+			vm.errorStack())
+	}
+
+	vm.ErrType = nil
+	vm.CurrentTestPassed = false
 }
 
-func (vm *VM) assert(pass bool, left, op, right, pos string) {
+// assert records the result of an assertion. message is the assertion itself,
+// like "assert(3 == 4)". description is the optional message provided with the
+// assertion. diff is the differences between the expected and actual values,
+// one per line.
+func (vm *VM) assert(
+	pass bool,
+	message, pos, description string,
+	diff []string,
+) {
+	if vm.root != nil {
+		vm.root.assert(pass, message, pos, description, diff)
+
+		return
+	}
+
 	if !pass {
+		// The reporter also receives the description and diff so that it can
+		// explain the failure.
+		detail := ""
+		if description != "" {
+			detail += ": " + description
+		}
+
+		for _, line := range diff {
+			detail += "\n    " + line
+		}
+
 		wd, _ := os.Getwd()
-		fmt.Printf("%s: %s: %s: assert(%s %s %s) failed\n",
-			vm.pkg, strings.TrimPrefix(pos, wd), vm.CurrentTestName,
-			left, op, right)
+		fmt.Fprintf(vm.TestOutput, "%s: %s: %s: %s failed%s\n",
+			vm.pkg, strings.TrimPrefix(pos, wd), vm.CurrentTestName, message,
+			detail)
 		vm.CurrentTestPassed = false
+		message += detail
 	}
 	vm.TotalAssertions++
+
+	// Benchmarks run the same assertions many times, so they are not
+	// reported.
+	if vm.TestReporter != nil && vm.currentTest != nil &&
+		!vm.currentTest.IsBench {
+		vm.TestReporter.Assertion(vm.currentTest, pos, pass, message)
+	}
 }
 
 func isRegister(register Register) bool {
@@ -429,7 +820,7 @@ func (vm *VM) Set(register Register, val *ast.Literal) {
 func (vm *VM) set(register Register, val *ast.Literal, offset int) {
 	switch {
 	case register[0] == '^':
-		parentScope := vm.Stack[len(vm.Stack)-1][StateRegister].Map[StateRegister].Map
+		parentScope := vm.Stack[len(vm.Stack)-offset][StateRegister].Map[StateRegister].Map
 		parentScope[string(register[1:])] = val
 
 	case register[0] == '$':
@@ -446,7 +837,7 @@ func (vm *VM) set(register Register, val *ast.Literal, offset int) {
 // Get will get a register.
 func (vm *VM) get(register Register, offset int) (lit *ast.Literal) {
 	if register[0] == '^' {
-		parentScope := vm.Stack[len(vm.Stack)-1][StateRegister].Map[StateRegister].Map
+		parentScope := vm.Stack[len(vm.Stack)-offset][StateRegister].Map[StateRegister].Map
 
 		return parentScope[string(register[1:])]
 	}
@@ -478,15 +869,6 @@ func (vm *VM) Raise(message string) {
 	}
 }
 
-func (vm *VM) LoadPackage(packageName string) error {
-	file, err := Load(packageName)
-	if err != nil {
-		return err
-	}
-
-	return vm.LoadFile(file)
-}
-
 func (vm *VM) LoadFile(file *File) error {
 	for k := range file.Types {
 		vm.Types[TypeRegister(k)] = file.Types.Get(k)
@@ -507,14 +889,23 @@ func (vm *VM) LoadFile(file *File) error {
 }
 
 func (vm *VM) captureCallStack(pos string) []string {
+	// The packages that have been initialized are left on the stack, but they
+	// are not the callers of anything that runs after them.
+	var frames []map[Register]*ast.Literal
+	for _, frame := range vm.Stack {
+		if !vm.packageFrames[frame[StackRegister]] {
+			frames = append(frames, frame)
+		}
+	}
+
 	var captured []string
-	for i, stack := range vm.Stack[1:] {
+	for i, stack := range frames[1:] {
 		a := strings.Split(stack[StackRegister].Value, "|")
-		b := strings.Split(vm.Stack[i][StackRegister].Value, "|")
+		b := strings.Split(frames[i][StackRegister].Value, "|")
 		captured = append(captured, fmt.Sprintf("%s|%s", a[0], b[1]))
 	}
 
-	a := strings.Split(vm.Stack[len(vm.Stack)-1][StackRegister].Value, "|")
+	a := strings.Split(frames[len(frames)-1][StackRegister].Value, "|")
 	captured = append(captured, fmt.Sprintf("%s|%s", pos, a[1]))
 
 	return captured
@@ -0,0 +1,67 @@
+// Package watch runs a command again each time the source files that it uses
+// change.
+//
+// Changes are found by polling the modification times and sizes of the files
+// so that it works on any filesystem, without needing support from the
+// operating system.
+package watch
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/elliotchance/ok/util"
+)
+
+// DefaultInterval is how often the files are checked for changes.
+const DefaultInterval = 500 * time.Millisecond
+
+// clearScreen moves the cursor to the top left and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// Watcher reruns a command when any ".ok" or ".okt" file changes in the
+// directories that it uses.
+type Watcher struct {
+	// Interval is how often to check for changes.
+	Interval time.Duration
+
+	// Out is where the screen is cleared before each run.
+	Out io.Writer
+}
+
+// Run calls run, then waits for a file to change in any of the directories
+// that were returned by run before calling it again. It never returns.
+func (w *Watcher) Run(run func() (dirs []string)) {
+	for i := 0; ; i++ {
+		if i > 0 {
+			fmt.Fprint(w.Out, clearScreen)
+		}
+
+		dirs := run()
+		w.wait(dirs)
+	}
+}
+
+// wait blocks until there is a change in any of the directories.
+func (w *Watcher) wait(dirs []string) {
+	before := snapshot(dirs)
+	for {
+		time.Sleep(w.Interval)
+
+		if snapshot(dirs) != before {
+			return
+		}
+	}
+}
+
+// snapshot describes all of the source files in the directories. It will be
+// different if any file is added, removed or modified.
+func snapshot(dirs []string) string {
+	s := ""
+	for _, dir := range dirs {
+		s += util.SourceFingerprint(dir, true) + "\n"
+	}
+
+	return s
+}
@@ -0,0 +1,41 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	for testName, test := range map[string]struct {
+		fileName string
+		changed  bool
+	}{
+		"source":   {"a.ok", true},
+		"test":     {"a.okt", true},
+		"new-file": {"b.ok", true},
+		"other":    {"README.md", false},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "ok-watch-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			for _, fileName := range []string{"a.ok", "a.okt", "README.md"} {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, fileName),
+					[]byte("foo"), 0644))
+			}
+
+			before := snapshot([]string{dir})
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, test.fileName),
+				[]byte("foo bar"), 0644))
+			after := snapshot([]string{dir})
+
+			assert.Equal(t, test.changed, before != after)
+		})
+	}
+}